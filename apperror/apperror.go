@@ -0,0 +1,86 @@
+// Package apperror berisi error domain yang dipakai bersama oleh repository, service, handler dan middleware.
+// Setiap error punya jenis (ErrValidation, ErrNotFound, ...) yang menentukan status HTTP di middleware.ErrorHandler,
+// dan kode yang stabil supaya client tidak perlu mencocokkan isi pesan error
+package apperror
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Jenis error. Cek dengan errors.Is(err, apperror.ErrNotFound)
+var (
+	ErrValidation   = errors.New("validation failed")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	// Request ditolak karena melewati batas rate limit atau akun sedang dikunci
+	ErrTooManyRequests = errors.New("too many requests")
+)
+
+// Error adalah error domain dengan jenis, kode dan pesan yang boleh ditampilkan ke client
+type Error struct {
+	// Salah satu jenis error di atas
+	Kind error
+	// Kode singkat seperti "reservation_not_found", dipakai client untuk membedakan error
+	Code    string
+	Message string
+	// Detail per field untuk error validasi, misalnya {"Email": "required"}
+	Fields map[string]string
+}
+
+func New(kind error, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func Validation(code, message string) *Error {
+	return New(ErrValidation, code, message)
+}
+
+func Unauthorized(code, message string) *Error {
+	return New(ErrUnauthorized, code, message)
+}
+
+func Forbidden(code, message string) *Error {
+	return New(ErrForbidden, code, message)
+}
+
+func NotFound(code, message string) *Error {
+	return New(ErrNotFound, code, message)
+}
+
+func Conflict(code, message string) *Error {
+	return New(ErrConflict, code, message)
+}
+
+func TooManyRequests(code, message string) *Error {
+	return New(ErrTooManyRequests, code, message)
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is membuat errors.Is cocok dengan jenis error dan dengan *Error lain yang kodenya sama,
+// sehingga error dari Withf tetap cocok dengan sentinel asalnya
+func (e *Error) Is(target error) bool {
+	if other, ok := target.(*Error); ok {
+		return e.Code == other.Code
+	}
+	return target == e.Kind
+}
+
+// Withf mengembalikan salinan error dengan pesan yang lebih spesifik, misalnya berisi id yang tidak ditemukan
+func (e *Error) Withf(format string, args ...interface{}) *Error {
+	copied := *e
+	copied.Message = fmt.Sprintf(format, args...)
+	return &copied
+}
+
+// WithFields mengembalikan salinan error dengan detail per field
+func (e *Error) WithFields(fields map[string]string) *Error {
+	copied := *e
+	copied.Fields = fields
+	return &copied
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"wereserve/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+var remindersCMD = &cobra.Command{
+	Use: "reminders",
+	Short: "send reservation reminders",
+	Long: `run the reservation reminder scheduler without the HTTP server`,
+	Run: func(cmd *cobra.Command, args []string) {
+		app.RunReminders()
+	},
+}
+
+func init() {
+	rootCMD.AddCommand(remindersCMD)
+}
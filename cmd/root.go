@@ -38,6 +38,6 @@ func initConfig(){
 	viper.AutomaticEnv()
 
 	if err := viper.ReadInConfig(); err != nil {
-		fmt.Println(os.Stdout, "using config file:", viper.ConfigFileUsed())
+		fmt.Fprintln(os.Stdout, "using config file:", viper.ConfigFileUsed())
 	}
 }
\ No newline at end of file
@@ -1,6 +1,12 @@
 package config
 
-import "github.com/spf13/viper"
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
 
 type App struct {
 	AppPort string	`json:"app_port"`
@@ -8,6 +14,8 @@ type App struct {
 
 	JwtSecretKey string	`json:"jwt_secret_key"`
 	JwtIssuer	string	`json:"jwt_issuer"`
+	// Masa berlaku (jam) refresh token sebelum user harus login ulang
+	JwtRefreshTokenHours	int64	`json:"jwt_refresh_token_hours"`
 }
 
 type PsqlDB	struct {
@@ -21,18 +29,279 @@ type PsqlDB	struct {
 	DBMaxIdle	int64	`json:"db_max_idle"`
 }
 
+type Reservation struct {
+	// Batas waktu (menit) sebelum reservasi dimulai di mana customer tidak bisa lagi membatalkan atau mengubah jadwal
+	CutoffMinutes	int64	`json:"cutoff_minutes"`
+}
+
+type Waitlist struct {
+	// Lama waktu (menit) customer di waitlist untuk menerima meja yang ditawarkan sebelum ditawarkan ke customer berikutnya
+	OfferMinutes	int64	`json:"offer_minutes"`
+}
+
+type PasswordReset struct {
+	// Masa berlaku (menit) link reset password yang dikirim lewat email
+	TokenMinutes	int64	`json:"token_minutes"`
+	// Halaman frontend untuk membuat password baru, token ditambahkan sebagai query ?token=
+	URL	string	`json:"url"`
+}
+
+type EmailVerification struct {
+	// Masa berlaku (jam) link verifikasi email
+	TokenHours	int64	`json:"token_hours"`
+	// Endpoint verifikasi email, token ditambahkan sebagai query ?token=
+	URL	string	`json:"url"`
+	// Akun yang belum diverifikasi setelah sekian jam dihapus otomatis
+	RetentionHours	int64	`json:"retention_hours"`
+}
+
+type Mail struct {
+	// smtp, file atau memory. file menyimpan email sebagai file .html di FileDir untuk development
+	Driver	string	`json:"driver"`
+	FileDir	string	`json:"file_dir"`
+
+	SMTPHost	string	`json:"smtp_host"`
+	SMTPPort	int	`json:"smtp_port"`
+	SenderName	string	`json:"sender_name"`
+	AuthEmail	string	`json:"auth_email"`
+	AuthPassword	string	`json:"auth_password"`
+}
+
+type Outbox struct {
+	// Interval (detik) worker memeriksa outbox
+	PollSeconds	int64	`json:"poll_seconds"`
+	// Jumlah percobaan sebelum notifikasi dipindahkan ke dead letter
+	MaxAttempts	int	`json:"max_attempts"`
+	// Jeda (detik) sebelum percobaan kedua, berlipat dua setiap kali gagal sampai BackoffMaxSeconds
+	BackoffSeconds	int64	`json:"backoff_seconds"`
+	BackoffMaxSeconds	int64	`json:"backoff_max_seconds"`
+	BatchSize	int	`json:"batch_size"`
+}
+
+type Reminder struct {
+	// Daftar jarak pengingat sebelum reservasi dipisahkan koma, dalam format durasi Go. Contoh: 24h,2h
+	Offsets	string	`json:"offsets"`
+	// Interval (detik) scheduler memeriksa reservasi yang perlu diingatkan
+	PollSeconds	int64	`json:"poll_seconds"`
+	// Endpoint untuk link konfirmasi dan pembatalan di email pengingat, token ditambahkan sebagai query ?token=
+	ActionURL	string	`json:"action_url"`
+}
+
+type Calendar struct {
+	// Base URL API untuk link feed kalender, contoh: http://localhost:8080/api
+	FeedBaseURL	string	`json:"feed_base_url"`
+}
+
+type Webhook struct {
+	// Interval (detik) worker memeriksa webhook yang perlu dikirim
+	PollSeconds	int64	`json:"poll_seconds"`
+	// Jumlah percobaan sebelum pengiriman webhook berstatus dead
+	MaxAttempts	int	`json:"max_attempts"`
+	// Jeda (detik) sebelum percobaan kedua, berlipat dua setiap kali gagal sampai BackoffMaxSeconds
+	BackoffSeconds	int64	`json:"backoff_seconds"`
+	BackoffMaxSeconds	int64	`json:"backoff_max_seconds"`
+	BatchSize	int	`json:"batch_size"`
+	// Batas waktu (detik) menunggu response dari penerima webhook
+	TimeoutSeconds	int64	`json:"timeout_seconds"`
+	// Mengizinkan penerima webhook di alamat private atau loopback, hanya untuk development
+	AllowPrivateTargets	bool	`json:"allow_private_targets"`
+}
+
+type PublicBooking struct {
+	// Halaman frontend untuk tamu tanpa akun melihat, mengubah atau membatalkan reservasinya, token ditambahkan sebagai query ?token=
+	ManageURL	string	`json:"manage_url"`
+	// Jumlah maksimal reservasi aktif yang akan datang untuk satu email atau nomor telepon tamu
+	MaxActivePerContact	int	`json:"max_active_per_contact"`
+	// Jumlah orang maksimal untuk satu reservasi tamu, rombongan yang lebih besar harus menghubungi restoran
+	MaxPartySize	int	`json:"max_party_size"`
+	// Masa berlaku link kelola reservasi, tamu bisa meminta link baru yang menggantikan link lama
+	ManageLinkHours	int64	`json:"manage_link_hours"`
+}
+
+type RateLimit struct {
+	// Alamat atau CIDR proxy yang boleh mengirim X-Forwarded-For, dipisahkan koma. Kosong berarti IP diambil dari koneksi
+	TrustedProxies	string	`json:"trusted_proxies"`
+	// Batas request per menit untuk login, register dan reset password, per alamat IP dan per email
+	AuthIPPerMinute	int	`json:"auth_ip_per_minute"`
+	AuthAccountPerMinute	int	`json:"auth_account_per_minute"`
+	// Batas request per menit per alamat IP untuk endpoint publik lain seperti reservasi tamu
+	PublicIPPerMinute	int	`json:"public_ip_per_minute"`
+	// Batas request per menit per user untuk endpoint yang memerlukan login
+	APIAccountPerMinute	int	`json:"api_account_per_minute"`
+	// Jumlah login gagal berturut-turut sebelum akun dikunci
+	LockoutThreshold	int	`json:"lockout_threshold"`
+	// Jumlah login gagal dari satu alamat IP sebelum IP tersebut dikunci, dibuat lebih tinggi karena IP bisa dipakai bersama
+	LockoutIPThreshold	int	`json:"lockout_ip_threshold"`
+	// Lama lockout pertama (detik), berlipat dua setiap kegagalan berikutnya sampai LockoutMaxSeconds
+	LockoutSeconds	int64	`json:"lockout_seconds"`
+	LockoutMaxSeconds	int64	`json:"lockout_max_seconds"`
+	// Hitungan login gagal direset setelah sekian menit tanpa kegagalan
+	LockoutWindowMinutes	int64	`json:"lockout_window_minutes"`
+}
+
 type Config struct {
 	App App
 	Psql PsqlDB
+	Reservation Reservation
+	Waitlist Waitlist
+	PasswordReset PasswordReset
+	EmailVerification EmailVerification
+	Mail Mail
+	Outbox Outbox
+	Reminder Reminder
+	Calendar Calendar
+	Webhook Webhook
+	PublicBooking PublicBooking
+	RateLimit RateLimit
+}
+
+func (a App) RefreshTokenTTL() time.Duration {
+	return time.Duration(a.JwtRefreshTokenHours) * time.Hour
+}
+
+func (r Reservation) Cutoff() time.Duration {
+	return time.Duration(r.CutoffMinutes) * time.Minute
+}
+
+func (w Waitlist) OfferTTL() time.Duration {
+	return time.Duration(w.OfferMinutes) * time.Minute
+}
+
+func (p PasswordReset) TokenTTL() time.Duration {
+	return time.Duration(p.TokenMinutes) * time.Minute
+}
+
+func (e EmailVerification) TokenTTL() time.Duration {
+	return time.Duration(e.TokenHours) * time.Hour
+}
+
+func (e EmailVerification) Retention() time.Duration {
+	return time.Duration(e.RetentionHours) * time.Hour
+}
+
+func (p PublicBooking) ManageLinkTTL() time.Duration {
+	return time.Duration(p.ManageLinkHours) * time.Hour
+}
+
+func (o Outbox) PollInterval() time.Duration {
+	return time.Duration(o.PollSeconds) * time.Second
+}
+
+func (o Outbox) Backoff() time.Duration {
+	return time.Duration(o.BackoffSeconds) * time.Second
+}
+
+func (o Outbox) BackoffMax() time.Duration {
+	return time.Duration(o.BackoffMaxSeconds) * time.Second
+}
+
+func (w Webhook) PollInterval() time.Duration {
+	return time.Duration(w.PollSeconds) * time.Second
+}
+
+func (w Webhook) Backoff() time.Duration {
+	return time.Duration(w.BackoffSeconds) * time.Second
+}
+
+func (w Webhook) BackoffMax() time.Duration {
+	return time.Duration(w.BackoffMaxSeconds) * time.Second
+}
+
+func (w Webhook) Timeout() time.Duration {
+	return time.Duration(w.TimeoutSeconds) * time.Second
+}
+
+// TrustedProxyList mengurai RATE_LIMIT_TRUSTED_PROXIES, nil berarti tidak ada proxy yang dipercaya
+func (r RateLimit) TrustedProxyList() []string {
+	var proxies []string
+	for _, part := range strings.Split(r.TrustedProxies, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			proxies = append(proxies, part)
+		}
+	}
+	return proxies
+}
+
+func (r RateLimit) Lockout() time.Duration {
+	return time.Duration(r.LockoutSeconds) * time.Second
+}
+
+func (r RateLimit) LockoutMax() time.Duration {
+	return time.Duration(r.LockoutMaxSeconds) * time.Second
+}
+
+func (r RateLimit) LockoutWindow() time.Duration {
+	return time.Duration(r.LockoutWindowMinutes) * time.Minute
+}
+
+func (r Reminder) PollInterval() time.Duration {
+	return time.Duration(r.PollSeconds) * time.Second
+}
+
+// OffsetDurations mengurai REMINDER_OFFSETS menjadi daftar durasi
+func (r Reminder) OffsetDurations() ([]time.Duration, error) {
+	var offsets []time.Duration
+	for _, part := range strings.Split(r.Offsets, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		offset, err := time.ParseDuration(part)
+		if err != nil || offset < time.Minute {
+			return nil, fmt.Errorf("invalid reminder offset %q: must be a duration of at least 1m", part)
+		}
+		offsets = append(offsets, offset)
+	}
+	return offsets, nil
 }
 
 func NewConfig() *Config{
+	viper.SetDefault("RESERVATION_CUTOFF_MINUTES", 120)
+	viper.SetDefault("WAITLIST_OFFER_MINUTES", 30)
+	viper.SetDefault("JWT_REFRESH_TOKEN_HOURS", 720)
+	viper.SetDefault("PASSWORD_RESET_TOKEN_MINUTES", 30)
+	viper.SetDefault("PASSWORD_RESET_URL", "http://localhost:3000/reset-password")
+	viper.SetDefault("EMAIL_VERIFICATION_TOKEN_HOURS", 24)
+	viper.SetDefault("EMAIL_VERIFICATION_URL", "http://localhost:8080/api/email/verify")
+	viper.SetDefault("UNVERIFIED_ACCOUNT_RETENTION_HOURS", 72)
+	viper.SetDefault("MAIL_DRIVER", "smtp")
+	viper.SetDefault("MAIL_FILE_DIR", "tmp/mail")
+	viper.SetDefault("OUTBOX_POLL_SECONDS", 5)
+	viper.SetDefault("OUTBOX_MAX_ATTEMPTS", 8)
+	viper.SetDefault("OUTBOX_BACKOFF_SECONDS", 30)
+	viper.SetDefault("OUTBOX_BACKOFF_MAX_SECONDS", 3600)
+	viper.SetDefault("OUTBOX_BATCH_SIZE", 20)
+	viper.SetDefault("REMINDER_OFFSETS", "24h,2h")
+	viper.SetDefault("REMINDER_POLL_SECONDS", 60)
+	viper.SetDefault("RESERVATION_ACTION_URL", "http://localhost:8080/api/reservation/respond")
+	viper.SetDefault("CALENDAR_FEED_BASE_URL", "http://localhost:8080/api")
+	viper.SetDefault("WEBHOOK_POLL_SECONDS", 5)
+	viper.SetDefault("WEBHOOK_MAX_ATTEMPTS", 10)
+	viper.SetDefault("WEBHOOK_BACKOFF_SECONDS", 30)
+	viper.SetDefault("WEBHOOK_BACKOFF_MAX_SECONDS", 3600)
+	viper.SetDefault("WEBHOOK_BATCH_SIZE", 20)
+	viper.SetDefault("WEBHOOK_TIMEOUT_SECONDS", 10)
+	viper.SetDefault("PUBLIC_BOOKING_MANAGE_URL", "http://localhost:3000/reservations/manage")
+	viper.SetDefault("PUBLIC_BOOKING_MAX_ACTIVE_PER_CONTACT", 3)
+	viper.SetDefault("PUBLIC_BOOKING_MAX_PARTY_SIZE", 8)
+	viper.SetDefault("PUBLIC_BOOKING_MANAGE_LINK_HOURS", 720)
+	viper.SetDefault("RATE_LIMIT_AUTH_IP_PER_MINUTE", 20)
+	viper.SetDefault("RATE_LIMIT_AUTH_ACCOUNT_PER_MINUTE", 5)
+	viper.SetDefault("RATE_LIMIT_PUBLIC_IP_PER_MINUTE", 60)
+	viper.SetDefault("RATE_LIMIT_API_ACCOUNT_PER_MINUTE", 300)
+	viper.SetDefault("LOGIN_LOCKOUT_THRESHOLD", 5)
+	viper.SetDefault("LOGIN_LOCKOUT_IP_THRESHOLD", 20)
+	viper.SetDefault("LOGIN_LOCKOUT_SECONDS", 60)
+	viper.SetDefault("LOGIN_LOCKOUT_MAX_SECONDS", 3600)
+	viper.SetDefault("LOGIN_LOCKOUT_WINDOW_MINUTES", 15)
+
 	return &Config{
 		App:  App{
 			AppPort:      viper.GetString("APP_ENV"),
 			AppEnv:       viper.GetString("APP_PORT"),
 			JwtSecretKey: viper.GetString("JWT_SECRET_KEY"),
 			JwtIssuer:    viper.GetString("JWT-ISSUER"),
+			JwtRefreshTokenHours: viper.GetInt64("JWT_REFRESH_TOKEN_HOURS"),
 		},
 		Psql: PsqlDB{
 			Host:      viper.GetString("DATABASE_HOST"),
@@ -44,5 +313,71 @@ func NewConfig() *Config{
 			DBMaxOpen: viper.GetInt64("DATABASE_MAX_OPEN_CONNECTION"),
 			DBMaxIdle: viper.GetInt64("DATABASE_MAX_IDLE_CONNECTION"),
 		},
+		Reservation: Reservation{
+			CutoffMinutes: viper.GetInt64("RESERVATION_CUTOFF_MINUTES"),
+		},
+		Waitlist: Waitlist{
+			OfferMinutes: viper.GetInt64("WAITLIST_OFFER_MINUTES"),
+		},
+		PasswordReset: PasswordReset{
+			TokenMinutes: viper.GetInt64("PASSWORD_RESET_TOKEN_MINUTES"),
+			URL:          viper.GetString("PASSWORD_RESET_URL"),
+		},
+		EmailVerification: EmailVerification{
+			TokenHours:     viper.GetInt64("EMAIL_VERIFICATION_TOKEN_HOURS"),
+			URL:            viper.GetString("EMAIL_VERIFICATION_URL"),
+			RetentionHours: viper.GetInt64("UNVERIFIED_ACCOUNT_RETENTION_HOURS"),
+		},
+		Mail: Mail{
+			Driver:       viper.GetString("MAIL_DRIVER"),
+			FileDir:      viper.GetString("MAIL_FILE_DIR"),
+			SMTPHost:     viper.GetString("CONFIG_SMTP_HOST"),
+			SMTPPort:     viper.GetInt("CONFIG_SMTP_PORT"),
+			SenderName:   viper.GetString("CONFIG_SENDER_NAME"),
+			AuthEmail:    viper.GetString("CONFIG_AUTH_EMAIL"),
+			AuthPassword: viper.GetString("CONFIG_AUTH_PASSWORD"),
+		},
+		Outbox: Outbox{
+			PollSeconds:       viper.GetInt64("OUTBOX_POLL_SECONDS"),
+			MaxAttempts:       viper.GetInt("OUTBOX_MAX_ATTEMPTS"),
+			BackoffSeconds:    viper.GetInt64("OUTBOX_BACKOFF_SECONDS"),
+			BackoffMaxSeconds: viper.GetInt64("OUTBOX_BACKOFF_MAX_SECONDS"),
+			BatchSize:         viper.GetInt("OUTBOX_BATCH_SIZE"),
+		},
+		Reminder: Reminder{
+			Offsets:     viper.GetString("REMINDER_OFFSETS"),
+			PollSeconds: viper.GetInt64("REMINDER_POLL_SECONDS"),
+			ActionURL:   viper.GetString("RESERVATION_ACTION_URL"),
+		},
+		Calendar: Calendar{
+			FeedBaseURL: viper.GetString("CALENDAR_FEED_BASE_URL"),
+		},
+		Webhook: Webhook{
+			PollSeconds:         viper.GetInt64("WEBHOOK_POLL_SECONDS"),
+			MaxAttempts:         viper.GetInt("WEBHOOK_MAX_ATTEMPTS"),
+			BackoffSeconds:      viper.GetInt64("WEBHOOK_BACKOFF_SECONDS"),
+			BackoffMaxSeconds:   viper.GetInt64("WEBHOOK_BACKOFF_MAX_SECONDS"),
+			BatchSize:           viper.GetInt("WEBHOOK_BATCH_SIZE"),
+			TimeoutSeconds:      viper.GetInt64("WEBHOOK_TIMEOUT_SECONDS"),
+			AllowPrivateTargets: viper.GetBool("WEBHOOK_ALLOW_PRIVATE_TARGETS"),
+		},
+		PublicBooking: PublicBooking{
+			ManageURL:           viper.GetString("PUBLIC_BOOKING_MANAGE_URL"),
+			MaxActivePerContact: viper.GetInt("PUBLIC_BOOKING_MAX_ACTIVE_PER_CONTACT"),
+			MaxPartySize:        viper.GetInt("PUBLIC_BOOKING_MAX_PARTY_SIZE"),
+			ManageLinkHours:     viper.GetInt64("PUBLIC_BOOKING_MANAGE_LINK_HOURS"),
+		},
+		RateLimit: RateLimit{
+			TrustedProxies:       viper.GetString("RATE_LIMIT_TRUSTED_PROXIES"),
+			AuthIPPerMinute:      viper.GetInt("RATE_LIMIT_AUTH_IP_PER_MINUTE"),
+			AuthAccountPerMinute: viper.GetInt("RATE_LIMIT_AUTH_ACCOUNT_PER_MINUTE"),
+			PublicIPPerMinute:    viper.GetInt("RATE_LIMIT_PUBLIC_IP_PER_MINUTE"),
+			APIAccountPerMinute:  viper.GetInt("RATE_LIMIT_API_ACCOUNT_PER_MINUTE"),
+			LockoutThreshold:     viper.GetInt("LOGIN_LOCKOUT_THRESHOLD"),
+			LockoutIPThreshold:   viper.GetInt("LOGIN_LOCKOUT_IP_THRESHOLD"),
+			LockoutSeconds:       viper.GetInt64("LOGIN_LOCKOUT_SECONDS"),
+			LockoutMaxSeconds:    viper.GetInt64("LOGIN_LOCKOUT_MAX_SECONDS"),
+			LockoutWindowMinutes: viper.GetInt64("LOGIN_LOCKOUT_WINDOW_MINUTES"),
+		},
 	}
 }
\ No newline at end of file
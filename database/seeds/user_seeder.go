@@ -1,6 +1,7 @@
 package seeds
 
 import (
+	"time"
 	"wereserve/models"
 
 	"github.com/rs/zerolog/log"
@@ -14,11 +15,14 @@ func SeedAdmin(db *gorm.DB) {
 		log.Fatal().Err(err).Msg("Error creating password hash")
 	}
 
+	// Akun admin hasil seed langsung dianggap terverifikasi
+	verifiedAt := time.Now()
 	admin :=  models.User{
 		Name: "Admin",
 		Email: "admin@wereserve.com",
 		Password: string(bytes),
 		Role: "admin",
+		EmailVerifiedAt: &verifiedAt,
 	}
 
 	if err := db.FirstOrCreate(&admin, models.User{Email: "admin@wereserve.com"}).Error; err != nil {
@@ -0,0 +1,20 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+-- Akun baru belum terverifikasi (NULL) sampai user membuka link verifikasi dari email
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP;
+
+-- Akun yang sudah ada sebelum fitur ini dianggap sudah terverifikasi
+UPDATE users SET email_verified_at = COALESCE(created_at, CURRENT_TIMESTAMP);
+
+CREATE INDEX idx_users_unverified ON users(created_at) WHERE email_verified_at IS NULL;
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+
+DROP INDEX IF EXISTS idx_users_unverified;
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
+
+-- +migrate StatementEnd
@@ -0,0 +1,33 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+CREATE TYPE outbox_status AS ENUM ('pending', 'sent', 'dead');
+
+-- Notifikasi ditulis ke outbox di transaksi yang sama dengan perubahan data, lalu dikirim oleh worker.
+-- Pesan yang gagal dicoba ulang pada next_attempt_at, dan berstatus dead setelah melewati batas percobaan
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id SERIAL PRIMARY KEY,
+    event_type VARCHAR(50) NOT NULL,
+    recipient VARCHAR(255) NOT NULL,
+    payload JSONB NOT NULL,
+    status outbox_status NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    sent_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_outbox_messages_due ON outbox_messages(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_outbox_messages_status ON outbox_messages(status);
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+
+DROP TABLE IF EXISTS outbox_messages;
+DROP TYPE IF EXISTS outbox_status;
+
+-- +migrate StatementEnd
@@ -0,0 +1,21 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+-- Pengingat yang sudah dikirim per reservasi dan per offset (menit sebelum reservation_datetime),
+-- supaya scheduler tidak mengirim pengingat yang sama lagi setelah restart
+CREATE TABLE IF NOT EXISTS reservation_reminders (
+    id SERIAL PRIMARY KEY,
+    reservation_id INT NOT NULL REFERENCES reservations(id) ON DELETE CASCADE,
+    offset_minutes INT NOT NULL CHECK (offset_minutes > 0),
+    sent_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (reservation_id, offset_minutes)
+);
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+
+DROP TABLE IF EXISTS reservation_reminders;
+
+-- +migrate StatementEnd
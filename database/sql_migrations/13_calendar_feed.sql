@@ -0,0 +1,14 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+-- Hash SHA-256 dari token feed kalender (.ics) user. NULL jika user belum pernah membuat link feed
+ALTER TABLE users ADD COLUMN calendar_token_hash VARCHAR(64) UNIQUE;
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+
+ALTER TABLE users DROP COLUMN IF EXISTS calendar_token_hash;
+
+-- +migrate StatementEnd
@@ -0,0 +1,86 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+-- Role tidak lagi berupa enum supaya admin bisa menambah role baru lewat API
+CREATE TABLE IF NOT EXISTS roles (
+    name VARCHAR(50) PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Daftar permission yang dimiliki setiap role, contoh: reservation:update, table:write
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_name VARCHAR(50) NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+    permission VARCHAR(50) NOT NULL,
+    PRIMARY KEY (role_name, permission)
+);
+
+INSERT INTO roles (name, description) VALUES
+    ('admin', 'Full access, including users, roles, outbox and webhooks'),
+    ('manager', 'Manages tables, schedule and every reservation'),
+    ('host', 'Seats and cancels reservations and manages the waitlist'),
+    ('staff', 'Seats and cancels reservations'),
+    ('customer', 'Books tables for themselves');
+
+INSERT INTO role_permissions (role_name, permission) VALUES
+    ('admin', 'user:read'),
+    ('admin', 'user:write'),
+    ('admin', 'user:delete'),
+    ('admin', 'role:manage'),
+    ('admin', 'table:read'),
+    ('admin', 'table:write'),
+    ('admin', 'schedule:write'),
+    ('admin', 'reservation:create'),
+    ('admin', 'reservation:read'),
+    ('admin', 'reservation:update'),
+    ('admin', 'reservation:cancel'),
+    ('admin', 'waitlist:manage'),
+    ('admin', 'outbox:manage'),
+    ('admin', 'webhook:manage'),
+    ('manager', 'user:read'),
+    ('manager', 'table:read'),
+    ('manager', 'table:write'),
+    ('manager', 'schedule:write'),
+    ('manager', 'reservation:create'),
+    ('manager', 'reservation:read'),
+    ('manager', 'reservation:update'),
+    ('manager', 'reservation:cancel'),
+    ('manager', 'waitlist:manage'),
+    ('host', 'table:read'),
+    ('host', 'reservation:create'),
+    ('host', 'reservation:read'),
+    ('host', 'reservation:update'),
+    ('host', 'reservation:cancel'),
+    ('host', 'waitlist:manage'),
+    ('staff', 'table:read'),
+    ('staff', 'reservation:create'),
+    ('staff', 'reservation:read'),
+    ('staff', 'reservation:update'),
+    ('staff', 'reservation:cancel'),
+    ('customer', 'table:read'),
+    ('customer', 'reservation:create');
+
+ALTER TABLE users ALTER COLUMN role DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN role TYPE VARCHAR(50) USING role::text;
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'customer';
+ALTER TABLE users ADD CONSTRAINT fk_users_role FOREIGN KEY (role) REFERENCES roles(name);
+DROP TYPE IF EXISTS user_role;
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+
+-- Enum lama hanya mengenal admin dan customer, user dengan role lain dikembalikan menjadi customer
+ALTER TABLE users DROP CONSTRAINT IF EXISTS fk_users_role;
+UPDATE users SET role = 'customer' WHERE role NOT IN ('admin', 'customer');
+CREATE TYPE user_role AS ENUM ('admin', 'customer');
+ALTER TABLE users ALTER COLUMN role DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN role TYPE user_role USING role::user_role;
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'customer';
+
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS roles;
+
+-- +migrate StatementEnd
\ No newline at end of file
@@ -0,0 +1,27 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+-- Kontak tamu tanpa akun yang dipesankan oleh staff. Minimal nomor telepon atau email harus diisi
+CREATE TABLE IF NOT EXISTS guest_contacts (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    phone VARCHAR(30) NOT NULL DEFAULT '',
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (phone <> '' OR email <> '')
+);
+
+-- Reservasi dimiliki oleh user atau oleh kontak tamu
+ALTER TABLE reservations ADD COLUMN guest_contact_id INT REFERENCES guest_contacts(id) ON DELETE SET NULL;
+CREATE INDEX idx_reservations_guest_contact ON reservations(guest_contact_id);
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+
+ALTER TABLE reservations DROP COLUMN IF EXISTS guest_contact_id;
+DROP TABLE IF EXISTS guest_contacts;
+
+-- +migrate StatementEnd
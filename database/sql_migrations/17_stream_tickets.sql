@@ -0,0 +1,23 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+-- Tiket stream disimpan dalam bentuk hash SHA-256, hanya bisa dipakai sekali (used_at) dan sampai expires_at
+CREATE TABLE IF NOT EXISTS stream_tickets (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    ticket_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_stream_tickets_expires ON stream_tickets(expires_at);
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+
+DROP TABLE IF EXISTS stream_tickets;
+
+-- +migrate StatementEnd
@@ -0,0 +1,22 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+-- Token magic link reservasi tamu disimpan dalam bentuk hash SHA-256. Satu reservasi hanya punya satu token,
+-- token diganti saat link dikirim ulang sehingga link lama langsung tidak berlaku
+CREATE TABLE IF NOT EXISTS reservation_manage_tokens (
+    id SERIAL PRIMARY KEY,
+    reservation_id INT NOT NULL UNIQUE REFERENCES reservations(id) ON DELETE CASCADE,
+    guest_contact_id INT NOT NULL REFERENCES guest_contacts(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+
+DROP TABLE IF EXISTS reservation_manage_tokens;
+
+-- +migrate StatementEnd
@@ -0,0 +1,17 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+-- Reservasi dimiliki oleh user atau oleh kontak tamu, tidak keduanya.
+-- Reservasi lama yang sudah dipindahkan ke user dilepas dari kontak tamunya sebelum constraint dipasang
+UPDATE reservations SET guest_contact_id = NULL WHERE user_id IS NOT NULL AND guest_contact_id IS NOT NULL;
+
+ALTER TABLE reservations ADD CONSTRAINT reservations_single_owner CHECK (user_id IS NULL OR guest_contact_id IS NULL);
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+
+ALTER TABLE reservations DROP CONSTRAINT IF EXISTS reservations_single_owner;
+
+-- +migrate StatementEnd
@@ -0,0 +1,32 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+-- Link berisi token (kelola reservasi, reset password, verifikasi email, pengingat) tidak disimpan lagi
+-- setelah pesan terkirim atau mati. Pesan lama yang sudah selesai disamakan dengan perilaku worker
+UPDATE outbox_messages SET payload = jsonb_set(payload, '{ManageLink}', '"[redacted]"')
+WHERE status IN ('sent', 'dead')
+    AND event_type IN ('reservation_created', 'reservation_manage_link')
+    AND COALESCE(payload->>'ManageLink', '') <> '';
+
+UPDATE outbox_messages SET payload = jsonb_set(payload, '{Link}', '"[redacted]"')
+WHERE status IN ('sent', 'dead')
+    AND event_type IN ('password_reset', 'email_verification');
+
+UPDATE outbox_messages SET payload = jsonb_set(payload, '{ConfirmLink}', '"[redacted]"')
+WHERE status IN ('sent', 'dead')
+    AND event_type = 'reservation_reminder';
+
+UPDATE outbox_messages SET payload = jsonb_set(payload, '{CancelLink}', '"[redacted]"')
+WHERE status IN ('sent', 'dead')
+    AND event_type = 'reservation_reminder'
+    AND COALESCE(payload->>'CancelLink', '') <> '';
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+
+-- Token yang sudah dihapus tidak bisa dikembalikan
+SELECT 1;
+
+-- +migrate StatementEnd
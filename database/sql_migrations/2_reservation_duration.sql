@@ -0,0 +1,27 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+-- btree_gist dibutuhkan supaya kolom integer (table_id) bisa dipakai di exclusion constraint
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+ALTER TABLE reservations
+    ADD COLUMN duration_minutes INT NOT NULL DEFAULT 120 CHECK (duration_minutes > 0);
+
+ALTER TABLE reservations
+    ADD COLUMN end_datetime TIMESTAMP GENERATED ALWAYS AS (reservation_datetime + duration_minutes * INTERVAL '1 minute') STORED;
+
+-- Tolak dua reservasi di meja yang sama dengan rentang waktu yang saling tumpang tindih
+ALTER TABLE reservations
+    ADD CONSTRAINT reservations_no_overlap
+    EXCLUDE USING gist (table_id WITH =, tsrange(reservation_datetime, end_datetime) WITH &&);
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+
+ALTER TABLE reservations DROP CONSTRAINT IF EXISTS reservations_no_overlap;
+ALTER TABLE reservations DROP COLUMN IF EXISTS end_datetime;
+ALTER TABLE reservations DROP COLUMN IF EXISTS duration_minutes;
+
+-- +migrate StatementEnd
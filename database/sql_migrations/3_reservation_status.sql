@@ -0,0 +1,36 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+CREATE TYPE reservation_status AS ENUM ('pending', 'confirmed', 'seated', 'completed', 'cancelled', 'no_show');
+
+ALTER TABLE reservations
+    ADD COLUMN status reservation_status NOT NULL DEFAULT 'pending';
+
+-- Reservasi yang sudah selesai, dibatalkan atau no-show tidak lagi memblokir meja
+ALTER TABLE reservations DROP CONSTRAINT IF EXISTS reservations_no_overlap;
+ALTER TABLE reservations
+    ADD CONSTRAINT reservations_no_overlap
+    EXCLUDE USING gist (table_id WITH =, tsrange(reservation_datetime, end_datetime) WITH &&)
+    WHERE (status IN ('pending', 'confirmed', 'seated'));
+
+CREATE INDEX idx_reservation_status ON reservations(status);
+
+-- Status "reserved" sekarang diturunkan dari reservasi aktif, bukan disimpan permanen di tabel
+UPDATE tables SET status = 'available' WHERE status = 'reserved';
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+
+DROP INDEX IF EXISTS idx_reservation_status;
+
+ALTER TABLE reservations DROP CONSTRAINT IF EXISTS reservations_no_overlap;
+ALTER TABLE reservations DROP COLUMN IF EXISTS status;
+ALTER TABLE reservations
+    ADD CONSTRAINT reservations_no_overlap
+    EXCLUDE USING gist (table_id WITH =, tsrange(reservation_datetime, end_datetime) WITH &&);
+
+DROP TYPE IF EXISTS reservation_status;
+
+-- +migrate StatementEnd
@@ -0,0 +1,18 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+-- Pembatalan disimpan sebagai status beserta alasannya, bukan dihapus dari tabel
+ALTER TABLE reservations
+    ADD COLUMN cancellation_reason TEXT,
+    ADD COLUMN cancelled_at TIMESTAMP;
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+
+ALTER TABLE reservations
+    DROP COLUMN IF EXISTS cancelled_at,
+    DROP COLUMN IF EXISTS cancellation_reason;
+
+-- +migrate StatementEnd
@@ -0,0 +1,84 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+-- Kombinasi meja yang boleh digabung untuk rombongan besar, diatur oleh admin
+CREATE TABLE IF NOT EXISTS table_combinations (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS table_combination_tables (
+    table_combination_id INT NOT NULL REFERENCES table_combinations(id) ON DELETE CASCADE,
+    table_id INT NOT NULL REFERENCES tables(id) ON DELETE CASCADE,
+    PRIMARY KEY (table_combination_id, table_id)
+);
+
+ALTER TABLE reservations
+    ADD COLUMN combination_id INT REFERENCES table_combinations(id) ON DELETE SET NULL;
+
+-- Semua meja yang dipakai sebuah reservasi. Rentang waktu dan status aktif disalin dari reservations
+-- oleh trigger di bawah supaya exclusion constraint bisa mengecek setiap meja dalam satu kombinasi
+CREATE TABLE IF NOT EXISTS reservation_tables (
+    reservation_id INT NOT NULL REFERENCES reservations(id) ON DELETE CASCADE,
+    table_id INT NOT NULL REFERENCES tables(id) ON DELETE CASCADE,
+    reservation_datetime TIMESTAMP,
+    end_datetime TIMESTAMP,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    PRIMARY KEY (reservation_id, table_id)
+);
+
+CREATE INDEX idx_reservation_tables_table_id ON reservation_tables(table_id);
+
+CREATE OR REPLACE FUNCTION reservation_tables_fill() RETURNS trigger AS $$
+BEGIN
+    SELECT r.reservation_datetime, r.end_datetime, r.status IN ('pending', 'confirmed', 'seated')
+      INTO NEW.reservation_datetime, NEW.end_datetime, NEW.active
+      FROM reservations r
+     WHERE r.id = NEW.reservation_id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_reservation_tables_fill
+    BEFORE INSERT OR UPDATE ON reservation_tables
+    FOR EACH ROW EXECUTE FUNCTION reservation_tables_fill();
+
+-- Setiap perubahan waktu atau status reservasi ikut disalin ke reservation_tables
+CREATE OR REPLACE FUNCTION reservations_sync_tables() RETURNS trigger AS $$
+BEGIN
+    UPDATE reservation_tables SET reservation_id = reservation_id WHERE reservation_id = NEW.id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_reservations_sync_tables
+    AFTER UPDATE OF reservation_datetime, duration_minutes, status ON reservations
+    FOR EACH ROW EXECUTE FUNCTION reservations_sync_tables();
+
+-- Isi reservation_tables untuk reservasi yang sudah ada
+INSERT INTO reservation_tables (reservation_id, table_id)
+SELECT id, table_id FROM reservations WHERE table_id IS NOT NULL;
+
+ALTER TABLE reservation_tables
+    ADD CONSTRAINT reservation_tables_no_overlap
+    EXCLUDE USING gist (table_id WITH =, tsrange(reservation_datetime, end_datetime) WITH &&)
+    WHERE (active);
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+
+DROP TRIGGER IF EXISTS trg_reservations_sync_tables ON reservations;
+DROP FUNCTION IF EXISTS reservations_sync_tables();
+DROP TABLE IF EXISTS reservation_tables;
+DROP FUNCTION IF EXISTS reservation_tables_fill();
+
+ALTER TABLE reservations DROP COLUMN IF EXISTS combination_id;
+
+DROP TABLE IF EXISTS table_combination_tables;
+DROP TABLE IF EXISTS table_combinations;
+
+-- +migrate StatementEnd
@@ -0,0 +1,51 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+-- Jam buka mingguan, satu baris per hari (0 = Minggu ... 6 = Sabtu). Jam disimpan sebagai HH:MM waktu lokal restoran
+CREATE TABLE IF NOT EXISTS opening_hours (
+    id SERIAL PRIMARY KEY,
+    day_of_week SMALLINT NOT NULL UNIQUE CHECK (day_of_week BETWEEN 0 AND 6),
+    open_time VARCHAR(5) NOT NULL DEFAULT '00:00' CHECK (open_time ~ '^([01][0-9]|2[0-3]):[0-5][0-9]$'),
+    close_time VARCHAR(5) NOT NULL DEFAULT '00:00' CHECK (close_time ~ '^([01][0-9]|2[0-3]):[0-5][0-9]$'),
+    last_seating_time VARCHAR(5) NOT NULL DEFAULT '00:00' CHECK (last_seating_time ~ '^([01][0-9]|2[0-3]):[0-5][0-9]$'),
+    is_closed BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Hari libur dan acara khusus di mana restoran tutup
+CREATE TABLE IF NOT EXISTS schedule_closures (
+    id SERIAL PRIMARY KEY,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX idx_schedule_closures_dates ON schedule_closures(start_date, end_date);
+
+-- Pengaturan slot reservasi, hanya ada satu baris
+CREATE TABLE IF NOT EXISTS schedule_settings (
+    id INT PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    slot_minutes INT NOT NULL DEFAULT 15 CHECK (slot_minutes > 0),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO schedule_settings (id, slot_minutes) VALUES (1, 15);
+
+-- Jadwal default supaya reservasi tetap bisa dibuat setelah migrasi
+INSERT INTO opening_hours (day_of_week, open_time, close_time, last_seating_time)
+SELECT d, '10:00', '22:00', '20:00' FROM generate_series(0, 6) AS d;
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+
+DROP TABLE IF EXISTS schedule_settings;
+DROP TABLE IF EXISTS schedule_closures;
+DROP TABLE IF EXISTS opening_hours;
+
+-- +migrate StatementEnd
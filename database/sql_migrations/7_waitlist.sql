@@ -0,0 +1,39 @@
+-- +migrate Up
+-- +migrate StatementBegin
+
+CREATE TYPE waitlist_status AS ENUM ('waiting', 'offered', 'accepted', 'expired', 'cancelled');
+
+-- Antrian customer yang tidak mendapat meja. Customer mau duduk kapan saja dengan waktu mulai di antara desired_start dan desired_end
+CREATE TABLE IF NOT EXISTS waitlist_entries (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    party_size INT NOT NULL CHECK (party_size > 0),
+    desired_start TIMESTAMP NOT NULL,
+    desired_end TIMESTAMP NOT NULL,
+    duration_minutes INT NOT NULL DEFAULT 120 CHECK (duration_minutes > 0),
+    status waitlist_status NOT NULL DEFAULT 'waiting',
+
+    -- Slot yang ditawarkan saat sebuah reservasi dibatalkan
+    offered_table_id INT REFERENCES tables(id) ON DELETE SET NULL,
+    offered_combination_id INT REFERENCES table_combinations(id) ON DELETE SET NULL,
+    offered_datetime TIMESTAMP,
+    offer_expires_at TIMESTAMP,
+    reservation_id INT REFERENCES reservations(id) ON DELETE SET NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (desired_end >= desired_start)
+);
+
+CREATE INDEX idx_waitlist_entries_status ON waitlist_entries(status, desired_start, desired_end);
+CREATE INDEX idx_waitlist_entries_user ON waitlist_entries(user_id);
+
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+
+DROP TABLE IF EXISTS waitlist_entries;
+DROP TYPE IF EXISTS waitlist_status;
+
+-- +migrate StatementEnd
@@ -10,7 +10,6 @@ type RegisterRequest struct {
 	Name string `json:"name" validate:"required,min=3,max=20"`
 	Email string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=8"`
-	Role string `json:"role" validate:"omitempty,oneof=admin customer"`
 }
 
 type LoginRequest struct {
@@ -18,12 +17,37 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required,min=8"`
 }
 
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"omitempty"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
 type UpdateUserRequest struct {
 	Name string `json:"name" validate:"omitempty,min=3,max=20"`
 	Email string `json:"email" validate:"omitempty,email"`
 	Password string `json:"password" validate:"omitempty,min=8"`
 }
 
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" validate:"required"`
+}
+
 
 // Table Validator
 
@@ -39,20 +63,135 @@ type UpdateTableRequest struct {
 	Status    string `json:"status" validate:"omitempty,oneof=available reserved occupied"`
 }
 
+type CreateTableCombinationRequest struct {
+	Name     string `json:"name" validate:"required,min=3,max=50"`
+	TableIDs []int  `json:"table_ids" validate:"required,min=2,dive,min=1"`
+}
+
+// Reservation dipesan untuk user yang login. Staff boleh mengisi user_id untuk memesan atas nama user lain,
+// atau guest untuk tamu tanpa akun
 type Reservation struct {
-    UserID             int       `json:"user_id" validate:"required"`
-    TableID            int       `json:"table_id" validate:"required"`
+    UserID             int       `json:"user_id" validate:"omitempty,min=1"`
+    Guest              *GuestContact `json:"guest" validate:"omitempty"`
+    TableID            int       `json:"table_id" validate:"required_without=CombinationID"`
+    CombinationID      int       `json:"combination_id" validate:"omitempty,min=1"`
     ReservationDateTime time.Time `json:"reservation_datetime" validate:"required"`
+    DurationMinutes    int       `json:"duration_minutes" validate:"omitempty,min=15,max=480"`
     NumberOfPeople     int       `json:"number_of_people" validate:"required,min=1"`
 }
 
+type GuestContact struct {
+    Name  string `json:"name" validate:"required,min=2,max=100"`
+    Phone string `json:"phone" validate:"required_without=Email,omitempty,max=30"`
+    Email string `json:"email" validate:"omitempty,email,max=255"`
+}
+
 type UpdateReservation struct {
     TableID            int       `json:"table_id" validate:"omitempty"`
+    CombinationID      int       `json:"combination_id" validate:"omitempty,min=1"`
     UserID             int       `json:"user_id" validate:"omitempty"`
     ReservationDateTime time.Time `json:"reservation_datetime" validate:"omitempty"`
+    DurationMinutes    int       `json:"duration_minutes" validate:"omitempty,min=15,max=480"`
     NumberOfPeople     int       `json:"number_of_people" validate:"omitempty,min=1"`
 }
 
+type CancelReservation struct {
+    Reason string `json:"reason" validate:"omitempty,max=255"`
+}
+
+// RespondToReminder menjalankan aksi dari link di email pengingat, token diambil dari link tersebut
+type RespondToReminder struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// PublicReservation dipesan oleh tamu tanpa akun lewat halaman publik. Email wajib karena magic link dikirim ke sana
+type PublicReservation struct {
+    Name               string    `json:"name" validate:"required,min=2,max=100"`
+    Email              string    `json:"email" validate:"required,email,max=255"`
+    Phone              string    `json:"phone" validate:"required,max=30"`
+    TableID            int       `json:"table_id" validate:"required_without=CombinationID"`
+    CombinationID      int       `json:"combination_id" validate:"omitempty,min=1"`
+    ReservationDateTime time.Time `json:"reservation_datetime" validate:"required"`
+    DurationMinutes    int       `json:"duration_minutes" validate:"omitempty,min=15,max=480"`
+    NumberOfPeople     int       `json:"number_of_people" validate:"required,min=1"`
+}
+
+// PublicUpdateReservation sama seperti UpdateReservation tanpa user_id
+type PublicUpdateReservation struct {
+    TableID            int       `json:"table_id" validate:"omitempty"`
+    CombinationID      int       `json:"combination_id" validate:"omitempty,min=1"`
+    ReservationDateTime time.Time `json:"reservation_datetime" validate:"omitempty"`
+    DurationMinutes    int       `json:"duration_minutes" validate:"omitempty,min=15,max=480"`
+    NumberOfPeople     int       `json:"number_of_people" validate:"omitempty,min=1"`
+}
+
+// ResendManageLinkRequest meminta link kelola reservasi baru untuk semua reservasi tamu dengan email tersebut
+type ResendManageLinkRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// Schedule Validator
+
+type OpeningHourRequest struct {
+	DayOfWeek       int    `json:"day_of_week" validate:"min=0,max=6"`
+	OpenTime        string `json:"open_time" validate:"omitempty,datetime=15:04"`
+	CloseTime       string `json:"close_time" validate:"omitempty,datetime=15:04"`
+	LastSeatingTime string `json:"last_seating_time" validate:"omitempty,datetime=15:04"`
+	IsClosed        bool   `json:"is_closed"`
+}
+
+type UpdateOpeningHoursRequest struct {
+	Days []OpeningHourRequest `json:"days" validate:"required,min=1,max=7,dive"`
+}
+
+type UpdateScheduleSettingsRequest struct {
+	SlotMinutes int `json:"slot_minutes" validate:"required,min=5,max=120"`
+}
+
+type CreateClosureRequest struct {
+	StartDate string `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate   string `json:"end_date" validate:"required,datetime=2006-01-02"`
+	Reason    string `json:"reason" validate:"required,max=255"`
+}
+
+// Waitlist Validator
+
+type JoinWaitlistRequest struct {
+	PartySize       int       `json:"party_size" validate:"required,min=1"`
+	DesiredStart    time.Time `json:"desired_start" validate:"required"`
+	DesiredEnd      time.Time `json:"desired_end" validate:"required,gtefield=DesiredStart"`
+	DurationMinutes int       `json:"duration_minutes" validate:"omitempty,min=15,max=480"`
+}
+
+// Webhook Validator
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" validate:"required,url,max=2048"`
+	Secret     string   `json:"secret" validate:"omitempty,min=16,max=255"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,required"`
+	Active     *bool    `json:"active"`
+}
+
+type UpdateWebhookRequest struct {
+	URL        string   `json:"url" validate:"omitempty,url,max=2048"`
+	Secret     string   `json:"secret" validate:"omitempty,min=16,max=255"`
+	EventTypes []string `json:"event_types" validate:"omitempty,min=1,dive,required"`
+	Active     *bool    `json:"active"`
+}
+
+// Role Validator
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name" validate:"required,min=2,max=50"`
+	Description string   `json:"description" validate:"max=255"`
+	Permissions []string `json:"permissions" validate:"dive,required"`
+}
+
+type UpdateRoleRequest struct {
+	Description string   `json:"description" validate:"max=255"`
+	Permissions []string `json:"permissions" validate:"required,dive,required"`
+}
+
 // Validator Instance
 var Validate *validator.Validate
 
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+	"wereserve/handler/response"
+	"wereserve/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AvailabilityHandler struct {
+	AvailabilityService *services.AvailabilityService
+}
+
+func NewAvailabilityHandler(availabilityService *services.AvailabilityService) *AvailabilityHandler {
+	return &AvailabilityHandler{AvailabilityService: availabilityService}
+}
+
+// SearchAvailability godoc
+// @Summary      Search available tables
+// @Description  Find free tables and table combinations for a party size at a given date and time, plus nearby alternative time slots
+// @Tags         availability
+// @Accept       json
+// @Produce      json
+// @Param        date        query     string  true   "Date (YYYY-MM-DD)"
+// @Param        time        query     string  true   "Time (HH:MM)"
+// @Param        party_size  query     int     true   "Number of people"
+// @Param        duration    query     int     false  "Duration in minutes (default 120)"
+// @Success      200  {object}  response.AvailabilityResponse "Availability retrieved successfully"
+// @Failure      400  {object}  response.ErrorResponse        "Invalid query parameter or time outside opening hours"
+// @Failure      500  {object}  response.ErrorResponse        "Internal server error"
+// @Router       /api/availability [get]
+func (h *AvailabilityHandler) SearchAvailability(c *gin.Context) {
+	date := c.Query("date")
+	clock := c.Query("time")
+	if date == "" || clock == "" {
+		c.Error(ErrInvalidQuery.Withf("date and time are required"))
+		return
+	}
+
+	start, err := time.ParseInLocation("2006-01-02 15:04", date+" "+clock, time.Local)
+	if err != nil {
+		c.Error(ErrInvalidQuery.Withf("Invalid date or time format. Expected YYYY-MM-DD and HH:MM"))
+		return
+	}
+
+	partySize, err := strconv.Atoi(c.Query("party_size"))
+	if err != nil || partySize < 1 {
+		c.Error(ErrInvalidQuery.Withf("party_size must be a positive number"))
+		return
+	}
+
+	duration := 0
+	if durationParam := c.Query("duration"); durationParam != "" {
+		duration, err = strconv.Atoi(durationParam)
+		if err != nil || duration < 15 || duration > 480 {
+			c.Error(ErrInvalidQuery.Withf("duration must be between 15 and 480 minutes"))
+			return
+		}
+	}
+
+	result, err := h.AvailabilityService.SearchAvailability(start, partySize, duration)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp := response.AvailabilityResponse{
+		PartySize:        result.PartySize,
+		DurationMinutes:  result.DurationMinutes,
+		Requested:        toAvailabilitySlotResponse(result.Requested),
+		AlternativeSlots: []response.AvailabilitySlotResponse{},
+	}
+	for _, slot := range result.AlternativeSlots {
+		resp.AlternativeSlots = append(resp.AlternativeSlots, toAvailabilitySlotResponse(slot))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "availability get successfully",
+		"data":    resp,
+	})
+}
+
+func toAvailabilitySlotResponse(slot services.AvailabilitySlot) response.AvailabilitySlotResponse {
+	combinations := []response.TableCombinationResponse{}
+	for _, combination := range slot.AvailableCombinations {
+		combinations = append(combinations, toTableCombinationResponse(combination))
+	}
+
+	return response.AvailabilitySlotResponse{
+		Start:                 slot.Start,
+		End:                   slot.End,
+		AvailableTables:       toTableResponses(slot.AvailableTables),
+		AvailableCombinations: combinations,
+	}
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"net/http"
+	"wereserve/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CalendarHandler struct {
+	CalendarService *services.CalendarService
+}
+
+func NewCalendarHandler(calendarService *services.CalendarService) *CalendarHandler {
+	return &CalendarHandler{CalendarService: calendarService}
+}
+
+// CreateCalendarFeed godoc
+// @Summary      Create a calendar feed link
+// @Description  Create a secret link to the user's calendar feed that can be added to a calendar app. Any previous link stops working. Only the user or someone with the user:write permission can do this
+// @Tags         users
+// @Produce      json
+// @Param        id   path      int                    true  "User ID"
+// @Success      200  {object}  map[string]string      "Calendar feed link created"
+// @Failure      400  {object}  response.ErrorResponse "Invalid user ID"
+// @Failure      401  {object}  response.ErrorResponse "Unauthorized"
+// @Failure      403  {object}  response.ErrorResponse "Not the owner of the account"
+// @Failure      500  {object}  response.ErrorResponse "Internal server error"
+// @Router       /api/users/{id}/calendar-token [post]
+func (h *CalendarHandler) CreateCalendarFeed(c *gin.Context) {
+	id, err := paramID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		c.Error(ErrUnauthenticated)
+		return
+	}
+
+	feedURL, err := h.CalendarService.RotateFeedToken(id, actor)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Calendar feed link created successfully",
+		"feed_url": feedURL,
+	})
+}
+
+// GetCalendarFeed godoc
+// @Summary      Calendar feed
+// @Description  iCalendar feed with the user's upcoming reservations. Authenticated with the token from the feed link instead of a JWT so calendar apps can subscribe to it
+// @Tags         users
+// @Produce      text/calendar
+// @Param        id     path      int                    true  "User ID"
+// @Param        token  query     string                 true  "Calendar feed token"
+// @Success      200    {string}  string                 "iCalendar file"
+// @Failure      400    {object}  response.ErrorResponse "Invalid user ID"
+// @Failure      404    {object}  response.ErrorResponse "Unknown or replaced feed link"
+// @Failure      500    {object}  response.ErrorResponse "Internal server error"
+// @Router       /api/users/{id}/calendar.ics [get]
+func (h *CalendarHandler) GetCalendarFeed(c *gin.Context) {
+	id, err := paramID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	feed, err := h.CalendarService.Feed(id, c.Query("token"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Disposition", `inline; filename="wereserve.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", feed)
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"wereserve/apperror"
+	"wereserve/dto"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// Error dari request yang tidak valid. Handler mencatatnya dengan c.Error, lalu middleware.ErrorHandler menulis response-nya
+var (
+	ErrInvalidBody      = apperror.Validation("invalid_body", "invalid request body")
+	ErrValidationFailed = apperror.Validation("validation_failed", "Validation Error")
+	ErrInvalidID        = apperror.Validation("invalid_id", "invalid id")
+	ErrInvalidQuery     = apperror.Validation("invalid_query", "invalid query parameter")
+	ErrUnauthenticated  = apperror.Unauthorized("unauthenticated", "User Id tidak di temukan di context")
+)
+
+// bindJSON membaca body JSON ke req lalu memvalidasinya dengan dto.Validate
+func bindJSON(c *gin.Context, req interface{}) error {
+	if err := c.ShouldBindJSON(req); err != nil {
+		return validationError(err)
+	}
+	if err := dto.Validate.Struct(req); err != nil {
+		return validationError(err)
+	}
+	return nil
+}
+
+// validationError mengubah error validator menjadi ErrValidationFailed dengan tag yang gagal per field,
+// error lain (misalnya JSON rusak) menjadi ErrInvalidBody
+func validationError(err error) error {
+	var fieldErrors validator.ValidationErrors
+	if errors.As(err, &fieldErrors) {
+		details := make(map[string]string)
+		for _, fieldErr := range fieldErrors {
+			details[fieldErr.Field()] = fieldErr.Tag()
+		}
+		return ErrValidationFailed.WithFields(details)
+	}
+	return ErrInvalidBody.Withf("invalid request body: %v", err)
+}
+
+// paramID membaca parameter path :id
+func paramID(c *gin.Context) (int, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return 0, ErrInvalidID.Withf("invalid id %q", c.Param("id"))
+	}
+	return id, nil
+}
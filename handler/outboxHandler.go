@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"net/http"
+	"wereserve/handler/response"
+	"wereserve/models"
+	"wereserve/notification"
+	"wereserve/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OutboxHandler struct {
+	OutboxService *services.OutboxService
+}
+
+func NewOutboxHandler(outboxService *services.OutboxService) *OutboxHandler {
+	return &OutboxHandler{OutboxService: outboxService}
+}
+
+// GetOutboxMessages godoc
+// @Summary      List outbox notifications
+// @Description  List queued, sent and dead notifications, newest first. Requires outbox:manage
+// @Tags         outbox
+// @Accept       json
+// @Produce      json
+// @Param        status  query     string  false  "Filter by status (pending, sent, dead)"
+// @Success      200     {array}   response.OutboxMessageResponse "Outbox messages retrieved successfully"
+// @Failure      400     {object}  response.ErrorResponse         "Invalid status"
+// @Failure      401     {object}  response.ErrorResponse         "Unauthorized"
+// @Failure      403     {object}  response.ErrorResponse         "Forbidden"
+// @Failure      500     {object}  response.ErrorResponse         "Internal server error"
+// @Router       /api/outbox [get]
+func (h *OutboxHandler) GetOutboxMessages(c *gin.Context) {
+	messages, err := h.OutboxService.GetMessages(c.Query("status"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp := []response.OutboxMessageResponse{}
+	for _, message := range messages {
+		resp = append(resp, toOutboxMessageResponse(message))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "data get successfully",
+		"data":    resp,
+	})
+}
+
+// GetOutboxMessage godoc
+// @Summary      Get an outbox notification
+// @Description  Get one notification with its payload and last error. Requires outbox:manage
+// @Tags         outbox
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Outbox message ID"
+// @Success      200  {object}  response.OutboxMessageResponse "Outbox message retrieved successfully"
+// @Failure      400  {object}  response.ErrorResponse         "Invalid outbox message ID"
+// @Failure      404  {object}  response.ErrorResponse         "Outbox message not found"
+// @Router       /api/outbox/{id} [get]
+func (h *OutboxHandler) GetOutboxMessage(c *gin.Context) {
+	id, err := paramID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	message, err := h.OutboxService.GetMessage(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "data get successfully",
+		"data":    toOutboxMessageResponse(*message),
+	})
+}
+
+// RetryOutboxMessage godoc
+// @Summary      Retry a dead notification
+// @Description  Put a dead notification back in the queue with its attempts reset. Requires outbox:manage
+// @Tags         outbox
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Outbox message ID"
+// @Success      200  {object}  map[string]string      "Outbox message queued for retry"
+// @Failure      400  {object}  response.ErrorResponse "Invalid outbox message ID"
+// @Failure      404  {object}  response.ErrorResponse "Outbox message not found"
+// @Failure      409  {object}  response.ErrorResponse "Outbox message is not dead, or its link is no longer stored"
+// @Router       /api/outbox/{id}/retry [post]
+func (h *OutboxHandler) RetryOutboxMessage(c *gin.Context) {
+	id, err := paramID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.OutboxService.RetryMessage(id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Outbox message queued for retry"})
+}
+
+func toOutboxMessageResponse(message models.OutboxMessage) response.OutboxMessageResponse {
+	return response.OutboxMessageResponse{
+		ID:            message.ID,
+		EventType:     message.EventType,
+		Recipient:     message.Recipient,
+		Payload:       notification.RedactPayload(message.EventType, []byte(message.Payload)),
+		Status:        message.Status,
+		Attempts:      message.Attempts,
+		NextAttemptAt: message.NextAttemptAt,
+		LastError:     message.LastError,
+		SentAt:        message.SentAt,
+		CreatedAt:     message.CreatedAt,
+		UpdatedAt:     message.UpdatedAt,
+	}
+}
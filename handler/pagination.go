@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"wereserve/handler/response"
+	"wereserve/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parsePage membaca query page (mulai dari 1), limit dan sort. Awalan "-" di sort berarti urutan menurun, contoh: sort=-created_at.
+// Nama field sort divalidasi di service
+func parsePage(c *gin.Context) (repository.Page, error) {
+	page := repository.Page{Limit: repository.DefaultPageLimit}
+
+	if limitParam := c.Query("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit < 1 || limit > repository.MaxPageLimit {
+			return page, ErrInvalidQuery.Withf("limit must be between 1 and %d", repository.MaxPageLimit)
+		}
+		page.Limit = limit
+	}
+
+	if pageParam := c.Query("page"); pageParam != "" {
+		number, err := strconv.Atoi(pageParam)
+		if err != nil || number < 1 {
+			return page, ErrInvalidQuery.Withf("page must be a positive number")
+		}
+		page.Offset = (number - 1) * page.Limit
+	}
+
+	sort := c.Query("sort")
+	if strings.HasPrefix(sort, "-") {
+		page.Desc = true
+		sort = strings.TrimPrefix(sort, "-")
+	}
+	page.Sort = sort
+
+	return page, nil
+}
+
+// parseIntQuery membaca query angka positif yang opsional, 0 jika tidak diisi
+func parseIntQuery(c *gin.Context, name string) (int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, ErrInvalidQuery.Withf("%s must be a positive number", name)
+	}
+	return n, nil
+}
+
+// parseDateQuery membaca tanggal YYYY-MM-DD atau waktu RFC3339. Untuk batas akhir (endOfRange),
+// tanggal tanpa jam berarti sampai akhir hari tersebut
+func parseDateQuery(c *gin.Context, name string, endOfRange bool) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return nil, ErrInvalidQuery.Withf("%s must be a date (YYYY-MM-DD) or an RFC3339 time", name)
+	}
+	if endOfRange {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+func toPaginationResponse(page repository.Page, total int64) response.PaginationResponse {
+	totalPages := int((total + int64(page.Limit) - 1) / int64(page.Limit))
+	return response.PaginationResponse{
+		Page:       page.Offset/page.Limit + 1,
+		Limit:      page.Limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+}
@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"net/http"
+	"wereserve/dto"
+	"wereserve/handler/response"
+	"wereserve/models"
+	"wereserve/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PublicReservationHandler struct {
+	GuestReservationService *services.GuestReservationService
+}
+
+func NewPublicReservationHandler(guestReservationService *services.GuestReservationService) *PublicReservationHandler {
+	return &PublicReservationHandler{GuestReservationService: guestReservationService}
+}
+
+// CreateReservation godoc
+// @Summary      Book a table without an account
+// @Description  Create a reservation for a guest without an account. A link to view, change or cancel the reservation is sent to the email address, it is not returned in the response
+// @Tags         public-reservations
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.PublicReservation  true  "Guest contact and reservation details"
+// @Success      201    {object}  response.PublicReservationResponse "Reservation created successfully"
+// @Failure      400    {object}  response.ErrorResponse "Invalid request body, validation failed or party too large"
+// @Failure      409    {object}  response.ErrorResponse "Table already reserved or too many upcoming reservations for this contact"
+// @Failure      429    {object}  response.ErrorResponse "Too many requests, retry after the number of seconds in the Retry-After header"
+// @Failure      500    {object}  response.ErrorResponse "Internal server error"
+// @Router       /api/public/reservations [post]
+func (h *PublicReservationHandler) CreateReservation(c *gin.Context) {
+	var req dto.PublicReservation
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	reservation := models.Reservation{
+		TableID:             req.TableID,
+		ReservationDateTime: req.ReservationDateTime,
+		DurationMinutes:     req.DurationMinutes,
+		NumberOfPeople:      req.NumberOfPeople,
+		GuestContact: &models.GuestContact{
+			Name:  req.Name,
+			Email: req.Email,
+			Phone: req.Phone,
+		},
+	}
+	if req.CombinationID != 0 {
+		reservation.CombinationID = &req.CombinationID
+	}
+
+	if err := h.GuestReservationService.CreateReservation(&reservation); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Reservation created, check your email for the link to manage it",
+		"data":    toPublicReservationResponse(&reservation),
+	})
+}
+
+// GetReservation godoc
+// @Summary      View a guest reservation
+// @Description  View the reservation of the token from the manage link in the confirmation email. No login is needed. The link stops working when it expires, when a new link is requested or once the reservation has ended
+// @Tags         public-reservations
+// @Produce      json
+// @Param        token  query     string  true  "Reservation manage token"
+// @Success      200    {object}  response.PublicReservationResponse "Reservation retrieved successfully"
+// @Failure      400    {object}  response.ErrorResponse "Token missing, invalid or expired"
+// @Failure      500    {object}  response.ErrorResponse "Internal server error"
+// @Router       /api/public/reservations/manage [get]
+func (h *PublicReservationHandler) GetReservation(c *gin.Context) {
+	token, ok := manageToken(c)
+	if !ok {
+		return
+	}
+
+	reservation, err := h.GuestReservationService.GetReservation(token)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reservation retrieved successfully",
+		"data":    toPublicReservationResponse(reservation),
+	})
+}
+
+// UpdateReservation godoc
+// @Summary      Change a guest reservation
+// @Description  Reschedule or change the party size of the reservation of the manage link, with the same cutoff window as customers
+// @Tags         public-reservations
+// @Accept       json
+// @Produce      json
+// @Param        token  query     string                       true  "Reservation manage token"
+// @Param        input  body      dto.PublicUpdateReservation  true  "Updated reservation details"
+// @Success      200    {object}  response.PublicReservationResponse "Reservation updated successfully"
+// @Failure      400    {object}  response.ErrorResponse "Token missing, invalid or expired, or validation failed"
+// @Failure      409    {object}  response.ErrorResponse "Table already reserved, reservation not active or cutoff window passed"
+// @Failure      500    {object}  response.ErrorResponse "Internal server error"
+// @Router       /api/public/reservations/manage [put]
+func (h *PublicReservationHandler) UpdateReservation(c *gin.Context) {
+	token, ok := manageToken(c)
+	if !ok {
+		return
+	}
+
+	var req dto.PublicUpdateReservation
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	update := models.Reservation{
+		TableID:             req.TableID,
+		ReservationDateTime: req.ReservationDateTime,
+		DurationMinutes:     req.DurationMinutes,
+		NumberOfPeople:      req.NumberOfPeople,
+	}
+	if req.CombinationID != 0 {
+		update.CombinationID = &req.CombinationID
+	}
+
+	reservation, err := h.GuestReservationService.UpdateReservation(token, update)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reservation updated successfully",
+		"data":    toPublicReservationResponse(reservation),
+	})
+}
+
+// CancelReservation godoc
+// @Summary      Cancel a guest reservation
+// @Description  Cancel the reservation of the manage link with an optional reason, with the same cutoff window as customers
+// @Tags         public-reservations
+// @Accept       json
+// @Produce      json
+// @Param        token  query     string                 true   "Reservation manage token"
+// @Param        input  body      dto.CancelReservation  false  "Cancellation reason"
+// @Success      200    {object}  response.PublicReservationResponse "Reservation cancelled"
+// @Failure      400    {object}  response.ErrorResponse "Token missing, invalid or expired"
+// @Failure      409    {object}  response.ErrorResponse "Cutoff window passed or invalid status transition"
+// @Failure      500    {object}  response.ErrorResponse "Internal server error"
+// @Router       /api/public/reservations/manage/cancel [post]
+func (h *PublicReservationHandler) CancelReservation(c *gin.Context) {
+	token, ok := manageToken(c)
+	if !ok {
+		return
+	}
+
+	// Alasan pembatalan bersifat opsional
+	var req dto.CancelReservation
+	if c.Request.ContentLength > 0 {
+		if err := bindJSON(c, &req); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	reservation, err := h.GuestReservationService.CancelReservation(token, req.Reason)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reservation cancelled Successfully",
+		"data":    toPublicReservationResponse(reservation),
+	})
+}
+
+// ResendManageLink godoc
+// @Summary      Resend the manage link
+// @Description  Send a new manage link for every upcoming reservation booked without an account with this email. Links sent earlier stop working. The response is the same whether or not the email has reservations
+// @Tags         public-reservations
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.ResendManageLinkRequest  true  "Guest email"
+// @Success      200    {object}  map[string]string      "Manage links sent if the email has upcoming reservations"
+// @Failure      400    {object}  response.ErrorResponse "Invalid request body or validation failed"
+// @Failure      429    {object}  response.ErrorResponse "Too many requests, retry after the number of seconds in the Retry-After header"
+// @Failure      500    {object}  response.ErrorResponse "Internal server error"
+// @Router       /api/public/reservations/manage/resend [post]
+func (h *PublicReservationHandler) ResendManageLink(c *gin.Context) {
+	var req dto.ResendManageLinkRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.GuestReservationService.ResendManageLink(req.Email); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If the email has upcoming reservations, a new link has been sent"})
+}
+
+// manageToken mengambil token magic link dari query ?token=
+func manageToken(c *gin.Context) (string, bool) {
+	token := c.Query("token")
+	if token == "" {
+		c.Error(ErrInvalidQuery.Withf("token wajib diisi"))
+		return "", false
+	}
+	return token, true
+}
+
+func toPublicReservationResponse(reservation *models.Reservation) response.PublicReservationResponse {
+	tables := []response.TablePreloadResponse{}
+	for _, table := range reservation.Tables {
+		tables = append(tables, response.TablePreloadResponse{
+			ID:        table.ID,
+			TableName: table.TableName,
+			Capacity:  table.Capacity,
+			Status:    table.Status,
+		})
+	}
+
+	return response.PublicReservationResponse{
+		ID:                  reservation.ID,
+		Guest:               toGuestContactResponse(reservation.GuestContact),
+		Tables:              tables,
+		CombinationID:       reservation.CombinationID,
+		ReservationDateTime: reservation.ReservationDateTime,
+		EndDateTime:         reservation.EndDateTime,
+		DurationMinutes:     reservation.DurationMinutes,
+		NumberOfPeople:      reservation.NumberOfPeople,
+		Status:              reservation.Status,
+		CancellationReason:  reservation.CancellationReason,
+		CreatedAt:           reservation.CreatedAt,
+		UpdatedAt:           reservation.UpdatedAt,
+	}
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	"wereserve/middleware"
+	"wereserve/models"
+	"wereserve/notification"
+	"wereserve/realtime"
+	"wereserve/repository/memory"
+	"wereserve/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPublicReservationRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := memory.NewStore()
+	table := models.Table{TableName: "A1", Capacity: 4, Status: "available"}
+	if err := store.Tables().CreateTable(&table); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+
+	notifier := notification.NewEmailNotifier(notification.NewMemoryMailer(), notification.EmailOptions{})
+	scheduleService := services.NewScheduleService(store.Schedule())
+	hub := realtime.NewHub(0)
+	waitlistService := services.NewWaitlistService(store.UnitOfWork(), store.Waitlist(), store.Reservations(), store.Tables(), store.TableCombinations(), scheduleService, time.Minute, notifier, hub)
+	reservationService := services.NewReservationService(store.UnitOfWork(), store.Reservations(), store.Tables(), store.TableCombinations(), scheduleService, waitlistService, 0, hub)
+	handler := NewPublicReservationHandler(services.NewGuestReservationService(reservationService, store.ReservationManageTokens(), services.GuestReservationOptions{ManageURL: "http://localhost:3000/reservations/manage"}))
+
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	r.POST("/api/public/reservations", handler.CreateReservation)
+	r.GET("/api/public/reservations/manage", handler.GetReservation)
+	r.PUT("/api/public/reservations/manage", handler.UpdateReservation)
+	r.POST("/api/public/reservations/manage/cancel", handler.CancelReservation)
+
+	do := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		var payload []byte
+		if body != nil {
+			payload, _ = json.Marshal(body)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	tomorrow := time.Now().AddDate(0, 0, 1)
+	start := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 12, 0, 0, 0, time.Local)
+
+	// Nomor telepon wajib untuk reservasi publik
+	if w := do(http.MethodPost, "/api/public/reservations", map[string]interface{}{"name": "Sari", "email": "sari@example.com",
+		"table_id": table.ID, "reservation_datetime": start, "number_of_people": 2}); w.Code != http.StatusBadRequest {
+		t.Errorf("create without phone status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	w := do(http.MethodPost, "/api/public/reservations", map[string]interface{}{"name": "Sari", "email": "sari@example.com", "phone": "0812",
+		"table_id": table.ID, "reservation_datetime": start, "number_of_people": 2})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, want %d, body = %s", w.Code, http.StatusCreated, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "token") {
+		t.Errorf("expected the manage token to be sent by email only, body = %s", w.Body.String())
+	}
+
+	var created struct {
+		Data struct {
+			ID int `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("invalid response body: %v", err)
+	}
+	reservation, err := store.Reservations().GetReservationDetail(created.Data.ID)
+	if err != nil {
+		t.Fatalf("failed to load reservation: %v", err)
+	}
+	token := ""
+	messages, _ := store.Outbox().GetMessages(models.OutboxStatusPending)
+	for _, message := range messages {
+		var data notification.ReservationData
+		if message.EventType == notification.EventReservationCreated && json.Unmarshal([]byte(message.Payload), &data) == nil {
+			if link, err := url.Parse(data.ManageLink); err == nil {
+				token = link.Query().Get("token")
+			}
+		}
+	}
+	if token == "" {
+		t.Fatalf("expected a manage link in the confirmation email of reservation %d", reservation.ID)
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     interface{}
+		wantCode int
+	}{
+		{name: "view without token", method: http.MethodGet, path: "/api/public/reservations/manage", wantCode: http.StatusBadRequest},
+		{name: "view with invalid token", method: http.MethodGet, path: "/api/public/reservations/manage?token=invalid", wantCode: http.StatusBadRequest},
+		{name: "view", method: http.MethodGet, path: "/api/public/reservations/manage?token=" + token, wantCode: http.StatusOK},
+		{name: "update", method: http.MethodPut, path: "/api/public/reservations/manage?token=" + token, body: map[string]int{"number_of_people": 3}, wantCode: http.StatusOK},
+		{name: "cancel", method: http.MethodPost, path: "/api/public/reservations/manage/cancel?token=" + token, wantCode: http.StatusOK},
+		{name: "cancel twice", method: http.MethodPost, path: "/api/public/reservations/manage/cancel?token=" + token, wantCode: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		if w := do(tt.method, tt.path, tt.body); w.Code != tt.wantCode {
+			t.Errorf("%s status = %d, want %d, body = %s", tt.name, w.Code, tt.wantCode, w.Body.String())
+		}
+	}
+}
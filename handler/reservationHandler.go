@@ -1,63 +1,75 @@
 package handler
 
 import (
-	"log"
 	"net/http"
 	"strconv"
-	"strings"
 	"time"
 	"wereserve/dto"
 	"wereserve/handler/response"
 	"wereserve/models"
+	"wereserve/repository"
 	"wereserve/services"
+	"wereserve/utils"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 )
 
 type ReservationHandler struct {
 	ReservationService *services.ReservationService
-	validate           *validator.Validate
 }
 
 func NewReservationsHandler(reservationService *services.ReservationService) *ReservationHandler {
-	return &ReservationHandler{ReservationService: reservationService,
-		validate:           validator.New(),
-	}
+	return &ReservationHandler{ReservationService: reservationService}
 }
 
 
 // GetAllReservation godoc
-// @Summary      Get all reservations
-// @Description  Retrieve a list of all reservations
+// @Summary      List reservations
+// @Description  Retrieve one page of reservations, optionally filtered by start date range, table, user and status
 // @Tags         reservations
 // @Accept       json
 // @Produce      json
+// @Param        page      query     int     false  "Page number, starting at 1"
+// @Param        limit     query     int     false  "Rows per page (default 20, max 100)"
+// @Param        sort      query     string  false  "Sort field: id, reservation_datetime, number_of_people, status, created_at. Prefix with - for descending"
+// @Param        from      query     string  false  "Reservations starting at or after this date (YYYY-MM-DD or RFC3339)"
+// @Param        to        query     string  false  "Reservations starting before the end of this date (YYYY-MM-DD) or before this time (RFC3339)"
+// @Param        table_id  query     int     false  "Reservations that use this table, including table combinations"
+// @Param        user_id   query     int     false  "Reservations of this user"
+// @Param        status    query     string  false  "Reservation status"
 // @Success      200  {array}   response.ReservationResponse "List of reservations retrieved successfully"
-// @Failure      404  {object}  response.ErrorResponse       "No reservations found"
+// @Failure      400  {object}  response.ErrorResponse       "Invalid query parameter"
 // @Failure      500  {object}  response.ErrorResponse       "Internal server error"
 // @Router       /api/reservation [get]
 func (h *ReservationHandler) GetAllReservation(c *gin.Context) {
-	reservations, err := h.ReservationService.GetAllReservation()
+	page, err := parsePage(c)
 	if err != nil {
-        // Tangani error khusus untuk "no reservations found"
-        if err.Error() == "no reservations found" {
-            c.JSON(http.StatusNotFound, gin.H{
-                "error": "No reservations found",
-            })
-            return
-        }
-
-        // Tangani error lainnya
-        c.JSON(http.StatusInternalServerError, gin.H{
-            "error": err.Error(),
-        })
-        return
-    }
+		c.Error(err)
+		return
+	}
+	filter := repository.ReservationFilter{Status: c.Query("status"), Page: page}
+	if filter.From, err = parseDateQuery(c, "from", false); err == nil {
+		filter.To, err = parseDateQuery(c, "to", true)
+	}
+	if err == nil {
+		filter.TableID, err = parseIntQuery(c, "table_id")
+	}
+	if err == nil {
+		filter.UserID, err = parseIntQuery(c, "user_id")
+	}
+	if err != nil {
+		c.Error(err)
+		return
+	}
 
+	reservations, total, err := h.ReservationService.ListReservations(filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
 
 	// Convert to ReseponseStruct
-	var allReserveResponse []response.ReservationResponse
+	allReserveResponse := []response.ReservationResponse{}
 	for _, reservation := range reservations{
 		allReserveResponse = append(allReserveResponse, response.ReservationResponse{
 			ID:                  reservation.ID,
@@ -73,7 +85,14 @@ func (h *ReservationHandler) GetAllReservation(c *gin.Context) {
 				Status:    reservation.Table.Status,
 			},
 			ReservationDateTime: reservation.ReservationDateTime,
+			EndDateTime:         reservation.EndDateTime,
+			DurationMinutes:     reservation.DurationMinutes,
 			NumberOfPeople:      reservation.NumberOfPeople,
+			Tables:              toTableResponses(reservation.Tables),
+			Guest:               toGuestContactResponse(reservation.GuestContact),
+			CombinationID:       reservation.CombinationID,
+			Status:              reservation.Status,
+			CancellationReason:  reservation.CancellationReason,
 			CreatedAt:           reservation.CreatedAt,
 			UpdatedAt:           reservation.UpdatedAt,
 		})
@@ -82,35 +101,42 @@ func (h *ReservationHandler) GetAllReservation(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"message" : "successfully get all Reservation",
 		"data" : allReserveResponse,
+		"pagination": toPaginationResponse(page, total),
 	})
 }
 
 
 // GetReservationDetail godoc
 // @Summary      Get a reservation by ID
-// @Description  Retrieve a reservation's details based on its ID
+// @Description  Retrieve a reservation's details based on its ID. Users can only read their own reservations unless they have the reservation:read permission
 // @Tags         reservations
 // @Accept       json
 // @Produce      json
 // @Param        id   path      int                      true  "Reservation ID"
 // @Success      200  {object}  response.ReservationResponse "Reservation retrieved successfully"
 // @Failure      400  {object}  response.ErrorResponse       "Invalid reservation ID"
+// @Failure      403  {object}  response.ErrorResponse       "Not the owner of the reservation"
 // @Failure      404  {object}  response.ErrorResponse       "Reservation not found"
 // @Failure      500  {object}  response.ErrorResponse       "Internal server error"
 // @Router       /api/reservation/{id} [get]
 func (h *ReservationHandler) GetReservationDetail(c *gin.Context) {
 	// get Param
-	ParamId := c.Param("id")
-	id, err := strconv.Atoi(ParamId)
+	id, err := paramID(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error" : "id tidak ditemukan"})
+		c.Error(err)
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		c.Error(ErrUnauthenticated)
 		return
 	}
 
 	// panggil service
-	reservation, err := h.ReservationService.GetReservationDetail(id)
+	reservation, err := h.ReservationService.GetReservationDetail(id, actor)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error" : "Data tidak di temukan"})
+		c.Error(err)
 		return
 	}
 
@@ -129,7 +155,14 @@ func (h *ReservationHandler) GetReservationDetail(c *gin.Context) {
 				Status:    reservation.Table.Status,
 			},
 			ReservationDateTime: reservation.ReservationDateTime,
+			EndDateTime:         reservation.EndDateTime,
+			DurationMinutes:     reservation.DurationMinutes,
 			NumberOfPeople:      reservation.NumberOfPeople,
+			Tables:              toTableResponses(reservation.Tables),
+			Guest:               toGuestContactResponse(reservation.GuestContact),
+			CombinationID:       reservation.CombinationID,
+			Status:              reservation.Status,
+			CancellationReason:  reservation.CancellationReason,
 			CreatedAt:           reservation.CreatedAt,
 			UpdatedAt:           reservation.UpdatedAt,
 	}
@@ -148,43 +181,20 @@ func (h *ReservationHandler) GetReservationDetail(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Success      200  {array}   response.ReservationResponse "List of reservations retrieved successfully"
-// @Failure      400  {object}  response.ErrorResponse       "Invalid user ID or failed to retrieve data"
 // @Failure      401  {object}  response.ErrorResponse       "Unauthorized, user ID not found in context"
 // @Failure      500  {object}  response.ErrorResponse       "Internal server error"
 // @Router       /api/reservation/my-reservation [get]
 func (h *ReservationHandler) GetReservationByUserLogin(c *gin.Context) {
 	// Get id user login now
-	userIDInterface, exists :=  c.Get("userID")	
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error" : "User Id tidak di temukan di context",
-		})
-		return
-	}
-
-	// Lakukan type assertion untuk mengkonversi ke string
-	userID, ok := userIDInterface.(string)
+	actor, ok := currentActor(c)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error" : "Gagal konversi userID ke string",
-		})
-		return
-	}
-
-	// convert to int
-	id, err := strconv.Atoi(userID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error" : "userID tidak valid",
-		})
+		c.Error(ErrUnauthenticated)
 		return
 	}
 
-	reservations, err := h.ReservationService.GetReservationByUserLogin(id)
+	reservations, err := h.ReservationService.GetReservationByUserLogin(actor.UserID)
 	if err != nil{
-		c.JSON(http.StatusBadRequest, gin.H {
-			"error" : "Gagal mengambil data reservasi",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -204,7 +214,14 @@ func (h *ReservationHandler) GetReservationByUserLogin(c *gin.Context) {
 				Status:    reservation.Table.Status,
 			},
 			ReservationDateTime: reservation.ReservationDateTime,
+			EndDateTime:         reservation.EndDateTime,
+			DurationMinutes:     reservation.DurationMinutes,
 			NumberOfPeople:      reservation.NumberOfPeople,
+			Tables:              toTableResponses(reservation.Tables),
+			Guest:               toGuestContactResponse(reservation.GuestContact),
+			CombinationID:       reservation.CombinationID,
+			Status:              reservation.Status,
+			CancellationReason:  reservation.CancellationReason,
 			CreatedAt:           reservation.CreatedAt,
 			UpdatedAt:           reservation.UpdatedAt,
 		})
@@ -219,42 +236,35 @@ func (h *ReservationHandler) GetReservationByUserLogin(c *gin.Context) {
 
 
 // DeleteReservation godoc
-// @Summary      Delete a reservation by ID
-// @Description  Delete a reservation based on the provided reservation ID
+// @Summary      Cancel a reservation by ID
+// @Description  Cancel a reservation based on the provided reservation ID. The reservation is kept with status cancelled and the given reason. Customers can only cancel their own reservations before the cutoff window
 // @Tags         reservations
 // @Accept       json
 // @Produce      json
-// @Param        id   path      int                  true  "Reservation ID"
-// @Success      200  {object}  map[string]string    "Reservation deleted successfully"
+// @Param        id     path      int                    true   "Reservation ID"
+// @Param        input  body      dto.CancelReservation  false  "Cancellation reason"
+// @Success      200  {object}  map[string]string    "Reservation cancelled successfully"
 // @Failure      400  {object}  response.ErrorResponse "Invalid reservation ID"
+// @Failure      403  {object}  response.ErrorResponse "Not the owner of the reservation"
+// @Failure      404  {object}  response.ErrorResponse "Reservation not found"
+// @Failure      409  {object}  response.ErrorResponse "Cutoff window passed or reservation can no longer be cancelled"
 // @Failure      500  {object}  response.ErrorResponse "Internal server error"
 // @Router       /api/reservation/{id} [delete]
 func (h *ReservationHandler) DeleteReservation(c *gin.Context) {
-	paramID := c.Param("id")
-	id, err := strconv.Atoi(paramID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest,gin.H{"error" : "Id invalid"})
-		return
-	}
-
-	err = h.ReservationService.DeleteReservation(id)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error" : err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{"message" : "Reservation deleted Successfully" })
+	h.cancelReservation(c)
 }
 
 // CreateReservation godoc
 // @Summary      Create a new reservation
-// @Description  Create a new reservation with the provided details
+// @Description  Create a new reservation for the logged in user. Staff with reservation:update can set user_id to book for another user, or guest to book for a walk-in or phone guest without an account
 // @Tags         reservations
 // @Accept       json
 // @Produce      json
 // @Param        input  body      dto.Reservation          true  "Reservation creation details"
 // @Success      201    {object}  response.ReservationResponse "Reservation created successfully"
 // @Failure      400    {object}  response.ErrorResponse       "Invalid request body or validation failed"
+// @Failure      403    {object}  response.ErrorResponse       "Not allowed to book for another user or a guest"
+// @Failure      404    {object}  response.ErrorResponse       "User not found"
 // @Failure      409    {object}  response.ErrorResponse       "Table already reserved"
 // @Failure      500    {object}  response.ErrorResponse       "Internal server error"
 // @Router       /api/reservation [post]
@@ -262,21 +272,9 @@ func (h *ReservationHandler) CreateReservation(c *gin.Context) {
 	// var reservation models.Reservation
 	var reservationDTO dto.Reservation
 
-    // Bind dan validasi request body
-    if err := c.ShouldBindJSON(&reservationDTO); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
-        return
-    }
-
-    // Validasi format waktu
-    if _, err := time.Parse(time.RFC3339, reservationDTO.ReservationDateTime.Format(time.RFC3339)); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reservation date format. Expected RFC3339 format."})
-        return
-    }
-
-    // Validasi request menggunakan validator
-    if err := h.validate.Struct(reservationDTO); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed: " + err.Error()})
+    // Bind dan validasi request body. Waktu yang bukan RFC3339 sudah ditolak saat binding
+    if err := bindJSON(c, &reservationDTO); err != nil {
+        c.Error(err)
         return
     }
 
@@ -285,50 +283,40 @@ func (h *ReservationHandler) CreateReservation(c *gin.Context) {
         UserID:             reservationDTO.UserID,
         TableID:            reservationDTO.TableID,
         ReservationDateTime: reservationDTO.ReservationDateTime,
+        DurationMinutes:    reservationDTO.DurationMinutes,
         NumberOfPeople:     reservationDTO.NumberOfPeople,
         CreatedAt:          time.Now(),
         UpdatedAt:          time.Now(),
     }
+    if reservationDTO.CombinationID != 0 {
+        reservationModel.CombinationID = &reservationDTO.CombinationID
+    }
+    if reservationDTO.Guest != nil {
+        reservationModel.GuestContact = &models.GuestContact{
+            Name:  reservationDTO.Guest.Name,
+            Phone: reservationDTO.Guest.Phone,
+            Email: reservationDTO.Guest.Email,
+        }
+    }
 
-	//Get email userLogin
-	emailInterface, exists :=  c.Get("email")	
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error" : "email tidak ditemukan di claims",
-		})
-		return
-	}
-
-	// Parse claims email to string
-	userEmail, ok := emailInterface.(string)
+	// Pemilik reservasi diambil dari token, kecuali staff memesan untuk user lain atau tamu
+	actor, ok := currentActor(c)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error" : "Gagal konversi userEmail ke string",
-		})
+		c.Error(ErrUnauthenticated)
 		return
 	}
 
-
     // Panggil service untuk membuat reservasi
-    if err := h.ReservationService.CreateReservation(&reservationModel, userEmail ); err != nil {
-        switch {
-        case strings.Contains(err.Error(), "already reserved"):
-            c.JSON(http.StatusBadRequest, gin.H{"error": "Table is already reserved"})
-        case strings.Contains(err.Error(), "failed to fetch table"):
-            c.JSON(http.StatusNotFound, gin.H{"error": "Table not found"})
-        default:
-            log.Printf("Error creating reservation: %v", err)
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create reservation"})
-        }
-        return
+    if err := h.ReservationService.CreateReservation(&reservationModel, actor); err != nil {
+    	c.Error(err)
+    	return
     }
 
     // Ambil detail reservasi yang baru dibuat
-    createReservation, err := h.ReservationService.GetReservationDetail(reservationModel.ID)
+    createReservation, err := h.ReservationService.GetReservationDetail(reservationModel.ID, actor)
     if err != nil {
-        log.Printf("Error fetching reservation detail: %v", err)
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reservation detail"})
-        return
+    	c.Error(err)
+    	return
     }
 
     // Format response
@@ -348,7 +336,14 @@ func (h *ReservationHandler) CreateReservation(c *gin.Context) {
             UpdatedAt: createReservation.Table.UpdatedAt,
         },
         ReservationDateTime: createReservation.ReservationDateTime,
+        EndDateTime:         createReservation.EndDateTime,
+        DurationMinutes:     createReservation.DurationMinutes,
         NumberOfPeople:      createReservation.NumberOfPeople,
+        Tables:              toTableResponses(createReservation.Tables),
+        Guest:               toGuestContactResponse(createReservation.GuestContact),
+        CombinationID:       createReservation.CombinationID,
+        Status:              createReservation.Status,
+        CancellationReason:  createReservation.CancellationReason,
         CreatedAt:           createReservation.CreatedAt,
         UpdatedAt:           createReservation.UpdatedAt,
     }
@@ -363,7 +358,7 @@ func (h *ReservationHandler) CreateReservation(c *gin.Context) {
 
 // UpdateReservation godoc
 // @Summary      Update a reservation by ID
-// @Description  Update a reservation's details based on its ID
+// @Description  Update a reservation's details based on its ID. Customers can only reschedule their own reservations before the cutoff window
 // @Tags         reservations
 // @Accept       json
 // @Produce      json
@@ -371,27 +366,21 @@ func (h *ReservationHandler) CreateReservation(c *gin.Context) {
 // @Param        input  body      dto.UpdateReservation    true  "Updated reservation details"
 // @Success      200    {object}  map[string]string        "Reservation updated successfully"
 // @Failure      400    {object}  response.ErrorResponse   "Invalid request body or validation failed"
-// @Failure      409    {object}  response.ErrorResponse   "Table and reservation time already used by another reservation"
+// @Failure      403    {object}  response.ErrorResponse   "Not the owner of the reservation"
+// @Failure      409    {object}  response.ErrorResponse   "Table and reservation time already used by another reservation or cutoff window passed"
 // @Failure      500    {object}  response.ErrorResponse   "Internal server error"
 // @Router       /api/reservation/{id} [put]
 func (h *ReservationHandler) UpdateReservation(c *gin.Context) {
-	paramID := c.Param("id")
-	id, err := strconv.Atoi(paramID)
+	id, err := paramID(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error" : "invalid reservation ID"})
+		c.Error(err)
 		return
 	}
 
 	// Bind and validate request body
 	var updatedDTO dto.UpdateReservation
 	if err := c.ShouldBindJSON(&updatedDTO); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-        return
-    }
-
-	// Validate request body using validator
-	if err := h.validate.Struct(updatedDTO); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error" : err.Error()})
+		c.Error(validationError(err))
 		return
 	}
 
@@ -400,23 +389,291 @@ func (h *ReservationHandler) UpdateReservation(c *gin.Context) {
 		TableID:            updatedDTO.TableID,
         UserID:             updatedDTO.UserID,
         ReservationDateTime: updatedDTO.ReservationDateTime,
+        DurationMinutes:    updatedDTO.DurationMinutes,
         NumberOfPeople:     updatedDTO.NumberOfPeople,
 	}
+	if updatedDTO.CombinationID != 0 {
+		updateReservation.CombinationID = &updatedDTO.CombinationID
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		c.Error(ErrUnauthenticated)
+		return
+	}
 
 	//Call service to update reservation
-	if err := h.ReservationService.UpdateReservation(id, updateReservation); err != nil {
-		switch err.Error(){
-		case "minimal 1 field harus diisi":
-			c.JSON(http.StatusBadRequest, gin.H{"error" : err.Error()})
-		case "table_id dan reservation_datetime sudah digunakan oleh reservasi lain":
-            c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
-        default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error" : "Failed to update reservation"})
-		}
+	if err := h.ReservationService.UpdateReservation(id, updateReservation, actor); err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK,gin.H{
 		"message" : "Reservation update successfuly",
 	})
-}
\ No newline at end of file
+}
+
+// ConfirmReservation godoc
+// @Summary      Confirm a reservation
+// @Description  Move a pending reservation to confirmed
+// @Tags         reservations
+// @Produce      json
+// @Param        id   path      int                  true  "Reservation ID"
+// @Success      200  {object}  map[string]string    "Reservation confirmed"
+// @Failure      400  {object}  response.ErrorResponse "Invalid reservation ID"
+// @Failure      409  {object}  response.ErrorResponse "Invalid status transition"
+// @Failure      500  {object}  response.ErrorResponse "Internal server error"
+// @Router       /api/reservation/{id}/confirm [post]
+func (h *ReservationHandler) ConfirmReservation(c *gin.Context) {
+	h.changeReservationStatus(c, models.ReservationStatusConfirmed)
+}
+
+// SeatReservation godoc
+// @Summary      Seat a reservation
+// @Description  Mark the guests of a confirmed reservation as seated
+// @Tags         reservations
+// @Produce      json
+// @Param        id   path      int                  true  "Reservation ID"
+// @Success      200  {object}  map[string]string    "Reservation seated"
+// @Failure      400  {object}  response.ErrorResponse "Invalid reservation ID"
+// @Failure      409  {object}  response.ErrorResponse "Invalid status transition"
+// @Failure      500  {object}  response.ErrorResponse "Internal server error"
+// @Router       /api/reservation/{id}/seat [post]
+func (h *ReservationHandler) SeatReservation(c *gin.Context) {
+	h.changeReservationStatus(c, models.ReservationStatusSeated)
+}
+
+// CompleteReservation godoc
+// @Summary      Complete a reservation
+// @Description  Mark a seated reservation as completed and free the table
+// @Tags         reservations
+// @Produce      json
+// @Param        id   path      int                  true  "Reservation ID"
+// @Success      200  {object}  map[string]string    "Reservation completed"
+// @Failure      400  {object}  response.ErrorResponse "Invalid reservation ID"
+// @Failure      409  {object}  response.ErrorResponse "Invalid status transition"
+// @Failure      500  {object}  response.ErrorResponse "Internal server error"
+// @Router       /api/reservation/{id}/complete [post]
+func (h *ReservationHandler) CompleteReservation(c *gin.Context) {
+	h.changeReservationStatus(c, models.ReservationStatusCompleted)
+}
+
+// CancelReservation godoc
+// @Summary      Cancel a reservation
+// @Description  Cancel a pending or confirmed reservation with an optional reason. Customers can only cancel their own reservations before the cutoff window
+// @Tags         reservations
+// @Accept       json
+// @Produce      json
+// @Param        id     path      int                    true   "Reservation ID"
+// @Param        input  body      dto.CancelReservation  false  "Cancellation reason"
+// @Success      200  {object}  map[string]string    "Reservation cancelled"
+// @Failure      400  {object}  response.ErrorResponse "Invalid reservation ID"
+// @Failure      403  {object}  response.ErrorResponse "Not the owner of the reservation"
+// @Failure      409  {object}  response.ErrorResponse "Cutoff window passed or invalid status transition"
+// @Failure      500  {object}  response.ErrorResponse "Internal server error"
+// @Router       /api/reservation/{id}/cancel [post]
+func (h *ReservationHandler) CancelReservation(c *gin.Context) {
+	h.cancelReservation(c)
+}
+
+// NoShowReservation godoc
+// @Summary      Mark a reservation as no-show
+// @Description  Mark a confirmed reservation whose guests never arrived as no-show
+// @Tags         reservations
+// @Produce      json
+// @Param        id   path      int                  true  "Reservation ID"
+// @Success      200  {object}  map[string]string    "Reservation marked as no-show"
+// @Failure      400  {object}  response.ErrorResponse "Invalid reservation ID"
+// @Failure      409  {object}  response.ErrorResponse "Invalid status transition"
+// @Failure      500  {object}  response.ErrorResponse "Internal server error"
+// @Router       /api/reservation/{id}/no-show [post]
+func (h *ReservationHandler) NoShowReservation(c *gin.Context) {
+	h.changeReservationStatus(c, models.ReservationStatusNoShow)
+}
+
+// PreviewReminderAction godoc
+// @Summary      Preview the action of a reminder link
+// @Description  Show the reservation and the confirm or cancel action in the signed token from a reminder email without running it. Mail link scanners and browser prefetch open links with GET, so the action only runs on POST /api/reservation/respond
+// @Tags         reservations
+// @Produce      json
+// @Param        token  query     string  true  "Reservation action token"
+// @Success      200    {object}  map[string]interface{} "Pending action and reservation"
+// @Failure      400    {object}  response.ErrorResponse "Token missing, invalid or expired"
+// @Failure      500    {object}  response.ErrorResponse "Internal server error"
+// @Router       /api/reservation/respond [get]
+func (h *ReservationHandler) PreviewReminderAction(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.Error(ErrInvalidQuery.Withf("token wajib diisi"))
+		return
+	}
+
+	reservation, action, err := h.ReservationService.PreviewReminderAction(token)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	message := "Send the token to POST /api/reservation/respond to confirm this reservation"
+	if action == utils.ReservationActionCancel {
+		message = "Send the token to POST /api/reservation/respond to cancel this reservation"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"data": gin.H{
+			"action":               action,
+			"reservation_id":       reservation.ID,
+			"reservation_datetime": reservation.ReservationDateTime,
+			"status":               reservation.Status,
+		},
+	})
+}
+
+// RespondToReminder godoc
+// @Summary      Confirm or cancel from a reminder email
+// @Description  Run the action in the signed token from the confirm or cancel link of a reminder email. No login is needed. Sending a token twice is not an error
+// @Tags         reservations
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.RespondToReminder  true  "Reservation action token"
+// @Success      200    {object}  map[string]interface{} "Reservation confirmed or cancelled"
+// @Failure      400    {object}  response.ErrorResponse "Token missing, invalid or expired"
+// @Failure      409    {object}  response.ErrorResponse "Cutoff window passed or invalid status transition"
+// @Failure      500    {object}  response.ErrorResponse "Internal server error"
+// @Router       /api/reservation/respond [post]
+func (h *ReservationHandler) RespondToReminder(c *gin.Context) {
+	var req dto.RespondToReminder
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	reservation, err := h.ReservationService.RespondToReminder(req.Token)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	message := "Reservation confirmed, see you soon"
+	if reservation.Status == models.ReservationStatusCancelled {
+		message = "Reservation cancelled Successfully"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"data": gin.H{
+			"reservation_id":       reservation.ID,
+			"reservation_datetime": reservation.ReservationDateTime,
+			"status":               reservation.Status,
+		},
+	})
+}
+
+// changeReservationStatus dipakai bersama oleh semua endpoint transisi status
+func (h *ReservationHandler) changeReservationStatus(c *gin.Context, status string) {
+	id, err := paramID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.ReservationService.ChangeReservationStatus(id, status); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reservation status updated successfully",
+		"status":  status,
+	})
+}
+
+// cancelReservation dipakai bersama oleh DELETE /reservation/:id dan POST /reservation/:id/cancel
+func (h *ReservationHandler) cancelReservation(c *gin.Context) {
+	id, err := paramID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Alasan pembatalan bersifat opsional
+	var req dto.CancelReservation
+	if c.Request.ContentLength > 0 {
+		if err := bindJSON(c, &req); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		c.Error(ErrUnauthenticated)
+		return
+	}
+
+	err = h.ReservationService.CancelReservation(id, actor, req.Reason)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reservation cancelled Successfully"})
+}
+
+// currentActor mengambil userID dan role dari claims JWT yang di-set oleh JWTAuthMiddleware,
+// beserta permission role yang di-set oleh LoadPermissions
+func currentActor(c *gin.Context) (services.Actor, bool) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		return services.Actor{}, false
+	}
+	role, ok := c.Get("role")
+	if !ok {
+		return services.Actor{}, false
+	}
+
+	userIDString, ok := userID.(string)
+	if !ok {
+		return services.Actor{}, false
+	}
+	id, err := strconv.Atoi(userIDString)
+	if err != nil {
+		return services.Actor{}, false
+	}
+	roleString, ok := role.(string)
+	if !ok {
+		return services.Actor{}, false
+	}
+
+	return services.Actor{UserID: id, Role: roleString, Permissions: c.GetStringSlice("permissions")}, true
+}
+
+// Konversi semua meja yang dipakai reservasi ke response
+func toTableResponses(tables []models.Table) []response.TableResponse {
+	result := []response.TableResponse{}
+	for _, table := range tables {
+		result = append(result, response.TableResponse{
+			ID:        table.ID,
+			TableName: table.TableName,
+			Capacity:  table.Capacity,
+			Status:    table.Status,
+			CreatedAt: table.CreatedAt,
+			UpdatedAt: table.UpdatedAt,
+		})
+	}
+	return result
+}
+
+// toGuestContactResponse mengembalikan nil untuk reservasi milik user supaya field guest tidak ikut dikirim
+func toGuestContactResponse(guest *models.GuestContact) *response.GuestContactResponse {
+	if guest == nil {
+		return nil
+	}
+	return &response.GuestContactResponse{
+		ID:    guest.ID,
+		Name:  guest.Name,
+		Phone: guest.Phone,
+		Email: guest.Email,
+	}
+}
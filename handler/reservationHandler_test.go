@@ -0,0 +1,392 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"wereserve/handler/response"
+	"wereserve/middleware"
+	"wereserve/models"
+	"wereserve/notification"
+	"wereserve/realtime"
+	"wereserve/repository/memory"
+	"wereserve/services"
+	"wereserve/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// policyTestServer berisi router dengan route user dan reservasi yang dijaga sama seperti di internal/app,
+// beserta satu user untuk setiap role yang dipakai di test
+type policyTestServer struct {
+	router *gin.Engine
+	store  *memory.Store
+	table  models.Table
+	users  map[string]models.User
+}
+
+// newPolicyTestServer memakai header X-User-ID sebagai pengganti JWT, lalu permission dibaca oleh middleware.LoadPermissions
+func newPolicyTestServer(t *testing.T) *policyTestServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	store := memory.NewStore()
+	users := map[string]models.User{}
+	for _, role := range []string{"owner", "other", "staff", "manager", "admin"} {
+		userRole := role
+		if role == "owner" || role == "other" {
+			userRole = models.RoleCustomer
+		}
+		user := models.User{Name: role, Email: role + "@example.com", Password: "secret", Role: userRole}
+		if err := store.Users().CreateUser(&user); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		users[role] = user
+	}
+
+	table := models.Table{TableName: "A1", Capacity: 4, Status: "available"}
+	if err := store.Tables().CreateTable(&table); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+
+	notifier := notification.NewEmailNotifier(notification.NewMemoryMailer(), notification.EmailOptions{})
+	userService := services.NewUserService(store.Users(), store.RefreshTokens(), store.PasswordResetTokens(), notifier, services.UserServiceOptions{})
+	scheduleService := services.NewScheduleService(store.Schedule())
+	hub := realtime.NewHub(0)
+	waitlistService := services.NewWaitlistService(store.UnitOfWork(), store.Waitlist(), store.Reservations(), store.Tables(), store.TableCombinations(), scheduleService, time.Minute, notifier, hub)
+	reservationService := services.NewReservationService(store.UnitOfWork(), store.Reservations(), store.Tables(), store.TableCombinations(), scheduleService, waitlistService, 0, hub)
+	userHandler := NewUserHandler(userService)
+	reservationHandler := NewReservationsHandler(reservationService)
+	tableHandler := NewTableHandler(services.NewTableService(store.Tables(), store.Reservations(), store.Webhooks(), hub))
+
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	r.GET("/api/reservation/respond", reservationHandler.PreviewReminderAction)
+	r.POST("/api/reservation/respond", reservationHandler.RespondToReminder)
+	api := r.Group("/api")
+	api.Use(func(c *gin.Context) {
+		id, _ := strconv.Atoi(c.GetHeader("X-User-ID"))
+		user, err := store.Users().GetUserByid(id)
+		if err != nil {
+			middleware.WriteError(c, middleware.ErrMissingToken)
+			return
+		}
+		c.Set("userID", strconv.Itoa(user.ID))
+		c.Set("role", user.Role)
+		c.Set("email", user.Email)
+	}, middleware.LoadPermissions(services.NewRoleService(store.Roles())))
+
+	api.GET("/users", middleware.RequirePermission(models.PermissionUserRead), userHandler.GetAllUser)
+	api.GET("/users/:id", userHandler.GetUserById)
+	api.PUT("/users/:id", userHandler.UpdateUser)
+	api.DELETE("/users/:id", middleware.RequirePermission(models.PermissionUserDelete), userHandler.DeleteUser)
+	api.PUT("/users/:id/role", middleware.RequirePermission(models.PermissionRoleManage), userHandler.UpdateUserRole)
+
+	api.GET("/reservation", middleware.RequirePermission(models.PermissionReservationRead), reservationHandler.GetAllReservation)
+	api.POST("/reservation", middleware.RequirePermission(models.PermissionReservationCreate), reservationHandler.CreateReservation)
+	api.GET("/reservation/:id", reservationHandler.GetReservationDetail)
+	api.PUT("/reservation/:id", reservationHandler.UpdateReservation)
+	api.DELETE("/reservation/:id", reservationHandler.DeleteReservation)
+	api.POST("/reservation/:id/confirm", middleware.RequirePermission(models.PermissionReservationUpdate), reservationHandler.ConfirmReservation)
+	api.POST("/reservation/:id/seat", middleware.RequirePermission(models.PermissionReservationUpdate), reservationHandler.SeatReservation)
+	api.POST("/reservation/:id/complete", middleware.RequirePermission(models.PermissionReservationUpdate), reservationHandler.CompleteReservation)
+	api.POST("/reservation/:id/cancel", reservationHandler.CancelReservation)
+	api.POST("/reservation/:id/no-show", middleware.RequirePermission(models.PermissionReservationUpdate), reservationHandler.NoShowReservation)
+	api.GET("/tables/:id", middleware.RequirePermission(models.PermissionTableRead), tableHandler.GetTableByID)
+
+	return &policyTestServer{router: r, store: store, table: table, users: users}
+}
+
+// do mengirim request sebagai user dengan nama tersebut, body nil berarti tanpa body
+func (s *policyTestServer) do(as, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", strconv.Itoa(s.users[as].ID))
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+// reservation membuat reservasi milik owner besok siang dengan status tertentu
+func (s *policyTestServer) reservation(t *testing.T, status string) models.Reservation {
+	t.Helper()
+
+	tomorrow := time.Now().AddDate(0, 0, 1)
+	return s.reservationAt(t, status, time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 12, 0, 0, 0, time.Local))
+}
+
+// reservationAt membuat reservasi milik owner pada waktu tertentu tanpa melewati validasi jadwal
+func (s *policyTestServer) reservationAt(t *testing.T, status string, start time.Time) models.Reservation {
+	t.Helper()
+
+	reservation := models.Reservation{
+		UserID:              s.users["owner"].ID,
+		TableID:             s.table.ID,
+		ReservationDateTime: start,
+		NumberOfPeople:      2,
+		Tables:              []models.Table{s.table},
+	}
+	if err := s.store.Reservations().CreateReservation(&reservation); err != nil {
+		t.Fatalf("failed to create reservation: %v", err)
+	}
+	if status != "" {
+		if err := s.store.Reservations().UpdateReservationStatus(reservation.ID, status); err != nil {
+			t.Fatalf("failed to set reservation status: %v", err)
+		}
+	}
+	return reservation
+}
+
+func TestReservationRoutesOwnershipPolicy(t *testing.T) {
+	tests := []struct {
+		method string
+		route  string
+		status string
+		body   interface{}
+		want   map[string]int
+	}{
+		{method: http.MethodGet, route: "", want: map[string]int{"owner": http.StatusForbidden, "staff": http.StatusOK, "admin": http.StatusOK}},
+		{method: http.MethodGet, route: "/:id", want: map[string]int{"owner": http.StatusOK, "other": http.StatusForbidden, "staff": http.StatusOK, "admin": http.StatusOK}},
+		{method: http.MethodPut, route: "/:id", body: map[string]int{"number_of_people": 3},
+			want: map[string]int{"owner": http.StatusOK, "other": http.StatusForbidden, "staff": http.StatusOK, "admin": http.StatusOK}},
+		{method: http.MethodDelete, route: "/:id", want: map[string]int{"owner": http.StatusOK, "other": http.StatusForbidden, "staff": http.StatusOK, "admin": http.StatusOK}},
+		{method: http.MethodPost, route: "/:id/cancel", want: map[string]int{"owner": http.StatusOK, "other": http.StatusForbidden, "staff": http.StatusOK, "admin": http.StatusOK}},
+		{method: http.MethodPost, route: "/:id/confirm", want: map[string]int{"owner": http.StatusForbidden, "staff": http.StatusOK, "admin": http.StatusOK}},
+		{method: http.MethodPost, route: "/:id/seat", status: models.ReservationStatusConfirmed,
+			want: map[string]int{"owner": http.StatusForbidden, "staff": http.StatusOK, "admin": http.StatusOK}},
+		{method: http.MethodPost, route: "/:id/complete", status: models.ReservationStatusSeated,
+			want: map[string]int{"owner": http.StatusForbidden, "staff": http.StatusOK, "admin": http.StatusOK}},
+		{method: http.MethodPost, route: "/:id/no-show", status: models.ReservationStatusConfirmed,
+			want: map[string]int{"owner": http.StatusForbidden, "staff": http.StatusOK, "admin": http.StatusOK}},
+	}
+
+	for _, tt := range tests {
+		for as, want := range tt.want {
+			t.Run(tt.method+" /api/reservation"+tt.route+" as "+as, func(t *testing.T) {
+				server := newPolicyTestServer(t)
+				reservation := server.reservation(t, tt.status)
+
+				path := "/api/reservation"
+				if tt.route != "" {
+					path += "/" + strconv.Itoa(reservation.ID) + tt.route[len("/:id"):]
+				}
+				if w := server.do(as, tt.method, path, tt.body); w.Code != want {
+					t.Errorf("status = %d, want %d, body = %s", w.Code, want, w.Body.String())
+				}
+			})
+		}
+	}
+}
+
+func TestReservationStatusTransitionMatrix(t *testing.T) {
+	actions := map[string]string{
+		"/confirm":  models.ReservationStatusConfirmed,
+		"/seat":     models.ReservationStatusSeated,
+		"/complete": models.ReservationStatusCompleted,
+		"/cancel":   models.ReservationStatusCancelled,
+		"/no-show":  models.ReservationStatusNoShow,
+	}
+	allowed := map[string][]string{
+		models.ReservationStatusPending:   {"/confirm", "/cancel"},
+		models.ReservationStatusConfirmed: {"/seat", "/cancel", "/no-show"},
+		models.ReservationStatusSeated:    {"/complete"},
+		models.ReservationStatusCompleted: nil,
+		models.ReservationStatusCancelled: nil,
+		models.ReservationStatusNoShow:    nil,
+	}
+	// Status meja diturunkan dari reservasi yang sedang berjalan, reservasi yang sudah selesai melepas mejanya
+	tableStatus := map[string]string{
+		models.ReservationStatusPending:   "reserved",
+		models.ReservationStatusConfirmed: "reserved",
+		models.ReservationStatusSeated:    "occupied",
+		models.ReservationStatusCompleted: "available",
+		models.ReservationStatusCancelled: "available",
+		models.ReservationStatusNoShow:    "available",
+	}
+
+	for from, next := range allowed {
+		for action, to := range actions {
+			isAllowed := false
+			for _, a := range next {
+				isAllowed = isAllowed || a == action
+			}
+
+			t.Run(from+" "+action, func(t *testing.T) {
+				server := newPolicyTestServer(t)
+				// Reservasi dimulai 30 menit lalu supaya sedang berjalan saat status meja dihitung
+				reservation := server.reservationAt(t, from, time.Now().Add(-30*time.Minute).Truncate(time.Minute))
+
+				wantCode, wantStatus := http.StatusConflict, from
+				if isAllowed {
+					wantCode, wantStatus = http.StatusOK, to
+				}
+				w := server.do("staff", http.MethodPost, "/api/reservation/"+strconv.Itoa(reservation.ID)+action, nil)
+				if w.Code != wantCode {
+					t.Fatalf("status = %d, want %d, body = %s", w.Code, wantCode, w.Body.String())
+				}
+
+				stored, err := server.store.Reservations().GetReservationDetail(reservation.ID)
+				if err != nil {
+					t.Fatalf("failed to load reservation: %v", err)
+				}
+				if stored.Status != wantStatus {
+					t.Errorf("reservation status = %s, want %s", stored.Status, wantStatus)
+				}
+
+				w = server.do("staff", http.MethodGet, "/api/tables/"+strconv.Itoa(server.table.ID), nil)
+				if w.Code != http.StatusOK {
+					t.Fatalf("table status = %d, body = %s", w.Code, w.Body.String())
+				}
+				var resp struct {
+					Data response.TableResponse `json:"data"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("invalid response body: %v", err)
+				}
+				if resp.Data.Status != tableStatus[wantStatus] {
+					t.Errorf("table status = %s, want %s", resp.Data.Status, tableStatus[wantStatus])
+				}
+			})
+		}
+	}
+}
+
+func TestCreateReservationOwnerFromToken(t *testing.T) {
+	tomorrow := time.Now().AddDate(0, 0, 1)
+	start := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name      string
+		as        string
+		body      map[string]interface{}
+		wantCode  int
+		wantOwner string
+		wantGuest string
+	}{
+		{name: "customer without user_id", as: "owner", body: map[string]interface{}{}, wantCode: http.StatusCreated, wantOwner: "owner"},
+		{name: "customer for another user", as: "owner", body: map[string]interface{}{"user_id": "other"}, wantCode: http.StatusForbidden},
+		{name: "customer for a guest", as: "owner", body: map[string]interface{}{"guest": map[string]string{"name": "Tamu", "phone": "0812"}}, wantCode: http.StatusForbidden},
+		{name: "staff for a user", as: "staff", body: map[string]interface{}{"user_id": "other"}, wantCode: http.StatusCreated, wantOwner: "other"},
+		{name: "staff for a guest", as: "staff", body: map[string]interface{}{"guest": map[string]string{"name": "Tamu", "phone": "0812"}}, wantCode: http.StatusCreated, wantGuest: "Tamu"},
+		{name: "guest without phone or email", as: "staff", body: map[string]interface{}{"guest": map[string]string{"name": "Tamu"}}, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newPolicyTestServer(t)
+			body := map[string]interface{}{"table_id": server.table.ID, "reservation_datetime": start, "number_of_people": 2}
+			for key, value := range tt.body {
+				body[key] = value
+			}
+			// user_id di tabel test ditulis sebagai nama user supaya id-nya diambil dari server
+			if name, ok := body["user_id"].(string); ok {
+				body["user_id"] = server.users[name].ID
+			}
+
+			w := server.do(tt.as, http.MethodPost, "/api/reservation", body)
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d, body = %s", w.Code, tt.wantCode, w.Body.String())
+			}
+			if w.Code != http.StatusCreated {
+				return
+			}
+
+			var resp struct {
+				Data response.ReservationResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid response body: %v", err)
+			}
+			if wantID := server.users[tt.wantOwner].ID; tt.wantOwner != "" && resp.Data.User.ID != wantID {
+				t.Errorf("user.id = %d, want %d", resp.Data.User.ID, wantID)
+			}
+			if tt.wantGuest != "" && (resp.Data.Guest == nil || resp.Data.Guest.Name != tt.wantGuest) {
+				t.Errorf("guest = %+v, want name %q", resp.Data.Guest, tt.wantGuest)
+			}
+		})
+	}
+}
+
+func TestUpdateReservationMovesGuestReservationToUser(t *testing.T) {
+	tomorrow := time.Now().AddDate(0, 0, 1)
+	start := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 12, 0, 0, 0, time.Local)
+	server := newPolicyTestServer(t)
+
+	w := server.do("staff", http.MethodPost, "/api/reservation", map[string]interface{}{"table_id": server.table.ID, "reservation_datetime": start,
+		"number_of_people": 2, "guest": map[string]string{"name": "Tamu", "phone": "0812"}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var created struct {
+		Data response.ReservationResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("invalid response body: %v", err)
+	}
+	path := "/api/reservation/" + strconv.Itoa(created.Data.ID)
+
+	// Reservasi hanya punya satu pemilik, jadi kontak tamu dilepas saat staff memindahkannya ke user
+	if w := server.do("staff", http.MethodPut, path, map[string]interface{}{"user_id": server.users["other"].ID}); w.Code != http.StatusOK {
+		t.Fatalf("update status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	reservation, err := server.store.Reservations().GetReservationDetail(created.Data.ID)
+	if err != nil {
+		t.Fatalf("failed to load reservation: %v", err)
+	}
+	if reservation.UserID != server.users["other"].ID || reservation.GuestContactID != nil {
+		t.Errorf("owner = user %d, guest %v, want only user %d", reservation.UserID, reservation.GuestContactID, server.users["other"].ID)
+	}
+	if w := server.do("other", http.MethodGet, path, nil); w.Code != http.StatusOK {
+		t.Errorf("new owner view status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestReminderResponseRunsOnlyOnPost(t *testing.T) {
+	server := newPolicyTestServer(t)
+	reservation := server.reservation(t, "")
+	token, err := utils.GenerateReservationActionToken(reservation.ID, utils.ReservationActionCancel, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GenerateReservationActionToken() error = %v", err)
+	}
+
+	status := func() string {
+		t.Helper()
+		stored, err := server.store.Reservations().GetReservationDetail(reservation.ID)
+		if err != nil {
+			t.Fatalf("failed to load reservation: %v", err)
+		}
+		return stored.Status
+	}
+
+	// Pemindai link email dan prefetch browser membuka link dengan GET, jadi GET hanya menampilkan aksinya
+	for i := 0; i < 2; i++ {
+		w := server.do("owner", http.MethodGet, "/api/reservation/respond?token="+token, nil)
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"action":"cancel"`) {
+			t.Fatalf("GET status = %d, body = %s", w.Code, w.Body.String())
+		}
+	}
+	if got := status(); got != models.ReservationStatusPending {
+		t.Fatalf("status after GET = %s, want %s", got, models.ReservationStatusPending)
+	}
+
+	if w := server.do("owner", http.MethodPost, "/api/reservation/respond", map[string]string{"token": token}); w.Code != http.StatusOK {
+		t.Fatalf("POST status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if got := status(); got != models.ReservationStatusCancelled {
+		t.Errorf("status after POST = %s, want %s", got, models.ReservationStatusCancelled)
+	}
+
+	if w := server.do("owner", http.MethodPost, "/api/reservation/respond", map[string]string{}); w.Code != http.StatusBadRequest {
+		t.Errorf("POST without token status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,17 @@
+package response
+
+import "time"
+
+type AvailabilitySlotResponse struct {
+	Start                 time.Time                  `json:"start"`
+	End                   time.Time                  `json:"end"`
+	AvailableTables       []TableResponse            `json:"available_tables"`
+	AvailableCombinations []TableCombinationResponse `json:"available_combinations"`
+}
+
+type AvailabilityResponse struct {
+	PartySize        int                        `json:"party_size"`
+	DurationMinutes  int                        `json:"duration_minutes"`
+	Requested        AvailabilitySlotResponse   `json:"requested"`
+	AlternativeSlots []AvailabilitySlotResponse `json:"alternative_slots"`
+}
@@ -0,0 +1,14 @@
+package response
+
+// ErrorResponse adalah body error semua endpoint dalam format RFC 7807 (application/problem+json),
+// ditambah kode error dan detail per field untuk error validasi
+type ErrorResponse struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail"`
+	// Path request yang gagal
+	Instance string            `json:"instance,omitempty"`
+	Code     string            `json:"code"`
+	Errors   map[string]string `json:"errors,omitempty"`
+}
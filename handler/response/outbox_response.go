@@ -0,0 +1,20 @@
+package response
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type OutboxMessageResponse struct {
+	ID            int             `json:"id"`
+	EventType     string          `json:"event_type"`
+	Recipient     string          `json:"recipient"`
+	Payload       json.RawMessage `json:"payload" swaggertype:"object"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	LastError     *string         `json:"last_error,omitempty"`
+	SentAt        *time.Time      `json:"sent_at,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
@@ -0,0 +1,9 @@
+package response
+
+// PaginationResponse adalah metadata halaman yang dikirim bersama data di endpoint list
+type PaginationResponse struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
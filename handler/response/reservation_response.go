@@ -5,9 +5,16 @@ import "time"
 type ReservationResponse struct {
     ID             int                 `json:"id"`
     User           UserPreloadResponse        `json:"user"`           // Preloaded User data
+    Guest          *GuestContactResponse      `json:"guest,omitempty"` // Kontak tamu jika reservasi dibuat staff untuk tamu tanpa akun
     Table          TableResponse       `json:"table"`          // Preloaded Table data
+    Tables         []TableResponse     `json:"tables"`         // Semua meja, lebih dari satu jika memakai kombinasi
+    CombinationID  *int                `json:"combination_id"`
     ReservationDateTime  time.Time      `json:"reservation_datetime"`
+    EndDateTime    time.Time           `json:"end_datetime"`
+    DurationMinutes int                `json:"duration_minutes"`
     NumberOfPeople int                 `json:"number_of_people"`
+    Status         string              `json:"status"`
+    CancellationReason string          `json:"cancellation_reason,omitempty"`
     CreatedAt      time.Time           `json:"created_at"`
     UpdatedAt      time.Time           `json:"updated_at"`
 }
@@ -19,9 +26,31 @@ type UserPreloadResponse struct {
 
 }
 
+type GuestContactResponse struct {
+    ID    int    `json:"id"`
+    Name  string `json:"name"`
+    Phone string `json:"phone"`
+    Email string `json:"email"`
+}
+
 type TablePreloadResponse struct {
     ID          int       `json:"id"`
     TableName   string    `json:"table_name"`
     Capacity    int       `json:"capacity"`
     Status      string    `json:"status"`
-}
\ No newline at end of file
+}
+// PublicReservationResponse dikirim ke tamu tanpa akun lewat endpoint publik, tanpa data user dan status meja
+type PublicReservationResponse struct {
+    ID             int                    `json:"id"`
+    Guest          *GuestContactResponse  `json:"guest"`
+    Tables         []TablePreloadResponse `json:"tables"`
+    CombinationID  *int                   `json:"combination_id"`
+    ReservationDateTime  time.Time        `json:"reservation_datetime"`
+    EndDateTime    time.Time              `json:"end_datetime"`
+    DurationMinutes int                   `json:"duration_minutes"`
+    NumberOfPeople int                    `json:"number_of_people"`
+    Status         string                 `json:"status"`
+    CancellationReason string             `json:"cancellation_reason,omitempty"`
+    CreatedAt      time.Time              `json:"created_at"`
+    UpdatedAt      time.Time              `json:"updated_at"`
+}
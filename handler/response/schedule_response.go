@@ -0,0 +1,23 @@
+package response
+
+type OpeningHourResponse struct {
+	DayOfWeek       int    `json:"day_of_week"`
+	Day             string `json:"day"`
+	OpenTime        string `json:"open_time"`
+	CloseTime       string `json:"close_time"`
+	LastSeatingTime string `json:"last_seating_time"`
+	IsClosed        bool   `json:"is_closed"`
+}
+
+type ClosureResponse struct {
+	ID        int    `json:"id"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Reason    string `json:"reason"`
+}
+
+type ScheduleResponse struct {
+	OpeningHours []OpeningHourResponse `json:"opening_hours"`
+	SlotMinutes  int                   `json:"slot_minutes"`
+	Closures     []ClosureResponse     `json:"closures"`
+}
@@ -0,0 +1,12 @@
+package response
+
+import "time"
+
+type TableCombinationResponse struct {
+	ID        int             `json:"id"`
+	Name      string          `json:"name"`
+	Capacity  int             `json:"capacity"`
+	Tables    []TableResponse `json:"tables"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
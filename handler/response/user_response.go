@@ -17,8 +17,4 @@ type ListUserResponse struct {
 	Role string `json:"role"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
-}
-
-type ErrorResponse struct {
-    Error string `json:"error"`
 }
\ No newline at end of file
@@ -0,0 +1,20 @@
+package response
+
+import "time"
+
+type WaitlistEntryResponse struct {
+	ID                   int                 `json:"id"`
+	User                 UserPreloadResponse `json:"user"`
+	PartySize            int                 `json:"party_size"`
+	DesiredStart         time.Time           `json:"desired_start"`
+	DesiredEnd           time.Time           `json:"desired_end"`
+	DurationMinutes      int                 `json:"duration_minutes"`
+	Status               string              `json:"status"`
+	OfferedTableID       *int                `json:"offered_table_id,omitempty"`
+	OfferedCombinationID *int                `json:"offered_combination_id,omitempty"`
+	OfferedDateTime      *time.Time          `json:"offered_datetime,omitempty"`
+	OfferExpiresAt       *time.Time          `json:"offer_expires_at,omitempty"`
+	ReservationID        *int                `json:"reservation_id,omitempty"`
+	CreatedAt            time.Time           `json:"created_at"`
+	UpdatedAt            time.Time           `json:"updated_at"`
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"net/http"
+	"wereserve/dto"
+	"wereserve/handler/response"
+	"wereserve/models"
+	"wereserve/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoleHandler struct {
+	RoleService *services.RoleService
+}
+
+func NewRoleHandler(roleService *services.RoleService) *RoleHandler {
+	return &RoleHandler{RoleService: roleService}
+}
+
+// GetPermissions godoc
+// @Summary      List permissions
+// @Description  Every permission that can be granted to a role, for example reservation:update or table:write. Requires role:manage
+// @Tags         roles
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   string                 "Permissions retrieved successfully"
+// @Failure      401  {object}  response.ErrorResponse "Unauthorized"
+// @Failure      403  {object}  response.ErrorResponse "Forbidden"
+// @Router       /api/permissions [get]
+func (h *RoleHandler) GetPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"message": "data get successfully",
+		"data":    models.Permissions,
+	})
+}
+
+// GetRoles godoc
+// @Summary      List roles
+// @Description  List roles with their permissions. Requires role:manage
+// @Tags         roles
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   response.RoleResponse  "Roles retrieved successfully"
+// @Failure      401  {object}  response.ErrorResponse "Unauthorized"
+// @Failure      403  {object}  response.ErrorResponse "Forbidden"
+// @Failure      500  {object}  response.ErrorResponse "Internal server error"
+// @Router       /api/roles [get]
+func (h *RoleHandler) GetRoles(c *gin.Context) {
+	roles, err := h.RoleService.GetRoles()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp := []response.RoleResponse{}
+	for _, role := range roles {
+		resp = append(resp, toRoleResponse(role))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "data get successfully",
+		"data":    resp,
+	})
+}
+
+// GetRole godoc
+// @Summary      Get a role
+// @Description  Get a role and its permissions by name. Requires role:manage
+// @Tags         roles
+// @Accept       json
+// @Produce      json
+// @Param        name  path      string                 true  "Role name"
+// @Success      200   {object}  response.RoleResponse  "Role retrieved successfully"
+// @Failure      404   {object}  response.ErrorResponse "Role not found"
+// @Router       /api/roles/{name} [get]
+func (h *RoleHandler) GetRole(c *gin.Context) {
+	role, err := h.RoleService.GetRole(c.Param("name"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "data get successfully",
+		"data":    toRoleResponse(*role),
+	})
+}
+
+// CreateRole godoc
+// @Summary      Create a role
+// @Description  Create a role such as host, manager or staff with a set of permissions. Requires role:manage
+// @Tags         roles
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.CreateRoleRequest  true  "Role details"
+// @Success      201    {object}  response.RoleResponse  "Role created successfully"
+// @Failure      400    {object}  response.ErrorResponse "Invalid request body, role name or permission"
+// @Failure      409    {object}  response.ErrorResponse "Role name already used"
+// @Router       /api/roles [post]
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req dto.CreateRoleRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	role, err := h.RoleService.CreateRole(services.RoleInput{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: req.Permissions,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Role created successfully",
+		"data":    toRoleResponse(*role),
+	})
+}
+
+// UpdateRole godoc
+// @Summary      Update a role
+// @Description  Replace the description and every permission of a role. Changes apply to logged in users immediately. The admin role cannot be changed. Requires role:manage
+// @Tags         roles
+// @Accept       json
+// @Produce      json
+// @Param        name   path      string                 true  "Role name"
+// @Param        input  body      dto.UpdateRoleRequest  true  "New description and permissions"
+// @Success      200    {object}  response.RoleResponse  "Role updated successfully"
+// @Failure      400    {object}  response.ErrorResponse "Invalid request body or permission"
+// @Failure      404    {object}  response.ErrorResponse "Role not found"
+// @Failure      409    {object}  response.ErrorResponse "Role is protected"
+// @Router       /api/roles/{name} [put]
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	var req dto.UpdateRoleRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	role, err := h.RoleService.UpdateRole(c.Param("name"), services.RoleInput{
+		Description: req.Description,
+		Permissions: req.Permissions,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role updated successfully",
+		"data":    toRoleResponse(*role),
+	})
+}
+
+// DeleteRole godoc
+// @Summary      Delete a role
+// @Description  Delete a role that is no longer assigned to any user. The admin and customer roles cannot be deleted. Requires role:manage
+// @Tags         roles
+// @Accept       json
+// @Produce      json
+// @Param        name  path      string                 true  "Role name"
+// @Success      200   {object}  map[string]string      "Role deleted successfully"
+// @Failure      404   {object}  response.ErrorResponse "Role not found"
+// @Failure      409   {object}  response.ErrorResponse "Role is protected or still assigned to users"
+// @Router       /api/roles/{name} [delete]
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	if err := h.RoleService.DeleteRole(c.Param("name")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role deleted successfully"})
+}
+
+func toRoleResponse(role models.Role) response.RoleResponse {
+	return response.RoleResponse{
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: role.Permissions,
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+	"wereserve/dto"
+	"wereserve/handler/response"
+	"wereserve/models"
+	"wereserve/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ScheduleHandler struct {
+	ScheduleService *services.ScheduleService
+}
+
+func NewScheduleHandler(scheduleService *services.ScheduleService) *ScheduleHandler {
+	return &ScheduleHandler{ScheduleService: scheduleService}
+}
+
+// GetSchedule godoc
+// @Summary      Get the restaurant schedule
+// @Description  Retrieve weekly opening hours, slot granularity and upcoming closures
+// @Tags         schedule
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  response.ScheduleResponse "Schedule retrieved successfully"
+// @Failure      500  {object}  response.ErrorResponse    "Internal server error"
+// @Router       /api/schedule [get]
+func (h *ScheduleHandler) GetSchedule(c *gin.Context) {
+	schedule, err := h.ScheduleService.GetSchedule()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp := response.ScheduleResponse{
+		OpeningHours: []response.OpeningHourResponse{},
+		SlotMinutes:  schedule.SlotMinutes,
+		Closures:     []response.ClosureResponse{},
+	}
+	for _, hour := range schedule.OpeningHours {
+		resp.OpeningHours = append(resp.OpeningHours, response.OpeningHourResponse{
+			DayOfWeek:       hour.DayOfWeek,
+			Day:             time.Weekday(hour.DayOfWeek).String(),
+			OpenTime:        hour.OpenTime,
+			CloseTime:       hour.CloseTime,
+			LastSeatingTime: hour.LastSeatingTime,
+			IsClosed:        hour.IsClosed,
+		})
+	}
+	for _, closure := range schedule.Closures {
+		resp.Closures = append(resp.Closures, toClosureResponse(closure))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "data get successfully",
+		"data":    resp,
+	})
+}
+
+// UpdateOpeningHours godoc
+// @Summary      Update weekly opening hours
+// @Description  Set opening, closing and last seating time for one or more days of the week (0 = Sunday)
+// @Tags         schedule
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.UpdateOpeningHoursRequest  true  "Opening hours per day"
+// @Success      200    {object}  map[string]string              "Opening hours updated successfully"
+// @Failure      400    {object}  response.ErrorResponse         "Invalid request body or validation failed"
+// @Router       /api/schedule/opening-hours [put]
+func (h *ScheduleHandler) UpdateOpeningHours(c *gin.Context) {
+	var req dto.UpdateOpeningHoursRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	var hours []models.OpeningHour
+	for _, day := range req.Days {
+		hour := models.OpeningHour{
+			DayOfWeek:       day.DayOfWeek,
+			OpenTime:        day.OpenTime,
+			CloseTime:       day.CloseTime,
+			LastSeatingTime: day.LastSeatingTime,
+			IsClosed:        day.IsClosed,
+		}
+		// Hari yang tutup tetap butuh nilai jam yang valid di database
+		if hour.IsClosed {
+			hour.OpenTime, hour.CloseTime, hour.LastSeatingTime = "00:00", "00:00", "00:00"
+		}
+		hours = append(hours, hour)
+	}
+
+	if err := h.ScheduleService.UpdateOpeningHours(hours); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Opening hours update successfully"})
+}
+
+// UpdateSettings godoc
+// @Summary      Update schedule settings
+// @Description  Set the slot granularity in minutes used to validate reservation start times
+// @Tags         schedule
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.UpdateScheduleSettingsRequest  true  "Schedule settings"
+// @Success      200    {object}  map[string]string                  "Schedule settings updated successfully"
+// @Failure      400    {object}  response.ErrorResponse             "Invalid request body or validation failed"
+// @Failure      500    {object}  response.ErrorResponse             "Internal server error"
+// @Router       /api/schedule/settings [put]
+func (h *ScheduleHandler) UpdateSettings(c *gin.Context) {
+	var req dto.UpdateScheduleSettingsRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.ScheduleService.UpdateSlotMinutes(req.SlotMinutes); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Schedule settings update successfully"})
+}
+
+// CreateClosure godoc
+// @Summary      Add a closure
+// @Description  Close the restaurant for a holiday or special event between two dates (inclusive)
+// @Tags         schedule
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.CreateClosureRequest  true  "Closure details"
+// @Success      201    {object}  response.ClosureResponse  "Closure created successfully"
+// @Failure      400    {object}  response.ErrorResponse    "Invalid request body or validation failed"
+// @Failure      500    {object}  response.ErrorResponse    "Internal server error"
+// @Router       /api/schedule/closures [post]
+func (h *ScheduleHandler) CreateClosure(c *gin.Context) {
+	var req dto.CreateClosureRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Format tanggal sudah divalidasi oleh validator
+	startDate, _ := time.Parse("2006-01-02", req.StartDate)
+	endDate, _ := time.Parse("2006-01-02", req.EndDate)
+	closure := models.ScheduleClosure{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Reason:    req.Reason,
+	}
+
+	if err := h.ScheduleService.CreateClosure(&closure); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Closure created successfully",
+		"data":    toClosureResponse(closure),
+	})
+}
+
+// DeleteClosure godoc
+// @Summary      Delete a closure
+// @Description  Remove a holiday or special-event closure
+// @Tags         schedule
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Closure ID"
+// @Success      200  {object}  map[string]string      "Closure deleted successfully"
+// @Failure      400  {object}  response.ErrorResponse "Invalid closure ID"
+// @Failure      500  {object}  response.ErrorResponse "Internal server error"
+// @Router       /api/schedule/closures/{id} [delete]
+func (h *ScheduleHandler) DeleteClosure(c *gin.Context) {
+	id, err := paramID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.ScheduleService.DeleteClosure(id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Closure deleted successfully"})
+}
+
+func toClosureResponse(closure models.ScheduleClosure) response.ClosureResponse {
+	return response.ClosureResponse{
+		ID:        closure.ID,
+		StartDate: closure.StartDate.Format("2006-01-02"),
+		EndDate:   closure.EndDate.Format("2006-01-02"),
+		Reason:    closure.Reason,
+	}
+}
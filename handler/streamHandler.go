@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+	"wereserve/realtime"
+	"wereserve/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Jeda komentar keep-alive supaya proxy tidak menutup koneksi yang sedang diam
+const streamHeartbeatInterval = 25 * time.Second
+
+type StreamHandler struct {
+	Hub           *realtime.Hub
+	TicketService *services.StreamTicketService
+}
+
+func NewStreamHandler(hub *realtime.Hub, ticketService *services.StreamTicketService) *StreamHandler {
+	return &StreamHandler{Hub: hub, TicketService: ticketService}
+}
+
+// CreateStreamTicket godoc
+// @Summary      Create a stream ticket
+// @Description  Create a single-use ticket for opening GET /api/stream with EventSource, which cannot send the Authorization header. The ticket expires after 30 seconds
+// @Tags         stream
+// @Produce      json
+// @Success      201  {object}  map[string]interface{} "Stream ticket created"
+// @Failure      401  {object}  response.ErrorResponse "Unauthorized"
+// @Failure      429  {object}  response.ErrorResponse "Too many requests"
+// @Failure      500  {object}  response.ErrorResponse "Internal server error"
+// @Router       /api/stream/ticket [post]
+func (h *StreamHandler) CreateStreamTicket(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		c.Error(ErrUnauthenticated)
+		return
+	}
+
+	ticket, expiresAt, err := h.TicketService.IssueTicket(actor.UserID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Stream ticket created successfully",
+		"ticket":     ticket,
+		"expires_at": expiresAt,
+	})
+}
+
+// Stream godoc
+// @Summary      Stream table and reservation updates
+// @Description  Server-Sent Events stream of table.status_changed, reservation.created, reservation.updated, reservation.cancelled and reservation.status_changed events.
+// @Description  Each message has the event type as `event` and the JSON event as `data`. Users with the reservation:read permission receive every event, others only table events and their own reservations.
+// @Description  Browsers using EventSource cannot send headers, so they pass a ticket from POST /api/stream/ticket as ?ticket= instead. Each ticket works once.
+// @Description  A client that reads too slowly is disconnected and should reconnect, then reload current state with GET /api/tables
+// @Tags         stream
+// @Produce      text/event-stream
+// @Param        ticket  query     string  false  "Stream ticket, only needed when the Authorization header cannot be set"
+// @Success      200     {string}  string                 "Event stream"
+// @Failure      401     {object}  response.ErrorResponse "Unauthorized or invalid ticket"
+// @Failure      429     {object}  response.ErrorResponse "Too many requests"
+// @Router       /api/stream [get]
+func (h *StreamHandler) Stream(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		c.Error(ErrUnauthenticated)
+		return
+	}
+
+	subscriber := h.Hub.Subscribe()
+	defer h.Hub.Unsubscribe(subscriber)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Matikan buffering di nginx supaya event langsung sampai ke client
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// Komentar pertama memastikan header langsung terkirim dan client tahu koneksi sudah terbuka
+	fmt.Fprint(c.Writer, ": connected\n\n")
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case event, open := <-subscriber.Events():
+			// Channel ditutup oleh hub jika client terlalu lambat atau server berhenti
+			if !open {
+				return
+			}
+			if !event.VisibleTo(actor.UserID, services.PolicyReservationRead.IsOverridden(actor)) {
+				continue
+			}
+
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Printf("Failed to encode realtime event %s: %v", event.Type, err)
+				continue
+			}
+			if _, err := fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"wereserve/middleware"
+	"wereserve/realtime"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newStreamTestServer menjalankan endpoint stream dengan user yang sudah login sebagai userID dan role
+func newStreamTestServer(t *testing.T, hub *realtime.Hub, userID, role string) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	r.GET("/api/stream", func(c *gin.Context) {
+		c.Set("userID", userID)
+		c.Set("role", role)
+	}, NewStreamHandler(hub, nil).Stream)
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// readStreamEvent membaca pesan SSE berikutnya dan mengembalikan nama event beserta datanya
+func readStreamEvent(t *testing.T, reader *bufio.Reader) (string, string) {
+	t.Helper()
+
+	var event, data string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read stream: %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "" && event != "":
+			return event, data
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestStreamSendsVisibleEvents(t *testing.T) {
+	hub := realtime.NewHub(10)
+	server := newStreamTestServer(t, hub, "1", "customer")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/stream", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("stream response = %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	// Tunggu sampai handler sudah berlangganan sebelum event dikirim
+	reader := bufio.NewReader(resp.Body)
+	if line, _ := reader.ReadString('\n'); line != ": connected\n" {
+		t.Fatalf("first line = %q", line)
+	}
+
+	hub.Publish(realtime.Event{Type: "reservation.created", UserID: 2, Data: map[string]int{"id": 10}})
+	hub.Publish(realtime.Event{Type: "table.status_changed", Data: map[string]int{"table_id": 3}})
+	hub.Publish(realtime.Event{Type: "reservation.updated", UserID: 1, Data: map[string]int{"id": 11}})
+
+	// Reservasi milik user lain tidak dikirim ke customer
+	event, data := readStreamEvent(t, reader)
+	if event != "table.status_changed" || !strings.Contains(data, `"table_id":3`) {
+		t.Errorf("first event = %s %s", event, data)
+	}
+	event, data = readStreamEvent(t, reader)
+	if event != "reservation.updated" || !strings.Contains(data, `"id":11`) {
+		t.Errorf("second event = %s %s", event, data)
+	}
+
+	// Koneksi yang ditutup client melepas subscriber dari hub
+	cancel()
+	deadline := time.Now().Add(2 * time.Second)
+	for hub.SubscriberCount() != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := hub.SubscriberCount(); got != 0 {
+		t.Errorf("SubscriberCount() after disconnect = %d, want 0", got)
+	}
+}
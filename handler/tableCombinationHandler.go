@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"net/http"
+	"wereserve/dto"
+	"wereserve/handler/response"
+	"wereserve/models"
+	"wereserve/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TableCombinationHandler struct {
+	TableCombinationService *services.TableCombinationService
+}
+
+func NewTableCombinationHandler(combinationService *services.TableCombinationService) *TableCombinationHandler {
+	return &TableCombinationHandler{TableCombinationService: combinationService}
+}
+
+// GetListCombination godoc
+// @Summary      Get all table combinations
+// @Description  Retrieve all admin-defined groups of tables that can be booked together
+// @Tags         table-combinations
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   response.TableCombinationResponse "List of table combinations retrieved successfully"
+// @Failure      500  {object}  response.ErrorResponse            "Internal server error"
+// @Router       /api/table-combinations [get]
+func (h *TableCombinationHandler) GetListCombination(c *gin.Context) {
+	combinations, err := h.TableCombinationService.GetAllCombinations()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	combinationResponse := []response.TableCombinationResponse{}
+	for _, combination := range combinations {
+		combinationResponse = append(combinationResponse, toTableCombinationResponse(combination))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": combinationResponse,
+	})
+}
+
+// GetCombinationByID godoc
+// @Summary      Get a table combination by ID
+// @Description  Retrieve a table combination and its tables based on its ID
+// @Tags         table-combinations
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int                  true  "Table combination ID"
+// @Success      200  {object}  response.TableCombinationResponse "Table combination retrieved successfully"
+// @Failure      400  {object}  response.ErrorResponse "Invalid table combination ID"
+// @Failure      404  {object}  response.ErrorResponse "Table combination not found"
+// @Router       /api/table-combinations/{id} [get]
+func (h *TableCombinationHandler) GetCombinationByID(c *gin.Context) {
+	id, err := paramID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	combination, err := h.TableCombinationService.GetCombinationByID(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "data get successfully",
+		"data":    toTableCombinationResponse(*combination),
+	})
+}
+
+// CreateCombination godoc
+// @Summary      Create a table combination
+// @Description  Define a group of at least two tables that can be combined for large parties
+// @Tags         table-combinations
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.CreateTableCombinationRequest  true  "Table combination details"
+// @Success      201    {object}  response.TableCombinationResponse  "Table combination created successfully"
+// @Failure      400    {object}  response.ErrorResponse             "Invalid request body or validation failed"
+// @Failure      500    {object}  response.ErrorResponse             "Internal server error"
+// @Router       /api/table-combinations [post]
+func (h *TableCombinationHandler) CreateCombination(c *gin.Context) {
+	var req dto.CreateTableCombinationRequest
+
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	combination, err := h.TableCombinationService.CreateCombination(req.Name, req.TableIDs)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Table combination created successfully",
+		"data":    toTableCombinationResponse(*combination),
+	})
+}
+
+// DeleteCombination godoc
+// @Summary      Delete a table combination
+// @Description  Delete a table combination. The tables themselves are kept
+// @Tags         table-combinations
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Table combination ID"
+// @Success      200  {object}  map[string]string      "Table combination deleted successfully"
+// @Failure      400  {object}  response.ErrorResponse "Invalid table combination ID"
+// @Failure      500  {object}  response.ErrorResponse "Internal server error"
+// @Router       /api/table-combinations/{id} [delete]
+func (h *TableCombinationHandler) DeleteCombination(c *gin.Context) {
+	id, err := paramID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.TableCombinationService.DeleteCombination(id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Table combination deleted successfully"})
+}
+
+func toTableCombinationResponse(combination models.TableCombination) response.TableCombinationResponse {
+	return response.TableCombinationResponse{
+		ID:        combination.ID,
+		Name:      combination.Name,
+		Capacity:  combination.Capacity(),
+		Tables:    toTableResponses(combination.Tables),
+		CreatedAt: combination.CreatedAt,
+		UpdatedAt: combination.UpdatedAt,
+	}
+}
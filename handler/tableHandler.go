@@ -2,14 +2,13 @@ package handler
 
 import (
 	"net/http"
-	"strconv"
 	"wereserve/dto"
 	"wereserve/handler/response"
 	"wereserve/models"
+	"wereserve/repository"
 	"wereserve/services"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 )
 
 type TableHandler struct {
@@ -21,24 +20,39 @@ func NewTableHandler(tableService *services.TableService) *TableHandler {
 }
 
 // GetListTable godoc
-// @Summary      Get all tables
-// @Description  Retrieve a list of all tables
+// @Summary      List tables
+// @Description  Retrieve one page of tables with their current status, optionally filtered by minimum capacity
 // @Tags         tables
 // @Accept       json
 // @Produce      json
+// @Param        page          query     int     false  "Page number, starting at 1"
+// @Param        limit         query     int     false  "Rows per page (default 20, max 100)"
+// @Param        sort          query     string  false  "Sort field: id, table_name, capacity, created_at. Prefix with - for descending"
+// @Param        min_capacity  query     int     false  "Only tables with at least this many seats"
 // @Success      200  {array}   response.TableResponse "List of tables retrieved successfully"
+// @Failure      400  {object}  response.ErrorResponse "Invalid query parameter"
 // @Failure      500  {object}  response.ErrorResponse "Internal server error"
 // @Router       /api/tables [get]
 func (h *TableHandler) GetListTable(c *gin.Context) {
-	tables, err := h.TableService.GetAllTable()
+	page, err := parsePage(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error" : err.Error()})
-			return
+		c.Error(err)
+		return
+	}
+	minCapacity, err := parseIntQuery(c, "min_capacity")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	tables, total, err := h.TableService.ListTables(repository.TableFilter{MinCapacity: minCapacity, Page: page})
+	if err != nil {
+		c.Error(err)
+		return
 	}
 
 	// Convert ke response list
-	var tableResponse []response.TableResponse
+	tableResponse := []response.TableResponse{}
 	for _, table := range tables{
 		tableResponse = append(tableResponse, response.TableResponse{
 			ID:        table.ID,
@@ -52,6 +66,7 @@ func (h *TableHandler) GetListTable(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"data" : tableResponse,
+		"pagination": toPaginationResponse(page, total),
 	})
 }
 
@@ -69,17 +84,16 @@ func (h *TableHandler) GetListTable(c *gin.Context) {
 // @Router       /api/tables/{id} [get]
 func (h *TableHandler) GetTableByID(c *gin.Context) {
 	//get Param
-	ParamId := c.Param("id")
-	id, err := strconv.Atoi(ParamId)
+	id, err := paramID(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error" : "Id tidak ditemukan"})
+		c.Error(err)
 		return
 	}
 
 	//panggil service
 	table, err := h.TableService.GetTableById(id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error" : "Data not Found"})
+		c.Error(err)
 		return
 	}
 
@@ -114,19 +128,8 @@ func (h *TableHandler) CreateTable(c *gin.Context) {
 	var req dto.CreateTableRequest
 
 	// bind json 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error" : err.Error()})
-		return
-	}
-
-
-	// Validation Json Req
-	if err := dto.Validate.Struct(req); err != nil {
-		errors := make(map[string]string)
-		for _, err := range err.(validator.ValidationErrors) {
-			errors[err.Field()] = err.Tag()
-		}
-		c.JSON(http.StatusBadRequest, gin.H{"error" : "Validator failed", "details" : errors})
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -139,7 +142,7 @@ func (h *TableHandler) CreateTable(c *gin.Context) {
 
 	// panggil service
 	if err := h.TableService.CreateTable(&table); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error" : err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -164,29 +167,17 @@ func (h *TableHandler) CreateTable(c *gin.Context) {
 // @Router       /api/tables/{id} [put]
 func (h *TableHandler) UpdateTable(c *gin.Context) {
 	// Ambil ID dari paramater
-	paramID := c.Param("id")
-	id, err := strconv.Atoi(paramID)
+	id, err := paramID(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error" : "Invalid ID"})
+		c.Error(err)
+		return
 	}
 
 
 	// Bind JSON request 
 	var req dto.UpdateTableRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error" : err.Error()})
-		return
-	}
-
-
-	// validation
-	if err := dto.Validate.Struct(req); err != nil {
-		errors := make(map[string]string)
-		for _,err := range err.(validator.ValidationErrors) {
-			errors[err.Field()] = err.Tag()
-		}
-
-		c.JSON(http.StatusBadRequest, gin.H{"error" : "Validation Error", "details" : errors})
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -198,7 +189,7 @@ func (h *TableHandler) UpdateTable(c *gin.Context) {
 	}
 
 	if err := h.TableService.UpdateTable(id, table); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error" : err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -218,16 +209,16 @@ func (h *TableHandler) UpdateTable(c *gin.Context) {
 // @Router       /api/tables/{id} [delete]
 func (h *TableHandler) DeleteTable(c *gin.Context) {
 	// Ambil id 
-	ParamID := c.Param("id")
-	id, err := strconv.Atoi(ParamID)
+	id, err := paramID(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error" : "Id Tidak ditemukan"})
+		c.Error(err)
+		return
 	}
 
 	// bind JSON Request 
 	err = h.TableService.DeleteTable(id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error" : err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message" : "Table deleted successfully"})
@@ -247,19 +238,19 @@ func (h *TableHandler) DeleteTable(c *gin.Context) {
 func (h *TableHandler) GetTableByStatus(c *gin.Context) {
 	status := c.Query("status")
 	if status == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error" : "Status is required"})
+		c.Error(ErrValidationFailed.WithFields(map[string]string{"status": "required"}))
 		return
 	}
 
 	// panggil service
 	tables, err := h.TableService.GetTableByStatus(status)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"errors" : err.Error()})
+		c.Error(err)
 		return
 	}
 
 	// Convert ke response list
-	var tableResponse []response.TableResponse
+	tableResponse := []response.TableResponse{}
 	for _, table := range tables {
 		tableResponse = append(tableResponse, response.TableResponse{
 			ID:        table.ID,
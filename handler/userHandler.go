@@ -2,14 +2,13 @@ package handler
 
 import (
 	"net/http"
-	"strconv"
 	"wereserve/dto"
 	response "wereserve/handler/response"
 	"wereserve/models"
+	"wereserve/repository"
 	"wereserve/services"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 )
 
 type UserHandler struct {
@@ -30,24 +29,15 @@ func NewUserHandler(userService *services.UserService) *UserHandler {
 // @Param        input  body      dto.RegisterRequest  true  "User registration details"
 // @Success      201    {object}  response.UserResponse "User registered successfully"
 // @Failure      400    {object}  response.ErrorResponse        "Invalid request body or validation failed"
+// @Failure      429    {object}  response.ErrorResponse     "Too many requests, retry after the number of seconds in the Retry-After header"
 // @Failure      500    {object}  response.ErrorResponse        "Internal server error"
 // @Router       /api/register [post]
 func (h *UserHandler) Register(c *gin.Context) {
 	var req dto.RegisterRequest
 
 	//Bind json request body ke struct
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error" : "Invalid body Request"})
-		return
-	}
-
-	// Validasi input menggunakan validator10
-	if err := dto.Validate.Struct(req); err != nil {
-		errors := make(map[string]string)
-		for _, err := range err.(validator.ValidationErrors) {
-			errors[err.Field()] = err.Tag()
-		}
-		c.JSON(http.StatusBadRequest, gin.H{"error" : "Validator failed", "details" : errors})
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -55,16 +45,15 @@ func (h *UserHandler) Register(c *gin.Context) {
 		Name:      req.Name,
 		Email:     req.Email,
 		Password:  req.Password,
-		Role:      req.Role,
 	}
 	
 	// Panggil service untuk register user
 	if err := h.UserService.RegisterUser(&user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "User registered successfully"})
+	c.JSON(http.StatusCreated, gin.H{"message": "User registered successfully, please check your email to verify your account"})
 }
 
 // Login godoc
@@ -74,43 +63,212 @@ func (h *UserHandler) Register(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param        input  body      dto.LoginRequest  true  "User login details"
-// @Success      200    {object}  map[string]string "Login successful, returns JWT token"
+// @Success      200    {object}  map[string]string "Login successful, returns JWT token and refresh token"
 // @Failure      400    {object}  response.ErrorResponse     "Invalid request body or validation failed"
 // @Failure      401    {object}  response.ErrorResponse     "Unauthorized, invalid credentials"
+// @Failure      429    {object}  response.ErrorResponse     "Too many requests or too many failed logins, retry after the number of seconds in the Retry-After header"
 // @Failure      500    {object}  response.ErrorResponse     "Internal server error"
 // @Router       /api/login [post]
 func (h *UserHandler) Login(c *gin.Context) {
 	var req dto.LoginRequest
 	
 	//bind JSON Request body struct
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error" : "invalid Body Request"})
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	//panggil Service untuk login user
+	tokens, err := h.UserService.LoginUser(req.Email, req.Password)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
+	//Set cookies
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie("Authorization", tokens.AccessToken, 3600*24*3, "","", false, true)
 
-	// Validasi input using validator10
-	if err := dto.Validate.Struct(req); err != nil {
-		errors := make(map[string]string)
-		for _,err := range err.(validator.ValidationErrors) {
-			errors[err.Field()] = err.Tag()
-		}
-		c.JSON(http.StatusBadRequest, gin.H{"errors" : "Validator failed", "details" : errors})
+	c.JSON(http.StatusOK, gin.H{"token" : tokens.AccessToken, "refresh_token" : tokens.RefreshToken})
+}
+
+// RefreshToken godoc
+// @Summary      Refresh access token
+// @Description  Exchange a refresh token for a new access token and refresh token. Each refresh token can only be used once; reusing one revokes the whole session
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.RefreshTokenRequest  true  "Refresh token"
+// @Success      200    {object}  map[string]string "Returns new JWT token and refresh token"
+// @Failure      400    {object}  response.ErrorResponse     "Invalid request body or validation failed"
+// @Failure      401    {object}  response.ErrorResponse     "Refresh token invalid, expired or reused"
+// @Failure      429    {object}  response.ErrorResponse     "Too many requests, retry after the number of seconds in the Retry-After header"
+// @Failure      500    {object}  response.ErrorResponse     "Internal server error"
+// @Router       /api/token/refresh [post]
+func (h *UserHandler) RefreshToken(c *gin.Context) {
+	var req dto.RefreshTokenRequest
+
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
 		return
 	}
 
-	//panggil Service untuk login user
-	token, err := h.UserService.LoginUser(req.Email, req.Password)
+	tokens, err := h.UserService.RefreshToken(req.RefreshToken)
 	if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error" : err.Error()})
-			return
+		c.Error(err)
+		return
 	}
 
-	//Set cookies
 	c.SetSameSite(http.SameSiteLaxMode)
-	c.SetCookie("Authorization", token, 3600*24*3, "","", false, true)
+	c.SetCookie("Authorization", tokens.AccessToken, 3600*24*3, "","", false, true)
+
+	c.JSON(http.StatusOK, gin.H{"token" : tokens.AccessToken, "refresh_token" : tokens.RefreshToken})
+}
+
+// Logout godoc
+// @Summary      Logout
+// @Description  Revoke the given refresh token's session and every access token of the logged in user
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.LogoutRequest  false  "Refresh token of the current session"
+// @Success      200    {object}  map[string]string "Logout successful"
+// @Failure      401    {object}  response.ErrorResponse     "Unauthorized"
+// @Failure      500    {object}  response.ErrorResponse     "Internal server error"
+// @Router       /api/logout [post]
+func (h *UserHandler) Logout(c *gin.Context) {
+	var req dto.LogoutRequest
+
+	// Body boleh kosong, hanya access token yang dicabut
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(validationError(err))
+			return
+		}
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		c.Error(ErrUnauthenticated)
+		return
+	}
+
+	if err := h.UserService.Logout(actor.UserID, req.RefreshToken); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.SetCookie("Authorization", "", -1, "","", false, true)
+	c.JSON(http.StatusOK, gin.H{"message" : "Logout successful"})
+}
+
+// ForgotPassword godoc
+// @Summary      Request a password reset
+// @Description  Send a one-time password reset link to the given email. The response is the same whether or not the email is registered
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.ForgotPasswordRequest  true  "Account email"
+// @Success      200    {object}  map[string]string "Reset link sent if the email is registered"
+// @Failure      400    {object}  response.ErrorResponse     "Invalid request body or validation failed"
+// @Failure      429    {object}  response.ErrorResponse     "Too many requests, retry after the number of seconds in the Retry-After header"
+// @Failure      500    {object}  response.ErrorResponse     "Internal server error"
+// @Router       /api/password/forgot [post]
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	var req dto.ForgotPasswordRequest
 
-	c.JSON(http.StatusOK, gin.H{"token" : token})
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.UserService.ForgotPassword(req.Email); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message" : "Jika email terdaftar, link reset password sudah dikirim"})
+}
+
+// ResetPassword godoc
+// @Summary      Reset password
+// @Description  Set a new password using the token from the reset email. All existing sessions of the user are revoked
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.ResetPasswordRequest  true  "Reset token and new password"
+// @Success      200    {object}  map[string]string "Password reset successfully"
+// @Failure      400    {object}  response.ErrorResponse     "Invalid request body, validation failed or invalid token"
+// @Failure      429    {object}  response.ErrorResponse     "Too many requests, retry after the number of seconds in the Retry-After header"
+// @Failure      500    {object}  response.ErrorResponse     "Internal server error"
+// @Router       /api/password/reset [post]
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordRequest
+
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.UserService.ResetPassword(req.Token, req.Password); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message" : "Password berhasil diubah, silakan login ulang"})
+}
+
+// VerifyEmail godoc
+// @Summary      Verify email address
+// @Description  Mark the account's email as verified using the signed token from the verification email
+// @Tags         users
+// @Produce      json
+// @Param        token  query     string  true  "Verification token"
+// @Success      200    {object}  map[string]string "Email verified successfully"
+// @Failure      400    {object}  response.ErrorResponse     "Token missing, invalid or expired"
+// @Failure      500    {object}  response.ErrorResponse     "Internal server error"
+// @Router       /api/email/verify [get]
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.Error(ErrInvalidQuery.Withf("token wajib diisi"))
+		return
+	}
+
+	if err := h.UserService.VerifyEmail(token); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message" : "Email berhasil diverifikasi"})
+}
+
+// ResendVerification godoc
+// @Summary      Resend verification email
+// @Description  Send a new verification link. The response is the same whether or not the email is registered or already verified
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.ResendVerificationRequest  true  "Account email"
+// @Success      200    {object}  map[string]string "Verification link sent if the account still needs it"
+// @Failure      400    {object}  response.ErrorResponse     "Invalid request body or validation failed"
+// @Failure      429    {object}  response.ErrorResponse     "Too many requests, retry after the number of seconds in the Retry-After header"
+// @Failure      500    {object}  response.ErrorResponse     "Internal server error"
+// @Router       /api/email/verify/resend [post]
+func (h *UserHandler) ResendVerification(c *gin.Context) {
+	var req dto.ResendVerificationRequest
+
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.UserService.ResendVerification(req.Email); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message" : "Jika akun belum diverifikasi, link verifikasi sudah dikirim"})
 }
 
 // DeleteUser godoc
@@ -126,40 +284,52 @@ func (h *UserHandler) Login(c *gin.Context) {
 // @Router       /api/users/{id} [delete]
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	// get id from Params
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
+	id, err := paramID(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error" : "id tidak ditemukan" })
+		c.Error(err)
+		return
 	}
 
 	// Panggil service untuk menghapus user
 	err = h.UserService.DeleteUser(id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
 }
 
 // GetAllUser godoc
-// @Summary      Get all users
-// @Description  Retrieve a list of all users
+// @Summary      List users
+// @Description  Retrieve one page of users, optionally filtered by role. Requires user:read
 // @Tags         users
 // @Accept       json
 // @Produce      json
+// @Param        page   query     int     false  "Page number, starting at 1"
+// @Param        limit  query     int     false  "Rows per page (default 20, max 100)"
+// @Param        sort   query     string  false  "Sort field: id, name, email, created_at. Prefix with - for descending"
+// @Param        role   query     string  false  "Filter by role, for example admin, host or customer"
 // @Success      200  {array}   response.ListUserResponse "List of users retrieved successfully"
+// @Failure      400  {object}  response.ErrorResponse            "Invalid query parameter"
+// @Failure      401  {object}  response.ErrorResponse            "Unauthorized"
+// @Failure      403  {object}  response.ErrorResponse            "Forbidden"
 // @Failure      500  {object}  response.ErrorResponse            "Internal server error"
 // @Router       /api/users [get]
 func (h *UserHandler) GetAllUser(c *gin.Context) {
-	users, err := h.UserService.GetAllUser()
+	page, err := parsePage(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error" : err.Error()})
-			return
+		c.Error(err)
+		return
+	}
+
+	users, total, err := h.UserService.ListUsers(repository.UserFilter{Role: c.Query("role"), Page: page})
+	if err != nil {
+		c.Error(err)
+		return
 	}
 
 	// Convert ke response list
-	var userResponses []response.ListUserResponse
+	userResponses := []response.ListUserResponse{}
 	for _, user := range users{
 		userResponses = append(userResponses, response.ListUserResponse{
 			ID:        int64(user.ID),
@@ -173,6 +343,7 @@ func (h *UserHandler) GetAllUser(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"data": userResponses,
+		"pagination": toPaginationResponse(page, total),
 	})
 
 
@@ -180,29 +351,35 @@ func (h *UserHandler) GetAllUser(c *gin.Context) {
 
 // GetUserById godoc
 // @Summary      Get a user by ID
-// @Description  Retrieve a user's information based on their ID
+// @Description  Retrieve a user's information based on their ID. Users can only read their own account unless they have the user:read permission
 // @Tags         users
 // @Accept       json
 // @Produce      json
 // @Param        id   path      int  true  "User ID"
 // @Success      200  {object}  response.UserResponse "User retrieved successfully"
 // @Failure      400  {object}  response.ErrorResponse        "Invalid user ID"
+// @Failure      403  {object}  response.ErrorResponse        "Not the owner of the account"
 // @Failure      404  {object}  response.ErrorResponse        "User not found"
 // @Failure      500  {object}  response.ErrorResponse        "Internal server error"
 // @Router       /api/users/{id} [get]
 func (h *UserHandler) GetUserById(c *gin.Context) {
 	// get id param
-	ParamId := c.Param("id")
-	id, err := strconv.Atoi(ParamId)
+	id, err := paramID(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error" : "Id tidak di temukan"})
+		c.Error(err)
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		c.Error(ErrUnauthenticated)
 		return
 	}
 
 	// panggil service
-	user, err := h.UserService.GetUserById(id)
+	user, err := h.UserService.GetUserById(id, actor)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error" : "Data not Found"})
+		c.Error(err)
 		return
 	}
 
@@ -222,7 +399,7 @@ func (h *UserHandler) GetUserById(c *gin.Context) {
 
 // UpdateUser godoc
 // @Summary      Update a user by ID
-// @Description  Update a user's information based on their ID
+// @Description  Update a user's information based on their ID. Users can only update their own account unless they have the user:write permission
 // @Tags         users
 // @Accept       json
 // @Produce      json
@@ -230,30 +407,21 @@ func (h *UserHandler) GetUserById(c *gin.Context) {
 // @Param        input  body      dto.UpdateUserRequest  true  "Updated user details"
 // @Success      200    {object}  map[string]string     "User updated successfully"
 // @Failure      400    {object}  response.ErrorResponse         "Invalid request body or validation failed"
+// @Failure      403    {object}  response.ErrorResponse         "Not the owner of the account"
 // @Failure      500    {object}  response.ErrorResponse         "Internal server error"
 // @Router       /api/users/{id} [put]
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	// Parse Id from url
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
+	id, err := paramID(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error" : "Id tidak di temukan"})
+		c.Error(err)
 		return
 	}
 
 	//bind json body
 	var user dto.UpdateUserRequest
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error" : "Invalid request body"})
-		return
-	}
-
-	if err := dto.Validate.Struct(user); err != nil {
-		errors := make(map[string]string)
-		for _, err := range err.(validator.ValidationErrors) {
-			errors[err.Field()] = err.Tag()
-		}
-		c.JSON(http.StatusBadRequest, gin.H{"error" : "Validator failed", "details" : errors})
+	if err := bindJSON(c, &user); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -264,14 +432,60 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		Password: user.Password,
 	}
 
+	actor, ok := currentActor(c)
+	if !ok {
+		c.Error(ErrUnauthenticated)
+		return
+	}
+
 	// panggil service
-	err = h.UserService.UpdateUser(id, reqBody)
+	err = h.UserService.UpdateUser(id, reqBody, actor)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error" : err.Error()})
+		c.Error(err)
 		return
 	}
 	
 	//response with success
 	c.JSON(http.StatusOK, gin.H{"message" : "User Update Successfully"})
 
+}
+
+// UpdateUserRole godoc
+// @Summary      Change the role of a user
+// @Description  Assign another role, for example host or staff. The user's access tokens are revoked so the new role applies after the next token refresh. Requires role:manage
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        id     path      int                        true  "User ID"
+// @Param        input  body      dto.UpdateUserRoleRequest  true  "New role"
+// @Success      200    {object}  map[string]string          "User role updated successfully"
+// @Failure      400    {object}  response.ErrorResponse     "Invalid request body or unknown role"
+// @Failure      404    {object}  response.ErrorResponse     "User not found"
+// @Failure      409    {object}  response.ErrorResponse     "Cannot change your own role"
+// @Router       /api/users/{id}/role [put]
+func (h *UserHandler) UpdateUserRole(c *gin.Context) {
+	id, err := paramID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req dto.UpdateUserRoleRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		c.Error(ErrUnauthenticated)
+		return
+	}
+
+	if err := h.UserService.ChangeUserRole(id, req.Role, actor); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User role updated successfully"})
 }
\ No newline at end of file
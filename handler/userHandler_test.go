@@ -0,0 +1,348 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"wereserve/handler/response"
+	"wereserve/middleware"
+	"wereserve/notification"
+	"wereserve/repository/memory"
+	"wereserve/services"
+	"wereserve/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newUserTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	store := memory.NewStore()
+	userService := services.NewUserService(store.Users(), store.RefreshTokens(), store.PasswordResetTokens(),
+		notification.NewEmailNotifier(notification.NewMemoryMailer(), notification.EmailOptions{}), services.UserServiceOptions{
+		RefreshTTL:          time.Hour,
+		PasswordResetTTL:    30 * time.Minute,
+		PasswordResetURL:    "http://localhost:3000/reset-password",
+		VerificationTTL:     24 * time.Hour,
+		VerificationURL:     "http://localhost:8080/api/email/verify",
+		UnverifiedRetention: 72 * time.Hour,
+	})
+	userHandler := NewUserHandler(userService)
+
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	r.POST("/api/register", userHandler.Register)
+	r.POST("/api/login", userHandler.Login)
+	r.POST("/api/token/refresh", userHandler.RefreshToken)
+	r.POST("/api/password/forgot", userHandler.ForgotPassword)
+	r.POST("/api/password/reset", userHandler.ResetPassword)
+	r.GET("/api/email/verify", userHandler.VerifyEmail)
+	r.GET("/api/users", userHandler.GetAllUser)
+	r.POST("/api/logout", middleware.JWTAuthMiddleware(userService), userHandler.Logout)
+	r.POST("/api/reservation", middleware.JWTAuthMiddleware(userService), middleware.VerifiedEmailMiddleware(userService), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+	return r
+}
+
+func postJSON(r *gin.Engine, path string, body interface{}) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	r := newUserTestRouter()
+
+	register := map[string]string{"name": "Budi", "email": "budi@example.com", "password": "rahasia123"}
+	if w := postJSON(r, "/api/register", register); w.Code != http.StatusCreated {
+		t.Fatalf("register status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	if w := postJSON(r, "/api/register", register); w.Code == http.StatusCreated {
+		t.Fatalf("expected duplicate register to fail")
+	}
+
+	tests := []struct {
+		name     string
+		body     map[string]string
+		wantCode int
+	}{
+		{name: "valid credentials", body: map[string]string{"email": "budi@example.com", "password": "rahasia123"}, wantCode: http.StatusOK},
+		{name: "wrong password", body: map[string]string{"email": "budi@example.com", "password": "salah12345"}, wantCode: http.StatusUnauthorized},
+		{name: "invalid body", body: map[string]string{"email": "bukan-email"}, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postJSON(r, "/api/login", tt.body)
+			if w.Code != tt.wantCode {
+				t.Fatalf("login status = %d, want %d, body = %s", w.Code, tt.wantCode, w.Body.String())
+			}
+
+			if tt.wantCode == http.StatusOK {
+				var resp map[string]string
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp["token"] == "" {
+					t.Errorf("expected a token in the response, got %s", w.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func loginForTokens(t *testing.T, r *gin.Engine) map[string]string {
+	t.Helper()
+	register := map[string]string{"name": "Budi", "email": "budi@example.com", "password": "rahasia123"}
+	if w := postJSON(r, "/api/register", register); w.Code != http.StatusCreated {
+		t.Fatalf("register status = %d, body = %s", w.Code, w.Body.String())
+	}
+	w := postJSON(r, "/api/login", map[string]string{"email": "budi@example.com", "password": "rahasia123"})
+	var tokens map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &tokens); err != nil || tokens["refresh_token"] == "" {
+		t.Fatalf("expected tokens in the login response, got %s", w.Body.String())
+	}
+	return tokens
+}
+
+func TestRefreshTokenEndpoint(t *testing.T) {
+	r := newUserTestRouter()
+	tokens := loginForTokens(t, r)
+
+	w := postJSON(r, "/api/token/refresh", map[string]string{"refresh_token": tokens["refresh_token"]})
+	if w.Code != http.StatusOK {
+		t.Fatalf("refresh status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	// Refresh token lama sudah dirotasi, memakainya lagi dianggap reuse
+	w = postJSON(r, "/api/token/refresh", map[string]string{"refresh_token": tokens["refresh_token"]})
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("reuse status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestLogoutRevokesAccessToken(t *testing.T) {
+	r := newUserTestRouter()
+	tokens := loginForTokens(t, r)
+
+	logout := func() int {
+		payload, _ := json.Marshal(map[string]string{"refresh_token": tokens["refresh_token"]})
+		req := httptest.NewRequest(http.MethodPost, "/api/logout", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+tokens["token"])
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := logout(); code != http.StatusOK {
+		t.Fatalf("logout status = %d, want %d", code, http.StatusOK)
+	}
+	if code := logout(); code != http.StatusUnauthorized {
+		t.Fatalf("expected access token to be rejected after logout, got %d", code)
+	}
+}
+
+func TestPasswordResetEndpoints(t *testing.T) {
+	r := newUserTestRouter()
+
+	tests := []struct {
+		name     string
+		path     string
+		body     map[string]string
+		wantCode int
+	}{
+		{name: "forgot with unknown email", path: "/api/password/forgot", body: map[string]string{"email": "tidakada@example.com"}, wantCode: http.StatusOK},
+		{name: "forgot with invalid email", path: "/api/password/forgot", body: map[string]string{"email": "bukan-email"}, wantCode: http.StatusBadRequest},
+		{name: "reset with unknown token", path: "/api/password/reset", body: map[string]string{"token": "bukan-token", "password": "passwordbaru"}, wantCode: http.StatusBadRequest},
+		{name: "reset with short password", path: "/api/password/reset", body: map[string]string{"token": "bukan-token", "password": "pendek"}, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := postJSON(r, tt.path, tt.body); w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d, body = %s", w.Code, tt.wantCode, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestReservationRequiresVerifiedEmail(t *testing.T) {
+	r := newUserTestRouter()
+	tokens := loginForTokens(t, r)
+
+	createReservation := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/api/reservation", nil)
+		req.Header.Set("Authorization", "Bearer "+tokens["token"])
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := createReservation(); code != http.StatusForbidden {
+		t.Fatalf("expected unverified account to be blocked, got %d", code)
+	}
+
+	// User pertama di store baru selalu mendapat ID 1
+	token, err := utils.GenerateEmailVerificationToken(1, "budi@example.com", time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateEmailVerificationToken() error = %v", err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/email/verify?token="+token, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("verify status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	if code := createReservation(); code != http.StatusCreated {
+		t.Fatalf("expected verified account to create a reservation, got %d", code)
+	}
+}
+
+func TestGetAllUserPagination(t *testing.T) {
+	r := newUserTestRouter()
+	for _, name := range []string{"Andi", "Budi", "Citra"} {
+		body := map[string]string{"name": name, "email": name + "@example.com", "password": "rahasia123"}
+		if w := postJSON(r, "/api/register", body); w.Code != http.StatusCreated {
+			t.Fatalf("register status = %d, body = %s", w.Code, w.Body.String())
+		}
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		wantCode  int
+		wantNames []string
+		wantTotal int64
+	}{
+		{name: "second page by name descending", query: "?limit=2&page=2&sort=-name", wantCode: http.StatusOK, wantNames: []string{"Andi"}, wantTotal: 3},
+		{name: "role filter", query: "?role=admin", wantCode: http.StatusOK, wantNames: []string{}},
+		{name: "unknown sort field", query: "?sort=password", wantCode: http.StatusBadRequest},
+		{name: "invalid limit", query: "?limit=abc", wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/users"+tt.query, nil))
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d, body = %s", w.Code, tt.wantCode, w.Body.String())
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				Data []struct {
+					Name string `json:"name"`
+				} `json:"data"`
+				Pagination struct {
+					Total int64 `json:"total"`
+				} `json:"pagination"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid response body: %v", err)
+			}
+			if body.Pagination.Total != tt.wantTotal {
+				t.Errorf("pagination.total = %d, want %d", body.Pagination.Total, tt.wantTotal)
+			}
+			if len(body.Data) != len(tt.wantNames) {
+				t.Fatalf("data = %+v, want %v", body.Data, tt.wantNames)
+			}
+			for i, user := range body.Data {
+				if user.Name != tt.wantNames[i] {
+					t.Errorf("data[%d].name = %q, want %q", i, user.Name, tt.wantNames[i])
+				}
+			}
+		})
+	}
+}
+
+func TestErrorResponsesUseProblemJSON(t *testing.T) {
+	r := newUserTestRouter()
+	register := map[string]string{"name": "Budi", "email": "budi@example.com", "password": "rahasia123"}
+	if w := postJSON(r, "/api/register", register); w.Code != http.StatusCreated {
+		t.Fatalf("register status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		body        map[string]string
+		wantCode    int
+		wantErrCode string
+		wantFields  map[string]string
+	}{
+		{name: "validation failed", path: "/api/register", body: map[string]string{"name": "Budi", "email": "bukan-email"},
+			wantCode: http.StatusBadRequest, wantErrCode: "validation_failed", wantFields: map[string]string{"Email": "email", "Password": "required"}},
+		{name: "duplicate email", path: "/api/register", body: register, wantCode: http.StatusConflict, wantErrCode: "email_taken"},
+		{name: "wrong password", path: "/api/login", body: map[string]string{"email": "budi@example.com", "password": "salah12345"},
+			wantCode: http.StatusUnauthorized, wantErrCode: "invalid_credentials"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postJSON(r, tt.path, tt.body)
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d, body = %s", w.Code, tt.wantCode, w.Body.String())
+			}
+			if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/problem+json") {
+				t.Errorf("Content-Type = %q, want application/problem+json", ct)
+			}
+
+			var problem response.ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("invalid problem body: %v", err)
+			}
+			if problem.Status != tt.wantCode || problem.Code != tt.wantErrCode || problem.Instance != tt.path {
+				t.Errorf("problem = %+v, want status %d code %q instance %q", problem, tt.wantCode, tt.wantErrCode, tt.path)
+			}
+			for field, tag := range tt.wantFields {
+				if problem.Errors[field] != tag {
+					t.Errorf("errors[%q] = %q, want %q", field, problem.Errors[field], tag)
+				}
+			}
+		})
+	}
+}
+
+func TestUserRoutesOwnershipPolicy(t *testing.T) {
+	tests := []struct {
+		method string
+		route  string
+		body   interface{}
+		want   map[string]int
+	}{
+		{method: http.MethodGet, route: "", want: map[string]int{"owner": http.StatusForbidden, "staff": http.StatusForbidden, "manager": http.StatusOK, "admin": http.StatusOK}},
+		{method: http.MethodGet, route: "/:id", want: map[string]int{"owner": http.StatusOK, "other": http.StatusForbidden, "manager": http.StatusOK, "admin": http.StatusOK}},
+		{method: http.MethodPut, route: "/:id", body: map[string]string{"name": "Pemilik"},
+			want: map[string]int{"owner": http.StatusOK, "other": http.StatusForbidden, "manager": http.StatusForbidden, "admin": http.StatusOK}},
+		{method: http.MethodDelete, route: "/:id", want: map[string]int{"owner": http.StatusForbidden, "manager": http.StatusForbidden, "admin": http.StatusOK}},
+		{method: http.MethodPut, route: "/:id/role", body: map[string]string{"role": "host"},
+			want: map[string]int{"owner": http.StatusForbidden, "manager": http.StatusForbidden, "admin": http.StatusOK}},
+	}
+
+	for _, tt := range tests {
+		for as, want := range tt.want {
+			t.Run(tt.method+" /api/users"+tt.route+" as "+as, func(t *testing.T) {
+				server := newPolicyTestServer(t)
+
+				path := "/api/users"
+				if tt.route != "" {
+					path += "/" + strconv.Itoa(server.users["owner"].ID) + tt.route[len("/:id"):]
+				}
+				if w := server.do(as, tt.method, path, tt.body); w.Code != want {
+					t.Errorf("status = %d, want %d, body = %s", w.Code, want, w.Body.String())
+				}
+			})
+		}
+	}
+}
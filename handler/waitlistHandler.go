@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+	"wereserve/dto"
+	"wereserve/handler/response"
+	"wereserve/models"
+	"wereserve/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WaitlistHandler struct {
+	WaitlistService *services.WaitlistService
+}
+
+func NewWaitlistHandler(waitlistService *services.WaitlistService) *WaitlistHandler {
+	return &WaitlistHandler{WaitlistService: waitlistService}
+}
+
+// JoinWaitlist godoc
+// @Summary      Join the waitlist
+// @Description  Wait for a table for the given party size with a start time between desired_start and desired_end. When a matching reservation is cancelled the slot is offered by email
+// @Tags         waitlist
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.JoinWaitlistRequest         true  "Waitlist request"
+// @Success      201    {object}  response.WaitlistEntryResponse  "Joined the waitlist successfully"
+// @Failure      400    {object}  response.ErrorResponse          "Invalid request body or validation failed"
+// @Failure      401    {object}  response.ErrorResponse          "Unauthorized"
+// @Failure      500    {object}  response.ErrorResponse          "Internal server error"
+// @Router       /api/waitlist [post]
+func (h *WaitlistHandler) JoinWaitlist(c *gin.Context) {
+	var req dto.JoinWaitlistRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		c.Error(ErrUnauthenticated)
+		return
+	}
+
+	entry := models.WaitlistEntry{
+		UserID:          actor.UserID,
+		PartySize:       req.PartySize,
+		DesiredStart:    req.DesiredStart,
+		DesiredEnd:      req.DesiredEnd,
+		DurationMinutes: req.DurationMinutes,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+
+	if err := h.WaitlistService.JoinWaitlist(&entry); err != nil {
+		c.Error(err)
+		return
+	}
+
+	entry.User.ID = actor.UserID
+	if email, ok := c.Get("email"); ok {
+		entry.User.Email, _ = email.(string)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Joined waitlist successfully",
+		"data":    toWaitlistEntryResponse(entry),
+	})
+}
+
+// GetWaitlist godoc
+// @Summary      Get the waitlist
+// @Description  Users with the waitlist:manage permission see every entry, others only see their own
+// @Tags         waitlist
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   response.WaitlistEntryResponse "Waitlist retrieved successfully"
+// @Failure      401  {object}  response.ErrorResponse         "Unauthorized"
+// @Failure      500  {object}  response.ErrorResponse         "Internal server error"
+// @Router       /api/waitlist [get]
+func (h *WaitlistHandler) GetWaitlist(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		c.Error(ErrUnauthenticated)
+		return
+	}
+
+	entries, err := h.WaitlistService.GetWaitlist(actor)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp := []response.WaitlistEntryResponse{}
+	for _, entry := range entries {
+		resp = append(resp, toWaitlistEntryResponse(entry))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "data get successfully",
+		"data":    resp,
+	})
+}
+
+// AcceptWaitlistOffer godoc
+// @Summary      Accept a waitlist offer
+// @Description  Turn an open waitlist offer into a reservation. Fails if the offer expired or the slot was booked in the meantime
+// @Tags         waitlist
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int                  true  "Waitlist entry ID"
+// @Success      201  {object}  map[string]interface{} "Reservation created from the offer"
+// @Failure      400  {object}  response.ErrorResponse "Invalid waitlist entry ID"
+// @Failure      403  {object}  response.ErrorResponse "Not the owner of the waitlist entry"
+// @Failure      409  {object}  response.ErrorResponse "No open offer, offer expired or slot already taken"
+// @Failure      500  {object}  response.ErrorResponse "Internal server error"
+// @Router       /api/waitlist/{id}/accept [post]
+func (h *WaitlistHandler) AcceptWaitlistOffer(c *gin.Context) {
+	id, err := paramID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		c.Error(ErrUnauthenticated)
+		return
+	}
+
+	reservation, err := h.WaitlistService.AcceptOffer(id, actor)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Reservation created successfully",
+		"data": gin.H{
+			"reservation_id":       reservation.ID,
+			"reservation_datetime": reservation.ReservationDateTime,
+			"duration_minutes":     reservation.DurationMinutes,
+			"tables":               toTableResponses(reservation.Tables),
+		},
+	})
+}
+
+// CancelWaitlistEntry godoc
+// @Summary      Leave the waitlist
+// @Description  Remove a waitlist entry. An open offer is passed on to the next guest
+// @Tags         waitlist
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int                  true  "Waitlist entry ID"
+// @Success      200  {object}  map[string]string    "Waitlist entry cancelled"
+// @Failure      400  {object}  response.ErrorResponse "Invalid waitlist entry ID or entry already closed"
+// @Failure      403  {object}  response.ErrorResponse "Not the owner of the waitlist entry"
+// @Router       /api/waitlist/{id} [delete]
+func (h *WaitlistHandler) CancelWaitlistEntry(c *gin.Context) {
+	id, err := paramID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		c.Error(ErrUnauthenticated)
+		return
+	}
+
+	if err := h.WaitlistService.CancelEntry(id, actor); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Waitlist entry cancelled successfully"})
+}
+
+func toWaitlistEntryResponse(entry models.WaitlistEntry) response.WaitlistEntryResponse {
+	return response.WaitlistEntryResponse{
+		ID: entry.ID,
+		User: response.UserPreloadResponse{
+			ID:    entry.User.ID,
+			Name:  entry.User.Name,
+			Email: entry.User.Email,
+		},
+		PartySize:            entry.PartySize,
+		DesiredStart:         entry.DesiredStart,
+		DesiredEnd:           entry.DesiredEnd,
+		DurationMinutes:      entry.DurationMinutes,
+		Status:               entry.Status,
+		OfferedTableID:       entry.OfferedTableID,
+		OfferedCombinationID: entry.OfferedCombinationID,
+		OfferedDateTime:      entry.OfferedDateTime,
+		OfferExpiresAt:       entry.OfferExpiresAt,
+		ReservationID:        entry.ReservationID,
+		CreatedAt:            entry.CreatedAt,
+		UpdatedAt:            entry.UpdatedAt,
+	}
+}
@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"net/http"
+	"wereserve/dto"
+	"wereserve/handler/response"
+	"wereserve/models"
+	"wereserve/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	WebhookService *services.WebhookService
+}
+
+func NewWebhookHandler(webhookService *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{WebhookService: webhookService}
+}
+
+// GetWebhooks godoc
+// @Summary      List webhook subscriptions
+// @Description  List endpoints that receive reservation and table events. Secrets are not returned. Requires webhook:manage
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   response.WebhookSubscriptionResponse "Webhook subscriptions retrieved successfully"
+// @Failure      401  {object}  response.ErrorResponse               "Unauthorized"
+// @Failure      403  {object}  response.ErrorResponse               "Forbidden"
+// @Failure      500  {object}  response.ErrorResponse               "Internal server error"
+// @Router       /api/webhooks [get]
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	subscriptions, err := h.WebhookService.GetSubscriptions()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp := []response.WebhookSubscriptionResponse{}
+	for _, subscription := range subscriptions {
+		resp = append(resp, toWebhookSubscriptionResponse(subscription, false))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "data get successfully",
+		"data":    resp,
+	})
+}
+
+// CreateWebhook godoc
+// @Summary      Create a webhook subscription
+// @Description  Register an endpoint for one or more events (reservation.created, reservation.updated, reservation.cancelled, reservation.status_changed, table.status_changed).
+// @Description  Every delivery is signed with HMAC-SHA256 in the X-WeReserve-Signature header. The secret is generated when empty and only returned in this response. Requires webhook:manage
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        input  body      dto.CreateWebhookRequest              true  "Webhook subscription details"
+// @Success      201    {object}  response.WebhookSubscriptionResponse  "Webhook subscription created successfully"
+// @Failure      400    {object}  response.ErrorResponse                "Invalid request body or validation failed"
+// @Failure      500    {object}  response.ErrorResponse                "Internal server error"
+// @Router       /api/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req dto.CreateWebhookRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	subscription, err := h.WebhookService.CreateSubscription(services.WebhookSubscriptionInput{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: req.EventTypes,
+		Active:     req.Active,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Webhook created successfully",
+		"data":    toWebhookSubscriptionResponse(*subscription, true),
+	})
+}
+
+// UpdateWebhook godoc
+// @Summary      Update a webhook subscription
+// @Description  Change the URL, events or active flag. Sending a new secret rotates it and returns it once. Requires webhook:manage
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id     path      int                                   true  "Webhook subscription ID"
+// @Param        input  body      dto.UpdateWebhookRequest              true  "Fields to update"
+// @Success      200    {object}  response.WebhookSubscriptionResponse  "Webhook subscription updated successfully"
+// @Failure      400    {object}  response.ErrorResponse                "Invalid request body or validation failed"
+// @Failure      404    {object}  response.ErrorResponse                "Webhook subscription not found"
+// @Router       /api/webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	id, err := paramID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req dto.UpdateWebhookRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	subscription, err := h.WebhookService.UpdateSubscription(id, services.WebhookSubscriptionInput{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: req.EventTypes,
+		Active:     req.Active,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Webhook update successfully",
+		"data":    toWebhookSubscriptionResponse(*subscription, req.Secret != ""),
+	})
+}
+
+// DeleteWebhook godoc
+// @Summary      Delete a webhook subscription
+// @Description  Stop sending events to the endpoint and remove its delivery log. Requires webhook:manage
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Webhook subscription ID"
+// @Success      200  {object}  map[string]string      "Webhook subscription deleted successfully"
+// @Failure      400  {object}  response.ErrorResponse "Invalid webhook subscription ID"
+// @Failure      404  {object}  response.ErrorResponse "Webhook subscription not found"
+// @Router       /api/webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, err := paramID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.WebhookService.DeleteSubscription(id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// GetWebhookDeliveries godoc
+// @Summary      List webhook deliveries
+// @Description  Delivery log of a subscription with attempts, response status and last error, newest first. Requires webhook:manage
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Webhook subscription ID"
+// @Success      200  {array}   response.WebhookDeliveryResponse "Webhook deliveries retrieved successfully"
+// @Failure      400  {object}  response.ErrorResponse           "Invalid webhook subscription ID"
+// @Failure      404  {object}  response.ErrorResponse           "Webhook subscription not found"
+// @Router       /api/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) GetWebhookDeliveries(c *gin.Context) {
+	id, err := paramID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	deliveries, err := h.WebhookService.GetDeliveries(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp := []response.WebhookDeliveryResponse{}
+	for _, delivery := range deliveries {
+		resp = append(resp, toWebhookDeliveryResponse(delivery))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "data get successfully",
+		"data":    resp,
+	})
+}
+
+func toWebhookSubscriptionResponse(subscription models.WebhookSubscription, withSecret bool) response.WebhookSubscriptionResponse {
+	resp := response.WebhookSubscriptionResponse{
+		ID:         subscription.ID,
+		URL:        subscription.URL,
+		EventTypes: subscription.Events(),
+		Active:     subscription.Active,
+		CreatedAt:  subscription.CreatedAt,
+		UpdatedAt:  subscription.UpdatedAt,
+	}
+	if withSecret {
+		resp.Secret = subscription.Secret
+	}
+	return resp
+}
+
+func toWebhookDeliveryResponse(delivery models.WebhookDelivery) response.WebhookDeliveryResponse {
+	return response.WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Payload:        []byte(delivery.Payload),
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		ResponseStatus: delivery.ResponseStatus,
+		LastError:      delivery.LastError,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+	}
+}
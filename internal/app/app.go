@@ -5,6 +5,9 @@ import (
 	"wereserve/config"
 	"wereserve/handler"
 	"wereserve/middleware"
+	"wereserve/models"
+	"wereserve/notification"
+	"wereserve/realtime"
 	"wereserve/repository"
 	"wereserve/services"
 
@@ -41,25 +44,190 @@ func RunServer() {
 		log.Fatal().Msgf("Error Connection to database: %v", err)
 	}
 
+	// Notifikasi ditulis ke outbox lalu dikirim oleh worker lewat mailer sesuai MAIL_DRIVER
+	outboxRepo := repository.NewOutboxRepository(db.DB)
+	notifier := services.NewOutboxNotifier(outboxRepo)
+	outboxService := services.NewOutboxService(outboxRepo, notification.NewEmailNotifier(newMailer(cfg.Mail), notification.EmailOptions{SenderName: cfg.Mail.SenderName, SenderEmail: cfg.Mail.AuthEmail}), services.OutboxOptions{
+		MaxAttempts: cfg.Outbox.MaxAttempts,
+		BaseBackoff: cfg.Outbox.Backoff(),
+		MaxBackoff:  cfg.Outbox.BackoffMax(),
+		BatchSize:   cfg.Outbox.BatchSize,
+		Lease:       5 * time.Minute,
+	})
+	outboxHandler := handler.NewOutboxHandler(outboxService)
+
+	go func() {
+		ticker := time.NewTicker(cfg.Outbox.PollInterval())
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := outboxService.ProcessDue(); err != nil {
+				log.Error().Msgf("Error delivering outbox messages: %v", err)
+			}
+		}
+	}()
+
 	// inisialisasi handler routes dan service user
 	userRepo := repository.NewUserRepository(db.DB)
-	userService := services.NewUserService(userRepo)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db.DB)
+	passwordResetRepo := repository.NewPasswordResetTokenRepository(db.DB)
+	userService := services.NewUserService(userRepo, refreshTokenRepo, passwordResetRepo, notifier, services.UserServiceOptions{
+		RefreshTTL:          cfg.App.RefreshTokenTTL(),
+		PasswordResetTTL:    cfg.PasswordReset.TokenTTL(),
+		PasswordResetURL:    cfg.PasswordReset.URL,
+		VerificationTTL:     cfg.EmailVerification.TokenTTL(),
+		VerificationURL:     cfg.EmailVerification.URL,
+		UnverifiedRetention: cfg.EmailVerification.Retention(),
+	})
 	userHandler := handler.NewUserHandler(userService)
 
+	// Permission dibaca dari role user pada setiap request yang memerlukan login
+	roleService := services.NewRoleService(repository.NewRoleRepository(db.DB))
+	roleHandler := handler.NewRoleHandler(roleService)
+
+	// Akun yang tidak pernah diverifikasi dihapus secara berkala
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			deleted, err := userService.CleanupUnverifiedUsers()
+			if err != nil {
+				log.Error().Msgf("Error deleting unverified users: %v", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Info().Msgf("Deleted %d unverified users", deleted)
+			}
+		}
+	}()
+
+
+	// Webhook diantrikan bersama perubahan data lalu dikirim oleh worker ke sistem lain seperti POS
+	webhookRepo := repository.NewWebhookRepository(db.DB)
+	webhookService := services.NewWebhookService(webhookRepo, services.WebhookOptions{
+		MaxAttempts:         cfg.Webhook.MaxAttempts,
+		BaseBackoff:         cfg.Webhook.Backoff(),
+		MaxBackoff:          cfg.Webhook.BackoffMax(),
+		BatchSize:           cfg.Webhook.BatchSize,
+		Lease:               5 * time.Minute,
+		Timeout:             cfg.Webhook.Timeout(),
+		AllowPrivateTargets: cfg.Webhook.AllowPrivateTargets,
+	})
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+
+	go func() {
+		ticker := time.NewTicker(cfg.Webhook.PollInterval())
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := webhookService.ProcessDue(); err != nil {
+				log.Error().Msgf("Error delivering webhooks: %v", err)
+			}
+		}
+	}()
+
+	// Perubahan meja dan reservasi disebarkan ke layar host stand lewat GET /api/stream
+	hub := realtime.NewHub(realtime.DefaultBufferSize)
+	// EventSource tidak bisa mengirim header, jadi stream dibuka dengan tiket sekali pakai, bukan access token di query
+	streamTicketService := services.NewStreamTicketService(repository.NewStreamTicketRepository(db.DB), userRepo, services.DefaultStreamTicketTTL)
+	streamHandler := handler.NewStreamHandler(hub, streamTicketService)
+
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := streamTicketService.CleanupExpiredTickets(); err != nil {
+				log.Error().Msgf("Error deleting expired stream tickets: %v", err)
+			}
+		}
+	}()
 
 	// initilitaions Table 
 	tableRepo := repository.NewTableRepository(db.DB)
-	tableService := services.NewTableService(tableRepo)
+	reservationRepo := repository.NewReservationRepository(db.DB)
+	tableService := services.NewTableService(tableRepo, reservationRepo, webhookRepo, hub)
 	tableHandler := handler.NewTableHandler(tableService)
 
+	// inisialisasi kombinasi meja
+	combinationRepo := repository.NewTableCombinationRepository(db.DB)
+	combinationService := services.NewTableCombinationService(combinationRepo, tableRepo)
+	combinationHandler := handler.NewTableCombinationHandler(combinationService)
+
+	// inisialisasi jadwal buka restoran
+	scheduleRepo := repository.NewScheduleRepository(db.DB)
+	scheduleService := services.NewScheduleService(scheduleRepo)
+	scheduleHandler := handler.NewScheduleHandler(scheduleService)
+
+	// inisialisasi waitlist
+	waitlistRepo := repository.NewWaitlistRepository(db.DB)
+	unitOfWork := repository.NewUnitOfWork(db.DB)
+	waitlistService := services.NewWaitlistService(unitOfWork, waitlistRepo, reservationRepo, tableRepo, combinationRepo, scheduleService, cfg.Waitlist.OfferTTL(), notifier, hub)
+	waitlistHandler := handler.NewWaitlistHandler(waitlistService)
+
+	// Penawaran waitlist yang kedaluwarsa diteruskan ke customer berikutnya secara berkala
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := waitlistService.ExpireOffers(); err != nil {
+				log.Error().Msgf("Error expiring waitlist offers: %v", err)
+			}
+		}
+	}()
+
 	// inisilisasi Reservation
-	reservationRepo := repository.NewReservationRepository(db.DB)
-	reservationService := services.NewReservationService(reservationRepo, tableRepo)
+	reservationService := services.NewReservationService(unitOfWork, reservationRepo, tableRepo, combinationRepo, scheduleService, waitlistService, cfg.Reservation.Cutoff(), hub)
 	reservationHandler := handler.NewReservationsHandler(reservationService)
 
+	// Reservasi tamu tanpa akun lewat halaman publik, dikelola lewat magic link di email konfirmasi
+	guestReservationService := services.NewGuestReservationService(reservationService, repository.NewReservationManageTokenRepository(db.DB), services.GuestReservationOptions{
+		ManageURL:           cfg.PublicBooking.ManageURL,
+		MaxActivePerContact: cfg.PublicBooking.MaxActivePerContact,
+		MaxPartySize:        cfg.PublicBooking.MaxPartySize,
+		ManageLinkTTL:       cfg.PublicBooking.ManageLinkTTL(),
+	})
+	publicReservationHandler := handler.NewPublicReservationHandler(guestReservationService)
+
+	// inisialisasi feed kalender
+	calendarService := services.NewCalendarService(userRepo, reservationRepo, cfg.Calendar.FeedBaseURL)
+	calendarHandler := handler.NewCalendarHandler(calendarService)
+
+	// Pengingat reservasi dikirim lewat outbox. Bisa juga dijalankan terpisah dengan command `reminders`
+	go runReminderScheduler(newReminderService(cfg, db.DB), cfg.Reminder.PollInterval())
+
+	// inisialisasi pencarian ketersediaan meja
+	availabilityService := services.NewAvailabilityService(tableRepo, combinationRepo, reservationRepo, scheduleService)
+	availabilityHandler := handler.NewAvailabilityHandler(availabilityService)
+
 
 	// Setup gin router
-	r := gin.Default()
+	// Logger bawaan gin mencatat query string apa adanya, AccessLogger menyamarkan token di dalamnya
+	r := gin.New()
+	r.Use(middleware.AccessLogger(), gin.Recovery())
+	r.Use(middleware.ErrorHandler())
+
+	// IP client untuk rate limit hanya diambil dari X-Forwarded-For jika request datang dari proxy yang dipercaya
+	if err := r.SetTrustedProxies(cfg.RateLimit.TrustedProxyList()); err != nil {
+		log.Fatal().Msgf("Invalid RATE_LIMIT_TRUSTED_PROXIES: %v", err)
+	}
+
+	// Rate limit per route group. State disimpan di memori, ganti store-nya jika API dijalankan di beberapa instance
+	limiter := middleware.NewRateLimiter(middleware.NewMemoryRateLimitStore())
+	authLimit := middleware.RateLimitConfig{
+		PerIP:      middleware.RateLimit{Requests: cfg.RateLimit.AuthIPPerMinute, Per: time.Minute},
+		PerAccount: middleware.RateLimit{Requests: cfg.RateLimit.AuthAccountPerMinute, Per: time.Minute},
+	}
+	publicLimit := middleware.RateLimitConfig{
+		PerIP: middleware.RateLimit{Requests: cfg.RateLimit.PublicIPPerMinute, Per: time.Minute},
+	}
+	apiLimit := middleware.RateLimitConfig{
+		PerAccount: middleware.RateLimit{Requests: cfg.RateLimit.APIAccountPerMinute, Per: time.Minute},
+	}
+	loginLockout := middleware.LockoutPolicy{
+		Threshold:    cfg.RateLimit.LockoutThreshold,
+		IPThreshold:  cfg.RateLimit.LockoutIPThreshold,
+		BaseDuration: cfg.RateLimit.Lockout(),
+		MaxDuration:  cfg.RateLimit.LockoutMax(),
+		Window:       cfg.RateLimit.LockoutWindow(),
+	}
 
 	//cors Config
 	r.Use(cors.New(cors.Config{
@@ -75,39 +243,129 @@ func RunServer() {
 	// route Swagger
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Route autentikasi dibatasi lebih ketat per IP dan per email, login juga dikunci setelah gagal berulang kali
+	auth := r.Group("/api", limiter.Limit("auth", authLimit))
+	{
+		auth.POST("/register", userHandler.Register)
+		auth.POST("/login", limiter.Lockout("login", loginLockout), userHandler.Login)
+		auth.POST("/token/refresh", userHandler.RefreshToken)
+		auth.POST("/password/forgot", userHandler.ForgotPassword)
+		auth.POST("/password/reset", userHandler.ResetPassword)
+		auth.POST("/email/verify/resend", userHandler.ResendVerification)
+		auth.POST("/public/reservations/manage/resend", publicReservationHandler.ResendManageLink)
+	}
+
 	// Public routes(tanpa middleware JWT)
-	public := r.Group("/api")
+	public := r.Group("/api", limiter.Limit("public", publicLimit))
 	{
-		public.POST("/register", userHandler.Register)
-		public.POST("/login", userHandler.Login)
-		public.GET("/users", userHandler.GetAllUser)
+		public.GET("/email/verify", userHandler.VerifyEmail)
 		public.GET("/tables",tableHandler.GetListTable)
+		public.GET("/availability", availabilityHandler.SearchAvailability)
+		public.GET("/schedule", scheduleHandler.GetSchedule)
+		public.GET("/reservation/respond", reservationHandler.PreviewReminderAction)
+		public.POST("/reservation/respond", reservationHandler.RespondToReminder)
+		public.GET("/users/:id/calendar.ics", calendarHandler.GetCalendarFeed)
+		public.POST("/public/reservations", publicReservationHandler.CreateReservation)
+		public.GET("/public/reservations/manage", publicReservationHandler.GetReservation)
+		public.PUT("/public/reservations/manage", publicReservationHandler.UpdateReservation)
+		public.POST("/public/reservations/manage/cancel", publicReservationHandler.CancelReservation)
 
 	}
 	
-	//Endpoint yang memerlukan authentication dan role tertentu
+	// Stream realtime menerima header Authorization atau tiket sekali pakai dari POST /api/stream/ticket
+	r.GET("/api/stream", middleware.StreamTicketMiddleware(streamTicketService, userService), middleware.LoadPermissions(roleService), limiter.Limit("api", apiLimit), streamHandler.Stream)
+
+	//Endpoint yang memerlukan authentication dan permission tertentu.
+	// Route tanpa RequirePermission boleh dipakai semua user yang login, service membatasi aksesnya ke data milik sendiri
 	api := r.Group("/api")
-	api.Use(middleware.JWTAuthMiddleware()) // gunakan middleware jwt
+	api.Use(middleware.JWTAuthMiddleware(userService), middleware.LoadPermissions(roleService), limiter.Limit("api", apiLimit)) // gunakan middleware jwt
 	{
 		// Contoh menggunakan jwt admin 
-		api.DELETE("/users/:id", middleware.RoleCheck("admin"), userHandler.DeleteUser)
-		api.GET("/users/:id", middleware.RoleCheck("customer","admin"), userHandler.GetUserById)
-		api.PUT("/users/:id", middleware.RoleCheck("customer","admin"), userHandler.UpdateUser)
-
-		api.GET("/tables", middleware.RoleCheck("customer", "admin"), tableHandler.GetListTable)
-		api.GET("/tables/:id", middleware.RoleCheck("customer", "admin"),tableHandler.GetTableByID)
-		api.GET("/tables/status", middleware.RoleCheck("customer", "admin"),tableHandler.GetTableByStatus)
-		api.POST("/tables", middleware.RoleCheck("admin"),tableHandler.CreateTable)
-		api.PUT("/tables/:id", middleware.RoleCheck("admin"),tableHandler.UpdateTable)
-		api.DELETE("/tables/:id", middleware.RoleCheck("admin"),tableHandler.DeleteTable)
-
-		api.GET("/reservation", middleware.RoleCheck("admin"), reservationHandler.GetAllReservation)
-		api.GET("/reservation/:id", middleware.RoleCheck("customer", "admin"),reservationHandler.GetReservationDetail)
-		api.GET("/reservation/my-reservation", middleware.RoleCheck("customer", "admin"),reservationHandler.GetReservationByUserLogin)
-		api.POST("/reservation", middleware.RoleCheck("customer","admin"),reservationHandler.CreateReservation)
-		api.PUT("/reservation/:id", middleware.RoleCheck("admin"),reservationHandler.UpdateReservation)
-		api.DELETE("/reservation/:id", middleware.RoleCheck("admin"),reservationHandler.DeleteReservation)
+		api.POST("/logout", userHandler.Logout)
+		api.POST("/stream/ticket", streamHandler.CreateStreamTicket)
+		api.GET("/users", middleware.RequirePermission(models.PermissionUserRead), userHandler.GetAllUser)
+		api.DELETE("/users/:id", middleware.RequirePermission(models.PermissionUserDelete), userHandler.DeleteUser)
+		api.GET("/users/:id", userHandler.GetUserById)
+		api.PUT("/users/:id", userHandler.UpdateUser)
+		api.PUT("/users/:id/role", middleware.RequirePermission(models.PermissionRoleManage), userHandler.UpdateUserRole)
+		api.POST("/users/:id/calendar-token", calendarHandler.CreateCalendarFeed)
+
+		api.GET("/tables/:id", middleware.RequirePermission(models.PermissionTableRead), tableHandler.GetTableByID)
+		api.GET("/tables/status", middleware.RequirePermission(models.PermissionTableRead), tableHandler.GetTableByStatus)
+		api.POST("/tables", middleware.RequirePermission(models.PermissionTableWrite), tableHandler.CreateTable)
+		api.PUT("/tables/:id", middleware.RequirePermission(models.PermissionTableWrite), tableHandler.UpdateTable)
+		api.DELETE("/tables/:id", middleware.RequirePermission(models.PermissionTableWrite), tableHandler.DeleteTable)
+
+		api.PUT("/schedule/opening-hours", middleware.RequirePermission(models.PermissionScheduleWrite), scheduleHandler.UpdateOpeningHours)
+		api.PUT("/schedule/settings", middleware.RequirePermission(models.PermissionScheduleWrite), scheduleHandler.UpdateSettings)
+		api.POST("/schedule/closures", middleware.RequirePermission(models.PermissionScheduleWrite), scheduleHandler.CreateClosure)
+		api.DELETE("/schedule/closures/:id", middleware.RequirePermission(models.PermissionScheduleWrite), scheduleHandler.DeleteClosure)
+
+		api.GET("/table-combinations", middleware.RequirePermission(models.PermissionTableRead), combinationHandler.GetListCombination)
+		api.GET("/table-combinations/:id", middleware.RequirePermission(models.PermissionTableRead), combinationHandler.GetCombinationByID)
+		api.POST("/table-combinations", middleware.RequirePermission(models.PermissionTableWrite), combinationHandler.CreateCombination)
+		api.DELETE("/table-combinations/:id", middleware.RequirePermission(models.PermissionTableWrite), combinationHandler.DeleteCombination)
+
+		api.GET("/reservation", middleware.RequirePermission(models.PermissionReservationRead), reservationHandler.GetAllReservation)
+		api.GET("/reservation/:id", reservationHandler.GetReservationDetail)
+		api.GET("/reservation/my-reservation", reservationHandler.GetReservationByUserLogin)
+		api.POST("/reservation", middleware.RequirePermission(models.PermissionReservationCreate), middleware.VerifiedEmailMiddleware(userService), reservationHandler.CreateReservation)
+		api.PUT("/reservation/:id", reservationHandler.UpdateReservation)
+		api.DELETE("/reservation/:id", reservationHandler.DeleteReservation)
+
+		api.GET("/waitlist", waitlistHandler.GetWaitlist)
+		api.POST("/waitlist", middleware.RequirePermission(models.PermissionReservationCreate), middleware.VerifiedEmailMiddleware(userService), waitlistHandler.JoinWaitlist)
+		api.POST("/waitlist/:id/accept", waitlistHandler.AcceptWaitlistOffer)
+		api.DELETE("/waitlist/:id", waitlistHandler.CancelWaitlistEntry)
+
+		// Siklus hidup reservasi
+		api.POST("/reservation/:id/confirm", middleware.RequirePermission(models.PermissionReservationUpdate), reservationHandler.ConfirmReservation)
+		api.POST("/reservation/:id/seat", middleware.RequirePermission(models.PermissionReservationUpdate), reservationHandler.SeatReservation)
+		api.POST("/reservation/:id/complete", middleware.RequirePermission(models.PermissionReservationUpdate), reservationHandler.CompleteReservation)
+		api.POST("/reservation/:id/cancel", reservationHandler.CancelReservation)
+		api.POST("/reservation/:id/no-show", middleware.RequirePermission(models.PermissionReservationUpdate), reservationHandler.NoShowReservation)
+
+		// Outbox notifikasi
+		api.GET("/outbox", middleware.RequirePermission(models.PermissionOutboxManage), outboxHandler.GetOutboxMessages)
+		api.GET("/outbox/:id", middleware.RequirePermission(models.PermissionOutboxManage), outboxHandler.GetOutboxMessage)
+		api.POST("/outbox/:id/retry", middleware.RequirePermission(models.PermissionOutboxManage), outboxHandler.RetryOutboxMessage)
+
+		// Role dan permission
+		api.GET("/permissions", middleware.RequirePermission(models.PermissionRoleManage), roleHandler.GetPermissions)
+		api.GET("/roles", middleware.RequirePermission(models.PermissionRoleManage), roleHandler.GetRoles)
+		api.GET("/roles/:name", middleware.RequirePermission(models.PermissionRoleManage), roleHandler.GetRole)
+		api.POST("/roles", middleware.RequirePermission(models.PermissionRoleManage), roleHandler.CreateRole)
+		api.PUT("/roles/:name", middleware.RequirePermission(models.PermissionRoleManage), roleHandler.UpdateRole)
+		api.DELETE("/roles/:name", middleware.RequirePermission(models.PermissionRoleManage), roleHandler.DeleteRole)
+
+		// Webhook untuk sistem lain
+		api.GET("/webhooks", middleware.RequirePermission(models.PermissionWebhookManage), webhookHandler.GetWebhooks)
+		api.POST("/webhooks", middleware.RequirePermission(models.PermissionWebhookManage), webhookHandler.CreateWebhook)
+		api.PUT("/webhooks/:id", middleware.RequirePermission(models.PermissionWebhookManage), webhookHandler.UpdateWebhook)
+		api.DELETE("/webhooks/:id", middleware.RequirePermission(models.PermissionWebhookManage), webhookHandler.DeleteWebhook)
+		api.GET("/webhooks/:id/deliveries", middleware.RequirePermission(models.PermissionWebhookManage), webhookHandler.GetWebhookDeliveries)
 	}
 	r.Run(":8080")
 
-}
\ No newline at end of file
+}
+
+// newMailer memilih implementasi Mailer dari konfigurasi. Default-nya SMTP
+func newMailer(cfg config.Mail) notification.Mailer {
+	switch cfg.Driver {
+	case "file":
+		log.Info().Msgf("Emails are written to %s", cfg.FileDir)
+		return notification.NewFileMailer(cfg.FileDir)
+	case "memory":
+		log.Warn().Msg("Emails are kept in memory and never sent")
+		return notification.NewMemoryMailer()
+	default:
+		return notification.NewSMTPMailer(notification.SMTPConfig{
+			Host:       cfg.SMTPHost,
+			Port:       cfg.SMTPPort,
+			Username:   cfg.AuthEmail,
+			Password:   cfg.AuthPassword,
+			From:       cfg.AuthEmail,
+			SenderName: cfg.SenderName,
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"time"
+	"wereserve/config"
+	"wereserve/repository"
+	"wereserve/services"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// RunReminders menjalankan scheduler pengingat reservasi tanpa HTTP server, dipakai oleh command `reminders`.
+// Pengingat hanya ditulis ke outbox, pengirimannya tetap dilakukan oleh worker outbox di server
+func RunReminders() {
+	cfg := config.NewConfig()
+	db, err := cfg.ConnectDB()
+	if err != nil {
+		log.Fatal().Msgf("Error Connection to database: %v", err)
+	}
+
+	runReminderScheduler(newReminderService(cfg, db.DB), cfg.Reminder.PollInterval())
+}
+
+func newReminderService(cfg *config.Config, db *gorm.DB) *services.ReminderService {
+	offsets, err := cfg.Reminder.OffsetDurations()
+	if err != nil {
+		log.Fatal().Msgf("Error reading REMINDER_OFFSETS: %v", err)
+	}
+
+	return services.NewReminderService(repository.NewUnitOfWork(db), repository.NewReminderRepository(db), services.ReminderOptions{
+		Offsets:   offsets,
+		ActionURL: cfg.Reminder.ActionURL,
+		Cutoff:    cfg.Reservation.Cutoff(),
+	})
+}
+
+// runReminderScheduler memeriksa reservasi yang perlu diingatkan setiap interval. Aman dijalankan di lebih dari satu proses
+// karena setiap pengingat dicatat dengan unique constraint sebelum dikirim
+func runReminderScheduler(reminderService *services.ReminderService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for ; ; <-ticker.C {
+		sent, err := reminderService.SendDueReminders()
+		if err != nil {
+			log.Error().Msgf("Error sending reservation reminders: %v", err)
+			continue
+		}
+		if sent > 0 {
+			log.Info().Msgf("Queued %d reservation reminders", sent)
+		}
+	}
+}
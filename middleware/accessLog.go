@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Parameter query yang berisi token. Nilainya diganti supaya tidak tersimpan di access log
+var sensitiveQueryParams = []string{"token", "ticket", "access_token"}
+
+// AccessLogger sama seperti logger bawaan gin, tetapi nilai token di query string disamarkan.
+// Magic link, feed kalender dan tiket stream membawa token lewat query, dan access log sering disimpan lebih lama dari tokennya
+func AccessLogger() gin.HandlerFunc {
+	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
+		return fmt.Sprintf("[GIN] %v | %3d | %13v | %15s | %-7s %#v\n%s",
+			param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+			param.StatusCode,
+			param.Latency.Truncate(time.Microsecond),
+			param.ClientIP,
+			param.Method,
+			redactQuery(param.Path),
+			param.ErrorMessage,
+		)
+	})
+}
+
+// redactQuery mengganti nilai parameter sensitif pada path beserta query-nya dengan "REDACTED"
+func redactQuery(path string) string {
+	base, rawQuery, found := strings.Cut(path, "?")
+	if !found {
+		return path
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		// Query yang tidak bisa dibaca tidak dicatat sama sekali daripada membocorkan token
+		return base + "?REDACTED"
+	}
+
+	redacted := false
+	for _, name := range sensitiveQueryParams {
+		if _, ok := query[name]; ok {
+			query.Set(name, "REDACTED")
+			redacted = true
+		}
+	}
+	if !redacted {
+		return path
+	}
+	return base + "?" + query.Encode()
+}
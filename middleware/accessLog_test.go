@@ -0,0 +1,26 @@
+package middleware
+
+import "testing"
+
+func TestRedactQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "no query", path: "/api/tables", want: "/api/tables"},
+		{name: "harmless query", path: "/api/availability?party_size=2", want: "/api/availability?party_size=2"},
+		{name: "stream ticket", path: "/api/stream?ticket=abc", want: "/api/stream?ticket=REDACTED"},
+		{name: "magic link", path: "/api/public/reservations/manage?token=abc&x=1", want: "/api/public/reservations/manage?token=REDACTED&x=1"},
+		{name: "access token", path: "/api/stream?access_token=eyJ", want: "/api/stream?access_token=REDACTED"},
+		{name: "unparsable query", path: "/api/stream?token=%zz", want: "/api/stream?REDACTED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactQuery(tt.path); got != tt.want {
+				t.Errorf("redactQuery(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
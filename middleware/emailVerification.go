@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"strconv"
+	"wereserve/apperror"
+
+	"github.com/gin-gonic/gin"
+)
+
+var ErrEmailNotVerified = apperror.Forbidden("email_not_verified", "Email belum diverifikasi, silakan cek email Anda untuk link verifikasi")
+
+// EmailVerificationProvider mengecek apakah email user sudah diverifikasi
+type EmailVerificationProvider interface {
+	IsEmailVerified(userID int) (bool, error)
+}
+
+// VerifiedEmailMiddleware menolak request dari user yang emailnya belum diverifikasi.
+// Dipasang setelah JWTAuthMiddleware karena membutuhkan userID di context
+func VerifiedEmailMiddleware(verifications EmailVerificationProvider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("userID")
+		if !exists {
+			WriteError(c, ErrMissingClaims.Withf("User Id tidak di temukan di context"))
+			return
+		}
+
+		id, err := strconv.Atoi(userID.(string))
+		if err != nil {
+			WriteError(c, ErrInvalidToken.Withf("User Id tidak valid"))
+			return
+		}
+
+		verified, err := verifications.IsEmailVerified(id)
+		if err != nil {
+			WriteError(c, err)
+			return
+		}
+
+		if !verified {
+			WriteError(c, ErrEmailNotVerified)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"wereserve/apperror"
+	"wereserve/handler/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+const problemContentType = "application/problem+json"
+
+// ErrorHandler mengubah error yang dicatat handler dengan c.Error menjadi response problem+json.
+// Status ditentukan dari jenis apperror, error lain dianggap 500 dan pesannya tidak dikirim ke client
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		WriteError(c, c.Errors.Last().Err)
+	}
+}
+
+// WriteError langsung menulis response error, dipakai oleh middleware yang berhenti sebelum handler berjalan
+func WriteError(c *gin.Context, err error) {
+	status := StatusCode(err)
+	problem := response.ErrorResponse{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   err.Error(),
+		Instance: c.Request.URL.Path,
+		Code:     "internal_error",
+	}
+
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		problem.Code = appErr.Code
+		problem.Errors = appErr.Fields
+	}
+	if status == http.StatusInternalServerError {
+		log.Printf("%s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		problem.Detail = "internal server error"
+	}
+
+	c.Header("Content-Type", problemContentType)
+	c.AbortWithStatusJSON(status, problem)
+}
+
+// StatusCode memetakan jenis apperror ke status HTTP
+func StatusCode(err error) int {
+	switch {
+	case errors.Is(err, apperror.ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, apperror.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, apperror.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, apperror.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, apperror.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, apperror.ErrTooManyRequests):
+		return http.StatusTooManyRequests
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wereserve/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fixedTokenVersion int
+
+func (v fixedTokenVersion) CurrentTokenVersion(int) (int, error) { return int(v), nil }
+
+func TestJWTAuthMiddlewareRejectsPurposeTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/me", JWTAuthMiddleware(fixedTokenVersion(0)), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	access, err := utils.GenerateJWT("budi@example.com", "customer", "1", 0)
+	if err != nil {
+		t.Fatalf("GenerateJWT() error = %v", err)
+	}
+	action, _ := utils.GenerateReservationActionToken(1, utils.ReservationActionCancel, time.Now().Add(time.Hour))
+	verification, _ := utils.GenerateEmailVerificationToken(1, "budi@example.com", time.Hour)
+
+	tests := []struct {
+		name     string
+		token    string
+		wantCode int
+	}{
+		{name: "access token", token: access, wantCode: http.StatusOK},
+		{name: "reservation action token", token: action, wantCode: http.StatusUnauthorized},
+		{name: "email verification token", token: verification, wantCode: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+		})
+	}
+}
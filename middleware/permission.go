@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// PermissionProvider mengembalikan permission yang dimiliki sebuah role
+type PermissionProvider interface {
+	GetPermissions(role string) ([]string, error)
+}
+
+// LoadPermissions membaca permission dari role user yang sedang login dan menyimpannya di context sebagai "permissions".
+// Dipasang setelah JWTAuthMiddleware. Permission dibaca setiap request, jadi perubahan role langsung berlaku
+func LoadPermissions(provider PermissionProvider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		if role == "" {
+			WriteError(c, ErrMissingClaims.Withf("Role tidak ditemukan"))
+			return
+		}
+
+		permissions, err := provider.GetPermissions(role)
+		if err != nil {
+			WriteError(c, err)
+			return
+		}
+
+		c.Set("permissions", permissions)
+		c.Next()
+	}
+}
+
+// RequirePermission menolak request dari user yang role-nya tidak punya permission tersebut
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, p := range c.GetStringSlice("permissions") {
+			if p == permission {
+				c.Next()
+				return
+			}
+		}
+
+		WriteError(c, ErrAccessDenied.Withf("Anda tidak memiliki permission %s", permission))
+	}
+}
@@ -0,0 +1,271 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"wereserve/apperror"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	ErrRateLimited = apperror.TooManyRequests("rate_limited", "Terlalu banyak request, coba lagi nanti")
+	ErrLoginLocked = apperror.TooManyRequests("login_locked", "Terlalu banyak percobaan login yang gagal, coba lagi nanti")
+)
+
+// Body request yang dibaca untuk mencari email akun dibatasi supaya request besar tidak ditampung di memori
+const maxAccountBodyBytes = 1 << 16
+
+// RateLimit adalah token bucket yang diisi Requests token setiap Per. Burst adalah isi maksimal bucket,
+// jika kosong sama dengan Requests. Requests 0 berarti tanpa batas
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+	Burst    int
+}
+
+func (l RateLimit) enabled() bool {
+	return l.Requests > 0 && l.Per > 0
+}
+
+func (l RateLimit) capacity() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+// rate adalah jumlah token yang bertambah setiap detik
+func (l RateLimit) rate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// RateLimitConfig adalah batas request untuk satu route group, dihitung per alamat IP dan per akun.
+// Akun diambil dari userID di context jika sudah login, atau dari field email di body untuk login dan register
+type RateLimitConfig struct {
+	PerIP      RateLimit
+	PerAccount RateLimit
+}
+
+// LockoutPolicy mengunci akun dan alamat IP setelah login gagal berulang kali. Akun dikunci setelah Threshold kegagalan
+// dan alamat IP setelah IPThreshold kegagalan, lalu lama lockout berlipat dua setiap kegagalan berikutnya sampai MaxDuration.
+// Hitungan kegagalan direset setelah Window tanpa kegagalan, hitungan akun juga direset setelah login berhasil.
+// Threshold atau IPThreshold 0 berarti akun atau alamat IP tersebut tidak dikunci
+type LockoutPolicy struct {
+	Threshold int
+	// Dibuat lebih tinggi dari Threshold karena satu alamat IP (NAT kantor, wifi publik) bisa dipakai banyak user
+	IPThreshold  int
+	BaseDuration time.Duration
+	MaxDuration  time.Duration
+	Window       time.Duration
+}
+
+// duration menghitung lama lockout untuk jumlah kegagalan tertentu, 0 jika belum perlu dikunci
+func (p LockoutPolicy) duration(threshold, failures int) time.Duration {
+	if threshold <= 0 || failures < threshold {
+		return 0
+	}
+
+	duration := p.BaseDuration
+	for i := threshold; i < failures; i++ {
+		duration *= 2
+		if p.MaxDuration > 0 && duration >= p.MaxDuration {
+			return p.MaxDuration
+		}
+	}
+	return duration
+}
+
+// RateLimiter membuat middleware rate limit dan lockout yang memakai store yang sama
+type RateLimiter struct {
+	store RateLimitStore
+	now   func() time.Time
+}
+
+func NewRateLimiter(store RateLimitStore) *RateLimiter {
+	return &RateLimiter{store: store, now: time.Now}
+}
+
+// Limit membatasi request ke route group dengan token bucket per IP dan per akun. name membedakan bucket antar group
+// sehingga route group yang sama-sama dipakai satu IP tidak saling menghabiskan limit.
+// Untuk route yang memerlukan login, pasang setelah JWTAuthMiddleware supaya limit per akun memakai userID
+func (l *RateLimiter) Limit(name string, config RateLimitConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		now := l.now()
+
+		if config.PerIP.enabled() {
+			if !l.take(c, name+":ip:"+c.ClientIP(), config.PerIP, now) {
+				return
+			}
+		}
+
+		if config.PerAccount.enabled() {
+			if account := accountKey(c); account != "" {
+				if !l.take(c, name+":account:"+account, config.PerAccount, now) {
+					return
+				}
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// take mengambil token dari bucket key dan menulis response 429 jika bucket kosong.
+// Jika store gagal, request tetap diteruskan supaya gangguan store tidak mematikan API
+func (l *RateLimiter) take(c *gin.Context, key string, limit RateLimit, now time.Time) bool {
+	allowed, retryAfter, err := l.store.Take(key, limit, now)
+	if err != nil {
+		log.Printf("rate limit store error for %s: %v", key, err)
+		return true
+	}
+	if !allowed {
+		writeRetryAfter(c, retryAfter, ErrRateLimited)
+		return false
+	}
+	return true
+}
+
+// lockoutKey adalah key di store beserta jumlah kegagalan sebelum key tersebut dikunci
+type lockoutKey struct {
+	key       string
+	threshold int
+	// Hitungan alamat IP tidak direset oleh login yang berhasil, supaya satu akun valid tidak bisa dipakai
+	// untuk terus mereset hitungan IP di sela-sela percobaan ke akun lain
+	resetOnSuccess bool
+}
+
+// Lockout menolak login dari akun atau alamat IP yang sedang dikunci, lalu mencatat hasil login setelah handler berjalan.
+// Login dianggap gagal jika handler mencatat error Unauthorized, misalnya email atau password salah
+func (l *RateLimiter) Lockout(name string, policy LockoutPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var keys []lockoutKey
+		if policy.IPThreshold > 0 {
+			keys = append(keys, lockoutKey{key: name + ":ip:" + c.ClientIP(), threshold: policy.IPThreshold})
+		}
+		if policy.Threshold > 0 {
+			if account := accountKey(c); account != "" {
+				keys = append(keys, lockoutKey{key: name + ":account:" + account, threshold: policy.Threshold, resetOnSuccess: true})
+			}
+		}
+		if len(keys) == 0 {
+			c.Next()
+			return
+		}
+
+		now := l.now()
+		for _, key := range keys {
+			lockedUntil, err := l.store.LockedUntil(key.key, now)
+			if err != nil {
+				log.Printf("rate limit store error for %s: %v", key.key, err)
+				continue
+			}
+			if !lockedUntil.IsZero() {
+				writeRetryAfter(c, lockedUntil.Sub(now), ErrLoginLocked)
+				return
+			}
+		}
+
+		c.Next()
+
+		if loginFailed(c) {
+			l.recordFailure(c, keys, policy)
+			return
+		}
+		if len(c.Errors) == 0 && c.Writer.Status() < 400 {
+			for _, key := range keys {
+				if !key.resetOnSuccess {
+					continue
+				}
+				if err := l.store.ResetFailures(key.key); err != nil {
+					log.Printf("rate limit store error for %s: %v", key.key, err)
+				}
+			}
+		}
+	}
+}
+
+// recordFailure menambah hitungan login gagal dan mengunci key yang sudah melewati batasnya.
+// Retry-After ikut dikirim di response gagal yang memicu lockout
+func (l *RateLimiter) recordFailure(c *gin.Context, keys []lockoutKey, policy LockoutPolicy) {
+	now := l.now()
+	var longest time.Duration
+	for _, key := range keys {
+		failures, err := l.store.AddFailure(key.key, policy.Window, now)
+		if err != nil {
+			log.Printf("rate limit store error for %s: %v", key.key, err)
+			continue
+		}
+
+		duration := policy.duration(key.threshold, failures)
+		if duration <= 0 {
+			continue
+		}
+		if err := l.store.Lock(key.key, now.Add(duration)); err != nil {
+			log.Printf("rate limit store error for %s: %v", key.key, err)
+			continue
+		}
+		longest = max(longest, duration)
+	}
+
+	if longest > 0 {
+		c.Header("Retry-After", retryAfterSeconds(longest))
+	}
+}
+
+func loginFailed(c *gin.Context) bool {
+	for _, err := range c.Errors {
+		if errors.Is(err.Err, apperror.ErrUnauthorized) {
+			return true
+		}
+	}
+	return false
+}
+
+// accountKey mengembalikan userID dari JWT, atau email dari body JSON untuk request yang belum login.
+// Body dikembalikan lagi supaya tetap bisa dibaca oleh handler
+func accountKey(c *gin.Context) string {
+	if userID := c.GetString("userID"); userID != "" {
+		return "user:" + userID
+	}
+	if c.Request.Body == nil || !strings.HasPrefix(c.ContentType(), "application/json") {
+		return ""
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxAccountBodyBytes))
+	c.Request.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), c.Request.Body), c.Request.Body}
+	if err != nil {
+		return ""
+	}
+
+	var req struct {
+		Email string `json:"email"`
+	}
+	if json.Unmarshal(body, &req) != nil {
+		return ""
+	}
+	if email := strings.ToLower(strings.TrimSpace(req.Email)); email != "" {
+		return "email:" + email
+	}
+	return ""
+}
+
+func writeRetryAfter(c *gin.Context, retryAfter time.Duration, err error) {
+	c.Header("Retry-After", retryAfterSeconds(retryAfter))
+	WriteError(c, err)
+}
+
+// retryAfterSeconds membulatkan ke atas dalam detik, minimal 1 detik
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(max(1, int(math.Ceil(d.Seconds()))))
+}
@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimitStore menyimpan isi token bucket dan hitungan login gagal. MemoryRateLimitStore cukup untuk satu instance,
+// jika API dijalankan di beberapa instance pakai implementasi store bersama (misalnya Redis) supaya limitnya tidak terbagi
+type RateLimitStore interface {
+	// Take mengambil satu token dari bucket key. Jika bucket kosong, mengembalikan false beserta lama menunggu
+	// sampai token berikutnya tersedia
+	Take(key string, limit RateLimit, now time.Time) (bool, time.Duration, error)
+	// AddFailure menambah hitungan login gagal untuk key lalu mengembalikan jumlahnya.
+	// Hitungan mulai dari nol lagi jika kegagalan terakhir lebih lama dari window
+	AddFailure(key string, window time.Duration, now time.Time) (int, error)
+	// Lock mengunci key sampai waktu until
+	Lock(key string, until time.Time) error
+	// LockedUntil mengembalikan akhir lockout key, atau waktu nol jika key tidak sedang dikunci
+	LockedUntil(key string, now time.Time) (time.Time, error)
+	// ResetFailures menghapus hitungan login gagal dan lockout key
+	ResetFailures(key string) error
+}
+
+// Entry yang tidak dipakai selama ini dihapus dari MemoryRateLimitStore
+const rateLimitSweepInterval = time.Minute
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+	// Setelah waktu ini bucket sudah penuh lagi sehingga aman dihapus
+	fullAt time.Time
+}
+
+type loginFailures struct {
+	count       int
+	last        time.Time
+	window      time.Duration
+	lockedUntil time.Time
+}
+
+// MemoryRateLimitStore menyimpan rate limit di memori proses. Isinya hilang saat server restart
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	failures  map[string]*loginFailures
+	lastSweep time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets:  map[string]*tokenBucket{},
+		failures: map[string]*loginFailures{},
+	}
+}
+
+func (s *MemoryRateLimitStore) Take(key string, limit RateLimit, now time.Time) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	capacity, rate := float64(limit.capacity()), limit.rate()
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: capacity, updated: now}
+		s.buckets[key] = bucket
+	}
+
+	// Isi ulang token sesuai waktu yang berlalu sejak request terakhir
+	if elapsed := now.Sub(bucket.updated).Seconds(); elapsed > 0 {
+		bucket.tokens = min(capacity, bucket.tokens+elapsed*rate)
+		bucket.updated = now
+	}
+
+	allowed := bucket.tokens >= 1
+	if allowed {
+		bucket.tokens--
+	}
+	bucket.fullAt = now.Add(secondsDuration((capacity - bucket.tokens) / rate))
+
+	if allowed {
+		return true, 0, nil
+	}
+	return false, secondsDuration((1 - bucket.tokens) / rate), nil
+}
+
+func (s *MemoryRateLimitStore) AddFailure(key string, window time.Duration, now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	failures, ok := s.failures[key]
+	if !ok {
+		failures = &loginFailures{}
+		s.failures[key] = failures
+	}
+	if now.Sub(failures.last) > window {
+		failures.count = 0
+	}
+	failures.count++
+	failures.last = now
+	failures.window = window
+	return failures.count, nil
+}
+
+func (s *MemoryRateLimitStore) Lock(key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	failures, ok := s.failures[key]
+	if !ok {
+		failures = &loginFailures{}
+		s.failures[key] = failures
+	}
+	failures.lockedUntil = until
+	return nil
+}
+
+func (s *MemoryRateLimitStore) LockedUntil(key string, now time.Time) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if failures, ok := s.failures[key]; ok && failures.lockedUntil.After(now) {
+		return failures.lockedUntil, nil
+	}
+	return time.Time{}, nil
+}
+
+func (s *MemoryRateLimitStore) ResetFailures(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.failures, key)
+	return nil
+}
+
+// sweep menghapus bucket yang sudah penuh dan hitungan gagal yang sudah kedaluwarsa supaya memori tidak terus bertambah.
+// Harus dipanggil saat mu sedang dikunci
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, bucket := range s.buckets {
+		if !now.Before(bucket.fullAt) {
+			delete(s.buckets, key)
+		}
+	}
+	for key, failures := range s.failures {
+		if now.Sub(failures.last) > failures.window && !failures.lockedUntil.After(now) {
+			delete(s.failures, key)
+		}
+	}
+}
+
+func secondsDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,183 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"wereserve/apperror"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errTestInvalidCredentials = apperror.Unauthorized("invalid_credentials", "email atau password salah")
+
+// testClock adalah jam palsu supaya test tidak perlu menunggu bucket terisi ulang atau lockout berakhir
+type testClock struct{ now time.Time }
+
+func (c *testClock) Now() time.Time { return c.now }
+
+func newTestLimiter() (*RateLimiter, *testClock) {
+	clock := &testClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	limiter := NewRateLimiter(NewMemoryRateLimitStore())
+	limiter.now = clock.Now
+	return limiter, clock
+}
+
+func sendLogin(r *gin.Engine, ip, email, password string) *httptest.ResponseRecorder {
+	body := `{"email":"` + email + `","password":"` + password + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = ip + ":1234"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// newLoginRouter membuat route login palsu yang hanya menerima password "secret"
+func newLoginRouter(handlers ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ErrorHandler())
+	r.POST("/login", append(handlers, func(c *gin.Context) {
+		var req struct {
+			Email    string `json:"email"`
+			Password string `json:"password"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil || req.Email == "" {
+			c.Error(apperror.Validation("invalid_body", "body tidak valid"))
+			return
+		}
+		if req.Password != "secret" {
+			c.Error(errTestInvalidCredentials)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"email": req.Email})
+	})...)
+	return r
+}
+
+func TestRateLimitTokenBucket(t *testing.T) {
+	limiter, clock := newTestLimiter()
+	r := newLoginRouter(limiter.Limit("auth", RateLimitConfig{
+		PerIP:      RateLimit{Requests: 3, Per: time.Minute},
+		PerAccount: RateLimit{Requests: 2, Per: time.Minute},
+	}))
+
+	// Limit per akun berlaku walaupun request datang dari IP yang berbeda
+	for i, ip := range []string{"10.0.0.1", "10.0.0.2"} {
+		if w := sendLogin(r, ip, "budi@example.com", "secret"); w.Code != http.StatusOK {
+			t.Fatalf("request %d status = %d, want %d, body = %s", i, w.Code, http.StatusOK, w.Body.String())
+		}
+	}
+	w := sendLogin(r, "10.0.0.3", "BUDI@example.com", "secret")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "30" {
+		t.Fatalf("third request for the same account = %d with Retry-After %q, want %d with 30", w.Code, w.Header().Get("Retry-After"), http.StatusTooManyRequests)
+	}
+
+	// Limit per IP berlaku untuk akun yang berbeda-beda
+	for _, email := range []string{"a@example.com", "b@example.com"} {
+		if w := sendLogin(r, "10.0.0.1", email, "secret"); w.Code != http.StatusOK {
+			t.Fatalf("request for %s status = %d, want %d", email, w.Code, http.StatusOK)
+		}
+	}
+	if w := sendLogin(r, "10.0.0.1", "c@example.com", "secret"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("fourth request from the same IP status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+
+	// Bucket terisi ulang seiring waktu
+	clock.now = clock.now.Add(time.Minute)
+	if w := sendLogin(r, "10.0.0.1", "budi@example.com", "secret"); w.Code != http.StatusOK {
+		t.Errorf("request after a minute status = %d, want %d, body = %s", w.Code, http.StatusOK, w.Body.String())
+	}
+}
+
+func TestLoginLockoutIsProgressive(t *testing.T) {
+	limiter, clock := newTestLimiter()
+	r := newLoginRouter(limiter.Lockout("login", LockoutPolicy{
+		Threshold:    3,
+		BaseDuration: time.Minute,
+		MaxDuration:  3 * time.Minute,
+		Window:       15 * time.Minute,
+	}))
+
+	for i := 1; i <= 2; i++ {
+		if w := sendLogin(r, "10.0.0.1", "budi@example.com", "wrong"); w.Code != http.StatusUnauthorized || w.Header().Get("Retry-After") != "" {
+			t.Fatalf("failed login %d = %d with Retry-After %q, want %d without Retry-After", i, w.Code, w.Header().Get("Retry-After"), http.StatusUnauthorized)
+		}
+	}
+
+	// Kegagalan ketiga memicu lockout, lalu password yang benar pun ditolak sampai lockout berakhir
+	if w := sendLogin(r, "10.0.0.1", "budi@example.com", "wrong"); w.Header().Get("Retry-After") != "60" {
+		t.Fatalf("third failed login Retry-After = %q, want 60", w.Header().Get("Retry-After"))
+	}
+	if w := sendLogin(r, "10.0.0.2", "budi@example.com", "secret"); w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "60" {
+		t.Fatalf("login while locked = %d with Retry-After %q, want %d with 60", w.Code, w.Header().Get("Retry-After"), http.StatusTooManyRequests)
+	}
+
+	// Setiap kegagalan berikutnya menggandakan lockout sampai batas maksimal
+	for _, want := range []string{"120", "180"} {
+		clock.now = clock.now.Add(5 * time.Minute)
+		if w := sendLogin(r, "10.0.0.1", "budi@example.com", "wrong"); w.Header().Get("Retry-After") != want {
+			t.Errorf("failed login after lockout Retry-After = %q, want %s", w.Header().Get("Retry-After"), want)
+		}
+	}
+
+	// Login berhasil mereset hitungan kegagalan
+	clock.now = clock.now.Add(5 * time.Minute)
+	if w := sendLogin(r, "10.0.0.1", "budi@example.com", "secret"); w.Code != http.StatusOK {
+		t.Fatalf("login after lockout status = %d, want %d, body = %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if w := sendLogin(r, "10.0.0.1", "budi@example.com", "wrong"); w.Header().Get("Retry-After") != "" {
+		t.Errorf("first failed login after a successful login Retry-After = %q, want none", w.Header().Get("Retry-After"))
+	}
+}
+
+func TestLockoutPolicyDuration(t *testing.T) {
+	policy := LockoutPolicy{Threshold: 5, BaseDuration: time.Minute, MaxDuration: time.Hour}
+	tests := map[int]time.Duration{4: 0, 5: time.Minute, 6: 2 * time.Minute, 7: 4 * time.Minute, 20: time.Hour}
+	for failures, want := range tests {
+		if got := policy.duration(policy.Threshold, failures); got != want {
+			t.Errorf("duration(%d) = %s, want %s", failures, got, want)
+		}
+	}
+}
+
+func TestLoginLockoutUsesSeparateIPThreshold(t *testing.T) {
+	limiter, _ := newTestLimiter()
+	r := newLoginRouter(limiter.Lockout("login", LockoutPolicy{
+		Threshold:    3,
+		IPThreshold:  5,
+		BaseDuration: time.Minute,
+		MaxDuration:  time.Hour,
+		Window:       15 * time.Minute,
+	}))
+
+	// Dua kegagalan untuk setiap akun dari satu IP belum mengunci akun maupun IP
+	for _, email := range []string{"budi@example.com", "sari@example.com"} {
+		for i := 1; i <= 2; i++ {
+			if w := sendLogin(r, "10.0.0.1", email, "wrong"); w.Code != http.StatusUnauthorized || w.Header().Get("Retry-After") != "" {
+				t.Fatalf("failed login %d for %s = %d with Retry-After %q, want %d without Retry-After", i, email, w.Code, w.Header().Get("Retry-After"), http.StatusUnauthorized)
+			}
+		}
+	}
+
+	// Login berhasil dari IP yang sama tidak mereset hitungan IP
+	if w := sendLogin(r, "10.0.0.1", "andi@example.com", "secret"); w.Code != http.StatusOK {
+		t.Fatalf("successful login status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	// Kegagalan kelima dari IP tersebut mengunci IP, walaupun akunnya baru gagal sekali
+	if w := sendLogin(r, "10.0.0.1", "dewi@example.com", "wrong"); w.Header().Get("Retry-After") != "60" {
+		t.Fatalf("fifth failed login from the IP Retry-After = %q, want 60", w.Header().Get("Retry-After"))
+	}
+	if w := sendLogin(r, "10.0.0.1", "andi@example.com", "secret"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("login from a locked IP = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+
+	// Akun yang belum mencapai Threshold tetap bisa login dari IP lain
+	if w := sendLogin(r, "10.0.0.2", "budi@example.com", "secret"); w.Code != http.StatusOK {
+		t.Fatalf("login from another IP = %d, want %d, body = %s", w.Code, http.StatusOK, w.Body.String())
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"strconv"
+	"wereserve/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StreamTicketRedeemer memakai tiket stream sekali pakai dan mengembalikan user pemiliknya
+type StreamTicketRedeemer interface {
+	RedeemStreamTicket(ticket string) (*models.User, error)
+}
+
+// StreamTicketMiddleware mengautentikasi GET /api/stream.
+// EventSource di browser tidak bisa mengirim header, jadi client mengirim tiket dari POST /api/stream/ticket lewat ?ticket=.
+// Access token tidak pernah diterima dari query, client lain tetap memakai header Authorization
+func StreamTicketMiddleware(tickets StreamTicketRedeemer, tokenVersions TokenVersionProvider) gin.HandlerFunc {
+	jwtAuth := JWTAuthMiddleware(tokenVersions)
+
+	return func(c *gin.Context) {
+		ticket := c.Query("ticket")
+		if ticket == "" {
+			jwtAuth(c)
+			return
+		}
+
+		user, err := tickets.RedeemStreamTicket(ticket)
+		if err != nil {
+			WriteError(c, err)
+			return
+		}
+
+		c.Set("email", user.Email)
+		c.Set("role", user.Role)
+		c.Set("userID", strconv.Itoa(user.ID))
+
+		c.Next()
+	}
+}
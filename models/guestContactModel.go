@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// GuestContact adalah kontak tamu tanpa akun, baik yang dipesankan oleh staff (walk-in atau lewat telepon)
+// maupun yang memesan sendiri lewat halaman reservasi publik
+type GuestContact struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name" gorm:"column:name"`
+	Phone     string    `json:"phone" gorm:"column:phone"`
+	Email     string    `json:"email" gorm:"column:email"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
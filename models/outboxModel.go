@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Status pesan di notification outbox
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusSent    = "sent"
+	OutboxStatusDead    = "dead"
+)
+
+// OutboxMessage adalah notifikasi yang menunggu dikirim oleh worker.
+// Payload berisi data notifikasi dalam format JSON sesuai EventType
+type OutboxMessage struct {
+	ID            int        `json:"id"`
+	EventType     string     `json:"event_type" gorm:"column:event_type"`
+	Recipient     string     `json:"recipient" gorm:"column:recipient"`
+	Payload       string     `json:"payload" gorm:"column:payload;type:jsonb"`
+	Status        string     `json:"status" gorm:"column:status;default:pending"`
+	Attempts      int        `json:"attempts" gorm:"column:attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"column:next_attempt_at"`
+	LastError     *string    `json:"last_error" gorm:"column:last_error"`
+	SentAt        *time.Time `json:"sent_at" gorm:"column:sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// PasswordResetToken menyimpan hash dari token yang dikirim lewat email saat user lupa password.
+// Token hanya bisa dipakai sekali (UsedAt) dan sebelum ExpiresAt
+type PasswordResetToken struct {
+	ID        int        `json:"id"`
+	UserID    int        `json:"user_id" gorm:"column:user_id"`
+	TokenHash string     `json:"-" gorm:"column:token_hash"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"column:expires_at"`
+	UsedAt    *time.Time `json:"used_at" gorm:"column:used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (t PasswordResetToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
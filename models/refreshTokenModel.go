@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// RefreshToken menyimpan hash dari refresh token yang diberikan saat login.
+// Setiap kali dipakai token dirotasi: token lama di-revoke dan ReplacedByID menunjuk ke token baru
+type RefreshToken struct {
+	ID           int        `json:"id"`
+	UserID       int        `json:"user_id" gorm:"column:user_id"`
+	TokenHash    string     `json:"-" gorm:"column:token_hash"`
+	FamilyID     string     `json:"family_id" gorm:"column:family_id"`
+	ExpiresAt    time.Time  `json:"expires_at" gorm:"column:expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at" gorm:"column:revoked_at"`
+	ReplacedByID *int       `json:"replaced_by_id" gorm:"column:replaced_by_id"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
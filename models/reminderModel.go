@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// ReservationReminder mencatat pengingat yang sudah dikirim untuk sebuah reservasi.
+// OffsetMinutes adalah jarak pengingat sebelum reservation_datetime, misalnya 1440 untuk 24 jam
+type ReservationReminder struct {
+	ID            int       `json:"id"`
+	ReservationID int       `json:"reservation_id" gorm:"column:reservation_id"`
+	OffsetMinutes int       `json:"offset_minutes" gorm:"column:offset_minutes"`
+	SentAt        time.Time `json:"sent_at" gorm:"column:sent_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// ReservationManageToken adalah token magic link untuk tamu tanpa akun mengelola reservasinya.
+// Satu reservasi hanya punya satu token aktif, token lama tidak berlaku lagi saat link dikirim ulang.
+// Hanya hash token yang disimpan
+type ReservationManageToken struct {
+	ID             int       `json:"id"`
+	ReservationID  int       `json:"reservation_id" gorm:"column:reservation_id"`
+	GuestContactID int       `json:"guest_contact_id" gorm:"column:guest_contact_id"`
+	TokenHash      string    `json:"-" gorm:"column:token_hash"`
+	ExpiresAt      time.Time `json:"expires_at" gorm:"column:expires_at"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func (t ReservationManageToken) IsUsable(now time.Time) bool {
+	return now.Before(t.ExpiresAt)
+}
@@ -2,16 +2,97 @@ package models
 
 import "time"
 
+// Durasi default sebuah reservasi jika client tidak mengirimkan duration_minutes
+const DefaultReservationDuration = 120
+
+// Status siklus hidup reservasi
+const (
+	ReservationStatusPending   = "pending"
+	ReservationStatusConfirmed = "confirmed"
+	ReservationStatusSeated    = "seated"
+	ReservationStatusCompleted = "completed"
+	ReservationStatusCancelled = "cancelled"
+	ReservationStatusNoShow    = "no_show"
+)
+
+// Status reservasi yang masih memblokir meja
+var ActiveReservationStatuses = []string{
+	ReservationStatusPending,
+	ReservationStatusConfirmed,
+	ReservationStatusSeated,
+}
+
 type Reservation struct {
 	ID             int       `json:"id"`
-    UserID         int       `json:"user_id" gorm:"column:user_id"`
+    // UserID 0 berarti reservasi milik kontak tamu tanpa akun dan disimpan sebagai NULL
+    UserID         int       `json:"user_id" gorm:"column:user_id;default:null"`
+    GuestContactID *int      `json:"guest_contact_id" gorm:"column:guest_contact_id"`
     TableID        int       `json:"table_id" gorm:"column:table_id"`
+    CombinationID  *int      `json:"combination_id" gorm:"column:combination_id"`
     ReservationDateTime          time.Time    `json:"reservation_datetime" gorm:"column:reservation_datetime"`
+    DurationMinutes int      `json:"duration_minutes" gorm:"column:duration_minutes"`
+    // end_datetime adalah generated column di database, jadi hanya dibaca
+    EndDateTime    time.Time `json:"end_datetime" gorm:"column:end_datetime;->"`
     NumberOfPeople int       `json:"number_of_people" gorm:"column:number_of_people"`
+    Status         string    `json:"status" gorm:"column:status;default:pending"`
+    CancellationReason string `json:"cancellation_reason" gorm:"column:cancellation_reason"`
+    CancelledAt    *time.Time `json:"cancelled_at" gorm:"column:cancelled_at"`
     CreatedAt      time.Time `json:"created_at" gorm:"column:created_at"`
     UpdatedAt      time.Time `json:"updated_at" gorm:"column:updated_at"`
 
-    // Relasi model ke User dan ke Table
+    // Relasi model ke User, Table dan kontak tamu
     User User `gorm:"foreignKey:UserID"`
     Table Table `gorm:"foreignKey:TableID"`
-}
\ No newline at end of file
+    GuestContact *GuestContact `gorm:"foreignKey:GuestContactID"`
+
+    // Semua meja yang dipakai reservasi, termasuk TableID. Lebih dari satu jika memakai kombinasi meja
+    Tables []Table `gorm:"many2many:reservation_tables;"`
+}
+
+// Waktu selesai reservasi dihitung dari waktu mulai dan durasi
+func (r *Reservation) EndTime() time.Time {
+	duration := r.DurationMinutes
+	if duration == 0 {
+		duration = DefaultReservationDuration
+	}
+	return r.ReservationDateTime.Add(time.Duration(duration) * time.Minute)
+}
+
+// ContactName adalah nama pemesan, diambil dari user atau dari kontak tamu
+func (r *Reservation) ContactName() string {
+	if r.GuestContact != nil {
+		return r.GuestContact.Name
+	}
+	return r.User.Name
+}
+
+// ContactEmail adalah alamat email untuk notifikasi reservasi. Kosong jika tamu tidak memberikan email
+func (r *Reservation) ContactEmail() string {
+	if r.GuestContact != nil {
+		return r.GuestContact.Email
+	}
+	return r.User.Email
+}
+
+// ID semua meja yang dipakai reservasi
+func (r *Reservation) TableIDs() []int {
+	if len(r.Tables) == 0 {
+		return []int{r.TableID}
+	}
+
+	ids := make([]int, 0, len(r.Tables))
+	for _, table := range r.Tables {
+		ids = append(ids, table.ID)
+	}
+	return ids
+}
+
+// Reservasi yang statusnya completed, cancelled atau no_show tidak bisa diubah lagi
+func (r *Reservation) IsActive() bool {
+	for _, status := range ActiveReservationStatuses {
+		if r.Status == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+// Role bawaan yang tidak boleh dihapus. Role lain seperti host, manager dan staff dikelola admin lewat API
+const (
+	RoleAdmin    = "admin"
+	RoleCustomer = "customer"
+)
+
+// Permission yang bisa diberikan ke role. Route dijaga dengan permission, bukan dengan nama role
+const (
+	// Melihat semua user, bukan hanya akun sendiri
+	PermissionUserRead = "user:read"
+	// Mengubah akun user lain
+	PermissionUserWrite  = "user:write"
+	PermissionUserDelete = "user:delete"
+	// Membuat, mengubah dan menghapus role serta mengganti role user
+	PermissionRoleManage = "role:manage"
+	PermissionTableRead  = "table:read"
+	// Mengelola meja dan kombinasi meja
+	PermissionTableWrite    = "table:write"
+	PermissionScheduleWrite = "schedule:write"
+	// Membuat reservasi dan masuk waitlist
+	PermissionReservationCreate = "reservation:create"
+	// Melihat reservasi semua user
+	PermissionReservationRead = "reservation:read"
+	// Mengubah reservasi user lain dan menjalankan siklus hidup reservasi (confirm, seat, complete, no-show)
+	PermissionReservationUpdate = "reservation:update"
+	// Membatalkan reservasi user lain tanpa batas waktu cutoff
+	PermissionReservationCancel = "reservation:cancel"
+	// Melihat dan mengelola antrian waitlist semua user
+	PermissionWaitlistManage = "waitlist:manage"
+	PermissionOutboxManage   = "outbox:manage"
+	PermissionWebhookManage  = "webhook:manage"
+)
+
+var Permissions = []string{
+	PermissionUserRead,
+	PermissionUserWrite,
+	PermissionUserDelete,
+	PermissionRoleManage,
+	PermissionTableRead,
+	PermissionTableWrite,
+	PermissionScheduleWrite,
+	PermissionReservationCreate,
+	PermissionReservationRead,
+	PermissionReservationUpdate,
+	PermissionReservationCancel,
+	PermissionWaitlistManage,
+	PermissionOutboxManage,
+	PermissionWebhookManage,
+}
+
+func IsValidPermission(permission string) bool {
+	for _, valid := range Permissions {
+		if permission == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// Role adalah kumpulan permission. Permission disimpan di tabel role_permissions
+type Role struct {
+	Name        string    `json:"name" gorm:"column:name;primaryKey"`
+	Description string    `json:"description" gorm:"column:description"`
+	Permissions []string  `json:"permissions" gorm:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// IsBuiltIn menandai role yang dibutuhkan aplikasi: admin untuk mengelola role, customer untuk user yang baru register
+func (r Role) IsBuiltIn() bool {
+	return r.Name == RoleAdmin || r.Name == RoleCustomer
+}
+
+type RolePermission struct {
+	RoleName   string `gorm:"column:role_name;primaryKey"`
+	Permission string `gorm:"column:permission;primaryKey"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// OpeningHour adalah jam buka restoran untuk satu hari dalam seminggu.
+// Jam disimpan dalam format HH:MM waktu lokal restoran
+type OpeningHour struct {
+	ID              int       `json:"id"`
+	DayOfWeek       int       `json:"day_of_week" gorm:"column:day_of_week"`
+	OpenTime        string    `json:"open_time" gorm:"column:open_time"`
+	CloseTime       string    `json:"close_time" gorm:"column:close_time"`
+	LastSeatingTime string    `json:"last_seating_time" gorm:"column:last_seating_time"`
+	IsClosed        bool      `json:"is_closed" gorm:"column:is_closed"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// ScheduleClosure adalah rentang tanggal di mana restoran tutup, misalnya hari libur atau acara khusus
+type ScheduleClosure struct {
+	ID        int       `json:"id"`
+	StartDate time.Time `json:"start_date" gorm:"column:start_date"`
+	EndDate   time.Time `json:"end_date" gorm:"column:end_date"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ScheduleSetting struct {
+	ID          int       `json:"id"`
+	SlotMinutes int       `json:"slot_minutes" gorm:"column:slot_minutes"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
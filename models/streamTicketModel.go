@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// StreamTicket adalah tiket sekali pakai untuk membuka GET /api/stream dari EventSource di browser.
+// Hanya hash tiket yang disimpan, dan tiket hanya berlaku beberapa detik supaya tidak berguna jika tercatat di log
+type StreamTicket struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"user_id" gorm:"column:user_id"`
+	TicketHash string     `json:"-" gorm:"column:ticket_hash"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"column:expires_at"`
+	UsedAt     *time.Time `json:"used_at" gorm:"column:used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// TableCombination adalah sekelompok meja yang boleh digabung untuk satu reservasi
+type TableCombination struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Tables    []Table   `json:"tables" gorm:"many2many:table_combination_tables;"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Total kapasitas semua meja di dalam kombinasi
+func (c *TableCombination) Capacity() int {
+	total := 0
+	for _, table := range c.Tables {
+		total += table.Capacity
+	}
+	return total
+}
@@ -9,6 +9,16 @@ type User struct {
 	Email string `json:"email"`
 	Password string `json:"password"`
 	Role      string    `json:"role"`
+	// Versi token yang masih berlaku, access token dengan versi berbeda ditolak
+	TokenVersion int    `json:"-" gorm:"column:token_version"`
+	// Nil jika email belum diverifikasi, akun seperti ini belum boleh membuat reservasi
+	EmailVerifiedAt *time.Time `json:"email_verified_at" gorm:"column:email_verified_at"`
+	// Hash token di link feed kalender, nil jika user belum pernah membuat link feed
+	CalendarTokenHash *string `json:"-" gorm:"column:calendar_token_hash"`
     CreatedAt time.Time `json:"created_at"`
     UpdatedAt time.Time `json:"updated_at"`
 }
+
+func (u User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Status antrian waitlist
+const (
+	WaitlistStatusWaiting   = "waiting"
+	WaitlistStatusOffered   = "offered"
+	WaitlistStatusAccepted  = "accepted"
+	WaitlistStatusExpired   = "expired"
+	WaitlistStatusCancelled = "cancelled"
+)
+
+// WaitlistEntry adalah customer yang menunggu meja untuk party_size orang dengan waktu mulai di antara DesiredStart dan DesiredEnd
+type WaitlistEntry struct {
+	ID              int       `json:"id"`
+	UserID          int       `json:"user_id" gorm:"column:user_id"`
+	PartySize       int       `json:"party_size" gorm:"column:party_size"`
+	DesiredStart    time.Time `json:"desired_start" gorm:"column:desired_start"`
+	DesiredEnd      time.Time `json:"desired_end" gorm:"column:desired_end"`
+	DurationMinutes int       `json:"duration_minutes" gorm:"column:duration_minutes"`
+	Status          string    `json:"status" gorm:"column:status;default:waiting"`
+
+	// Diisi saat slot ditawarkan ke customer
+	OfferedTableID       *int       `json:"offered_table_id" gorm:"column:offered_table_id"`
+	OfferedCombinationID *int       `json:"offered_combination_id" gorm:"column:offered_combination_id"`
+	OfferedDateTime      *time.Time `json:"offered_datetime" gorm:"column:offered_datetime"`
+	OfferExpiresAt       *time.Time `json:"offer_expires_at" gorm:"column:offer_expires_at"`
+	ReservationID        *int       `json:"reservation_id" gorm:"column:reservation_id"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	User User `gorm:"foreignKey:UserID"`
+}
+
+// Penawaran yang sudah lewat batas waktunya tidak bisa diterima lagi
+func (w *WaitlistEntry) IsOfferExpired(now time.Time) bool {
+	return w.Status == WaitlistStatusOffered && w.OfferExpiresAt != nil && !now.Before(*w.OfferExpiresAt)
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Event yang bisa dikirim lewat webhook
+const (
+	WebhookEventReservationCreated       = "reservation.created"
+	WebhookEventReservationUpdated       = "reservation.updated"
+	WebhookEventReservationCancelled     = "reservation.cancelled"
+	WebhookEventReservationStatusChanged = "reservation.status_changed"
+	WebhookEventTableStatusChanged       = "table.status_changed"
+)
+
+var WebhookEventTypes = []string{
+	WebhookEventReservationCreated,
+	WebhookEventReservationUpdated,
+	WebhookEventReservationCancelled,
+	WebhookEventReservationStatusChanged,
+	WebhookEventTableStatusChanged,
+}
+
+// Status pengiriman webhook
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
+
+// WebhookSubscription adalah endpoint milik sistem lain (POS, tablet) yang menerima event.
+// Secret dipakai untuk menandatangani body dengan HMAC-SHA256
+type WebhookSubscription struct {
+	ID         int       `json:"id"`
+	URL        string    `json:"url" gorm:"column:url"`
+	Secret     string    `json:"-" gorm:"column:secret"`
+	EventTypes string    `json:"event_types" gorm:"column:event_types"`
+	Active     bool      `json:"active" gorm:"column:active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Events mengembalikan daftar event yang dilanggan
+func (s WebhookSubscription) Events() []string {
+	if s.EventTypes == "" {
+		return []string{}
+	}
+	return strings.Split(s.EventTypes, ",")
+}
+
+func (s WebhookSubscription) Subscribes(eventType string) bool {
+	for _, event := range s.Events() {
+		if event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery adalah satu event yang dikirim ke satu subscription beserta hasil percobaan terakhirnya
+type WebhookDelivery struct {
+	ID             int        `json:"id"`
+	SubscriptionID int        `json:"subscription_id" gorm:"column:subscription_id"`
+	EventID        string     `json:"event_id" gorm:"column:event_id"`
+	EventType      string     `json:"event_type" gorm:"column:event_type"`
+	Payload        string     `json:"payload" gorm:"column:payload;type:jsonb"`
+	Status         string     `json:"status" gorm:"column:status;default:pending"`
+	Attempts       int        `json:"attempts" gorm:"column:attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"column:next_attempt_at"`
+	ResponseStatus *int       `json:"response_status" gorm:"column:response_status"`
+	LastError      *string    `json:"last_error" gorm:"column:last_error"`
+	DeliveredAt    *time.Time `json:"delivered_at" gorm:"column:delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	Subscription WebhookSubscription `gorm:"foreignKey:SubscriptionID"`
+}
@@ -0,0 +1,128 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Nilai METHOD iCalendar (RFC 5546) untuk lampiran email. REQUEST dan CANCEL wajib punya ORGANIZER dan ATTENDEE,
+// PUBLISH dipakai untuk event tanpa penyelenggara
+const (
+	CalendarMethodPublish = "PUBLISH"
+	CalendarMethodRequest = "REQUEST"
+	CalendarMethodCancel  = "CANCEL"
+)
+
+// CalendarAddress adalah nama dan email untuk properti ORGANIZER atau ATTENDEE
+type CalendarAddress struct {
+	Name  string
+	Email string
+}
+
+// CalendarEvent adalah satu VEVENT di file .ics
+type CalendarEvent struct {
+	// UID harus sama untuk reservasi yang sama supaya aplikasi kalender mengganti event lama, bukan menambah event baru
+	UID string
+	// Sequence harus naik setiap kali event berubah
+	Sequence    int64
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Cancelled   bool
+	// Organizer adalah alamat pengirim email dan Attendee adalah penerimanya. Kosong untuk feed kalender
+	Organizer CalendarAddress
+	Attendee  CalendarAddress
+}
+
+// ReservationCalendarUID mengembalikan UID event kalender untuk sebuah reservasi
+func ReservationCalendarUID(reservationID int) string {
+	return fmt.Sprintf("reservation-%d@wereserve", reservationID)
+}
+
+// BuildCalendar menyusun file iCalendar (RFC 5545). method kosong dipakai untuk feed kalender
+func BuildCalendar(method string, events ...CalendarEvent) []byte {
+	var b bytes.Buffer
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:-//WeReserve//Reservations//EN")
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	if method != "" {
+		writeLine(&b, "METHOD:"+method)
+	}
+	writeLine(&b, "X-WR-CALNAME:WeReserve")
+
+	stamp := formatCalendarTime(time.Now())
+	for _, event := range events {
+		status := "CONFIRMED"
+		if event.Cancelled {
+			status = "CANCELLED"
+		}
+
+		writeLine(&b, "BEGIN:VEVENT")
+		writeLine(&b, "UID:"+escapeCalendarText(event.UID))
+		writeLine(&b, fmt.Sprintf("SEQUENCE:%d", event.Sequence))
+		writeLine(&b, "DTSTAMP:"+stamp)
+		writeLine(&b, "DTSTART:"+formatCalendarTime(event.Start))
+		writeLine(&b, "DTEND:"+formatCalendarTime(event.End))
+		writeLine(&b, "SUMMARY:"+escapeCalendarText(event.Summary))
+		if event.Description != "" {
+			writeLine(&b, "DESCRIPTION:"+escapeCalendarText(event.Description))
+		}
+		if event.Organizer.Email != "" {
+			writeLine(&b, "ORGANIZER"+calendarNameParam(event.Organizer)+":mailto:"+event.Organizer.Email)
+		}
+		// Reservasi tidak perlu dibalas, jadi tamu langsung tercatat hadir dan aplikasi kalender tidak meminta RSVP
+		if event.Attendee.Email != "" {
+			writeLine(&b, "ATTENDEE"+calendarNameParam(event.Attendee)+";ROLE=REQ-PARTICIPANT;PARTSTAT=ACCEPTED;RSVP=FALSE:mailto:"+event.Attendee.Email)
+		}
+		writeLine(&b, "STATUS:"+status)
+		writeLine(&b, "END:VEVENT")
+	}
+
+	writeLine(&b, "END:VCALENDAR")
+	return b.Bytes()
+}
+
+func formatCalendarTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// calendarNameParam menulis parameter CN. Nilai parameter diapit tanda kutip dan tidak boleh berisi tanda kutip (RFC 5545 bagian 3.2)
+func calendarNameParam(address CalendarAddress) string {
+	name := strings.NewReplacer(`"`, "", "\r", " ", "\n", " ").Replace(address.Name)
+	if name == "" {
+		return ""
+	}
+	return `;CN="` + name + `"`
+}
+
+// escapeCalendarText meng-escape karakter khusus di nilai TEXT (RFC 5545 bagian 3.3.11)
+func escapeCalendarText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace(s)
+}
+
+// writeLine menulis satu content line diakhiri CRLF. Baris yang lebih dari 75 oktet dilipat (RFC 5545 bagian 3.1)
+// tanpa memotong karakter UTF-8 di tengah
+func writeLine(b *bytes.Buffer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Baris lanjutan diawali satu spasi, jadi sisanya maksimal 74 oktet
+		limit = 74
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
@@ -0,0 +1,191 @@
+package notification
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Jenis event yang bisa disimpan di outbox, satu untuk setiap method Notifier
+const (
+	EventReservationCreated    = "reservation_created"
+	EventReservationConfirmed  = "reservation_confirmed"
+	EventReservationUpdated    = "reservation_updated"
+	EventReservationCancelled  = "reservation_cancelled"
+	EventReservationReminder   = "reservation_reminder"
+	EventReservationManageLink = "reservation_manage_link"
+	EventWaitlistOffer         = "waitlist_offer"
+	EventPasswordReset         = "password_reset"
+	EventEmailVerification     = "email_verification"
+)
+
+// Event adalah pemanggilan Notifier yang disimpan untuk dikirim nanti
+type Event struct {
+	Type    string
+	To      string
+	Payload json.RawMessage
+}
+
+// EventNotifier mengubah setiap pemanggilan Notifier menjadi Event dan menyerahkannya ke record,
+// misalnya untuk disimpan di outbox. Pengiriman sebenarnya dilakukan oleh Deliver
+type EventNotifier struct {
+	record func(event Event) error
+}
+
+func NewEventNotifier(record func(event Event) error) *EventNotifier {
+	return &EventNotifier{record: record}
+}
+
+func (n *EventNotifier) ReservationCreated(to string, data ReservationData) error {
+	return n.emit(EventReservationCreated, to, data)
+}
+
+func (n *EventNotifier) ReservationConfirmed(to string, data ReservationData) error {
+	return n.emit(EventReservationConfirmed, to, data)
+}
+
+func (n *EventNotifier) ReservationUpdated(to string, data ReservationData) error {
+	return n.emit(EventReservationUpdated, to, data)
+}
+
+func (n *EventNotifier) ReservationCancelled(to string, data ReservationData) error {
+	return n.emit(EventReservationCancelled, to, data)
+}
+
+func (n *EventNotifier) ReservationReminder(to string, data ReminderData) error {
+	return n.emit(EventReservationReminder, to, data)
+}
+
+func (n *EventNotifier) ReservationManageLink(to string, data ReservationData) error {
+	return n.emit(EventReservationManageLink, to, data)
+}
+
+func (n *EventNotifier) WaitlistOffer(to string, data WaitlistOfferData) error {
+	return n.emit(EventWaitlistOffer, to, data)
+}
+
+func (n *EventNotifier) PasswordReset(to string, data LinkData) error {
+	return n.emit(EventPasswordReset, to, data)
+}
+
+func (n *EventNotifier) EmailVerification(to string, data LinkData) error {
+	return n.emit(EventEmailVerification, to, data)
+}
+
+func (n *EventNotifier) emit(eventType, to string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s notification: %w", eventType, err)
+	}
+	return n.record(Event{Type: eventType, To: to, Payload: payload})
+}
+
+// Deliver mengirim event yang tersimpan lewat notifier
+func Deliver(notifier Notifier, event Event) error {
+	switch event.Type {
+	case EventReservationCreated, EventReservationConfirmed, EventReservationUpdated, EventReservationCancelled, EventReservationManageLink:
+		var data ReservationData
+		if err := json.Unmarshal(event.Payload, &data); err != nil {
+			return fmt.Errorf("failed to decode %s notification: %w", event.Type, err)
+		}
+		switch event.Type {
+		case EventReservationCreated:
+			return notifier.ReservationCreated(event.To, data)
+		case EventReservationConfirmed:
+			return notifier.ReservationConfirmed(event.To, data)
+		case EventReservationUpdated:
+			return notifier.ReservationUpdated(event.To, data)
+		case EventReservationManageLink:
+			return notifier.ReservationManageLink(event.To, data)
+		default:
+			return notifier.ReservationCancelled(event.To, data)
+		}
+	case EventReservationReminder:
+		var data ReminderData
+		if err := json.Unmarshal(event.Payload, &data); err != nil {
+			return fmt.Errorf("failed to decode %s notification: %w", event.Type, err)
+		}
+		return notifier.ReservationReminder(event.To, data)
+	case EventWaitlistOffer:
+		var data WaitlistOfferData
+		if err := json.Unmarshal(event.Payload, &data); err != nil {
+			return fmt.Errorf("failed to decode %s notification: %w", event.Type, err)
+		}
+		return notifier.WaitlistOffer(event.To, data)
+	case EventPasswordReset, EventEmailVerification:
+		var data LinkData
+		if err := json.Unmarshal(event.Payload, &data); err != nil {
+			return fmt.Errorf("failed to decode %s notification: %w", event.Type, err)
+		}
+		if event.Type == EventPasswordReset {
+			return notifier.PasswordReset(event.To, data)
+		}
+		return notifier.EmailVerification(event.To, data)
+	default:
+		return fmt.Errorf("unknown notification event %q", event.Type)
+	}
+}
+
+// redactedValue menggantikan link yang disembunyikan di payload
+const redactedValue = "[redacted]"
+
+// RedactPayload menyembunyikan link di payload reset password, verifikasi email, pengingat reservasi
+// dan link kelola reservasi tamu, karena link tersebut berisi token yang bisa dipakai atas nama pemiliknya
+func RedactPayload(eventType string, payload []byte) json.RawMessage {
+	switch eventType {
+	case EventReservationCreated, EventReservationManageLink:
+		var data ReservationData
+		if err := json.Unmarshal(payload, &data); err != nil {
+			return json.RawMessage(`{}`)
+		}
+		if data.ManageLink == "" {
+			return payload
+		}
+		data.ManageLink = redactedValue
+		return marshalRedacted(data)
+	case EventPasswordReset, EventEmailVerification:
+		var data LinkData
+		if err := json.Unmarshal(payload, &data); err != nil {
+			return json.RawMessage(`{}`)
+		}
+		data.Link = redactedValue
+		return marshalRedacted(data)
+	case EventReservationReminder:
+		var data ReminderData
+		if err := json.Unmarshal(payload, &data); err != nil {
+			return json.RawMessage(`{}`)
+		}
+		data.ConfirmLink = redactedValue
+		if data.CancelLink != "" {
+			data.CancelLink = redactedValue
+		}
+		return marshalRedacted(data)
+	default:
+		return payload
+	}
+}
+
+// IsPayloadRedacted mengembalikan true jika link di payload sudah disembunyikan. Token aslinya tidak disimpan lagi,
+// jadi pesan seperti ini tidak bisa dikirim ulang
+func IsPayloadRedacted(eventType string, payload []byte) bool {
+	switch eventType {
+	case EventReservationCreated, EventReservationManageLink:
+		var data ReservationData
+		return json.Unmarshal(payload, &data) == nil && data.ManageLink == redactedValue
+	case EventPasswordReset, EventEmailVerification:
+		var data LinkData
+		return json.Unmarshal(payload, &data) == nil && data.Link == redactedValue
+	case EventReservationReminder:
+		var data ReminderData
+		return json.Unmarshal(payload, &data) == nil && data.ConfirmLink == redactedValue
+	default:
+		return false
+	}
+}
+
+func marshalRedacted(data interface{}) json.RawMessage {
+	redacted, err := json.Marshal(data)
+	if err != nil {
+		return json.RawMessage(`{}`)
+	}
+	return redacted
+}
@@ -0,0 +1,162 @@
+// Package notification mengirim pemberitahuan ke user. Notifier menyusun pesan dari template,
+// Mailer menentukan ke mana pesan dikirim (SMTP, file atau memori)
+package notification
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/gomail.v2"
+)
+
+// Message adalah email yang sudah siap dikirim
+type Message struct {
+	To          string
+	Subject     string
+	HTMLBody    string
+	Attachments []Attachment
+}
+
+// Attachment adalah file yang dilampirkan ke email, misalnya undangan kalender .ics
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// SMTPConfig berisi pengaturan server SMTP
+type SMTPConfig struct {
+	Host       string
+	Port       int
+	Username   string
+	Password   string
+	From       string
+	SenderName string
+}
+
+// SMTPMailer mengirim email lewat server SMTP
+type SMTPMailer struct {
+	config SMTPConfig
+	dialer *gomail.Dialer
+}
+
+func NewSMTPMailer(config SMTPConfig) *SMTPMailer {
+	return &SMTPMailer{
+		config: config,
+		dialer: gomail.NewDialer(config.Host, config.Port, config.Username, config.Password),
+	}
+}
+
+func (m *SMTPMailer) Send(msg Message) error {
+	message := gomail.NewMessage()
+	if m.config.SenderName != "" {
+		message.SetAddressHeader("From", m.config.From, m.config.SenderName)
+	} else {
+		message.SetHeader("From", m.config.From)
+	}
+	message.SetHeader("To", msg.To)
+	message.SetHeader("Subject", msg.Subject)
+	message.SetBody("text/html", msg.HTMLBody)
+	for _, attachment := range msg.Attachments {
+		data := attachment.Data
+		message.Attach(attachment.Filename,
+			gomail.SetHeader(map[string][]string{"Content-Type": {attachment.ContentType}}),
+			gomail.SetCopyFunc(func(w io.Writer) error {
+				_, err := w.Write(data)
+				return err
+			}),
+		)
+	}
+
+	if err := m.dialer.DialAndSend(message); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// FileMailer menyimpan setiap email sebagai file .html di Dir. Dipakai saat development supaya email bisa dibuka di browser
+type FileMailer struct {
+	Dir string
+}
+
+func NewFileMailer(dir string) *FileMailer {
+	return &FileMailer{Dir: dir}
+}
+
+func (m *FileMailer) Send(msg Message) error {
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create mail directory: %w", err)
+	}
+
+	prefix := fmt.Sprintf("%s_%s", time.Now().Format("20060102T150405.000000000"), sanitizeFileName(msg.To))
+	content := fmt.Sprintf("<!--\nTo: %s\nSubject: %s\n-->\n%s", msg.To, msg.Subject, msg.HTMLBody)
+
+	if err := os.WriteFile(filepath.Join(m.Dir, prefix+".html"), []byte(content), 0o644); err != nil {
+		return fmt.Errorf("failed to write email file: %w", err)
+	}
+
+	// Lampiran disimpan di sebelah file email dengan prefix yang sama
+	for _, attachment := range msg.Attachments {
+		name := prefix + "_" + sanitizeFileName(attachment.Filename)
+		if err := os.WriteFile(filepath.Join(m.Dir, name), attachment.Data, 0o644); err != nil {
+			return fmt.Errorf("failed to write email attachment: %w", err)
+		}
+	}
+	return nil
+}
+
+func sanitizeFileName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_', r == '@':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
+
+// MemoryMailer menyimpan email di memori. Dipakai di test untuk memeriksa email yang dikirim
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+func (m *MemoryMailer) Send(msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// Messages mengembalikan salinan semua email yang sudah dikirim
+func (m *MemoryMailer) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.messages...)
+}
+
+// Last mengembalikan email terakhir yang dikirim ke alamat to
+func (m *MemoryMailer) Last(to string) (Message, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].To == to {
+			return m.messages[i], true
+		}
+	}
+	return Message{}, false
+}
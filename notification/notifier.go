@@ -0,0 +1,171 @@
+package notification
+
+import (
+	"fmt"
+	"time"
+)
+
+// ReservationData adalah isi email yang berkaitan dengan satu reservasi
+type ReservationData struct {
+	Name      string
+	TableName string
+	DateTime  string
+	PartySize int
+	// Alasan pembatalan, hanya dipakai di email reservasi dibatalkan
+	Reason string
+	// Link halaman kelola reservasi untuk tamu tanpa akun, hanya dipakai di email reservasi diterima dan kirim ulang link
+	ManageLink string `json:",omitempty"`
+	// Batas waktu link kelola reservasi, tamu bisa meminta link baru setelahnya
+	ManageLinkExpiresAt string `json:",omitempty"`
+
+	// Data untuk lampiran .ics. Lampiran tidak dibuat jika ReservationID kosong
+	ReservationID int
+	StartsAt      time.Time
+	EndsAt        time.Time
+	UpdatedAt     time.Time
+}
+
+// ReminderData adalah isi email pengingat reservasi beserta link untuk mengonfirmasi kedatangan atau membatalkan
+type ReminderData struct {
+	ReservationData
+	ConfirmLink string
+	// Kosong jika reservasi sudah melewati batas waktu pembatalan
+	CancelLink string
+}
+
+// WaitlistOfferData adalah isi email penawaran meja untuk customer di waitlist
+type WaitlistOfferData struct {
+	Name      string
+	TableName string
+	DateTime  string
+	PartySize int
+	ExpiresAt string
+}
+
+// LinkData adalah isi email yang berisi satu link dengan masa berlaku, seperti reset password dan verifikasi email
+type LinkData struct {
+	Name      string
+	Link      string
+	ExpiresAt string
+}
+
+// Notifier memberi tahu user tentang kejadian di aplikasi
+type Notifier interface {
+	ReservationCreated(to string, data ReservationData) error
+	ReservationConfirmed(to string, data ReservationData) error
+	ReservationUpdated(to string, data ReservationData) error
+	ReservationCancelled(to string, data ReservationData) error
+	ReservationReminder(to string, data ReminderData) error
+	ReservationManageLink(to string, data ReservationData) error
+	WaitlistOffer(to string, data WaitlistOfferData) error
+	PasswordReset(to string, data LinkData) error
+	EmailVerification(to string, data LinkData) error
+}
+
+// EmailOptions berisi alamat pengirim email, dipakai sebagai ORGANIZER di undangan kalender
+type EmailOptions struct {
+	SenderName  string
+	SenderEmail string
+}
+
+// EmailNotifier menyusun email dari template lalu mengirimnya lewat Mailer
+type EmailNotifier struct {
+	mailer  Mailer
+	options EmailOptions
+}
+
+func NewEmailNotifier(mailer Mailer, options EmailOptions) *EmailNotifier {
+	return &EmailNotifier{mailer: mailer, options: options}
+}
+
+func (n *EmailNotifier) ReservationCreated(to string, data ReservationData) error {
+	return n.send(to, "We Received Your WeReserve Reservation", templateReservationCreated, data, n.reservationInvite(to, data, false)...)
+}
+
+func (n *EmailNotifier) ReservationConfirmed(to string, data ReservationData) error {
+	return n.send(to, "Your WeReserve Reservation Is Confirmed", templateReservationConfirmed, data, n.reservationInvite(to, data, false)...)
+}
+
+func (n *EmailNotifier) ReservationUpdated(to string, data ReservationData) error {
+	return n.send(to, "Your WeReserve Reservation Has Been Updated", templateReservationUpdated, data, n.reservationInvite(to, data, false)...)
+}
+
+func (n *EmailNotifier) ReservationCancelled(to string, data ReservationData) error {
+	return n.send(to, "Your WeReserve Reservation Has Been Cancelled", templateReservationCancelled, data, n.reservationInvite(to, data, true)...)
+}
+
+func (n *EmailNotifier) ReservationReminder(to string, data ReminderData) error {
+	return n.send(to, "Reminder: Your Upcoming WeReserve Reservation", templateReservationReminder, data)
+}
+
+func (n *EmailNotifier) ReservationManageLink(to string, data ReservationData) error {
+	return n.send(to, "Manage Your WeReserve Reservation", templateReservationManageLink, data)
+}
+
+func (n *EmailNotifier) WaitlistOffer(to string, data WaitlistOfferData) error {
+	return n.send(to, "A Table Is Available For You", templateWaitlistOffer, data)
+}
+
+func (n *EmailNotifier) PasswordReset(to string, data LinkData) error {
+	return n.send(to, "Reset Your WeReserve Password", templatePasswordReset, data)
+}
+
+func (n *EmailNotifier) EmailVerification(to string, data LinkData) error {
+	return n.send(to, "Verify Your WeReserve Email", templateEmailVerification, data)
+}
+
+func (n *EmailNotifier) send(to, subject, templateName string, data interface{}, attachments ...Attachment) error {
+	body, err := render(templateName, data)
+	if err != nil {
+		return err
+	}
+	return n.mailer.Send(Message{To: to, Subject: subject, HTMLBody: body, Attachments: attachments})
+}
+
+// calendarEpoch adalah titik nol SEQUENCE event kalender. SEQUENCE dihitung dari waktu update terakhir
+// supaya selalu naik, dan dikurangi epoch ini supaya tetap muat di integer 32 bit
+var calendarEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ReservationCalendarEvent menyusun event kalender untuk reservasi dengan UID yang sama di email dan feed kalender
+func ReservationCalendarEvent(data ReservationData, cancelled bool) CalendarEvent {
+	summary := "WeReserve reservation"
+	if data.TableName != "" {
+		summary = fmt.Sprintf("WeReserve reservation (table %s)", data.TableName)
+	}
+
+	return CalendarEvent{
+		UID:         ReservationCalendarUID(data.ReservationID),
+		Sequence:    int64(data.UpdatedAt.Sub(calendarEpoch) / time.Second),
+		Start:       data.StartsAt,
+		End:         data.EndsAt,
+		Summary:     summary,
+		Description: fmt.Sprintf("Table for %d guests", data.PartySize),
+		Cancelled:   cancelled,
+	}
+}
+
+// reservationInvite membuat lampiran .ics untuk email reservasi. Event yang dibatalkan dikirim dengan METHOD:CANCEL
+// supaya aplikasi kalender menghapus event dengan UID yang sama. REQUEST dan CANCEL wajib punya ORGANIZER,
+// jadi tanpa alamat pengirim event dikirim sebagai METHOD:PUBLISH biasa
+func (n *EmailNotifier) reservationInvite(to string, data ReservationData, cancelled bool) []Attachment {
+	if data.ReservationID == 0 {
+		return nil
+	}
+
+	event := ReservationCalendarEvent(data, cancelled)
+	method := CalendarMethodPublish
+	if n.options.SenderEmail != "" {
+		method = CalendarMethodRequest
+		if cancelled {
+			method = CalendarMethodCancel
+		}
+		event.Organizer = CalendarAddress{Name: n.options.SenderName, Email: n.options.SenderEmail}
+		event.Attendee = CalendarAddress{Name: data.Name, Email: to}
+	}
+
+	return []Attachment{{
+		Filename:    "reservation.ics",
+		ContentType: "text/calendar; charset=UTF-8; method=" + method,
+		Data:        BuildCalendar(method, event),
+	}}
+}
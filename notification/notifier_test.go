@@ -0,0 +1,252 @@
+package notification
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEmailNotifierRendersEveryTemplate(t *testing.T) {
+	mailer := NewMemoryMailer()
+	notifier := NewEmailNotifier(mailer, EmailOptions{})
+	reservation := ReservationData{Name: "Budi", TableName: "A1 + A2", DateTime: "2026-01-02 19:00", PartySize: 6}
+	link := LinkData{Name: "Budi", Link: "http://localhost:3000/reset-password?token=abc", ExpiresAt: "2026-01-02 19:30"}
+
+	tests := []struct {
+		name     string
+		send     func() error
+		wantText string
+	}{
+		{name: "created", send: func() error { return notifier.ReservationCreated("budi@example.com", reservation) }, wantText: "pending confirmation"},
+		{name: "confirmed", send: func() error { return notifier.ReservationConfirmed("budi@example.com", reservation) }, wantText: "Reservation Confirmed"},
+		{name: "created for guest", send: func() error {
+			guest := reservation
+			guest.ManageLink = "http://localhost:3000/reservations/manage?token=abc"
+			return notifier.ReservationCreated("budi@example.com", guest)
+		}, wantText: "http://localhost:3000/reservations/manage?token=abc"},
+		{name: "manage link", send: func() error {
+			guest := reservation
+			guest.ManageLink = "http://localhost:3000/reservations/manage?token=def"
+			guest.ManageLinkExpiresAt = "2026-01-31 19:00"
+			return notifier.ReservationManageLink("budi@example.com", guest)
+		}, wantText: "http://localhost:3000/reservations/manage?token=def"},
+		{name: "updated", send: func() error { return notifier.ReservationUpdated("budi@example.com", reservation) }, wantText: "Reservation Updated"},
+		{name: "cancelled", send: func() error { return notifier.ReservationCancelled("budi@example.com", reservation) }, wantText: "Reservation Cancelled"},
+		{name: "reminder", send: func() error {
+			return notifier.ReservationReminder("budi@example.com", ReminderData{ReservationData: reservation, ConfirmLink: "http://localhost:8080/api/reservation/respond?token=abc"})
+		}, wantText: "http://localhost:8080/api/reservation/respond?token=abc"},
+		{name: "waitlist offer", send: func() error {
+			return notifier.WaitlistOffer("budi@example.com", WaitlistOfferData{TableName: "A1", DateTime: "2026-01-02 19:00", ExpiresAt: "2026-01-02 18:30"})
+		}, wantText: "2026-01-02 18:30"},
+		{name: "password reset", send: func() error { return notifier.PasswordReset("budi@example.com", link) }, wantText: link.Link},
+		{name: "email verification", send: func() error { return notifier.EmailVerification("budi@example.com", link) }, wantText: "Verify Email"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.send(); err != nil {
+				t.Fatalf("send error = %v", err)
+			}
+			msg, ok := mailer.Last("budi@example.com")
+			if !ok {
+				t.Fatalf("expected an email to be sent")
+			}
+			if !strings.Contains(msg.HTMLBody, tt.wantText) {
+				t.Errorf("expected body to contain %q", tt.wantText)
+			}
+		})
+	}
+}
+
+func TestEmailNotifierEscapesValues(t *testing.T) {
+	mailer := NewMemoryMailer()
+	notifier := NewEmailNotifier(mailer, EmailOptions{})
+
+	data := ReservationData{Name: `<script>alert("x")</script>`, TableName: "A1", DateTime: "2026-01-02 19:00"}
+	if err := notifier.ReservationCreated("budi@example.com", data); err != nil {
+		t.Fatalf("ReservationCreated() error = %v", err)
+	}
+
+	body := mailer.Messages()[0].HTMLBody
+	if strings.Contains(body, "<script>") {
+		t.Errorf("expected the name to be HTML-escaped")
+	}
+
+	// Link dengan skema berbahaya tidak boleh dirender apa adanya
+	if err := notifier.PasswordReset("budi@example.com", LinkData{Link: "javascript:alert(1)"}); err != nil {
+		t.Fatalf("PasswordReset() error = %v", err)
+	}
+	if body, _ := mailer.Last("budi@example.com"); strings.Contains(body.HTMLBody, "javascript:alert") {
+		t.Errorf("expected an unsafe link to be rejected")
+	}
+}
+
+func TestFileMailerWritesMessage(t *testing.T) {
+	dir := t.TempDir()
+	mailer := NewFileMailer(dir)
+
+	if err := mailer.Send(Message{To: "budi@example.com", Subject: "Halo", HTMLBody: "<p>isi</p>"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.html"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one email file, got %v (%v)", files, err)
+	}
+	content, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("failed to read email file: %v", err)
+	}
+	if !strings.Contains(string(content), "Subject: Halo") || !strings.Contains(string(content), "<p>isi</p>") {
+		t.Errorf("unexpected email file content: %s", content)
+	}
+}
+
+func TestRedactPayloadHidesReminderLinks(t *testing.T) {
+	payload, err := json.Marshal(ReminderData{
+		ReservationData: ReservationData{Name: "Budi", TableName: "A1"},
+		ConfirmLink:     "http://localhost:8080/api/reservation/respond?token=confirm",
+		CancelLink:      "http://localhost:8080/api/reservation/respond?token=cancel",
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+
+	redacted := string(RedactPayload(EventReservationReminder, payload))
+	if strings.Contains(redacted, "token=") || !strings.Contains(redacted, "Budi") {
+		t.Errorf("expected links to be redacted and other data kept, got %s", redacted)
+	}
+}
+
+func TestRedactPayloadHidesGuestManageLink(t *testing.T) {
+	payload, err := json.Marshal(ReservationData{Name: "Tamu", TableName: "A1", ManageLink: "http://localhost:3000/reservations/manage?token=abc"})
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+
+	redacted := string(RedactPayload(EventReservationCreated, payload))
+	if strings.Contains(redacted, "token=") || !strings.Contains(redacted, "Tamu") {
+		t.Errorf("expected manage link to be redacted and other data kept, got %s", redacted)
+	}
+}
+
+func TestBuildCalendarFoldsAndEscapes(t *testing.T) {
+	start := time.Date(2026, 1, 2, 19, 0, 0, 0, time.UTC)
+	event := CalendarEvent{
+		UID:         ReservationCalendarUID(7),
+		Start:       start,
+		End:         start.Add(2 * time.Hour),
+		Summary:     "Dinner; table A1, A2",
+		Description: strings.Repeat("Meja untuk rombongan besar ", 5),
+	}
+
+	ics := string(BuildCalendar(CalendarMethodRequest, event))
+
+	for _, want := range []string{"METHOD:REQUEST\r\n", "UID:reservation-7@wereserve\r\n", "DTSTART:20260102T190000Z\r\n", `SUMMARY:Dinner\; table A1\, A2`} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("expected calendar to contain %q", want)
+		}
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line longer than 75 octets: %q", line)
+		}
+		if strings.Contains(line, "\n") {
+			t.Errorf("expected CRLF line endings only, got %q", line)
+		}
+	}
+}
+
+func TestReservationEmailsCarryCalendarInvite(t *testing.T) {
+	mailer := NewMemoryMailer()
+	notifier := NewEmailNotifier(mailer, EmailOptions{SenderName: "WeReserve", SenderEmail: "reservations@wereserve.id"})
+	start := time.Date(2026, 1, 2, 19, 0, 0, 0, time.UTC)
+	data := ReservationData{Name: "Budi", TableName: "A1", PartySize: 2, ReservationID: 7, StartsAt: start, EndsAt: start.Add(2 * time.Hour), UpdatedAt: start}
+
+	if err := notifier.ReservationCreated("budi@example.com", data); err != nil {
+		t.Fatalf("ReservationCreated() error = %v", err)
+	}
+	data.UpdatedAt = start.Add(time.Minute)
+	if err := notifier.ReservationCancelled("budi@example.com", data); err != nil {
+		t.Fatalf("ReservationCancelled() error = %v", err)
+	}
+
+	messages := mailer.Messages()
+	if len(messages[0].Attachments) != 1 || len(messages[1].Attachments) != 1 {
+		t.Fatalf("expected one .ics attachment per email")
+	}
+	created, cancelled := string(messages[0].Attachments[0].Data), string(messages[1].Attachments[0].Data)
+
+	// UID yang sama membuat aplikasi kalender mengganti event lama
+	for _, ics := range []string{created, cancelled} {
+		if !strings.Contains(ics, "UID:reservation-7@wereserve") {
+			t.Errorf("expected a stable UID, got %s", ics)
+		}
+	}
+	if !strings.Contains(cancelled, "METHOD:CANCEL") || !strings.Contains(cancelled, "STATUS:CANCELLED") {
+		t.Errorf("expected the cancellation to cancel the event, got %s", cancelled)
+	}
+	// REQUEST dan CANCEL wajib punya ORGANIZER dan ATTENDEE (RFC 5546), baris panjang dibuka lipatannya sebelum dicek
+	for _, ics := range []string{created, cancelled} {
+		unfolded := strings.ReplaceAll(ics, "\r\n ", "")
+		for _, want := range []string{
+			"ORGANIZER;CN=\"WeReserve\":mailto:reservations@wereserve.id\r\n",
+			"ATTENDEE;CN=\"Budi\";ROLE=REQ-PARTICIPANT;PARTSTAT=ACCEPTED;RSVP=FALSE:mailto:budi@example.com\r\n",
+		} {
+			if !strings.Contains(unfolded, want) {
+				t.Errorf("expected calendar to contain %q, got %s", want, ics)
+			}
+		}
+	}
+	if !strings.Contains(created, "METHOD:REQUEST") {
+		t.Errorf("expected the new reservation to be sent as METHOD:REQUEST, got %s", created)
+	}
+	if sequenceOf(t, cancelled) <= sequenceOf(t, created) {
+		t.Errorf("expected SEQUENCE to increase after the reservation changed")
+	}
+
+	// Email tanpa id reservasi (misalnya dari outbox lama) tetap terkirim tanpa lampiran
+	if err := notifier.ReservationUpdated("budi@example.com", ReservationData{TableName: "A1"}); err != nil {
+		t.Fatalf("ReservationUpdated() error = %v", err)
+	}
+	if last, _ := mailer.Last("budi@example.com"); len(last.Attachments) != 0 {
+		t.Errorf("expected no attachment without a reservation id")
+	}
+}
+
+func TestCalendarInviteWithoutSenderIsPublished(t *testing.T) {
+	mailer := NewMemoryMailer()
+	notifier := NewEmailNotifier(mailer, EmailOptions{})
+	start := time.Date(2026, 1, 2, 19, 0, 0, 0, time.UTC)
+	data := ReservationData{Name: "Budi", ReservationID: 7, StartsAt: start, EndsAt: start.Add(2 * time.Hour), UpdatedAt: start}
+
+	if err := notifier.ReservationCancelled("budi@example.com", data); err != nil {
+		t.Fatalf("ReservationCancelled() error = %v", err)
+	}
+
+	// Tanpa alamat pengirim tidak ada ORGANIZER, jadi event tidak boleh dikirim sebagai REQUEST atau CANCEL
+	last, _ := mailer.Last("budi@example.com")
+	ics := string(last.Attachments[0].Data)
+	if !strings.Contains(ics, "METHOD:PUBLISH") || strings.Contains(ics, "ORGANIZER") || !strings.Contains(ics, "STATUS:CANCELLED") {
+		t.Errorf("expected a published cancelled event without organizer, got %s", ics)
+	}
+	if got := last.Attachments[0].ContentType; got != "text/calendar; charset=UTF-8; method=PUBLISH" {
+		t.Errorf("content type = %q", got)
+	}
+}
+
+func sequenceOf(t *testing.T, ics string) int {
+	t.Helper()
+
+	match := regexp.MustCompile(`SEQUENCE:(\d+)`).FindStringSubmatch(ics)
+	if match == nil {
+		t.Fatalf("SEQUENCE not found in %s", ics)
+	}
+	sequence, _ := strconv.Atoi(match[1])
+	return sequence
+}
@@ -0,0 +1,66 @@
+package notification
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// Nama template sama dengan nama file di folder templates tanpa .html
+const (
+	templateReservationCreated    = "reservation_created"
+	templateReservationConfirmed  = "reservation_confirmed"
+	templateReservationUpdated    = "reservation_updated"
+	templateReservationCancelled  = "reservation_cancelled"
+	templateReservationReminder   = "reservation_reminder"
+	templateReservationManageLink = "reservation_manage_link"
+	templateWaitlistOffer         = "waitlist_offer"
+	templatePasswordReset         = "password_reset"
+	templateEmailVerification     = "email_verification"
+)
+
+var templateFuncs = template.FuncMap{
+	"button": func(link, label string) map[string]string {
+		return map[string]string{"Link": link, "Label": label}
+	},
+}
+
+// Setiap template di-parse bersama layout.html dalam set sendiri, karena semuanya mendefinisikan blok "title" dan "content"
+var templates = mustParseTemplates(
+	templateReservationCreated,
+	templateReservationConfirmed,
+	templateReservationUpdated,
+	templateReservationCancelled,
+	templateReservationReminder,
+	templateReservationManageLink,
+	templateWaitlistOffer,
+	templatePasswordReset,
+	templateEmailVerification,
+)
+
+func mustParseTemplates(names ...string) map[string]*template.Template {
+	parsed := make(map[string]*template.Template, len(names))
+	for _, name := range names {
+		parsed[name] = template.Must(template.New(name).Funcs(templateFuncs).
+			ParseFS(templateFS, "templates/layout.html", "templates/"+name+".html"))
+	}
+	return parsed
+}
+
+// render mengisi template dengan data. Semua nilai di-escape oleh html/template
+func render(name string, data interface{}) (string, error) {
+	tmpl, ok := templates[name]
+	if !ok {
+		return "", fmt.Errorf("email template %q not found", name)
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&body, "layout", data); err != nil {
+		return "", fmt.Errorf("failed to render email template %q: %w", name, err)
+	}
+	return body.String(), nil
+}
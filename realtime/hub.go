@@ -0,0 +1,128 @@
+// Package realtime menyebarkan event perubahan meja dan reservasi ke client yang sedang terhubung,
+// misalnya layar host stand yang mendengarkan GET /api/stream
+package realtime
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// DefaultBufferSize adalah jumlah event yang boleh menumpuk di satu subscriber sebelum dianggap terlalu lambat
+const DefaultBufferSize = 64
+
+// Event adalah satu perubahan yang disebarkan ke semua subscriber
+type Event struct {
+	// Nomor urut event di hub ini, dipakai sebagai id di Server-Sent Events
+	ID        uint64      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+	// Pemilik data event. Customer hanya menerima event miliknya sendiri dan event tanpa pemilik (0)
+	UserID int `json:"-"`
+	// StaffOnly untuk event tanpa pemilik yang tetap tidak boleh dilihat customer, misalnya reservasi tamu tanpa akun
+	StaffOnly bool `json:"-"`
+}
+
+// VisibleTo menentukan apakah event boleh dikirim ke user. seeAll untuk user yang boleh melihat reservasi semua user
+func (e Event) VisibleTo(userID int, seeAll bool) bool {
+	if seeAll {
+		return true
+	}
+	if e.StaffOnly {
+		return false
+	}
+	return e.UserID == 0 || e.UserID == userID
+}
+
+// Subscriber menerima event dari hub lewat channel Events. Channel ditutup saat subscriber berhenti berlangganan
+// atau diputus oleh hub karena terlalu lambat membaca
+type Subscriber struct {
+	events chan Event
+}
+
+func (s *Subscriber) Events() <-chan Event {
+	return s.events
+}
+
+// Hub adalah pub/sub di dalam proses. Publish tidak pernah menunggu subscriber,
+// subscriber yang buffer-nya penuh langsung diputus supaya tidak menahan publisher atau subscriber lain
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[*Subscriber]struct{}
+	bufferSize  int
+	lastID      uint64
+}
+
+func NewHub(bufferSize int) *Hub {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	return &Hub{
+		subscribers: make(map[*Subscriber]struct{}),
+		bufferSize:  bufferSize,
+	}
+}
+
+// Subscribe mendaftarkan subscriber baru. Pemanggil wajib memanggil Unsubscribe saat koneksi selesai
+func (h *Hub) Subscribe() *Subscriber {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subscriber := &Subscriber{events: make(chan Event, h.bufferSize)}
+	h.subscribers[subscriber] = struct{}{}
+	return subscriber
+}
+
+// Unsubscribe melepas subscriber dan menutup channel-nya. Aman dipanggil lebih dari sekali
+func (h *Hub) Unsubscribe(subscriber *Subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(subscriber)
+}
+
+// remove harus dipanggil saat mu sedang dikunci
+func (h *Hub) remove(subscriber *Subscriber) {
+	if _, ok := h.subscribers[subscriber]; !ok {
+		return
+	}
+	delete(h.subscribers, subscriber)
+	close(subscriber.events)
+}
+
+// Publish mengirim event ke semua subscriber. ID dan CreatedAt diisi oleh hub
+func (h *Hub) Publish(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastID++
+	event.ID = h.lastID
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+
+	for subscriber := range h.subscribers {
+		select {
+		case subscriber.events <- event:
+		default:
+			log.Printf("Dropping slow realtime subscriber after %d buffered events", h.bufferSize)
+			h.remove(subscriber)
+		}
+	}
+}
+
+// Close memutus semua subscriber, dipakai saat server berhenti
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for subscriber := range h.subscribers {
+		h.remove(subscriber)
+	}
+}
+
+// SubscriberCount mengembalikan jumlah subscriber yang sedang terhubung
+func (h *Hub) SubscriberCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers)
+}
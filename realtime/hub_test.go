@@ -0,0 +1,111 @@
+package realtime
+
+import (
+	"testing"
+	"time"
+)
+
+func receive(t *testing.T, subscriber *Subscriber) (Event, bool) {
+	t.Helper()
+
+	select {
+	case event, open := <-subscriber.Events():
+		return event, open
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for an event")
+		return Event{}, false
+	}
+}
+
+func TestHubBroadcastsToAllSubscribers(t *testing.T) {
+	hub := NewHub(4)
+	first := hub.Subscribe()
+	second := hub.Subscribe()
+
+	hub.Publish(Event{Type: "table.status_changed", Data: map[string]int{"table_id": 1}})
+	hub.Publish(Event{Type: "reservation.created"})
+
+	for _, subscriber := range []*Subscriber{first, second} {
+		event, open := receive(t, subscriber)
+		if !open || event.ID != 1 || event.Type != "table.status_changed" || event.CreatedAt.IsZero() {
+			t.Errorf("first event = %+v, open = %v", event, open)
+		}
+		event, _ = receive(t, subscriber)
+		if event.ID != 2 || event.Type != "reservation.created" {
+			t.Errorf("second event = %+v", event)
+		}
+	}
+}
+
+func TestHubDropsSlowSubscriber(t *testing.T) {
+	hub := NewHub(2)
+	slow := hub.Subscribe()
+	fast := hub.Subscribe()
+
+	for i := 0; i < 3; i++ {
+		hub.Publish(Event{Type: "reservation.updated"})
+		if _, open := receive(t, fast); !open {
+			t.Fatalf("fast subscriber was disconnected after %d events", i+1)
+		}
+	}
+
+	// Subscriber lambat menerima event yang sempat masuk buffer, lalu channel-nya ditutup
+	for i := 0; i < 2; i++ {
+		if _, open := receive(t, slow); !open {
+			t.Fatalf("expected buffered event %d before the channel closes", i+1)
+		}
+	}
+	if _, open := receive(t, slow); open {
+		t.Fatal("expected the slow subscriber to be disconnected")
+	}
+	if got := hub.SubscriberCount(); got != 1 {
+		t.Errorf("SubscriberCount() = %d, want 1", got)
+	}
+
+	// Unsubscribe setelah diputus oleh hub tidak boleh panic
+	hub.Unsubscribe(slow)
+}
+
+func TestHubUnsubscribeAndClose(t *testing.T) {
+	hub := NewHub(1)
+	subscriber := hub.Subscribe()
+	hub.Unsubscribe(subscriber)
+	hub.Unsubscribe(subscriber)
+
+	if _, open := receive(t, subscriber); open {
+		t.Error("expected the channel to be closed after Unsubscribe")
+	}
+
+	other := hub.Subscribe()
+	hub.Close()
+	if _, open := receive(t, other); open {
+		t.Error("expected the channel to be closed after Close")
+	}
+
+	// Publish tanpa subscriber tetap aman
+	hub.Publish(Event{Type: "reservation.created"})
+}
+
+func TestEventVisibleTo(t *testing.T) {
+	tests := []struct {
+		name   string
+		event  Event
+		userID int
+		seeAll bool
+		want   bool
+	}{
+		{"staff sees other users", Event{UserID: 2}, 1, true, true},
+		{"customer sees own reservation", Event{UserID: 1}, 1, false, true},
+		{"customer does not see other users", Event{UserID: 2}, 1, false, false},
+		{"customer sees events without owner", Event{}, 1, false, true},
+		{"staff sees guest reservation", Event{StaffOnly: true}, 1, true, true},
+		{"customer does not see guest reservation", Event{StaffOnly: true}, 1, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.event.VisibleTo(tt.userID, tt.seeAll); got != tt.want {
+				t.Errorf("VisibleTo(%d, %v) = %v, want %v", tt.userID, tt.seeAll, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package repository
+
+import "wereserve/apperror"
+
+// Error domain yang dikembalikan repository gorm dan memory. Pesan boleh diganti dengan Withf,
+// errors.Is tetap cocok karena dibandingkan berdasarkan kode
+var (
+	// Rentang waktu reservasi bertabrakan dengan reservasi lain di meja yang sama
+	ErrReservationOverlap  = apperror.Conflict("reservation_overlap", "table is already reserved for the requested time")
+	ErrReservationNotFound = apperror.NotFound("reservation_not_found", "reservation tidak di temukan")
+
+	ErrTableNotFound  = apperror.NotFound("table_not_found", "table tidak di temukan")
+	ErrTableNameTaken = apperror.Conflict("table_name_taken", "table name sudah terdaftar")
+
+	ErrTableCombinationNotFound  = apperror.NotFound("table_combination_not_found", "table combination tidak di temukan")
+	ErrTableCombinationNameTaken = apperror.Conflict("table_combination_name_taken", "nama kombinasi meja sudah digunakan")
+
+	ErrUserNotFound    = apperror.NotFound("user_not_found", "user tidak ditemukan")
+	ErrEmailTaken      = apperror.Conflict("email_taken", "email sudah terdaftar")
+	ErrInvalidUserRole = apperror.Validation("invalid_user_role", "invalid user role")
+
+	ErrRoleNotFound  = apperror.NotFound("role_not_found", "role not found")
+	ErrRoleNameTaken = apperror.Conflict("role_name_taken", "role name is already used")
+
+	ErrWaitlistEntryNotFound = apperror.NotFound("waitlist_entry_not_found", "waitlist entry not found")
+	ErrClosureNotFound       = apperror.NotFound("closure_not_found", "closure not found")
+	ErrOutboxMessageNotFound = apperror.NotFound("outbox_message_not_found", "outbox message not found")
+
+	ErrWebhookSubscriptionNotFound = apperror.NotFound("webhook_subscription_not_found", "webhook subscription not found")
+	ErrWebhookDeliveryNotFound     = apperror.NotFound("webhook_delivery_not_found", "webhook delivery not found")
+)
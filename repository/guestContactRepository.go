@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"wereserve/models"
+
+	"gorm.io/gorm"
+)
+
+type guestContactRepository struct {
+	DB *gorm.DB
+}
+
+func NewGuestContactRepository(db *gorm.DB) GuestContactRepository {
+	return &guestContactRepository{DB: db}
+}
+
+func (r *guestContactRepository) CreateGuestContact(guest *models.GuestContact) error {
+	if err := r.DB.Create(guest).Error; err != nil {
+		return fmt.Errorf("failed to save guest contact: %w", err)
+	}
+	return nil
+}
+
+// LockContact mengambil advisory lock untuk email lalu nomor telepon tamu sampai transaksi selesai,
+// supaya reservasi bersamaan dari kontak yang sama diproses satu per satu. Urutannya selalu sama sehingga tidak bisa deadlock
+func (r *guestContactRepository) LockContact(email, phone string) error {
+	for _, key := range []string{"guest_email:" + strings.ToLower(email), "guest_phone:" + phone} {
+		if err := r.DB.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", key).Error; err != nil {
+			return fmt.Errorf("failed to lock guest contact: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"time"
+	"wereserve/models"
+)
+
+// Interface repository yang dipakai oleh services. Implementasi Postgres ada di file *Repository.go,
+// implementasi in-memory untuk test ada di package repository/memory
+
+type UserRepository interface {
+	IsValidUserRole(role string) (bool, error)
+	IsEmailExists(email string) (bool, error)
+	IsUserExists(id int) (bool, error)
+	GetAllUser() ([]models.User, error)
+	ListUsers(filter UserFilter) ([]models.User, int64, error)
+	GetUserByid(id int) (*models.User, error)
+	GetUserByEmail(email string) (*models.User, error)
+	CreateUser(user *models.User) error
+	UpdateUser(id int, user *models.User) error
+	DeleteUser(id int) error
+	UpdateUserRole(id int, role string) error
+	GetAllUserByRole(role string) ([]models.User, error)
+	GetTokenVersion(id int) (int, error)
+	IncrementTokenVersion(id int) error
+	IsEmailVerified(id int) (bool, error)
+	MarkEmailVerified(id int, email string) (bool, error)
+	DeleteUnverifiedUsers(createdBefore time.Time) (int64, error)
+	GetCalendarTokenHash(id int) (string, error)
+	SetCalendarTokenHash(id int, hash string) error
+}
+
+type RoleRepository interface {
+	GetRoles() ([]models.Role, error)
+	GetRoleByName(name string) (*models.Role, error)
+	GetPermissions(role string) ([]string, error)
+	CreateRole(role *models.Role) error
+	UpdateRole(name string, role *models.Role) error
+	DeleteRole(name string) error
+	CountUsersWithRole(name string) (int64, error)
+}
+
+type RefreshTokenRepository interface {
+	CreateRefreshToken(token *models.RefreshToken) error
+	GetRefreshTokenByHash(hash string) (*models.RefreshToken, error)
+	RevokeRefreshToken(id int, replacedByID *int) (bool, error)
+	RevokeFamily(familyID string) error
+	RevokeAllForUser(userID int) error
+}
+
+type PasswordResetTokenRepository interface {
+	CreatePasswordResetToken(token *models.PasswordResetToken) error
+	GetPasswordResetTokenByHash(hash string) (*models.PasswordResetToken, error)
+	MarkPasswordResetTokenUsed(id int) (bool, error)
+	InvalidatePasswordResetTokens(userID int) error
+}
+
+type ReservationManageTokenRepository interface {
+	// SaveReservationManageToken menyimpan token baru dan menggantikan token lama milik reservasi yang sama
+	SaveReservationManageToken(token *models.ReservationManageToken) error
+	GetReservationManageTokenByHash(hash string) (*models.ReservationManageToken, error)
+}
+
+type StreamTicketRepository interface {
+	CreateStreamTicket(ticket *models.StreamTicket) error
+	// ConsumeStreamTicket menandai tiket yang belum dipakai dan belum kedaluwarsa sebagai sudah dipakai lalu mengembalikannya.
+	// nil dikembalikan jika tiket tidak ada, sudah dipakai atau sudah kedaluwarsa
+	ConsumeStreamTicket(hash string, now time.Time) (*models.StreamTicket, error)
+	DeleteExpiredStreamTickets(before time.Time) (int64, error)
+}
+
+type TableRepository interface {
+	IsTableExists(tableName string) (bool, error)
+	GetAllTables() ([]models.Table, error)
+	ListTables(filter TableFilter) ([]models.Table, int64, error)
+	GetTableByID(id int) (*models.Table, error)
+	GetTableByStatus(status string) ([]models.Table, error)
+	GetTablesByMinCapacity(capacity int) ([]models.Table, error)
+	LockTables(ids []int) error
+	CreateTable(table *models.Table) error
+	DeleteTable(id int) error
+	UpdateTable(id int, table *models.Table) error
+}
+
+type ReservationRepository interface {
+	ListReservations(filter ReservationFilter) ([]models.Reservation, int64, error)
+	GetReservationDetail(id int) (*models.Reservation, error)
+	GetReservationForUpdate(id int) (*models.Reservation, error)
+	GetReservationByUserLogin(userID int) ([]models.Reservation, error)
+	IsReservationOverlap(tableIDs []int, start, end time.Time, excludeID int) (bool, error)
+	GetReservationsInRange(from, to time.Time) ([]models.Reservation, error)
+	GetCurrentReservations(at time.Time) ([]models.Reservation, error)
+	// CountUpcomingGuestReservations menghitung reservasi tamu aktif mulai dari from dengan email atau nomor telepon yang sama
+	CountUpcomingGuestReservations(email, phone string, from time.Time) (int64, error)
+	// GetUpcomingGuestReservationIDs mengembalikan id reservasi tamu aktif mulai dari from dengan email kontak tersebut
+	GetUpcomingGuestReservationIDs(email string, from time.Time) ([]int, error)
+	CreateReservation(reservation *models.Reservation) error
+	UpdateReservation(id int, reservation *models.Reservation) error
+	UpdateReservationStatus(id int, status string) error
+	CancelReservation(id int, reason string) error
+}
+
+type GuestContactRepository interface {
+	CreateGuestContact(guest *models.GuestContact) error
+	// LockContact mengunci email dan nomor telepon tamu sampai transaksi selesai
+	LockContact(email, phone string) error
+}
+
+type TableCombinationRepository interface {
+	IsCombinationExists(name string) (bool, error)
+	GetAllCombinations() ([]models.TableCombination, error)
+	GetCombinationByID(id int) (*models.TableCombination, error)
+	GetCombinationsByMinCapacity(capacity int) ([]models.TableCombination, error)
+	CreateCombination(combination *models.TableCombination) error
+	DeleteCombination(id int) error
+}
+
+type ScheduleRepository interface {
+	GetOpeningHours() ([]models.OpeningHour, error)
+	GetOpeningHourByDay(day time.Weekday) (*models.OpeningHour, error)
+	UpsertOpeningHours(hours []models.OpeningHour) error
+	GetSetting() (*models.ScheduleSetting, error)
+	UpdateSlotMinutes(minutes int) error
+	GetUpcomingClosures(from time.Time) ([]models.ScheduleClosure, error)
+	GetClosureOn(date time.Time) (*models.ScheduleClosure, error)
+	CreateClosure(closure *models.ScheduleClosure) error
+	DeleteClosure(id int) error
+}
+
+type WaitlistRepository interface {
+	CreateEntry(entry *models.WaitlistEntry) error
+	GetAllEntries() ([]models.WaitlistEntry, error)
+	GetEntriesByUser(userID int) ([]models.WaitlistEntry, error)
+	GetEntryByID(id int) (*models.WaitlistEntry, error)
+	GetCandidates(start time.Time, maxPartySize int) ([]models.WaitlistEntry, error)
+	GetExpiredOffers(now time.Time) ([]models.WaitlistEntry, error)
+	MarkOffered(id int, tableID, combinationID *int, offeredAt, expiresAt time.Time) (bool, error)
+	// MarkAccepted menandai penawaran diterima. false dikembalikan jika entry sudah tidak berstatus offered
+	MarkAccepted(id, reservationID int) (bool, error)
+	UpdateStatus(id int, from, to string) (bool, error)
+}
+
+type OutboxRepository interface {
+	CreateMessage(message *models.OutboxMessage) error
+	ClaimDueMessages(now time.Time, limit int, lease time.Duration) ([]models.OutboxMessage, error)
+	// payload menggantikan isi pesan, dipakai untuk menghapus link bertoken setelah pesan tidak dikirim lagi
+	MarkSent(id int, payload string) error
+	MarkFailed(id int, attempts int, nextAttemptAt time.Time, lastError string, dead bool, payload string) error
+	GetMessages(status string) ([]models.OutboxMessage, error)
+	GetMessageByID(id int) (*models.OutboxMessage, error)
+	RetryMessage(id int) (bool, error)
+}
+
+type ReminderRepository interface {
+	GetReservationsDueForReminder(offsetMinutes int, now time.Time) ([]models.Reservation, error)
+	CreateReminder(reservationID, offsetMinutes int) (bool, error)
+}
+
+type WebhookRepository interface {
+	CreateSubscription(subscription *models.WebhookSubscription) error
+	GetSubscriptions() ([]models.WebhookSubscription, error)
+	GetSubscriptionByID(id int) (*models.WebhookSubscription, error)
+	UpdateSubscription(id int, subscription *models.WebhookSubscription) error
+	DeleteSubscription(id int) error
+	EnqueueDeliveries(eventID, eventType, payload string) (int64, error)
+	ClaimDueDeliveries(now time.Time, limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	MarkDelivered(id int, responseStatus int) error
+	MarkDeliveryFailed(id int, attempts int, nextAttemptAt time.Time, responseStatus *int, lastError string, dead bool) error
+	GetDeliveries(subscriptionID int) ([]models.WebhookDelivery, error)
+}
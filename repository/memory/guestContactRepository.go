@@ -0,0 +1,27 @@
+package memory
+
+import (
+	"time"
+	"wereserve/models"
+)
+
+type guestContactRepository struct {
+	store *Store
+}
+
+func (r *guestContactRepository) CreateGuestContact(guest *models.GuestContact) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	guest.ID = r.store.newID()
+	guest.CreatedAt = now
+	guest.UpdatedAt = now
+	r.store.data.guestContacts[guest.ID] = *guest
+	return nil
+}
+
+// LockContact tidak perlu mengunci apa pun karena transaksi di store ini sudah berjalan satu per satu
+func (r *guestContactRepository) LockContact(email, phone string) error {
+	return nil
+}
@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"sort"
+	"time"
+	"wereserve/models"
+	"wereserve/repository"
+)
+
+type outboxRepository struct {
+	store *Store
+}
+
+func (r *outboxRepository) CreateMessage(message *models.OutboxMessage) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	message.ID = r.store.newID()
+	if message.Status == "" {
+		message.Status = models.OutboxStatusPending
+	}
+	if message.NextAttemptAt.IsZero() {
+		message.NextAttemptAt = now
+	}
+	message.CreatedAt = now
+	message.UpdatedAt = now
+	r.store.data.outbox[message.ID] = *message
+	return nil
+}
+
+func (r *outboxRepository) ClaimDueMessages(now time.Time, limit int, lease time.Duration) ([]models.OutboxMessage, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	messages := []models.OutboxMessage{}
+	for _, message := range r.store.data.outbox {
+		if message.Status == models.OutboxStatusPending && !message.NextAttemptAt.After(now) {
+			messages = append(messages, message)
+		}
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		if messages[i].NextAttemptAt.Equal(messages[j].NextAttemptAt) {
+			return messages[i].ID < messages[j].ID
+		}
+		return messages[i].NextAttemptAt.Before(messages[j].NextAttemptAt)
+	})
+	if len(messages) > limit {
+		messages = messages[:limit]
+	}
+
+	for _, message := range messages {
+		message.NextAttemptAt = now.Add(lease)
+		r.store.data.outbox[message.ID] = message
+	}
+	return messages, nil
+}
+
+func (r *outboxRepository) MarkSent(id int, payload string) error {
+	return r.update(id, func(message *models.OutboxMessage) {
+		now := time.Now()
+		message.Status = models.OutboxStatusSent
+		message.Payload = payload
+		message.SentAt = &now
+		message.LastError = nil
+	})
+}
+
+func (r *outboxRepository) MarkFailed(id int, attempts int, nextAttemptAt time.Time, lastError string, dead bool, payload string) error {
+	return r.update(id, func(message *models.OutboxMessage) {
+		message.Status = models.OutboxStatusPending
+		if dead {
+			message.Status = models.OutboxStatusDead
+		}
+		message.Attempts = attempts
+		message.NextAttemptAt = nextAttemptAt
+		message.LastError = &lastError
+		message.Payload = payload
+	})
+}
+
+func (r *outboxRepository) update(id int, change func(message *models.OutboxMessage)) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	message, ok := r.store.data.outbox[id]
+	if !ok {
+		return repository.ErrOutboxMessageNotFound.Withf("outbox message with ID %d not found", id)
+	}
+	change(&message)
+	message.UpdatedAt = time.Now()
+	r.store.data.outbox[id] = message
+	return nil
+}
+
+func (r *outboxRepository) GetMessages(status string) ([]models.OutboxMessage, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	messages := []models.OutboxMessage{}
+	for _, message := range r.store.data.outbox {
+		if status == "" || message.Status == status {
+			messages = append(messages, message)
+		}
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].ID > messages[j].ID })
+	return messages, nil
+}
+
+func (r *outboxRepository) GetMessageByID(id int) (*models.OutboxMessage, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	message, ok := r.store.data.outbox[id]
+	if !ok {
+		return nil, repository.ErrOutboxMessageNotFound.Withf("outbox message with ID %d not found", id)
+	}
+	return &message, nil
+}
+
+func (r *outboxRepository) RetryMessage(id int) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	message, ok := r.store.data.outbox[id]
+	if !ok || message.Status != models.OutboxStatusDead {
+		return false, nil
+	}
+	now := time.Now()
+	message.Status = models.OutboxStatusPending
+	message.Attempts = 0
+	message.NextAttemptAt = now
+	message.UpdatedAt = now
+	r.store.data.outbox[id] = message
+	return true, nil
+}
@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"time"
+	"wereserve/models"
+)
+
+type passwordResetTokenRepository struct {
+	store *Store
+}
+
+func (r *passwordResetTokenRepository) CreatePasswordResetToken(token *models.PasswordResetToken) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	token.ID = r.store.newID()
+	token.CreatedAt = time.Now()
+	r.store.data.passwordResetTokens[token.ID] = *token
+	return nil
+}
+
+func (r *passwordResetTokenRepository) GetPasswordResetTokenByHash(hash string) (*models.PasswordResetToken, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, token := range r.store.data.passwordResetTokens {
+		if token.TokenHash == hash {
+			return &token, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *passwordResetTokenRepository) MarkPasswordResetTokenUsed(id int) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	token, ok := r.store.data.passwordResetTokens[id]
+	if !ok || token.UsedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	token.UsedAt = &now
+	r.store.data.passwordResetTokens[id] = token
+	return true, nil
+}
+
+func (r *passwordResetTokenRepository) InvalidatePasswordResetTokens(userID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	for id, token := range r.store.data.passwordResetTokens {
+		if token.UserID == userID && token.UsedAt == nil {
+			token.UsedAt = &now
+			r.store.data.passwordResetTokens[id] = token
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"time"
+	"wereserve/models"
+)
+
+type refreshTokenRepository struct {
+	store *Store
+}
+
+func (r *refreshTokenRepository) CreateRefreshToken(token *models.RefreshToken) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	token.ID = r.store.newID()
+	token.CreatedAt = time.Now()
+	r.store.data.refreshTokens[token.ID] = *token
+	return nil
+}
+
+func (r *refreshTokenRepository) GetRefreshTokenByHash(hash string) (*models.RefreshToken, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, token := range r.store.data.refreshTokens {
+		if token.TokenHash == hash {
+			return &token, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *refreshTokenRepository) RevokeRefreshToken(id int, replacedByID *int) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	token, ok := r.store.data.refreshTokens[id]
+	if !ok || token.RevokedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	token.RevokedAt = &now
+	token.ReplacedByID = replacedByID
+	r.store.data.refreshTokens[id] = token
+	return true, nil
+}
+
+func (r *refreshTokenRepository) RevokeFamily(familyID string) error {
+	return r.revokeWhere(func(token models.RefreshToken) bool { return token.FamilyID == familyID })
+}
+
+func (r *refreshTokenRepository) RevokeAllForUser(userID int) error {
+	return r.revokeWhere(func(token models.RefreshToken) bool { return token.UserID == userID })
+}
+
+func (r *refreshTokenRepository) revokeWhere(match func(models.RefreshToken) bool) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	for id, token := range r.store.data.refreshTokens {
+		if token.RevokedAt == nil && match(token) {
+			token.RevokedAt = &now
+			r.store.data.refreshTokens[id] = token
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"sort"
+	"time"
+	"wereserve/models"
+)
+
+type reminderRepository struct {
+	store *Store
+}
+
+func (r *reminderRepository) GetReservationsDueForReminder(offsetMinutes int, now time.Time) ([]models.Reservation, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	until := now.Add(time.Duration(offsetMinutes) * time.Minute)
+	reservations := (&reservationRepository{store: r.store}).sortedReservations(func(reservation models.Reservation) bool {
+		if reservation.Status != models.ReservationStatusPending && reservation.Status != models.ReservationStatusConfirmed {
+			return false
+		}
+		if !reservation.ReservationDateTime.After(now) || reservation.ReservationDateTime.After(until) {
+			return false
+		}
+		for _, reminder := range r.store.data.reminders {
+			if reminder.ReservationID == reservation.ID && reminder.OffsetMinutes == offsetMinutes {
+				return false
+			}
+		}
+		return true
+	})
+	sort.SliceStable(reservations, func(i, j int) bool {
+		return reservations[i].ReservationDateTime.Before(reservations[j].ReservationDateTime)
+	})
+	return reservations, nil
+}
+
+func (r *reminderRepository) CreateReminder(reservationID, offsetMinutes int) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, reminder := range r.store.data.reminders {
+		if reminder.ReservationID == reservationID && reminder.OffsetMinutes == offsetMinutes {
+			return false, nil
+		}
+	}
+
+	id := r.store.newID()
+	r.store.data.reminders[id] = models.ReservationReminder{
+		ID:            id,
+		ReservationID: reservationID,
+		OffsetMinutes: offsetMinutes,
+		SentAt:        time.Now(),
+	}
+	return true, nil
+}
+
+// deleteReminders menghapus pengingat milik reservasi, sama seperti ON DELETE CASCADE di Postgres.
+// Harus dipanggil saat mu sedang dikunci
+func (s *Store) deleteReminders(reservationID int) {
+	for id, reminder := range s.data.reminders {
+		if reminder.ReservationID == reservationID {
+			delete(s.data.reminders, id)
+		}
+	}
+}
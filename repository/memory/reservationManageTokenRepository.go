@@ -0,0 +1,38 @@
+package memory
+
+import (
+	"time"
+	"wereserve/models"
+)
+
+type reservationManageTokenRepository struct {
+	store *Store
+}
+
+func (r *reservationManageTokenRepository) SaveReservationManageToken(token *models.ReservationManageToken) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, existing := range r.store.data.reservationManageTokens {
+		if existing.ReservationID == token.ReservationID {
+			delete(r.store.data.reservationManageTokens, id)
+		}
+	}
+
+	token.ID = r.store.newID()
+	token.CreatedAt = time.Now()
+	r.store.data.reservationManageTokens[token.ID] = *token
+	return nil
+}
+
+func (r *reservationManageTokenRepository) GetReservationManageTokenByHash(hash string) (*models.ReservationManageToken, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, token := range r.store.data.reservationManageTokens {
+		if token.TokenHash == hash {
+			return &token, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,317 @@
+package memory
+
+import (
+	"cmp"
+	"sort"
+	"strings"
+	"time"
+	"wereserve/models"
+	"wereserve/repository"
+)
+
+type reservationRepository struct {
+	store *Store
+}
+
+// populate mengisi relasi User, GuestContact, Table, Tables dan end_datetime seperti Preload di repository Postgres.
+// Harus dipanggil saat mu sedang dikunci
+func (r *reservationRepository) populate(reservation models.Reservation) models.Reservation {
+	reservation.User = withoutPassword(r.store.data.users[reservation.UserID])
+	reservation.GuestContact = nil
+	if reservation.GuestContactID != nil {
+		if guest, ok := r.store.data.guestContacts[*reservation.GuestContactID]; ok {
+			reservation.GuestContact = &guest
+		}
+	}
+	reservation.Table = r.store.data.tables[reservation.TableID]
+	reservation.Tables = nil
+	for _, tableID := range r.store.data.reservationTables[reservation.ID] {
+		reservation.Tables = append(reservation.Tables, r.store.data.tables[tableID])
+	}
+	reservation.EndDateTime = reservation.EndTime()
+	return reservation
+}
+
+// sortedReservations mengembalikan reservasi yang lolos filter, diurutkan berdasarkan id. Harus dipanggil saat mu sedang dikunci
+func (r *reservationRepository) sortedReservations(filter func(models.Reservation) bool) []models.Reservation {
+	reservations := []models.Reservation{}
+	for _, reservation := range r.store.data.reservations {
+		reservation = r.populate(reservation)
+		if filter(reservation) {
+			reservations = append(reservations, reservation)
+		}
+	}
+	sort.Slice(reservations, func(i, j int) bool { return reservations[i].ID < reservations[j].ID })
+	return reservations
+}
+
+func (r *reservationRepository) ListReservations(filter repository.ReservationFilter) ([]models.Reservation, int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	reservations := r.sortedReservations(func(reservation models.Reservation) bool {
+		if filter.From != nil && reservation.ReservationDateTime.Before(*filter.From) {
+			return false
+		}
+		if filter.To != nil && !reservation.ReservationDateTime.Before(*filter.To) {
+			return false
+		}
+		if filter.UserID != 0 && reservation.UserID != filter.UserID {
+			return false
+		}
+		if filter.Status != "" && reservation.Status != filter.Status {
+			return false
+		}
+		if filter.TableID != 0 && !containsInt(reservation.TableIDs(), filter.TableID) {
+			return false
+		}
+		return true
+	})
+
+	page, total := paginate(reservations, filter.Page, func(a, b models.Reservation) int {
+		switch filter.Sort {
+		case "reservation_datetime":
+			return a.ReservationDateTime.Compare(b.ReservationDateTime)
+		case "number_of_people":
+			return cmp.Compare(a.NumberOfPeople, b.NumberOfPeople)
+		case "status":
+			return strings.Compare(a.Status, b.Status)
+		case "created_at":
+			return a.CreatedAt.Compare(b.CreatedAt)
+		}
+		return 0
+	}, func(reservation models.Reservation) int { return reservation.ID })
+	return page, total, nil
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *reservationRepository) GetReservationDetail(id int) (*models.Reservation, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	reservation, ok := r.store.data.reservations[id]
+	if !ok {
+		return nil, repository.ErrReservationNotFound.Withf("reservation with ID %d not found", id)
+	}
+	reservation = r.populate(reservation)
+	return &reservation, nil
+}
+
+// Transaksi in-memory sudah berjalan satu per satu, jadi tidak ada penguncian tambahan
+func (r *reservationRepository) GetReservationForUpdate(id int) (*models.Reservation, error) {
+	return r.GetReservationDetail(id)
+}
+
+func (r *reservationRepository) GetReservationByUserLogin(userID int) ([]models.Reservation, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return r.sortedReservations(func(reservation models.Reservation) bool { return reservation.UserID == userID }), nil
+}
+
+func (r *reservationRepository) IsReservationOverlap(tableIDs []int, start, end time.Time, excludeID int) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return r.isOverlap(tableIDs, start, end, excludeID), nil
+}
+
+// isOverlap harus dipanggil saat mu sedang dikunci
+func (r *reservationRepository) isOverlap(tableIDs []int, start, end time.Time, excludeID int) bool {
+	wanted := make(map[int]bool, len(tableIDs))
+	for _, id := range tableIDs {
+		wanted[id] = true
+	}
+
+	for id, reservation := range r.store.data.reservations {
+		if id == excludeID || !reservation.IsActive() {
+			continue
+		}
+		if !overlaps(reservation.ReservationDateTime, reservation.EndTime(), start, end) {
+			continue
+		}
+		for _, tableID := range r.store.data.reservationTables[id] {
+			if wanted[tableID] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *reservationRepository) GetReservationsInRange(from, to time.Time) ([]models.Reservation, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return r.sortedReservations(func(reservation models.Reservation) bool {
+		return reservation.IsActive() && overlaps(reservation.ReservationDateTime, reservation.EndTime(), from, to)
+	}), nil
+}
+
+func (r *reservationRepository) GetCurrentReservations(at time.Time) ([]models.Reservation, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return r.sortedReservations(func(reservation models.Reservation) bool {
+		if reservation.Status == models.ReservationStatusSeated {
+			return true
+		}
+		return (reservation.Status == models.ReservationStatusPending || reservation.Status == models.ReservationStatusConfirmed) &&
+			!reservation.ReservationDateTime.After(at) && reservation.EndTime().After(at)
+	}), nil
+}
+
+func (r *reservationRepository) CountUpcomingGuestReservations(email, phone string, from time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var count int64
+	for _, reservation := range r.store.data.reservations {
+		if reservation.GuestContactID == nil || !reservation.IsActive() || reservation.ReservationDateTime.Before(from) {
+			continue
+		}
+		guest := r.store.data.guestContacts[*reservation.GuestContactID]
+		if (guest.Email != "" && strings.EqualFold(guest.Email, email)) || (guest.Phone != "" && guest.Phone == phone) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *reservationRepository) GetUpcomingGuestReservationIDs(email string, from time.Time) ([]int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var reservations []models.Reservation
+	for _, reservation := range r.store.data.reservations {
+		if reservation.GuestContactID == nil || !reservation.IsActive() || reservation.ReservationDateTime.Before(from) {
+			continue
+		}
+		guest := r.store.data.guestContacts[*reservation.GuestContactID]
+		if guest.Email != "" && strings.EqualFold(guest.Email, email) {
+			reservations = append(reservations, reservation)
+		}
+	}
+	sort.Slice(reservations, func(i, j int) bool {
+		return reservations[i].ReservationDateTime.Before(reservations[j].ReservationDateTime)
+	})
+
+	ids := make([]int, 0, len(reservations))
+	for _, reservation := range reservations {
+		ids = append(ids, reservation.ID)
+	}
+	return ids, nil
+}
+
+func (r *reservationRepository) CreateReservation(reservation *models.Reservation) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if reservation.DurationMinutes == 0 {
+		reservation.DurationMinutes = models.DefaultReservationDuration
+	}
+	if reservation.Status == "" {
+		reservation.Status = models.ReservationStatusPending
+	}
+
+	tableIDs := reservation.TableIDs()
+	if r.isOverlap(tableIDs, reservation.ReservationDateTime, reservation.EndTime(), 0) {
+		return repository.ErrReservationOverlap
+	}
+
+	now := time.Now()
+	reservation.ID = r.store.newID()
+	if reservation.CreatedAt.IsZero() {
+		reservation.CreatedAt = now
+	}
+	reservation.UpdatedAt = now
+	reservation.EndDateTime = reservation.EndTime()
+
+	stored := *reservation
+	stored.User, stored.GuestContact, stored.Table, stored.Tables = models.User{}, nil, models.Table{}, nil
+	r.store.data.reservations[reservation.ID] = stored
+	r.store.data.reservationTables[reservation.ID] = append([]int(nil), tableIDs...)
+	return nil
+}
+
+// UpdateReservation hanya mengubah field yang diisi, sama seperti Updates di gorm
+func (r *reservationRepository) UpdateReservation(id int, reservation *models.Reservation) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current, ok := r.store.data.reservations[id]
+	if !ok {
+		return repository.ErrReservationNotFound
+	}
+
+	if reservation.UserID != 0 {
+		current.UserID = reservation.UserID
+		current.GuestContactID = nil
+	}
+	if reservation.TableID != 0 {
+		current.TableID = reservation.TableID
+	}
+	if reservation.CombinationID != nil {
+		current.CombinationID = reservation.CombinationID
+	}
+	if !reservation.ReservationDateTime.IsZero() {
+		current.ReservationDateTime = reservation.ReservationDateTime
+	}
+	if reservation.DurationMinutes != 0 {
+		current.DurationMinutes = reservation.DurationMinutes
+	}
+	if reservation.NumberOfPeople != 0 {
+		current.NumberOfPeople = reservation.NumberOfPeople
+	}
+
+	tableIDs := r.store.data.reservationTables[id]
+	if len(reservation.Tables) > 0 {
+		current.CombinationID = reservation.CombinationID
+		tableIDs = reservation.TableIDs()
+	}
+
+	if r.isOverlap(tableIDs, current.ReservationDateTime, current.EndTime(), id) {
+		return repository.ErrReservationOverlap
+	}
+
+	current.UpdatedAt = time.Now()
+	current.EndDateTime = current.EndTime()
+	r.store.data.reservations[id] = current
+	r.store.data.reservationTables[id] = append([]int(nil), tableIDs...)
+	return nil
+}
+
+func (r *reservationRepository) UpdateReservationStatus(id int, status string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current, ok := r.store.data.reservations[id]
+	if !ok {
+		return repository.ErrReservationNotFound.Withf("reservation with ID %d not found", id)
+	}
+	current.Status = status
+	current.UpdatedAt = time.Now()
+	r.store.data.reservations[id] = current
+	return nil
+}
+
+func (r *reservationRepository) CancelReservation(id int, reason string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current, ok := r.store.data.reservations[id]
+	if !ok {
+		return repository.ErrReservationNotFound.Withf("reservation with ID %d not found", id)
+	}
+	now := time.Now()
+	current.Status = models.ReservationStatusCancelled
+	current.CancellationReason = reason
+	current.CancelledAt = &now
+	current.UpdatedAt = now
+	r.store.data.reservations[id] = current
+	return nil
+}
@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"sort"
+	"time"
+	"wereserve/models"
+	"wereserve/repository"
+)
+
+// defaultRoles sama dengan role dan permission yang dibuat oleh migrasi 15_roles.sql
+func defaultRoles() map[string]models.Role {
+	staff := []string{
+		models.PermissionTableRead,
+		models.PermissionReservationCreate,
+		models.PermissionReservationRead,
+		models.PermissionReservationUpdate,
+		models.PermissionReservationCancel,
+	}
+	host := append(append([]string(nil), staff...), models.PermissionWaitlistManage)
+	manager := append(append([]string(nil), host...), models.PermissionUserRead, models.PermissionTableWrite, models.PermissionScheduleWrite)
+
+	now := time.Now()
+	roles := map[string]models.Role{}
+	for _, role := range []models.Role{
+		{Name: models.RoleAdmin, Description: "Full access, including users, roles, outbox and webhooks", Permissions: append([]string(nil), models.Permissions...)},
+		{Name: "manager", Description: "Manages tables, schedule and every reservation", Permissions: manager},
+		{Name: "host", Description: "Seats and cancels reservations and manages the waitlist", Permissions: host},
+		{Name: "staff", Description: "Seats and cancels reservations", Permissions: staff},
+		{Name: models.RoleCustomer, Description: "Books tables for themselves", Permissions: []string{models.PermissionTableRead, models.PermissionReservationCreate}},
+	} {
+		sort.Strings(role.Permissions)
+		role.CreatedAt = now
+		role.UpdatedAt = now
+		roles[role.Name] = role
+	}
+	return roles
+}
+
+type roleRepository struct {
+	store *Store
+}
+
+// withPermissions menyalin slice permission supaya pemanggil tidak bisa mengubah data di store
+func withPermissions(role models.Role) models.Role {
+	role.Permissions = append([]string{}, role.Permissions...)
+	return role
+}
+
+func (r *roleRepository) GetRoles() ([]models.Role, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	roles := make([]models.Role, 0, len(r.store.data.roles))
+	for _, role := range r.store.data.roles {
+		roles = append(roles, withPermissions(role))
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+func (r *roleRepository) GetRoleByName(name string) (*models.Role, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	role, ok := r.store.data.roles[name]
+	if !ok {
+		return nil, repository.ErrRoleNotFound.Withf("role %q not found", name)
+	}
+	role = withPermissions(role)
+	return &role, nil
+}
+
+func (r *roleRepository) GetPermissions(role string) ([]string, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return append([]string{}, r.store.data.roles[role].Permissions...), nil
+}
+
+func (r *roleRepository) CreateRole(role *models.Role) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.data.roles[role.Name]; ok {
+		return repository.ErrRoleNameTaken.Withf("role %q already exists", role.Name)
+	}
+
+	now := time.Now()
+	role.CreatedAt = now
+	role.UpdatedAt = now
+	stored := withPermissions(*role)
+	sort.Strings(stored.Permissions)
+	r.store.data.roles[role.Name] = stored
+	return nil
+}
+
+func (r *roleRepository) UpdateRole(name string, role *models.Role) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current, ok := r.store.data.roles[name]
+	if !ok {
+		return repository.ErrRoleNotFound.Withf("role %q not found", name)
+	}
+	current.Description = role.Description
+	current.Permissions = append([]string{}, role.Permissions...)
+	sort.Strings(current.Permissions)
+	current.UpdatedAt = time.Now()
+	r.store.data.roles[name] = current
+	return nil
+}
+
+func (r *roleRepository) DeleteRole(name string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.data.roles[name]; !ok {
+		return repository.ErrRoleNotFound.Withf("role %q not found", name)
+	}
+	delete(r.store.data.roles, name)
+	return nil
+}
+
+func (r *roleRepository) CountUsersWithRole(name string) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var count int64
+	for _, user := range r.store.data.users {
+		if user.Role == name {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"sort"
+	"time"
+	"wereserve/models"
+	"wereserve/repository"
+)
+
+type scheduleRepository struct {
+	store *Store
+}
+
+func (r *scheduleRepository) GetOpeningHours() ([]models.OpeningHour, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	hours := []models.OpeningHour{}
+	for _, hour := range r.store.data.openingHours {
+		hours = append(hours, hour)
+	}
+	sort.Slice(hours, func(i, j int) bool { return hours[i].DayOfWeek < hours[j].DayOfWeek })
+	return hours, nil
+}
+
+func (r *scheduleRepository) GetOpeningHourByDay(day time.Weekday) (*models.OpeningHour, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	hour, ok := r.store.data.openingHours[int(day)]
+	if !ok {
+		return nil, nil
+	}
+	return &hour, nil
+}
+
+func (r *scheduleRepository) UpsertOpeningHours(hours []models.OpeningHour) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	for _, hour := range hours {
+		if current, ok := r.store.data.openingHours[hour.DayOfWeek]; ok {
+			hour.ID = current.ID
+			hour.CreatedAt = current.CreatedAt
+		} else {
+			hour.ID = r.store.newID()
+			hour.CreatedAt = now
+		}
+		hour.UpdatedAt = now
+		r.store.data.openingHours[hour.DayOfWeek] = hour
+	}
+	return nil
+}
+
+func (r *scheduleRepository) GetSetting() (*models.ScheduleSetting, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return &models.ScheduleSetting{ID: 1, SlotMinutes: r.store.data.slotMinutes}, nil
+}
+
+func (r *scheduleRepository) UpdateSlotMinutes(minutes int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	r.store.data.slotMinutes = minutes
+	return nil
+}
+
+func (r *scheduleRepository) GetUpcomingClosures(from time.Time) ([]models.ScheduleClosure, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	day := from.Format("2006-01-02")
+	closures := []models.ScheduleClosure{}
+	for _, closure := range r.store.data.closures {
+		if closure.EndDate.Format("2006-01-02") >= day {
+			closures = append(closures, closure)
+		}
+	}
+	sort.Slice(closures, func(i, j int) bool { return closures[i].StartDate.Before(closures[j].StartDate) })
+	return closures, nil
+}
+
+func (r *scheduleRepository) GetClosureOn(date time.Time) (*models.ScheduleClosure, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// Tanggal dibandingkan sebagai string YYYY-MM-DD seperti kolom DATE di Postgres
+	day := date.Format("2006-01-02")
+	for _, closure := range r.store.data.closures {
+		if closure.StartDate.Format("2006-01-02") <= day && closure.EndDate.Format("2006-01-02") >= day {
+			return &closure, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *scheduleRepository) CreateClosure(closure *models.ScheduleClosure) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	closure.ID = r.store.newID()
+	closure.CreatedAt = now
+	closure.UpdatedAt = now
+	r.store.data.closures[closure.ID] = *closure
+	return nil
+}
+
+func (r *scheduleRepository) DeleteClosure(id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.data.closures[id]; !ok {
+		return repository.ErrClosureNotFound.Withf("closure with ID %d not found", id)
+	}
+	delete(r.store.data.closures, id)
+	return nil
+}
@@ -0,0 +1,305 @@
+// Package memory berisi implementasi in-memory dari interface di package repository.
+// Dipakai oleh test services dan handler supaya bisa berjalan tanpa Postgres
+package memory
+
+import (
+	"cmp"
+	"sort"
+	"sync"
+	"time"
+	"wereserve/models"
+	"wereserve/repository"
+)
+
+// Store menyimpan semua data di memori. Semua repository yang dibuat dari Store yang sama berbagi data,
+// sama seperti repository Postgres yang berbagi satu database
+type Store struct {
+	mu   sync.Mutex
+	txMu sync.Mutex
+
+	data   data
+	nextID int
+}
+
+type data struct {
+	users                   map[int]models.User
+	tables                  map[int]models.Table
+	reservations            map[int]models.Reservation
+	reservationTables       map[int][]int
+	guestContacts           map[int]models.GuestContact
+	combinations            map[int]models.TableCombination
+	openingHours            map[int]models.OpeningHour
+	closures                map[int]models.ScheduleClosure
+	slotMinutes             int
+	waitlist                map[int]models.WaitlistEntry
+	refreshTokens           map[int]models.RefreshToken
+	passwordResetTokens     map[int]models.PasswordResetToken
+	streamTickets           map[int]models.StreamTicket
+	reservationManageTokens map[int]models.ReservationManageToken
+	outbox                  map[int]models.OutboxMessage
+	reminders               map[int]models.ReservationReminder
+	webhookSubscriptions    map[int]models.WebhookSubscription
+	webhookDeliveries       map[int]models.WebhookDelivery
+	roles                   map[string]models.Role
+}
+
+// NewStore membuat Store kosong dengan jadwal dan role default yang sama dengan migrasi database:
+// buka setiap hari 10:00-22:00, last seating 20:00, slot 15 menit
+func NewStore() *Store {
+	s := &Store{
+		data: data{
+			users:                   map[int]models.User{},
+			tables:                  map[int]models.Table{},
+			reservations:            map[int]models.Reservation{},
+			reservationTables:       map[int][]int{},
+			guestContacts:           map[int]models.GuestContact{},
+			combinations:            map[int]models.TableCombination{},
+			openingHours:            map[int]models.OpeningHour{},
+			closures:                map[int]models.ScheduleClosure{},
+			slotMinutes:             15,
+			waitlist:                map[int]models.WaitlistEntry{},
+			refreshTokens:           map[int]models.RefreshToken{},
+			passwordResetTokens:     map[int]models.PasswordResetToken{},
+			streamTickets:           map[int]models.StreamTicket{},
+			reservationManageTokens: map[int]models.ReservationManageToken{},
+			outbox:                  map[int]models.OutboxMessage{},
+			reminders:               map[int]models.ReservationReminder{},
+			webhookSubscriptions:    map[int]models.WebhookSubscription{},
+			webhookDeliveries:       map[int]models.WebhookDelivery{},
+			roles:                   defaultRoles(),
+		},
+	}
+
+	for day := 0; day <= 6; day++ {
+		s.data.openingHours[day] = models.OpeningHour{
+			ID:              day + 1,
+			DayOfWeek:       day,
+			OpenTime:        "10:00",
+			CloseTime:       "22:00",
+			LastSeatingTime: "20:00",
+		}
+	}
+
+	return s
+}
+
+func (s *Store) Users() repository.UserRepository {
+	return &userRepository{store: s}
+}
+
+func (s *Store) Tables() repository.TableRepository {
+	return &tableRepository{store: s}
+}
+
+func (s *Store) Reservations() repository.ReservationRepository {
+	return &reservationRepository{store: s}
+}
+
+func (s *Store) GuestContacts() repository.GuestContactRepository {
+	return &guestContactRepository{store: s}
+}
+
+func (s *Store) TableCombinations() repository.TableCombinationRepository {
+	return &tableCombinationRepository{store: s}
+}
+
+func (s *Store) Schedule() repository.ScheduleRepository {
+	return &scheduleRepository{store: s}
+}
+
+func (s *Store) Waitlist() repository.WaitlistRepository {
+	return &waitlistRepository{store: s}
+}
+
+func (s *Store) RefreshTokens() repository.RefreshTokenRepository {
+	return &refreshTokenRepository{store: s}
+}
+
+func (s *Store) PasswordResetTokens() repository.PasswordResetTokenRepository {
+	return &passwordResetTokenRepository{store: s}
+}
+
+func (s *Store) StreamTickets() repository.StreamTicketRepository {
+	return &streamTicketRepository{store: s}
+}
+
+func (s *Store) ReservationManageTokens() repository.ReservationManageTokenRepository {
+	return &reservationManageTokenRepository{store: s}
+}
+
+func (s *Store) Outbox() repository.OutboxRepository {
+	return &outboxRepository{store: s}
+}
+
+func (s *Store) Reminders() repository.ReminderRepository {
+	return &reminderRepository{store: s}
+}
+
+func (s *Store) Webhooks() repository.WebhookRepository {
+	return &webhookRepository{store: s}
+}
+
+func (s *Store) Roles() repository.RoleRepository {
+	return &roleRepository{store: s}
+}
+
+func (s *Store) UnitOfWork() repository.UnitOfWork {
+	return &unitOfWork{store: s}
+}
+
+// newID mengembalikan id unik berikutnya. Harus dipanggil saat mu sedang dikunci
+func (s *Store) newID() int {
+	s.nextID++
+	return s.nextID
+}
+
+// clone menyalin semua map supaya perubahan di dalam transaksi bisa dibatalkan
+func (d data) clone() data {
+	c := data{
+		users:                   make(map[int]models.User, len(d.users)),
+		tables:                  make(map[int]models.Table, len(d.tables)),
+		reservations:            make(map[int]models.Reservation, len(d.reservations)),
+		reservationTables:       make(map[int][]int, len(d.reservationTables)),
+		guestContacts:           make(map[int]models.GuestContact, len(d.guestContacts)),
+		combinations:            make(map[int]models.TableCombination, len(d.combinations)),
+		openingHours:            make(map[int]models.OpeningHour, len(d.openingHours)),
+		closures:                make(map[int]models.ScheduleClosure, len(d.closures)),
+		slotMinutes:             d.slotMinutes,
+		waitlist:                make(map[int]models.WaitlistEntry, len(d.waitlist)),
+		refreshTokens:           make(map[int]models.RefreshToken, len(d.refreshTokens)),
+		passwordResetTokens:     make(map[int]models.PasswordResetToken, len(d.passwordResetTokens)),
+		streamTickets:           make(map[int]models.StreamTicket, len(d.streamTickets)),
+		reservationManageTokens: make(map[int]models.ReservationManageToken, len(d.reservationManageTokens)),
+		outbox:                  make(map[int]models.OutboxMessage, len(d.outbox)),
+		reminders:               make(map[int]models.ReservationReminder, len(d.reminders)),
+		webhookSubscriptions:    make(map[int]models.WebhookSubscription, len(d.webhookSubscriptions)),
+		webhookDeliveries:       make(map[int]models.WebhookDelivery, len(d.webhookDeliveries)),
+		roles:                   make(map[string]models.Role, len(d.roles)),
+	}
+	for k, v := range d.users {
+		c.users[k] = v
+	}
+	for k, v := range d.tables {
+		c.tables[k] = v
+	}
+	for k, v := range d.reservations {
+		c.reservations[k] = v
+	}
+	for k, v := range d.reservationTables {
+		c.reservationTables[k] = append([]int(nil), v...)
+	}
+	for k, v := range d.guestContacts {
+		c.guestContacts[k] = v
+	}
+	for k, v := range d.combinations {
+		c.combinations[k] = v
+	}
+	for k, v := range d.openingHours {
+		c.openingHours[k] = v
+	}
+	for k, v := range d.closures {
+		c.closures[k] = v
+	}
+	for k, v := range d.waitlist {
+		c.waitlist[k] = v
+	}
+	for k, v := range d.refreshTokens {
+		c.refreshTokens[k] = v
+	}
+	for k, v := range d.passwordResetTokens {
+		c.passwordResetTokens[k] = v
+	}
+	for k, v := range d.streamTickets {
+		c.streamTickets[k] = v
+	}
+	for k, v := range d.reservationManageTokens {
+		c.reservationManageTokens[k] = v
+	}
+	for k, v := range d.outbox {
+		c.outbox[k] = v
+	}
+	for k, v := range d.reminders {
+		c.reminders[k] = v
+	}
+	for k, v := range d.webhookSubscriptions {
+		c.webhookSubscriptions[k] = v
+	}
+	for k, v := range d.webhookDeliveries {
+		c.webhookDeliveries[k] = v
+	}
+	for k, v := range d.roles {
+		v.Permissions = append([]string(nil), v.Permissions...)
+		c.roles[k] = v
+	}
+	return c
+}
+
+// unitOfWork menjalankan transaksi satu per satu. Jika fn gagal, semua data dikembalikan ke kondisi sebelum transaksi
+type unitOfWork struct {
+	store *Store
+}
+
+func (u *unitOfWork) Do(fn func(repos repository.Repositories) error) (err error) {
+	u.store.txMu.Lock()
+	defer u.store.txMu.Unlock()
+
+	u.store.mu.Lock()
+	snapshot := u.store.data.clone()
+	u.store.mu.Unlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			u.store.restore(snapshot)
+			panic(r)
+		}
+		if err != nil {
+			u.store.restore(snapshot)
+		}
+	}()
+
+	return fn(repository.Repositories{
+		Reservations:  u.store.Reservations(),
+		Tables:        u.store.Tables(),
+		Users:         u.store.Users(),
+		GuestContacts: u.store.GuestContacts(),
+		ManageTokens:  u.store.ReservationManageTokens(),
+		Outbox:        u.store.Outbox(),
+		Reminders:     u.store.Reminders(),
+		Webhooks:      u.store.Webhooks(),
+		Waitlist:      u.store.Waitlist(),
+	})
+}
+
+func (s *Store) restore(snapshot data) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data = snapshot
+}
+
+// overlaps mengecek apakah [startA, endA) bersinggungan dengan [startB, endB)
+func overlaps(startA, endA, startB, endB time.Time) bool {
+	return startA.Before(endB) && endA.After(startB)
+}
+
+// paginate mengurutkan items dengan compare, memakai id sebagai urutan kedua, lalu mengambil satu halaman
+// seperti ORDER BY ... LIMIT ... OFFSET di repository Postgres. compare mengembalikan 0 jika field sort tidak dikenal
+func paginate[T any](items []T, page repository.Page, compare func(a, b T) int, id func(T) int) ([]T, int64) {
+	sort.SliceStable(items, func(i, j int) bool {
+		c := compare(items[i], items[j])
+		if c == 0 {
+			c = cmp.Compare(id(items[i]), id(items[j]))
+		}
+		if page.Desc {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	total := int64(len(items))
+	start := min(page.Offset, len(items))
+	end := len(items)
+	if page.Limit > 0 {
+		end = min(start+page.Limit, end)
+	}
+	return items[start:end], total
+}
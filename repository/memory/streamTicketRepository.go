@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"time"
+	"wereserve/models"
+)
+
+type streamTicketRepository struct {
+	store *Store
+}
+
+func (r *streamTicketRepository) CreateStreamTicket(ticket *models.StreamTicket) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	ticket.ID = r.store.newID()
+	ticket.CreatedAt = time.Now()
+	r.store.data.streamTickets[ticket.ID] = *ticket
+	return nil
+}
+
+func (r *streamTicketRepository) ConsumeStreamTicket(hash string, now time.Time) (*models.StreamTicket, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, ticket := range r.store.data.streamTickets {
+		if ticket.TicketHash != hash || ticket.UsedAt != nil || !ticket.ExpiresAt.After(now) {
+			continue
+		}
+		ticket.UsedAt = &now
+		r.store.data.streamTickets[id] = ticket
+		return &ticket, nil
+	}
+	return nil, nil
+}
+
+func (r *streamTicketRepository) DeleteExpiredStreamTickets(before time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var deleted int64
+	for id, ticket := range r.store.data.streamTickets {
+		if ticket.ExpiresAt.Before(before) {
+			delete(r.store.data.streamTickets, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"sort"
+	"time"
+	"wereserve/models"
+	"wereserve/repository"
+)
+
+type tableCombinationRepository struct {
+	store *Store
+}
+
+func (r *tableCombinationRepository) IsCombinationExists(name string) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return r.combinationExists(name), nil
+}
+
+func (r *tableCombinationRepository) combinationExists(name string) bool {
+	for _, combination := range r.store.data.combinations {
+		if combination.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// populate mengambil data meja terbaru untuk setiap meja di kombinasi. Harus dipanggil saat mu sedang dikunci
+func (r *tableCombinationRepository) populate(combination models.TableCombination) models.TableCombination {
+	tables := make([]models.Table, 0, len(combination.Tables))
+	for _, table := range combination.Tables {
+		if current, ok := r.store.data.tables[table.ID]; ok {
+			tables = append(tables, current)
+		}
+	}
+	combination.Tables = tables
+	return combination
+}
+
+func (r *tableCombinationRepository) GetAllCombinations() ([]models.TableCombination, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	combinations := []models.TableCombination{}
+	for _, combination := range r.store.data.combinations {
+		combinations = append(combinations, r.populate(combination))
+	}
+	sort.Slice(combinations, func(i, j int) bool { return combinations[i].ID < combinations[j].ID })
+	return combinations, nil
+}
+
+func (r *tableCombinationRepository) GetCombinationByID(id int) (*models.TableCombination, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	combination, ok := r.store.data.combinations[id]
+	if !ok {
+		return nil, repository.ErrTableCombinationNotFound.Withf("table combination with ID %d not found", id)
+	}
+	combination = r.populate(combination)
+	return &combination, nil
+}
+
+func (r *tableCombinationRepository) GetCombinationsByMinCapacity(capacity int) ([]models.TableCombination, error) {
+	combinations, err := r.GetAllCombinations()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []models.TableCombination
+	for _, combination := range combinations {
+		if combination.Capacity() >= capacity {
+			result = append(result, combination)
+		}
+	}
+	return result, nil
+}
+
+func (r *tableCombinationRepository) CreateCombination(combination *models.TableCombination) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.combinationExists(combination.Name) {
+		return repository.ErrTableCombinationNameTaken
+	}
+
+	now := time.Now()
+	combination.ID = r.store.newID()
+	combination.CreatedAt = now
+	combination.UpdatedAt = now
+	stored := *combination
+	stored.Tables = append([]models.Table(nil), combination.Tables...)
+	r.store.data.combinations[combination.ID] = stored
+	return nil
+}
+
+func (r *tableCombinationRepository) DeleteCombination(id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.data.combinations[id]; !ok {
+		return repository.ErrTableCombinationNotFound.Withf("table combination with ID %d not found", id)
+	}
+	delete(r.store.data.combinations, id)
+	return nil
+}
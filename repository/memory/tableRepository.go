@@ -0,0 +1,162 @@
+package memory
+
+import (
+	"cmp"
+	"sort"
+	"strings"
+	"time"
+	"wereserve/models"
+	"wereserve/repository"
+)
+
+type tableRepository struct {
+	store *Store
+}
+
+func (r *tableRepository) IsTableExists(tableName string) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return r.tableExists(tableName), nil
+}
+
+func (r *tableRepository) tableExists(tableName string) bool {
+	for _, table := range r.store.data.tables {
+		if table.TableName == tableName {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedTables mengembalikan meja yang lolos filter, diurutkan berdasarkan id. Harus dipanggil saat mu sedang dikunci
+func (r *tableRepository) sortedTables(filter func(models.Table) bool) []models.Table {
+	tables := []models.Table{}
+	for _, table := range r.store.data.tables {
+		if filter(table) {
+			tables = append(tables, table)
+		}
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].ID < tables[j].ID })
+	return tables
+}
+
+func (r *tableRepository) GetAllTables() ([]models.Table, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return r.sortedTables(func(models.Table) bool { return true }), nil
+}
+
+func (r *tableRepository) ListTables(filter repository.TableFilter) ([]models.Table, int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	tables := r.sortedTables(func(table models.Table) bool { return table.Capacity >= filter.MinCapacity })
+	page, total := paginate(tables, filter.Page, func(a, b models.Table) int {
+		switch filter.Sort {
+		case "table_name":
+			return strings.Compare(a.TableName, b.TableName)
+		case "capacity":
+			return cmp.Compare(a.Capacity, b.Capacity)
+		case "created_at":
+			return a.CreatedAt.Compare(b.CreatedAt)
+		}
+		return 0
+	}, func(table models.Table) int { return table.ID })
+	return page, total, nil
+}
+
+func (r *tableRepository) GetTableByID(id int) (*models.Table, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	table, ok := r.store.data.tables[id]
+	if !ok {
+		return nil, repository.ErrTableNotFound.Withf("table with ID %d not found", id)
+	}
+	return &table, nil
+}
+
+func (r *tableRepository) GetTableByStatus(status string) ([]models.Table, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return r.sortedTables(func(table models.Table) bool { return table.Status == status }), nil
+}
+
+func (r *tableRepository) GetTablesByMinCapacity(capacity int) ([]models.Table, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	tables := r.sortedTables(func(table models.Table) bool { return table.Capacity >= capacity })
+	sort.SliceStable(tables, func(i, j int) bool { return tables[i].Capacity < tables[j].Capacity })
+	return tables, nil
+}
+
+// Transaksi in-memory sudah berjalan satu per satu, jadi LockTables hanya memastikan meja ada
+func (r *tableRepository) LockTables(ids []int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, id := range ids {
+		if _, ok := r.store.data.tables[id]; !ok {
+			return repository.ErrTableNotFound.Withf("failed to fetch table: some of tables %v not found", ids)
+		}
+	}
+	return nil
+}
+
+func (r *tableRepository) CreateTable(table *models.Table) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.tableExists(table.TableName) {
+		return repository.ErrTableNameTaken.Withf("meja dengan nomor tersebut telah digunakan")
+	}
+
+	now := time.Now()
+	table.ID = r.store.newID()
+	if table.Status == "" {
+		table.Status = "available"
+	}
+	table.CreatedAt = now
+	table.UpdatedAt = now
+	r.store.data.tables[table.ID] = *table
+	return nil
+}
+
+func (r *tableRepository) DeleteTable(id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.data.tables[id]; !ok {
+		return repository.ErrTableNotFound.Withf("tables with id %d not found", id)
+	}
+	delete(r.store.data.tables, id)
+	return nil
+}
+
+func (r *tableRepository) UpdateTable(id int, table *models.Table) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current, ok := r.store.data.tables[id]
+	if !ok {
+		return repository.ErrTableNotFound
+	}
+
+	if table.TableName != "" && table.TableName != current.TableName && r.tableExists(table.TableName) {
+		return repository.ErrTableNameTaken
+	}
+
+	if table.TableName != "" {
+		current.TableName = table.TableName
+	}
+	if table.Capacity != 0 {
+		current.Capacity = table.Capacity
+	}
+	if table.Status != "" {
+		current.Status = table.Status
+	}
+	current.UpdatedAt = time.Now()
+	r.store.data.tables[id] = current
+	return nil
+}
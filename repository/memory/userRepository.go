@@ -0,0 +1,311 @@
+package memory
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"wereserve/models"
+	"wereserve/repository"
+)
+
+type userRepository struct {
+	store *Store
+}
+
+func (r *userRepository) IsValidUserRole(role string) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	_, ok := r.store.data.roles[role]
+	return ok, nil
+}
+
+func (r *userRepository) IsEmailExists(email string) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return r.emailExists(email), nil
+}
+
+func (r *userRepository) emailExists(email string) bool {
+	for _, user := range r.store.data.users {
+		if user.Email == email {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *userRepository) IsUserExists(id int) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	_, ok := r.store.data.users[id]
+	return ok, nil
+}
+
+// Password tidak pernah dikembalikan, sama seperti query di repository Postgres
+func withoutPassword(user models.User) models.User {
+	user.Password = ""
+	return user
+}
+
+func (r *userRepository) GetAllUser() ([]models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	users := make([]models.User, 0, len(r.store.data.users))
+	for _, user := range r.store.data.users {
+		users = append(users, withoutPassword(user))
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+func (r *userRepository) ListUsers(filter repository.UserFilter) ([]models.User, int64, error) {
+	users, _ := r.GetAllUser()
+
+	filtered := []models.User{}
+	for _, user := range users {
+		if filter.Role == "" || user.Role == filter.Role {
+			filtered = append(filtered, user)
+		}
+	}
+
+	page, total := paginate(filtered, filter.Page, func(a, b models.User) int {
+		switch filter.Sort {
+		case "name":
+			return strings.Compare(a.Name, b.Name)
+		case "email":
+			return strings.Compare(a.Email, b.Email)
+		case "created_at":
+			return a.CreatedAt.Compare(b.CreatedAt)
+		}
+		return 0
+	}, func(user models.User) int { return user.ID })
+	return page, total, nil
+}
+
+func (r *userRepository) GetUserByid(id int) (*models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.data.users[id]
+	if !ok {
+		return nil, repository.ErrUserNotFound.Withf("user with Id %d not found", id)
+	}
+	user = withoutPassword(user)
+	return &user, nil
+}
+
+func (r *userRepository) GetUserByEmail(email string) (*models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, user := range r.store.data.users {
+		if user.Email == email {
+			return &user, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (r *userRepository) CreateUser(user *models.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.emailExists(user.Email) {
+		return repository.ErrEmailTaken
+	}
+	if _, ok := r.store.data.roles[user.Role]; !ok {
+		return repository.ErrInvalidUserRole
+	}
+
+	now := time.Now()
+	user.ID = r.store.newID()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	r.store.data.users[user.ID] = *user
+	return nil
+}
+
+func (r *userRepository) UpdateUser(id int, user *models.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current, ok := r.store.data.users[id]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+
+	if user.Email != "" && user.Email != current.Email && r.emailExists(user.Email) {
+		return repository.ErrEmailTaken
+	}
+
+	if user.Name != "" {
+		current.Name = user.Name
+	}
+	if user.Email != "" && user.Email != current.Email {
+		current.Email = user.Email
+		current.EmailVerifiedAt = nil
+	}
+	if user.Password != "" {
+		current.Password = user.Password
+	}
+	current.UpdatedAt = time.Now()
+	r.store.data.users[id] = current
+	return nil
+}
+
+func (r *userRepository) DeleteUser(id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.data.users[id]; !ok {
+		return repository.ErrUserNotFound.Withf("user with ID %d not found", id)
+	}
+	r.deleteUser(id)
+	return nil
+}
+
+// deleteUser menghapus user beserta data miliknya, sama seperti ON DELETE CASCADE di Postgres.
+// Harus dipanggil saat mu sedang dikunci
+func (r *userRepository) deleteUser(id int) {
+	delete(r.store.data.users, id)
+
+	for tokenID, token := range r.store.data.refreshTokens {
+		if token.UserID == id {
+			delete(r.store.data.refreshTokens, tokenID)
+		}
+	}
+	for tokenID, token := range r.store.data.passwordResetTokens {
+		if token.UserID == id {
+			delete(r.store.data.passwordResetTokens, tokenID)
+		}
+	}
+	for reservationID, reservation := range r.store.data.reservations {
+		if reservation.UserID == id {
+			delete(r.store.data.reservations, reservationID)
+			delete(r.store.data.reservationTables, reservationID)
+			r.store.deleteReminders(reservationID)
+		}
+	}
+	for entryID, entry := range r.store.data.waitlist {
+		if entry.UserID == id {
+			delete(r.store.data.waitlist, entryID)
+		}
+	}
+}
+
+func (r *userRepository) GetAllUserByRole(role string) ([]models.User, error) {
+	users, _ := r.GetAllUser()
+
+	var result []models.User
+	for _, user := range users {
+		if user.Role == role {
+			result = append(result, user)
+		}
+	}
+	return result, nil
+}
+
+func (r *userRepository) GetTokenVersion(id int) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.data.users[id]
+	if !ok {
+		return 0, repository.ErrUserNotFound.Withf("user with Id %d not found", id)
+	}
+	return user.TokenVersion, nil
+}
+
+func (r *userRepository) IncrementTokenVersion(id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.data.users[id]
+	if !ok {
+		return repository.ErrUserNotFound.Withf("user with ID %d not found", id)
+	}
+	user.TokenVersion++
+	r.store.data.users[id] = user
+	return nil
+}
+
+func (r *userRepository) UpdateUserRole(id int, role string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.data.users[id]
+	if !ok {
+		return repository.ErrUserNotFound.Withf("user with ID %d not found", id)
+	}
+	user.Role = role
+	user.TokenVersion++
+	user.UpdatedAt = time.Now()
+	r.store.data.users[id] = user
+	return nil
+}
+
+func (r *userRepository) IsEmailVerified(id int) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.data.users[id]
+	return ok && user.IsEmailVerified(), nil
+}
+
+func (r *userRepository) MarkEmailVerified(id int, email string) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.data.users[id]
+	if !ok || user.Email != email || user.IsEmailVerified() {
+		return false, nil
+	}
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	r.store.data.users[id] = user
+	return true, nil
+}
+
+func (r *userRepository) DeleteUnverifiedUsers(createdBefore time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var deleted []int
+	for id, user := range r.store.data.users {
+		if !user.IsEmailVerified() && user.CreatedAt.Before(createdBefore) {
+			deleted = append(deleted, id)
+		}
+	}
+	for _, id := range deleted {
+		r.deleteUser(id)
+	}
+	return int64(len(deleted)), nil
+}
+
+func (r *userRepository) GetCalendarTokenHash(id int) (string, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.data.users[id]
+	if !ok {
+		return "", repository.ErrUserNotFound.Withf("user with Id %d not found", id)
+	}
+	if user.CalendarTokenHash == nil {
+		return "", nil
+	}
+	return *user.CalendarTokenHash, nil
+}
+
+func (r *userRepository) SetCalendarTokenHash(id int, hash string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.data.users[id]
+	if !ok {
+		return repository.ErrUserNotFound.Withf("user with ID %d not found", id)
+	}
+	user.CalendarTokenHash = &hash
+	r.store.data.users[id] = user
+	return nil
+}
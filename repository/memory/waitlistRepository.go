@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"sort"
+	"time"
+	"wereserve/models"
+	"wereserve/repository"
+)
+
+type waitlistRepository struct {
+	store *Store
+}
+
+// sortedEntries mengembalikan entry yang lolos filter beserta user-nya, diurutkan dari yang paling lama menunggu.
+// Harus dipanggil saat mu sedang dikunci
+func (r *waitlistRepository) sortedEntries(filter func(models.WaitlistEntry) bool) []models.WaitlistEntry {
+	entries := []models.WaitlistEntry{}
+	for _, entry := range r.store.data.waitlist {
+		if filter(entry) {
+			entry.User = withoutPassword(r.store.data.users[entry.UserID])
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].ID < entries[j].ID
+		}
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+	return entries
+}
+
+func (r *waitlistRepository) CreateEntry(entry *models.WaitlistEntry) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	entry.ID = r.store.newID()
+	if entry.Status == "" {
+		entry.Status = models.WaitlistStatusWaiting
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = now
+	}
+	entry.UpdatedAt = now
+
+	stored := *entry
+	stored.User = models.User{}
+	r.store.data.waitlist[entry.ID] = stored
+	return nil
+}
+
+func (r *waitlistRepository) GetAllEntries() ([]models.WaitlistEntry, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return r.sortedEntries(func(models.WaitlistEntry) bool { return true }), nil
+}
+
+func (r *waitlistRepository) GetEntriesByUser(userID int) ([]models.WaitlistEntry, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return r.sortedEntries(func(entry models.WaitlistEntry) bool { return entry.UserID == userID }), nil
+}
+
+func (r *waitlistRepository) GetEntryByID(id int) (*models.WaitlistEntry, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	entry, ok := r.store.data.waitlist[id]
+	if !ok {
+		return nil, repository.ErrWaitlistEntryNotFound.Withf("waitlist entry with ID %d not found", id)
+	}
+	entry.User = withoutPassword(r.store.data.users[entry.UserID])
+	return &entry, nil
+}
+
+func (r *waitlistRepository) GetCandidates(start time.Time, maxPartySize int) ([]models.WaitlistEntry, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	entries := r.sortedEntries(func(entry models.WaitlistEntry) bool {
+		return entry.Status == models.WaitlistStatusWaiting &&
+			!entry.DesiredStart.After(start) && !entry.DesiredEnd.Before(start) &&
+			entry.PartySize <= maxPartySize
+	})
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].PartySize > entries[j].PartySize })
+	return entries, nil
+}
+
+func (r *waitlistRepository) GetExpiredOffers(now time.Time) ([]models.WaitlistEntry, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return r.sortedEntries(func(entry models.WaitlistEntry) bool { return entry.IsOfferExpired(now) }), nil
+}
+
+func (r *waitlistRepository) MarkOffered(id int, tableID, combinationID *int, offeredAt, expiresAt time.Time) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	entry, ok := r.store.data.waitlist[id]
+	if !ok || entry.Status != models.WaitlistStatusWaiting {
+		return false, nil
+	}
+	entry.Status = models.WaitlistStatusOffered
+	entry.OfferedTableID = tableID
+	entry.OfferedCombinationID = combinationID
+	entry.OfferedDateTime = &offeredAt
+	entry.OfferExpiresAt = &expiresAt
+	entry.UpdatedAt = time.Now()
+	r.store.data.waitlist[id] = entry
+	return true, nil
+}
+
+func (r *waitlistRepository) MarkAccepted(id, reservationID int) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	entry, ok := r.store.data.waitlist[id]
+	if !ok || entry.Status != models.WaitlistStatusOffered {
+		return false, nil
+	}
+	entry.Status = models.WaitlistStatusAccepted
+	entry.ReservationID = &reservationID
+	entry.UpdatedAt = time.Now()
+	r.store.data.waitlist[id] = entry
+	return true, nil
+}
+
+func (r *waitlistRepository) UpdateStatus(id int, from, to string) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	entry, ok := r.store.data.waitlist[id]
+	if !ok || entry.Status != from {
+		return false, nil
+	}
+	entry.Status = to
+	entry.UpdatedAt = time.Now()
+	r.store.data.waitlist[id] = entry
+	return true, nil
+}
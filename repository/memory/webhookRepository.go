@@ -0,0 +1,192 @@
+package memory
+
+import (
+	"sort"
+	"time"
+	"wereserve/models"
+	"wereserve/repository"
+)
+
+type webhookRepository struct {
+	store *Store
+}
+
+func (r *webhookRepository) CreateSubscription(subscription *models.WebhookSubscription) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	subscription.ID = r.store.newID()
+	subscription.CreatedAt = now
+	subscription.UpdatedAt = now
+	r.store.data.webhookSubscriptions[subscription.ID] = *subscription
+	return nil
+}
+
+func (r *webhookRepository) GetSubscriptions() ([]models.WebhookSubscription, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	subscriptions := []models.WebhookSubscription{}
+	for _, subscription := range r.store.data.webhookSubscriptions {
+		subscriptions = append(subscriptions, subscription)
+	}
+	sort.Slice(subscriptions, func(i, j int) bool { return subscriptions[i].ID < subscriptions[j].ID })
+	return subscriptions, nil
+}
+
+func (r *webhookRepository) GetSubscriptionByID(id int) (*models.WebhookSubscription, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	subscription, ok := r.store.data.webhookSubscriptions[id]
+	if !ok {
+		return nil, repository.ErrWebhookSubscriptionNotFound.Withf("webhook subscription with ID %d not found", id)
+	}
+	return &subscription, nil
+}
+
+func (r *webhookRepository) UpdateSubscription(id int, subscription *models.WebhookSubscription) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current, ok := r.store.data.webhookSubscriptions[id]
+	if !ok {
+		return repository.ErrWebhookSubscriptionNotFound.Withf("webhook subscription with ID %d not found", id)
+	}
+	if subscription.URL != "" {
+		current.URL = subscription.URL
+	}
+	if subscription.Secret != "" {
+		current.Secret = subscription.Secret
+	}
+	if subscription.EventTypes != "" {
+		current.EventTypes = subscription.EventTypes
+	}
+	current.Active = subscription.Active
+	current.UpdatedAt = time.Now()
+	r.store.data.webhookSubscriptions[id] = current
+	return nil
+}
+
+func (r *webhookRepository) DeleteSubscription(id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.data.webhookSubscriptions[id]; !ok {
+		return repository.ErrWebhookSubscriptionNotFound.Withf("webhook subscription with ID %d not found", id)
+	}
+	delete(r.store.data.webhookSubscriptions, id)
+	for deliveryID, delivery := range r.store.data.webhookDeliveries {
+		if delivery.SubscriptionID == id {
+			delete(r.store.data.webhookDeliveries, deliveryID)
+		}
+	}
+	return nil
+}
+
+func (r *webhookRepository) EnqueueDeliveries(eventID, eventType, payload string) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	var created int64
+	for _, subscription := range r.store.data.webhookSubscriptions {
+		if !subscription.Active || !subscription.Subscribes(eventType) {
+			continue
+		}
+		id := r.store.newID()
+		r.store.data.webhookDeliveries[id] = models.WebhookDelivery{
+			ID:             id,
+			SubscriptionID: subscription.ID,
+			EventID:        eventID,
+			EventType:      eventType,
+			Payload:        payload,
+			Status:         models.WebhookDeliveryPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		created++
+	}
+	return created, nil
+}
+
+func (r *webhookRepository) ClaimDueDeliveries(now time.Time, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	deliveries := []models.WebhookDelivery{}
+	for _, delivery := range r.store.data.webhookDeliveries {
+		if delivery.Status == models.WebhookDeliveryPending && !delivery.NextAttemptAt.After(now) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		if deliveries[i].NextAttemptAt.Equal(deliveries[j].NextAttemptAt) {
+			return deliveries[i].ID < deliveries[j].ID
+		}
+		return deliveries[i].NextAttemptAt.Before(deliveries[j].NextAttemptAt)
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	for i, delivery := range deliveries {
+		delivery.NextAttemptAt = now.Add(lease)
+		r.store.data.webhookDeliveries[delivery.ID] = delivery
+		deliveries[i].Subscription = r.store.data.webhookSubscriptions[delivery.SubscriptionID]
+	}
+	return deliveries, nil
+}
+
+func (r *webhookRepository) MarkDelivered(id int, responseStatus int) error {
+	return r.updateDelivery(id, func(delivery *models.WebhookDelivery) {
+		now := time.Now()
+		delivery.Status = models.WebhookDeliveryDelivered
+		delivery.ResponseStatus = &responseStatus
+		delivery.DeliveredAt = &now
+		delivery.LastError = nil
+	})
+}
+
+func (r *webhookRepository) MarkDeliveryFailed(id int, attempts int, nextAttemptAt time.Time, responseStatus *int, lastError string, dead bool) error {
+	return r.updateDelivery(id, func(delivery *models.WebhookDelivery) {
+		delivery.Status = models.WebhookDeliveryPending
+		if dead {
+			delivery.Status = models.WebhookDeliveryDead
+		}
+		delivery.Attempts = attempts
+		delivery.NextAttemptAt = nextAttemptAt
+		delivery.ResponseStatus = responseStatus
+		delivery.LastError = &lastError
+	})
+}
+
+func (r *webhookRepository) updateDelivery(id int, change func(delivery *models.WebhookDelivery)) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delivery, ok := r.store.data.webhookDeliveries[id]
+	if !ok {
+		return repository.ErrWebhookDeliveryNotFound.Withf("webhook delivery with ID %d not found", id)
+	}
+	change(&delivery)
+	delivery.UpdatedAt = time.Now()
+	r.store.data.webhookDeliveries[id] = delivery
+	return nil
+}
+
+func (r *webhookRepository) GetDeliveries(subscriptionID int) ([]models.WebhookDelivery, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	deliveries := []models.WebhookDelivery{}
+	for _, delivery := range r.store.data.webhookDeliveries {
+		if delivery.SubscriptionID == subscriptionID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID > deliveries[j].ID })
+	return deliveries, nil
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"wereserve/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type outboxRepository struct {
+	DB *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{DB: db}
+}
+
+func (r *outboxRepository) CreateMessage(message *models.OutboxMessage) error {
+	err := r.DB.Create(message).Error
+	if err != nil {
+		return fmt.Errorf("failed to save outbox message: %w", err)
+	}
+	return nil
+}
+
+// ClaimDueMessages mengambil pesan pending yang sudah waktunya dikirim lalu menggeser next_attempt_at sejauh lease,
+// supaya worker lain tidak mengambil pesan yang sama. Jika worker berhenti sebelum selesai, pesan diambil lagi setelah lease habis
+func (r *outboxRepository) ClaimDueMessages(now time.Time, limit int, lease time.Duration) ([]models.OutboxMessage, error) {
+	var messages []models.OutboxMessage
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.OutboxStatusPending, now).
+			Order("next_attempt_at ASC, id ASC").Limit(limit).Find(&messages).Error
+		if err != nil || len(messages) == 0 {
+			return err
+		}
+
+		ids := make([]int, len(messages))
+		for i, message := range messages {
+			ids[i] = message.ID
+		}
+		return tx.Model(&models.OutboxMessage{}).Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+	return messages, nil
+}
+
+func (r *outboxRepository) MarkSent(id int, payload string) error {
+	now := time.Now()
+	err := r.DB.Model(&models.OutboxMessage{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":     models.OutboxStatusSent,
+		"payload":    payload,
+		"sent_at":    now,
+		"last_error": nil,
+		"updated_at": now,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox message %d as sent: %w", id, err)
+	}
+	return nil
+}
+
+// MarkFailed mencatat percobaan yang gagal. Jika dead, pesan tidak dicoba lagi sampai admin melakukan retry
+func (r *outboxRepository) MarkFailed(id int, attempts int, nextAttemptAt time.Time, lastError string, dead bool, payload string) error {
+	status := models.OutboxStatusPending
+	if dead {
+		status = models.OutboxStatusDead
+	}
+
+	err := r.DB.Model(&models.OutboxMessage{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          status,
+		"attempts":        attempts,
+		"next_attempt_at": nextAttemptAt,
+		"last_error":      lastError,
+		"payload":         payload,
+		"updated_at":      time.Now(),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox message %d as failed: %w", id, err)
+	}
+	return nil
+}
+
+// GetMessages mengembalikan pesan terbaru lebih dulu. status kosong berarti semua status
+func (r *outboxRepository) GetMessages(status string) ([]models.OutboxMessage, error) {
+	var messages []models.OutboxMessage
+	query := r.DB.Order("created_at DESC, id DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch outbox messages: %w", err)
+	}
+	return messages, nil
+}
+
+func (r *outboxRepository) GetMessageByID(id int) (*models.OutboxMessage, error) {
+	var message models.OutboxMessage
+	err := r.DB.First(&message, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOutboxMessageNotFound.Withf("outbox message with ID %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to fetch outbox message with ID %d: %w", id, err)
+	}
+	return &message, nil
+}
+
+// RetryMessage mengembalikan pesan dead ke antrian dengan jumlah percobaan dari nol. false jika pesan tidak berstatus dead
+func (r *outboxRepository) RetryMessage(id int) (bool, error) {
+	now := time.Now()
+	result := r.DB.Model(&models.OutboxMessage{}).Where("id = ? AND status = ?", id, models.OutboxStatusDead).
+		Updates(map[string]interface{}{
+			"status":          models.OutboxStatusPending,
+			"attempts":        0,
+			"next_attempt_at": now,
+			"updated_at":      now,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to retry outbox message %d: %w", id, result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
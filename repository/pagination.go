@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+)
+
+// Batas jumlah baris per halaman di endpoint list
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// Field yang boleh dipakai di parameter sort. Nama field sama dengan nama kolom di tabel masing-masing
+var (
+	ReservationSortFields = []string{"id", "reservation_datetime", "number_of_people", "status", "created_at"}
+	TableSortFields       = []string{"id", "table_name", "capacity", "created_at"}
+	UserSortFields        = []string{"id", "name", "email", "created_at"}
+)
+
+// Page mengatur offset pagination dan urutan hasil. Sort harus salah satu dari *SortFields,
+// urutan id selalu ditambahkan supaya hasil stabil di antara halaman
+type Page struct {
+	Limit  int
+	Offset int
+	Sort   string
+	Desc   bool
+}
+
+// ReservationFilter menyaring reservasi untuk GET /api/reservation. Field kosong tidak dipakai sebagai filter
+type ReservationFilter struct {
+	// Rentang waktu mulai reservasi [From, To)
+	From    *time.Time
+	To      *time.Time
+	TableID int
+	UserID  int
+	Status  string
+	Page
+}
+
+type TableFilter struct {
+	MinCapacity int
+	Page
+}
+
+type UserFilter struct {
+	Role string
+	Page
+}
+
+// IsSortField memastikan field sort ada di whitelist sebelum dipakai di query
+func IsSortField(fields []string, field string) bool {
+	for _, allowed := range fields {
+		if allowed == field {
+			return true
+		}
+	}
+	return false
+}
+
+// orderBy menyusun klausa ORDER BY untuk Page. Field di luar whitelist diganti dengan id
+func orderBy(table string, fields []string, page Page) string {
+	column := page.Sort
+	if !IsSortField(fields, column) {
+		column = "id"
+	}
+	direction := "ASC"
+	if page.Desc {
+		direction = "DESC"
+	}
+
+	order := fmt.Sprintf("%s.%s %s", table, column, direction)
+	if column != "id" {
+		order += fmt.Sprintf(", %s.id %s", table, direction)
+	}
+	return order
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"wereserve/models"
+
+	"gorm.io/gorm"
+)
+
+type passwordResetTokenRepository struct {
+	DB *gorm.DB
+}
+
+func NewPasswordResetTokenRepository(db *gorm.DB) PasswordResetTokenRepository {
+	return &passwordResetTokenRepository{DB: db}
+}
+
+func (r *passwordResetTokenRepository) CreatePasswordResetToken(token *models.PasswordResetToken) error {
+	err := r.DB.Create(token).Error
+	if err != nil {
+		return fmt.Errorf("failed to save password reset token: %w", err)
+	}
+	return nil
+}
+
+// Ambil token reset berdasarkan hash-nya, nil jika tidak ada
+func (r *passwordResetTokenRepository) GetPasswordResetTokenByHash(hash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	err := r.DB.Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch password reset token: %w", err)
+	}
+	return &token, nil
+}
+
+// MarkPasswordResetTokenUsed menandai token sudah dipakai. false dikembalikan jika token sudah dipakai lebih dulu
+func (r *passwordResetTokenRepository) MarkPasswordResetTokenUsed(id int) (bool, error) {
+	result := r.DB.Model(&models.PasswordResetToken{}).Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to mark password reset token as used: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Token reset lama yang belum dipakai tidak berlaku lagi saat user meminta token baru atau sudah berhasil reset
+func (r *passwordResetTokenRepository) InvalidatePasswordResetTokens(userID int) error {
+	err := r.DB.Model(&models.PasswordResetToken{}).Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error
+	if err != nil {
+		return fmt.Errorf("failed to invalidate password reset tokens: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"wereserve/models"
+
+	"gorm.io/gorm"
+)
+
+type refreshTokenRepository struct {
+	DB *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{DB: db}
+}
+
+func (r *refreshTokenRepository) CreateRefreshToken(token *models.RefreshToken) error {
+	err := r.DB.Create(token).Error
+	if err != nil {
+		return fmt.Errorf("failed to save refresh token: %w", err)
+	}
+	return nil
+}
+
+// Ambil refresh token berdasarkan hash-nya, nil jika tidak ada
+func (r *refreshTokenRepository) GetRefreshTokenByHash(hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.DB.Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch refresh token: %w", err)
+	}
+	return &token, nil
+}
+
+// RevokeRefreshToken me-revoke token yang belum di-revoke. false dikembalikan jika token sudah di-revoke lebih dulu,
+// misalnya karena token yang sama dipakai dua kali secara bersamaan
+func (r *refreshTokenRepository) RevokeRefreshToken(id int, replacedByID *int) (bool, error) {
+	result := r.DB.Model(&models.RefreshToken{}).Where("id = ? AND revoked_at IS NULL", id).Updates(map[string]interface{}{
+		"revoked_at":     time.Now(),
+		"replaced_by_id": replacedByID,
+	})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to revoke refresh token: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Revoke semua token dalam satu sesi login
+func (r *refreshTokenRepository) RevokeFamily(familyID string) error {
+	err := r.DB.Model(&models.RefreshToken{}).Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+	return nil
+}
+
+// Revoke semua token milik user di semua perangkat
+func (r *refreshTokenRepository) RevokeAllForUser(userID int) error {
+	err := r.DB.Model(&models.RefreshToken{}).Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+	"wereserve/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type reminderRepository struct {
+	DB *gorm.DB
+}
+
+func NewReminderRepository(db *gorm.DB) ReminderRepository {
+	return &reminderRepository{DB: db}
+}
+
+// Ambil reservasi pending atau confirmed yang dimulai dalam offsetMinutes ke depan dan belum mendapat pengingat untuk offset tersebut
+func (r *reminderRepository) GetReservationsDueForReminder(offsetMinutes int, now time.Time) ([]models.Reservation, error) {
+	var reservations []models.Reservation
+	err := r.DB.Preload("User").Preload("GuestContact").Preload("Table").Preload("Tables").
+		Where("status IN ?", []string{models.ReservationStatusPending, models.ReservationStatusConfirmed}).
+		Where("reservation_datetime > ? AND reservation_datetime <= ?", now, now.Add(time.Duration(offsetMinutes)*time.Minute)).
+		Where("NOT EXISTS (SELECT 1 FROM reservation_reminders rr WHERE rr.reservation_id = reservations.id AND rr.offset_minutes = ?)", offsetMinutes).
+		Order("reservation_datetime").
+		Find(&reservations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch reservations due for reminder: %w", err)
+	}
+
+	return reservations, nil
+}
+
+// Catat pengingat yang dikirim. false jika pengingat untuk reservasi dan offset ini sudah pernah dicatat
+func (r *reminderRepository) CreateReminder(reservationID, offsetMinutes int) (bool, error) {
+	reminder := models.ReservationReminder{ReservationID: reservationID, OffsetMinutes: offsetMinutes, SentAt: time.Now()}
+	result := r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&reminder)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to save reservation reminder: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
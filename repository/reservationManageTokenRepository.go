@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"wereserve/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type reservationManageTokenRepository struct {
+	DB *gorm.DB
+}
+
+func NewReservationManageTokenRepository(db *gorm.DB) ReservationManageTokenRepository {
+	return &reservationManageTokenRepository{DB: db}
+}
+
+// SaveReservationManageToken mengganti token reservasi yang sudah ada, sehingga link lama langsung tidak berlaku
+func (r *reservationManageTokenRepository) SaveReservationManageToken(token *models.ReservationManageToken) error {
+	err := r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "reservation_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"guest_contact_id", "token_hash", "expires_at", "created_at"}),
+	}).Create(token).Error
+	if err != nil {
+		return fmt.Errorf("failed to save reservation manage token: %w", err)
+	}
+	return nil
+}
+
+func (r *reservationManageTokenRepository) GetReservationManageTokenByHash(hash string) (*models.ReservationManageToken, error) {
+	var token models.ReservationManageToken
+	err := r.DB.Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get reservation manage token: %w", err)
+	}
+	return &token, nil
+}
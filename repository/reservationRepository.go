@@ -6,42 +6,93 @@ import (
 	"time"
 	"wereserve/models"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-
-type ReservationRepository struct {
+type reservationRepository struct {
 	DB *gorm.DB
 }
 
-func NewReservationRepository(db *gorm.DB) *ReservationRepository {
-	return &ReservationRepository{DB: db}
+func NewReservationRepository(db *gorm.DB) ReservationRepository {
+	return &reservationRepository{DB: db}
 }
 
-func (r *ReservationRepository) GetAllReservation() ([]models.Reservation, error) {
-	var reservations []models.Reservation	
-	err := r.DB.Preload("User").Preload("Table").Find(&reservations)
-	if err.Error != nil {
-		return nil, fmt.Errorf("failed to fetch reservations : %w", err.Error)
+// ListReservations mengembalikan satu halaman reservasi dan jumlah semua reservasi yang lolos filter.
+// Relasi hanya di-preload untuk reservasi di halaman tersebut
+func (r *reservationRepository) ListReservations(filter ReservationFilter) ([]models.Reservation, int64, error) {
+	var total int64
+	if err := r.DB.Model(&models.Reservation{}).Scopes(reservationFilterScope(filter)).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count reservations: %w", err)
 	}
 
-	return reservations, nil
+	var reservations []models.Reservation
+	err := r.DB.Scopes(reservationFilterScope(filter)).
+		Preload("User").Preload("GuestContact").Preload("Table").Preload("Tables").
+		Order(orderBy("reservations", ReservationSortFields, filter.Page)).
+		Limit(filter.Limit).Offset(filter.Offset).
+		Find(&reservations).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch reservations : %w", err)
+	}
+
+	return reservations, total, nil
+}
+
+func reservationFilterScope(filter ReservationFilter) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if filter.From != nil {
+			db = db.Where("reservations.reservation_datetime >= ?", *filter.From)
+		}
+		if filter.To != nil {
+			db = db.Where("reservations.reservation_datetime < ?", *filter.To)
+		}
+		if filter.UserID != 0 {
+			db = db.Where("reservations.user_id = ?", filter.UserID)
+		}
+		if filter.Status != "" {
+			db = db.Where("reservations.status = ?", filter.Status)
+		}
+		// Reservasi kombinasi meja dicari lewat reservation_tables supaya semua mejanya ikut terhitung
+		if filter.TableID != 0 {
+			db = db.Where("EXISTS (SELECT 1 FROM reservation_tables rt WHERE rt.reservation_id = reservations.id AND rt.table_id = ?)", filter.TableID)
+		}
+		return db
+	}
 }
 
-func (r *ReservationRepository) GetReservationDetail(id int) (*models.Reservation, error) {
+func (r *reservationRepository) GetReservationDetail(id int) (*models.Reservation, error) {
 	var reservation models.Reservation
-	err := r.DB.Preload("User").Preload("Table").First(&reservation, id).Error
+	err := r.DB.Preload("User").Preload("GuestContact").Preload("Table").Preload("Tables").First(&reservation, id).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReservationNotFound.Withf("reservation with ID %d not found", id)
+		}
 		return nil, err
 	}
 
 	return &reservation, nil
 }
 
+// GetReservationForUpdate mengunci baris reservasi dengan SELECT ... FOR UPDATE lalu mengambil detailnya.
+// Hanya berguna di dalam transaksi
+func (r *reservationRepository) GetReservationForUpdate(id int) (*models.Reservation, error) {
+	var locked models.Reservation
+	err := r.DB.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&locked, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReservationNotFound.Withf("reservation with ID %d not found", id)
+		}
+		return nil, err
+	}
+
+	return r.GetReservationDetail(id)
+}
 
-func (r *ReservationRepository) GetReservationByUserLogin(userID int) ([]models.Reservation, error) {
+func (r *reservationRepository) GetReservationByUserLogin(userID int) ([]models.Reservation, error) {
 	var selfReservations []models.Reservation
-	err := r.DB.Preload("User").Preload("Table").Where("user_id = ?", userID).Find(&selfReservations).Error
+	err := r.DB.Preload("User").Preload("GuestContact").Preload("Table").Preload("Tables").Where("user_id = ?", userID).Find(&selfReservations).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch reservations: %w", err)
 	}
@@ -49,80 +100,236 @@ func (r *ReservationRepository) GetReservationByUserLogin(userID int) ([]models.
 	return selfReservations, nil
 }
 
-func (r *ReservationRepository) IsReservationExists(tableID int, reservationDatetime time.Time) (bool, error) {
+// IsReservationOverlap mengecek apakah ada reservasi lain di salah satu tableIDs yang rentang waktunya
+// bersinggungan dengan [start, end). excludeID dipakai saat update supaya reservasi itu sendiri tidak ikut dihitung
+func (r *reservationRepository) IsReservationOverlap(tableIDs []int, start, end time.Time, excludeID int) (bool, error) {
 	var count int64
-	// query untuk menghitung jumlah reservasi yang sudah ada di tableID dan reservationDatetime
 
-	err := r.DB.Model(&models.Reservation{}).Where("table_id = ? AND reservation_datetime = ? ",tableID, reservationDatetime).Count(&count).Error
+	err := r.DB.Model(&models.Reservation{}).
+		Joins("JOIN reservation_tables rt ON rt.reservation_id = reservations.id").
+		Where("rt.table_id IN ? AND reservations.id <> ?", tableIDs, excludeID).
+		Where("reservations.reservation_datetime < ? AND reservations.end_datetime > ?", end, start).
+		Where("reservations.status IN ?", models.ActiveReservationStatuses).
+		Count(&count).Error
 
 	if err != nil {
-		return false, fmt.Errorf("failed to check reservation existance: %w", err)
+		return false, fmt.Errorf("failed to check reservation overlap: %w", err)
 	}
 
 	return count > 0, nil
-} 
+}
 
+// Ambil semua reservasi yang rentang waktunya bersinggungan dengan [from, to)
+func (r *reservationRepository) GetReservationsInRange(from, to time.Time) ([]models.Reservation, error) {
+	var reservations []models.Reservation
+	err := r.DB.Preload("Tables").Where("reservation_datetime < ? AND end_datetime > ?", to, from).
+		Where("status IN ?", models.ActiveReservationStatuses).
+		Find(&reservations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch reservations: %w", err)
+	}
 
+	return reservations, nil
+}
 
-func (r *ReservationRepository) CreateReservation(reservation *models.Reservation) error {
-	reservationExists, err := r.IsReservationExists(reservation.TableID, reservation.ReservationDateTime)
+// Ambil reservasi aktif yang sedang berjalan pada waktu at, atau yang tamunya sudah duduk
+func (r *reservationRepository) GetCurrentReservations(at time.Time) ([]models.Reservation, error) {
+	var reservations []models.Reservation
+	err := r.DB.Preload("Tables").Where("status = ?", models.ReservationStatusSeated).
+		Or("status IN ? AND reservation_datetime <= ? AND end_datetime > ?",
+			[]string{models.ReservationStatusPending, models.ReservationStatusConfirmed}, at, at).
+		Find(&reservations).Error
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to fetch current reservations: %w", err)
 	}
 
-	if reservationExists {
-		return errors.New("reservasi sudah terdaftar sebelumnya")
+	return reservations, nil
+}
+
+// Email dibandingkan tanpa membedakan huruf besar kecil, nomor telepon kosong tidak ikut dicocokkan
+func (r *reservationRepository) CountUpcomingGuestReservations(email, phone string, from time.Time) (int64, error) {
+	var count int64
+	err := r.DB.Model(&models.Reservation{}).
+		Joins("JOIN guest_contacts gc ON gc.id = reservations.guest_contact_id").
+		Where("(gc.email <> '' AND LOWER(gc.email) = LOWER(?)) OR (gc.phone <> '' AND gc.phone = ?)", email, phone).
+		Where("reservations.reservation_datetime >= ?", from).
+		Where("reservations.status IN ?", models.ActiveReservationStatuses).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count guest reservations: %w", err)
 	}
 
-	err = r.DB.Create(reservation).Error
+	return count, nil
+}
+
+func (r *reservationRepository) GetUpcomingGuestReservationIDs(email string, from time.Time) ([]int, error) {
+	var ids []int
+	err := r.DB.Model(&models.Reservation{}).
+		Joins("JOIN guest_contacts gc ON gc.id = reservations.guest_contact_id").
+		Where("gc.email <> '' AND LOWER(gc.email) = LOWER(?)", email).
+		Where("reservations.reservation_datetime >= ?", from).
+		Where("reservations.status IN ?", models.ActiveReservationStatuses).
+		Order("reservations.reservation_datetime").
+		Pluck("reservations.id", &ids).Error
 	if err != nil {
+		return nil, fmt.Errorf("failed to get guest reservations: %w", err)
+	}
+
+	return ids, nil
+}
+
+// isOverlapViolation mendeteksi error dari exclusion constraint reservations_no_overlap dan reservation_tables_no_overlap
+func isOverlapViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23P01"
+}
+
+// replaceReservationTables mengganti isi reservation_tables untuk satu reservasi.
+// Rentang waktu dan status di reservation_tables diisi oleh trigger database
+func replaceReservationTables(db *gorm.DB, reservationID int, tableIDs []int) error {
+	if err := db.Exec(`DELETE FROM reservation_tables WHERE reservation_id = ?`, reservationID).Error; err != nil {
 		return err
 	}
+
+	for _, tableID := range tableIDs {
+		err := db.Exec(`INSERT INTO reservation_tables (reservation_id, table_id) VALUES (?, ?)`, reservationID, tableID).Error
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (r *ReservationRepository) DeleteReservation(id int) error {
-	sqlQuery := `DELETE FROM reservations WHERE id = $1`
-	result := r.DB.Exec(sqlQuery, id)
-	if result.Error != nil {
-		return result.Error
+func (r *reservationRepository) CreateReservation(reservation *models.Reservation) error {
+	if reservation.DurationMinutes == 0 {
+		reservation.DurationMinutes = models.DefaultReservationDuration
 	}
 
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("reservation with ID %d not found", id) 
+	tableIDs := reservation.TableIDs()
+	overlap, err := r.IsReservationOverlap(tableIDs, reservation.ReservationDateTime, reservation.EndTime(), 0)
+	if err != nil {
+		return err
+	}
+
+	if overlap {
+		return ErrReservationOverlap
 	}
 
+	// Reservasi dan relasi mejanya di reservation_tables disimpan dalam satu transaksi
+	err = r.DB.Transaction(func(tx *gorm.DB) error {
+		// Relasi tidak ikut disimpan, user dan kontak tamu sudah ada sebelum reservasi dibuat
+		if err := tx.Omit(clause.Associations).Create(reservation).Error; err != nil {
+			return err
+		}
+		return replaceReservationTables(tx, reservation.ID, tableIDs)
+	})
+	if err != nil {
+		if isOverlapViolation(err) {
+			return ErrReservationOverlap
+		}
+		return err
+	}
+	reservation.EndDateTime = reservation.EndTime()
 	return nil
 }
 
-
-
-func (r *ReservationRepository) UpdateReservation(id int, reservation *models.Reservation) error {
+func (r *reservationRepository) UpdateReservation(id int, reservation *models.Reservation) error {
 	var currentReservation models.Reservation
 	err := r.DB.First(&currentReservation, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("reservation tidak di temukan")
+			return ErrReservationNotFound
 		}
 		return err
 	}
 
-	// validasi 
-	if reservation.TableID != currentReservation.TableID || !reservation.ReservationDateTime.Equal(currentReservation.ReservationDateTime) {
-		exists, err := r.IsReservationExists(reservation.TableID, reservation.ReservationDateTime)
-		if err != nil {
-			return fmt.Errorf("gagal menvalidasi reservasi: %w", err)
-		}
-		if exists {
-			return errors.New("table_id dan reservation sudah digunakan oleh reservasi lain")
-		}
+	// validasi rentang waktu dengan nilai akhir setelah update
+	merged := currentReservation
+	if err := r.DB.Model(&currentReservation).Association("Tables").Find(&merged.Tables); err != nil {
+		return err
+	}
+	if len(reservation.Tables) > 0 {
+		merged.Tables = reservation.Tables
+	}
+	if !reservation.ReservationDateTime.IsZero() {
+		merged.ReservationDateTime = reservation.ReservationDateTime
+	}
+	if reservation.DurationMinutes != 0 {
+		merged.DurationMinutes = reservation.DurationMinutes
+	}
+
+	overlap, err := r.IsReservationOverlap(merged.TableIDs(), merged.ReservationDateTime, merged.EndTime(), id)
+	if err != nil {
+		return fmt.Errorf("gagal menvalidasi reservasi: %w", err)
+	}
+	if overlap {
+		return ErrReservationOverlap
 	}
 
 	//update
-	err = r.DB.Model(&currentReservation).Updates(reservation).Error
+	err = r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&currentReservation).Omit("Tables").Updates(reservation).Error; err != nil {
+			return err
+		}
+		// Reservasi hanya punya satu pemilik, kontak tamu dilepas saat reservasi dipindahkan ke user
+		if reservation.UserID != 0 && currentReservation.GuestContactID != nil {
+			if err := tx.Model(&currentReservation).Update("guest_contact_id", nil).Error; err != nil {
+				return err
+			}
+		}
+		if len(reservation.Tables) == 0 {
+			return nil
+		}
+
+		// Updates mengabaikan pointer nil, jadi combination_id di-set eksplisit saat meja diganti
+		if err := tx.Model(&currentReservation).Update("combination_id", reservation.CombinationID).Error; err != nil {
+			return err
+		}
+		return replaceReservationTables(tx, id, reservation.TableIDs())
+	})
 	if err != nil {
+		if isOverlapViolation(err) {
+			return ErrReservationOverlap
+		}
 		return fmt.Errorf("gagal update reservasi : %w", err)
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// Ubah status reservasi tanpa menyentuh field lain
+func (r *reservationRepository) UpdateReservationStatus(id int, status string) error {
+	result := r.DB.Model(&models.Reservation{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":     status,
+		"updated_at": time.Now(),
+	})
+	if result.Error != nil {
+		return fmt.Errorf("gagal update status reservasi : %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrReservationNotFound.Withf("reservation with ID %d not found", id)
+	}
+
+	return nil
+}
+
+// Batalkan reservasi dengan menyimpan status dan alasannya, data reservasi tetap disimpan
+func (r *reservationRepository) CancelReservation(id int, reason string) error {
+	now := time.Now()
+	result := r.DB.Model(&models.Reservation{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":              models.ReservationStatusCancelled,
+		"cancellation_reason": reason,
+		"cancelled_at":        now,
+		"updated_at":          now,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("gagal membatalkan reservasi : %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrReservationNotFound.Withf("reservation with ID %d not found", id)
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"wereserve/models"
+
+	"gorm.io/gorm"
+)
+
+type roleRepository struct {
+	DB *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{DB: db}
+}
+
+func (r *roleRepository) GetRoles() ([]models.Role, error) {
+	var roles []models.Role
+	if err := r.DB.Order("name ASC").Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch roles: %w", err)
+	}
+
+	var permissions []models.RolePermission
+	if err := r.DB.Order("permission ASC").Find(&permissions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch role permissions: %w", err)
+	}
+
+	byRole := make(map[string][]string, len(roles))
+	for _, permission := range permissions {
+		byRole[permission.RoleName] = append(byRole[permission.RoleName], permission.Permission)
+	}
+	for i := range roles {
+		roles[i].Permissions = byRole[roles[i].Name]
+		if roles[i].Permissions == nil {
+			roles[i].Permissions = []string{}
+		}
+	}
+	return roles, nil
+}
+
+func (r *roleRepository) GetRoleByName(name string) (*models.Role, error) {
+	var role models.Role
+	err := r.DB.Where("name = ?", name).First(&role).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound.Withf("role %q not found", name)
+		}
+		return nil, fmt.Errorf("failed to fetch role %q: %w", name, err)
+	}
+
+	role.Permissions, err = r.GetPermissions(name)
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// GetPermissions mengembalikan permission milik role. Role yang tidak dikenal tidak punya permission sama sekali
+func (r *roleRepository) GetPermissions(role string) ([]string, error) {
+	permissions := []string{}
+	err := r.DB.Model(&models.RolePermission{}).Where("role_name = ?", role).
+		Order("permission ASC").Pluck("permission", &permissions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch permissions of role %q: %w", role, err)
+	}
+	return permissions, nil
+}
+
+func (r *roleRepository) CreateRole(role *models.Role) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		var exists bool
+		if err := tx.Raw("SELECT EXISTS (SELECT 1 FROM roles WHERE name = ?)", role.Name).Scan(&exists).Error; err != nil {
+			return fmt.Errorf("failed to check role %q: %w", role.Name, err)
+		}
+		if exists {
+			return ErrRoleNameTaken.Withf("role %q already exists", role.Name)
+		}
+
+		if err := tx.Create(role).Error; err != nil {
+			return fmt.Errorf("failed to save role %q: %w", role.Name, err)
+		}
+		return replacePermissions(tx, role.Name, role.Permissions)
+	})
+}
+
+// UpdateRole mengganti deskripsi dan seluruh permission role
+func (r *roleRepository) UpdateRole(name string, role *models.Role) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Role{}).Where("name = ?", name).Updates(map[string]interface{}{
+			"description": role.Description,
+			"updated_at":  time.Now(),
+		})
+		if result.Error != nil {
+			return fmt.Errorf("failed to update role %q: %w", name, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrRoleNotFound.Withf("role %q not found", name)
+		}
+		return replacePermissions(tx, name, role.Permissions)
+	})
+}
+
+func replacePermissions(tx *gorm.DB, role string, permissions []string) error {
+	if err := tx.Where("role_name = ?", role).Delete(&models.RolePermission{}).Error; err != nil {
+		return fmt.Errorf("failed to clear permissions of role %q: %w", role, err)
+	}
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	rows := make([]models.RolePermission, 0, len(permissions))
+	for _, permission := range permissions {
+		rows = append(rows, models.RolePermission{RoleName: role, Permission: permission})
+	}
+	if err := tx.Create(&rows).Error; err != nil {
+		return fmt.Errorf("failed to save permissions of role %q: %w", role, err)
+	}
+	return nil
+}
+
+// DeleteRole menghapus role beserta permission-nya. Role yang masih dipakai user ditolak oleh foreign key,
+// jadi service harus memeriksa CountUsersWithRole lebih dulu
+func (r *roleRepository) DeleteRole(name string) error {
+	result := r.DB.Where("name = ?", name).Delete(&models.Role{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete role %q: %w", name, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrRoleNotFound.Withf("role %q not found", name)
+	}
+	return nil
+}
+
+func (r *roleRepository) CountUsersWithRole(name string) (int64, error) {
+	var count int64
+	if err := r.DB.Model(&models.User{}).Where("role = ?", name).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count users with role %q: %w", name, err)
+	}
+	return count, nil
+}
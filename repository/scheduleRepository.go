@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"wereserve/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type scheduleRepository struct {
+	DB *gorm.DB
+}
+
+func NewScheduleRepository(db *gorm.DB) ScheduleRepository {
+	return &scheduleRepository{DB: db}
+}
+
+func (r *scheduleRepository) GetOpeningHours() ([]models.OpeningHour, error) {
+	var hours []models.OpeningHour
+	err := r.DB.Order("day_of_week ASC").Find(&hours).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch opening hours: %w", err)
+	}
+	return hours, nil
+}
+
+// Ambil jam buka untuk satu hari, nil jika hari tersebut belum diatur
+func (r *scheduleRepository) GetOpeningHourByDay(day time.Weekday) (*models.OpeningHour, error) {
+	var hour models.OpeningHour
+	err := r.DB.Where("day_of_week = ?", int(day)).First(&hour).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch opening hour: %w", err)
+	}
+	return &hour, nil
+}
+
+// Simpan jam buka, baris dengan day_of_week yang sama akan ditimpa
+func (r *scheduleRepository) UpsertOpeningHours(hours []models.OpeningHour) error {
+	now := time.Now()
+	for i := range hours {
+		hours[i].UpdatedAt = now
+	}
+
+	err := r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "day_of_week"}},
+		DoUpdates: clause.AssignmentColumns([]string{"open_time", "close_time", "last_seating_time", "is_closed", "updated_at"}),
+	}).Create(&hours).Error
+	if err != nil {
+		return fmt.Errorf("failed to save opening hours: %w", err)
+	}
+	return nil
+}
+
+func (r *scheduleRepository) GetSetting() (*models.ScheduleSetting, error) {
+	var setting models.ScheduleSetting
+	err := r.DB.First(&setting, 1).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.ScheduleSetting{ID: 1, SlotMinutes: 15}, nil
+		}
+		return nil, fmt.Errorf("failed to fetch schedule setting: %w", err)
+	}
+	return &setting, nil
+}
+
+func (r *scheduleRepository) UpdateSlotMinutes(minutes int) error {
+	sqlQuery := `INSERT INTO schedule_settings (id, slot_minutes, updated_at) VALUES (1, $1, $2)
+		ON CONFLICT (id) DO UPDATE SET slot_minutes = EXCLUDED.slot_minutes, updated_at = EXCLUDED.updated_at`
+	err := r.DB.Exec(sqlQuery, minutes, time.Now()).Error
+	if err != nil {
+		return fmt.Errorf("failed to update schedule setting: %w", err)
+	}
+	return nil
+}
+
+// Ambil penutupan yang belum lewat, diurutkan dari yang paling dekat
+func (r *scheduleRepository) GetUpcomingClosures(from time.Time) ([]models.ScheduleClosure, error) {
+	var closures []models.ScheduleClosure
+	err := r.DB.Where("end_date >= ?", from.Format("2006-01-02")).Order("start_date ASC").Find(&closures).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch closures: %w", err)
+	}
+	return closures, nil
+}
+
+// Ambil penutupan yang mencakup tanggal tertentu, nil jika restoran tidak tutup
+func (r *scheduleRepository) GetClosureOn(date time.Time) (*models.ScheduleClosure, error) {
+	var closure models.ScheduleClosure
+	day := date.Format("2006-01-02")
+	err := r.DB.Where("start_date <= ? AND end_date >= ?", day, day).First(&closure).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch closure: %w", err)
+	}
+	return &closure, nil
+}
+
+func (r *scheduleRepository) CreateClosure(closure *models.ScheduleClosure) error {
+	err := r.DB.Create(closure).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *scheduleRepository) DeleteClosure(id int) error {
+	sqlQuery := `DELETE FROM schedule_closures WHERE id = $1`
+	result := r.DB.Exec(sqlQuery, id)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrClosureNotFound.Withf("closure with ID %d not found", id)
+	}
+
+	return nil
+}
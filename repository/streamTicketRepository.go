@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+	"wereserve/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type streamTicketRepository struct {
+	DB *gorm.DB
+}
+
+func NewStreamTicketRepository(db *gorm.DB) StreamTicketRepository {
+	return &streamTicketRepository{DB: db}
+}
+
+func (r *streamTicketRepository) CreateStreamTicket(ticket *models.StreamTicket) error {
+	if err := r.DB.Create(ticket).Error; err != nil {
+		return fmt.Errorf("failed to save stream ticket: %w", err)
+	}
+	return nil
+}
+
+// ConsumeStreamTicket memakai satu UPDATE ... RETURNING supaya tiket yang sama tidak bisa dipakai oleh dua request bersamaan
+func (r *streamTicketRepository) ConsumeStreamTicket(hash string, now time.Time) (*models.StreamTicket, error) {
+	var tickets []models.StreamTicket
+	result := r.DB.Model(&tickets).Clauses(clause.Returning{}).
+		Where("ticket_hash = ? AND used_at IS NULL AND expires_at > ?", hash, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to consume stream ticket: %w", result.Error)
+	}
+	if len(tickets) == 0 {
+		return nil, nil
+	}
+	return &tickets[0], nil
+}
+
+// Tiket yang sudah kedaluwarsa tidak berguna lagi, baik sudah dipakai maupun belum
+func (r *streamTicketRepository) DeleteExpiredStreamTickets(before time.Time) (int64, error) {
+	result := r.DB.Where("expires_at < ?", before).Delete(&models.StreamTicket{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired stream tickets: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
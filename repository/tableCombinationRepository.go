@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"wereserve/models"
+
+	"gorm.io/gorm"
+)
+
+type tableCombinationRepository struct {
+	DB *gorm.DB
+}
+
+func NewTableCombinationRepository(db *gorm.DB) TableCombinationRepository {
+	return &tableCombinationRepository{DB: db}
+}
+
+func (r *tableCombinationRepository) IsCombinationExists(name string) (bool, error) {
+	var exists bool
+	err := r.DB.Raw("SELECT EXISTS(SELECT 1 FROM table_combinations WHERE name = ?)", name).Scan(&exists).Error
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (r *tableCombinationRepository) GetAllCombinations() ([]models.TableCombination, error) {
+	var combinations []models.TableCombination
+	err := r.DB.Preload("Tables").Order("id ASC").Find(&combinations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch table combinations: %w", err)
+	}
+	return combinations, nil
+}
+
+func (r *tableCombinationRepository) GetCombinationByID(id int) (*models.TableCombination, error) {
+	var combination models.TableCombination
+	err := r.DB.Preload("Tables").First(&combination, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTableCombinationNotFound.Withf("table combination with ID %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to fetch table combination with ID %d: %w", id, err)
+	}
+	return &combination, nil
+}
+
+// Ambil kombinasi yang total kapasitas mejanya cukup untuk jumlah orang tertentu
+func (r *tableCombinationRepository) GetCombinationsByMinCapacity(capacity int) ([]models.TableCombination, error) {
+	combinations, err := r.GetAllCombinations()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []models.TableCombination
+	for _, combination := range combinations {
+		if combination.Capacity() >= capacity {
+			result = append(result, combination)
+		}
+	}
+	return result, nil
+}
+
+func (r *tableCombinationRepository) CreateCombination(combination *models.TableCombination) error {
+	exists, err := r.IsCombinationExists(combination.Name)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return ErrTableCombinationNameTaken
+	}
+
+	// Meja sudah ada, jadi hanya relasi di table_combination_tables yang dibuat
+	err = r.DB.Omit("Tables.*").Create(combination).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *tableCombinationRepository) DeleteCombination(id int) error {
+	sqlQuery := `DELETE FROM table_combinations WHERE id = $1`
+	result := r.DB.Exec(sqlQuery, id)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrTableCombinationNotFound.Withf("table combination with ID %d not found", id)
+	}
+
+	return nil
+}
@@ -7,19 +7,34 @@ import (
 	"wereserve/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-type TableRepository struct {
+type tableRepository struct {
 	DB *gorm.DB
 }
 
 
-func NewTableRepository(db *gorm.DB) *TableRepository {
-	return &TableRepository{DB: db}
+func NewTableRepository(db *gorm.DB) TableRepository {
+	return &tableRepository{DB: db}
 }
 
+// LockTables mengunci baris meja dengan SELECT ... FOR UPDATE sampai transaksi selesai.
+// Baris dikunci berurutan berdasarkan id supaya dua transaksi tidak saling menunggu (deadlock)
+func (r *tableRepository) LockTables(ids []int) error {
+	var tables []models.Table
+	err := r.DB.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", ids).Order("id ASC").Find(&tables).Error
+	if err != nil {
+		return fmt.Errorf("failed to lock tables: %w", err)
+	}
+	if len(tables) != len(ids) {
+		return ErrTableNotFound.Withf("failed to fetch table: some of tables %v not found", ids)
+	}
+	return nil
+}
 
-func (r *TableRepository) IsTableExists(tableName string) (bool, error) {
+
+func (r *tableRepository) IsTableExists(tableName string) (bool, error) {
 	var tableExists bool
 	err := r.DB.Raw("SELECT EXISTS(SELECT 1 FROM tables WHERE table_name = ?)",tableName).Scan(&tableExists).Error
 	if err != nil {
@@ -28,7 +43,7 @@ func (r *TableRepository) IsTableExists(tableName string) (bool, error) {
 	return tableExists, nil
 }
 
-func (r *TableRepository) GetAllTables() ([]models.Table, error) {
+func (r *tableRepository) GetAllTables() ([]models.Table, error) {
 	var tables []models.Table
 	err := r.DB.Raw("SELECT * FROM tables").Scan(&tables).Error
 	if err != nil {
@@ -37,19 +52,41 @@ func (r *TableRepository) GetAllTables() ([]models.Table, error) {
 	return tables, nil
 } 
 
-func (r *TableRepository) GetTableByID(id int) (*models.Table, error) {
+func (r *tableRepository) ListTables(filter TableFilter) ([]models.Table, int64, error) {
+	query := func() *gorm.DB {
+		db := r.DB.Model(&models.Table{})
+		if filter.MinCapacity > 0 {
+			db = db.Where("capacity >= ?", filter.MinCapacity)
+		}
+		return db
+	}
+
+	var total int64
+	if err := query().Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count tables: %w", err)
+	}
+
+	var tables []models.Table
+	err := query().Order(orderBy("tables", TableSortFields, filter.Page)).Limit(filter.Limit).Offset(filter.Offset).Find(&tables).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch tables: %w", err)
+	}
+	return tables, total, nil
+}
+
+func (r *tableRepository) GetTableByID(id int) (*models.Table, error) {
 	var table models.Table
     err := r.DB.First(&table, id).Error
     if err != nil {
         if errors.Is(err, gorm.ErrRecordNotFound) {
-            return nil, fmt.Errorf("table with ID %d not found", id)
+            return nil, ErrTableNotFound.Withf("table with ID %d not found", id)
         }
         return nil, fmt.Errorf("failed to fetch table with ID %d: %w", id, err)
     }
     return &table, nil
 }
 
-func (r *TableRepository) GetTableByStatus(status string) ([]models.Table, error) {
+func (r *tableRepository) GetTableByStatus(status string) ([]models.Table, error) {
 	var tables []models.Table
 	// err := r.DB.Raw("SELECT * FROM tables WHERE status = $1", status).Scan(&tables).Error
 	err := r.DB.Where("status = ?",status).Find(&tables).Error
@@ -61,21 +98,31 @@ func (r *TableRepository) GetTableByStatus(status string) ([]models.Table, error
 	return tables, nil 
 }
 
+// Ambil semua meja yang kapasitasnya cukup untuk jumlah orang tertentu
+func (r *tableRepository) GetTablesByMinCapacity(capacity int) ([]models.Table, error) {
+	var tables []models.Table
+	err := r.DB.Where("capacity >= ?", capacity).Order("capacity ASC, id ASC").Find(&tables).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tables: %w", err)
+	}
+
+	return tables, nil
+}
 
-func (r *TableRepository) CreateTable(table *models.Table) error {
+func (r *tableRepository) CreateTable(table *models.Table) error {
 	result := r.DB.FirstOrCreate(&table, models.Table{TableName: table.TableName})
 	if result.Error != nil {
 		return result.Error
 	} 
 
 	if result.RowsAffected == 0 {
-		return errors.New("meja dengan nomor tersebut telah digunakan")
+		return ErrTableNameTaken.Withf("meja dengan nomor tersebut telah digunakan")
 	}
 
 	return nil
 }
 
-func (r *TableRepository) DeleteTable(id int) error {
+func (r *tableRepository) DeleteTable(id int) error {
 	sqlQuery := `DELETE FROM tables WHERE id = $1`
 	result := r.DB.Exec(sqlQuery, id)
 
@@ -85,19 +132,19 @@ func (r *TableRepository) DeleteTable(id int) error {
 
 	// validate if err
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("tables with id %d not found", id)
+		return ErrTableNotFound.Withf("tables with id %d not found", id)
 	}
 
 	return nil
 }
 
 // update Table
-func (r *TableRepository) UpdateTable(id int, table *models.Table) error {
+func (r *tableRepository) UpdateTable(id int, table *models.Table) error {
 	var currentTable models.Table
 	err := r.DB.First(&currentTable, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("table tidak di temukan")
+			return ErrTableNotFound
 		}
 		return err
 	}
@@ -110,7 +157,7 @@ func (r *TableRepository) UpdateTable(id int, table *models.Table) error {
 		}
 
 		if tableExists {
-			return errors.New("table name sudah terdaftar")
+			return ErrTableNameTaken
 		}
 	}
 
@@ -0,0 +1,46 @@
+package repository
+
+import "gorm.io/gorm"
+
+// Repositories adalah kumpulan repository yang memakai koneksi transaksi yang sama
+type Repositories struct {
+	Reservations  ReservationRepository
+	Tables        TableRepository
+	Users         UserRepository
+	GuestContacts GuestContactRepository
+	ManageTokens  ReservationManageTokenRepository
+	Outbox        OutboxRepository
+	Reminders     ReminderRepository
+	Webhooks      WebhookRepository
+	Waitlist      WaitlistRepository
+}
+
+// UnitOfWork menjalankan beberapa operasi repository dalam satu transaksi database
+type UnitOfWork interface {
+	// Do menjalankan fn di dalam transaksi. Transaksi di-commit jika fn mengembalikan nil dan di-rollback jika error atau panic
+	Do(fn func(repos Repositories) error) error
+}
+
+type gormUnitOfWork struct {
+	DB *gorm.DB
+}
+
+func NewUnitOfWork(db *gorm.DB) UnitOfWork {
+	return &gormUnitOfWork{DB: db}
+}
+
+func (u *gormUnitOfWork) Do(fn func(repos Repositories) error) error {
+	return u.DB.Transaction(func(tx *gorm.DB) error {
+		return fn(Repositories{
+			Reservations:  NewReservationRepository(tx),
+			Tables:        NewTableRepository(tx),
+			Users:         NewUserRepository(tx),
+			GuestContacts: NewGuestContactRepository(tx),
+			ManageTokens:  NewReservationManageTokenRepository(tx),
+			Outbox:        NewOutboxRepository(tx),
+			Reminders:     NewReminderRepository(tx),
+			Webhooks:      NewWebhookRepository(tx),
+			Waitlist:      NewWaitlistRepository(tx),
+		})
+	})
+}
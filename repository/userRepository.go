@@ -11,27 +11,27 @@ import (
 )
 
 
-type UserRepository struct {
+type userRepository struct {
 	DB *gorm.DB
 }
 
-func NewUserRepository(db *gorm.DB) *UserRepository {
-	return &UserRepository{DB: db}
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{DB: db}
 }
 
 
-func (r *UserRepository) IsValidUserRole(role string) bool {
-	validRoles := []string{"admin","customer"}
-	for _, valid := range validRoles{
-		if role == valid {
-			return true
-		}
+// Role valid jika sudah terdaftar di tabel roles
+func (r *userRepository) IsValidUserRole(role string) (bool, error) {
+	var exists bool
+	err := r.DB.Raw("SELECT EXISTS (SELECT 1 FROM roles WHERE name = ?)", role).Scan(&exists).Error
+	if err != nil {
+		return false, err
 	}
-	return false
+	return exists, nil
 }
 
 // Cek email apakah sudah ada didatabase
-func (r *UserRepository) IsEmailExists(email string) (bool, error) {
+func (r *userRepository) IsEmailExists(email string) (bool, error) {
 	var exists bool
 	err := r.DB.Raw("SELECT EXISTS (SELECT 1 FROM users WHERE email = ?)", email).Scan(&exists).Error
 	if err != nil {
@@ -40,7 +40,7 @@ func (r *UserRepository) IsEmailExists(email string) (bool, error) {
 	return exists, nil
 }
 
-func (r *UserRepository) IsUserExists(id int) (bool, error) {
+func (r *userRepository) IsUserExists(id int) (bool, error) {
 	var existId	bool
 	err := r.DB.Raw("SELECT EXISTS (SELECT 1 FROM users WHERE id = ?)", id).Scan(&existId).Error
 	if err != nil {
@@ -50,25 +50,50 @@ func (r *UserRepository) IsUserExists(id int) (bool, error) {
 }
 
 
-func (r *UserRepository) GetAllUser() ([]models.User, error) {
+func (r *userRepository) GetAllUser() ([]models.User, error) {
 	var users []models.User
-	err := r.DB.Raw("SELECT id, name, email, role, created_at, updated_at from users").Scan(&users).Error
+	err := r.DB.Raw("SELECT id, name, email, role, email_verified_at, created_at, updated_at from users").Scan(&users).Error
 	if err != nil {
 		return nil, err // Kembalikan nil dan error jika terjadi kesalahan
 	}
 	return users, nil 
 }
 
-func (r *UserRepository) GetUserByid(id int) (*models.User, error) {
+// ListUsers mengembalikan satu halaman user tanpa kolom password
+func (r *userRepository) ListUsers(filter UserFilter) ([]models.User, int64, error) {
+	query := func() *gorm.DB {
+		db := r.DB.Model(&models.User{})
+		if filter.Role != "" {
+			db = db.Where("role = ?", filter.Role)
+		}
+		return db
+	}
+
+	var total int64
+	if err := query().Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	var users []models.User
+	err := query().Select("id, name, email, role, email_verified_at, created_at, updated_at").
+		Order(orderBy("users", UserSortFields, filter.Page)).Limit(filter.Limit).Offset(filter.Offset).
+		Find(&users).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch users: %w", err)
+	}
+	return users, total, nil
+}
+
+func (r *userRepository) GetUserByid(id int) (*models.User, error) {
 	var user models.User
 
-	query := `SELECT id, name, email, role, created_at, updated_at FROM users WHERE id = $1`
+	query := `SELECT id, name, email, role, email_verified_at, created_at, updated_at FROM users WHERE id = $1`
 
 	err := r.DB.Raw(query, id).First(&user).Error
 
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("user with Id %d not found", id)
+			return nil, ErrUserNotFound.Withf("user with Id %d not found", id)
 		}
 		return nil, err
 	}
@@ -78,23 +103,43 @@ func (r *UserRepository) GetUserByid(id int) (*models.User, error) {
 		Name:     user.Name,
 		Email:    user.Email,
 		Role:     user.Role,
+		EmailVerifiedAt: user.EmailVerifiedAt,
 	}, nil
 }
 
+// Ambil user beserta hash password untuk proses login
+func (r *userRepository) GetUserByEmail(email string) (*models.User, error) {
+	var user models.User
+	err := r.DB.Raw("SELECT id, name, email, password, role, token_version, email_verified_at FROM users WHERE email = ?", email).Scan(&user).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if user.ID == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	return &user, nil
+}
+
 // Create user
-func (r *UserRepository) CreateUser(user *models.User) error {
+func (r *userRepository) CreateUser(user *models.User) error {
 	emailExists, err := r.IsEmailExists(user.Email)
 	if err != nil {
 		return err
 	}
 
 	if emailExists {
-		return errors.New("email sudah terdaftar")
+		return ErrEmailTaken
 	}
 
 	//Validasi role Pengguna
-	if !r.IsValidUserRole(user.Role) {
-		return errors.New("invalid user role")
+	validRole, err := r.IsValidUserRole(user.Role)
+	if err != nil {
+		return err
+	}
+	if !validRole {
+		return ErrInvalidUserRole
 	}
 
 	// membuat user baru menggunakan GORM
@@ -107,12 +152,12 @@ func (r *UserRepository) CreateUser(user *models.User) error {
 
 
 // Update User
-func (r *UserRepository) UpdateUser(id int, user *models.User) error {
+func (r *userRepository) UpdateUser(id int, user *models.User) error {
 	var currentUser models.User
 	err := r.DB.First(&currentUser, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("user tidak ditemukan")
+			return ErrUserNotFound
 		}
 		return err
 	}
@@ -125,7 +170,7 @@ func (r *UserRepository) UpdateUser(id int, user *models.User) error {
 		}
 
 		if emailExists {
-			return errors.New("email sudah terdaftar")
+			return ErrEmailTaken
 		}
 	}
 	
@@ -137,6 +182,10 @@ func (r *UserRepository) UpdateUser(id int, user *models.User) error {
 	if user.Email != "" {
 		updates["email"] = user.Email
 	}
+	// Email baru harus diverifikasi ulang
+	if user.Email != "" && user.Email != currentUser.Email {
+		updates["email_verified_at"] = nil
+	}
 	if user.Password != "" {
 		updates["password"] = user.Password
 	}
@@ -153,7 +202,7 @@ func (r *UserRepository) UpdateUser(id int, user *models.User) error {
 
 
 // Delete User 
-func (r *UserRepository) DeleteUser (id int) error {
+func (r *userRepository) DeleteUser (id int) error {
 	sqlQuery := `DELETE FROM users WHERE id = $1`
 	result := r.DB.Exec(sqlQuery, id)
 
@@ -163,15 +212,31 @@ func (r *UserRepository) DeleteUser (id int) error {
 
 	//validate if error 
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("user with ID %d not found", id)
+		return ErrUserNotFound.Withf("user with ID %d not found", id)
 	}
 
 	return nil
 }
 
+// UpdateUserRole mengganti role user sekaligus mencabut access token lama,
+// karena role di claims token lama sudah tidak sesuai
+func (r *userRepository) UpdateUserRole(id int, role string) error {
+	result := r.DB.Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"role":          role,
+		"token_version": gorm.Expr("token_version + 1"),
+		"updated_at":    time.Now(),
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update role of user %d: %w", id, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound.Withf("user with ID %d not found", id)
+	}
+	return nil
+}
 
 //
-func (r *UserRepository) GetAllUserByRole (role string) ([]models.User, error) {
+func (r *userRepository) GetAllUserByRole (role string) ([]models.User, error) {
 	var users []models.User
 
 	//Eksekusi query
@@ -181,4 +246,89 @@ func (r *UserRepository) GetAllUserByRole (role string) ([]models.User, error) {
 	}
 
 	return users, nil
-}
\ No newline at end of file
+}
+
+// Ambil token_version user, dipakai JWTAuthMiddleware untuk menolak token yang sudah dicabut
+func (r *userRepository) GetTokenVersion(id int) (int, error) {
+	var user models.User
+	err := r.DB.Select("id", "token_version").First(&user, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrUserNotFound.Withf("user with Id %d not found", id)
+		}
+		return 0, err
+	}
+	return user.TokenVersion, nil
+}
+
+// Naikkan token_version sehingga semua access token user yang sudah diterbitkan tidak berlaku lagi
+func (r *userRepository) IncrementTokenVersion(id int) error {
+	result := r.DB.Model(&models.User{}).Where("id = ?", id).
+		Update("token_version", gorm.Expr("token_version + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound.Withf("user with ID %d not found", id)
+	}
+	return nil
+}
+
+func (r *userRepository) IsEmailVerified(id int) (bool, error) {
+	var verified bool
+	err := r.DB.Raw("SELECT EXISTS (SELECT 1 FROM users WHERE id = ? AND email_verified_at IS NOT NULL)", id).Scan(&verified).Error
+	if err != nil {
+		return false, err
+	}
+	return verified, nil
+}
+
+// Tandai email terverifikasi. Email harus masih sama dengan email di link,
+// jadi link lama tidak berlaku lagi jika user sudah mengganti email. false jika tidak ada yang diubah
+func (r *userRepository) MarkEmailVerified(id int, email string) (bool, error) {
+	result := r.DB.Model(&models.User{}).Where("id = ? AND email = ? AND email_verified_at IS NULL", id, email).
+		Update("email_verified_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Hapus akun yang tidak pernah diverifikasi sampai createdBefore, mengembalikan jumlah akun yang dihapus
+func (r *userRepository) DeleteUnverifiedUsers(createdBefore time.Time) (int64, error) {
+	result := r.DB.Where("email_verified_at IS NULL AND created_at < ?", createdBefore).Delete(&models.User{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// Ambil hash token feed kalender user, string kosong jika user belum pernah membuat link feed
+func (r *userRepository) GetCalendarTokenHash(id int) (string, error) {
+	var user models.User
+	err := r.DB.Select("id", "calendar_token_hash").First(&user, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrUserNotFound.Withf("user with Id %d not found", id)
+		}
+		return "", err
+	}
+	if user.CalendarTokenHash == nil {
+		return "", nil
+	}
+	return *user.CalendarTokenHash, nil
+}
+
+// Ganti hash token feed kalender, link feed yang lama otomatis tidak berlaku
+func (r *userRepository) SetCalendarTokenHash(id int, hash string) error {
+	result := r.DB.Model(&models.User{}).Where("id = ?", id).Update("calendar_token_hash", hash)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound.Withf("user with ID %d not found", id)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"wereserve/models"
+
+	"gorm.io/gorm"
+)
+
+type waitlistRepository struct {
+	DB *gorm.DB
+}
+
+func NewWaitlistRepository(db *gorm.DB) WaitlistRepository {
+	return &waitlistRepository{DB: db}
+}
+
+func (r *waitlistRepository) CreateEntry(entry *models.WaitlistEntry) error {
+	err := r.DB.Omit("User").Create(entry).Error
+	if err != nil {
+		return fmt.Errorf("failed to join waitlist: %w", err)
+	}
+	return nil
+}
+
+func (r *waitlistRepository) GetAllEntries() ([]models.WaitlistEntry, error) {
+	var entries []models.WaitlistEntry
+	err := r.DB.Preload("User").Order("created_at ASC").Find(&entries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch waitlist: %w", err)
+	}
+	return entries, nil
+}
+
+func (r *waitlistRepository) GetEntriesByUser(userID int) ([]models.WaitlistEntry, error) {
+	var entries []models.WaitlistEntry
+	err := r.DB.Preload("User").Where("user_id = ?", userID).Order("created_at ASC").Find(&entries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch waitlist: %w", err)
+	}
+	return entries, nil
+}
+
+func (r *waitlistRepository) GetEntryByID(id int) (*models.WaitlistEntry, error) {
+	var entry models.WaitlistEntry
+	err := r.DB.Preload("User").First(&entry, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWaitlistEntryNotFound.Withf("waitlist entry with ID %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to fetch waitlist entry with ID %d: %w", id, err)
+	}
+	return &entry, nil
+}
+
+// Ambil customer yang masih menunggu dan mau duduk pada waktu start dengan jumlah orang maksimal maxPartySize.
+// Rombongan terbesar yang muat didahulukan supaya meja terpakai maksimal, lalu yang paling lama menunggu
+func (r *waitlistRepository) GetCandidates(start time.Time, maxPartySize int) ([]models.WaitlistEntry, error) {
+	var entries []models.WaitlistEntry
+	err := r.DB.Preload("User").
+		Where("status = ?", models.WaitlistStatusWaiting).
+		Where("desired_start <= ? AND desired_end >= ?", start, start).
+		Where("party_size <= ?", maxPartySize).
+		Order("party_size DESC, created_at ASC").
+		Find(&entries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch waitlist candidates: %w", err)
+	}
+	return entries, nil
+}
+
+// Ambil penawaran yang sudah melewati batas waktunya
+func (r *waitlistRepository) GetExpiredOffers(now time.Time) ([]models.WaitlistEntry, error) {
+	var entries []models.WaitlistEntry
+	err := r.DB.Where("status = ? AND offer_expires_at <= ?", models.WaitlistStatusOffered, now).Find(&entries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch expired waitlist offers: %w", err)
+	}
+	return entries, nil
+}
+
+// MarkOffered menyimpan slot yang ditawarkan. Hanya entry yang masih waiting yang diubah,
+// false dikembalikan jika entry sudah diambil oleh proses lain
+func (r *waitlistRepository) MarkOffered(id int, tableID, combinationID *int, offeredAt, expiresAt time.Time) (bool, error) {
+	result := r.DB.Model(&models.WaitlistEntry{}).
+		Where("id = ? AND status = ?", id, models.WaitlistStatusWaiting).
+		Updates(map[string]interface{}{
+			"status":                 models.WaitlistStatusOffered,
+			"offered_table_id":       tableID,
+			"offered_combination_id": combinationID,
+			"offered_datetime":       offeredAt,
+			"offer_expires_at":       expiresAt,
+			"updated_at":             time.Now(),
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to offer waitlist slot: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *waitlistRepository) MarkAccepted(id, reservationID int) (bool, error) {
+	result := r.DB.Model(&models.WaitlistEntry{}).Where("id = ? AND status = ?", id, models.WaitlistStatusOffered).Updates(map[string]interface{}{
+		"status":         models.WaitlistStatusAccepted,
+		"reservation_id": reservationID,
+		"updated_at":     time.Now(),
+	})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to accept waitlist offer: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// UpdateStatus mengubah status entry jika statusnya saat ini adalah from.
+// false dikembalikan jika status sudah berubah lebih dulu
+func (r *waitlistRepository) UpdateStatus(id int, from, to string) (bool, error) {
+	result := r.DB.Model(&models.WaitlistEntry{}).Where("id = ? AND status = ?", id, from).Updates(map[string]interface{}{
+		"status":     to,
+		"updated_at": time.Now(),
+	})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to update waitlist status: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"wereserve/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type webhookRepository struct {
+	DB *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{DB: db}
+}
+
+func (r *webhookRepository) CreateSubscription(subscription *models.WebhookSubscription) error {
+	err := r.DB.Create(subscription).Error
+	if err != nil {
+		return fmt.Errorf("failed to save webhook subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookRepository) GetSubscriptions() ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	if err := r.DB.Order("id ASC").Find(&subscriptions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch webhook subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+func (r *webhookRepository) GetSubscriptionByID(id int) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	err := r.DB.First(&subscription, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookSubscriptionNotFound.Withf("webhook subscription with ID %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to fetch webhook subscription with ID %d: %w", id, err)
+	}
+	return &subscription, nil
+}
+
+// UpdateSubscription mengganti url, secret, event dan status aktif. Field string kosong tidak diubah
+func (r *webhookRepository) UpdateSubscription(id int, subscription *models.WebhookSubscription) error {
+	updates := map[string]interface{}{
+		"active":     subscription.Active,
+		"updated_at": time.Now(),
+	}
+	if subscription.URL != "" {
+		updates["url"] = subscription.URL
+	}
+	if subscription.Secret != "" {
+		updates["secret"] = subscription.Secret
+	}
+	if subscription.EventTypes != "" {
+		updates["event_types"] = subscription.EventTypes
+	}
+
+	result := r.DB.Model(&models.WebhookSubscription{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update webhook subscription %d: %w", id, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrWebhookSubscriptionNotFound.Withf("webhook subscription with ID %d not found", id)
+	}
+	return nil
+}
+
+func (r *webhookRepository) DeleteSubscription(id int) error {
+	result := r.DB.Delete(&models.WebhookSubscription{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete webhook subscription %d: %w", id, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrWebhookSubscriptionNotFound.Withf("webhook subscription with ID %d not found", id)
+	}
+	return nil
+}
+
+// EnqueueDeliveries membuat satu baris pengiriman untuk setiap subscription aktif yang melanggan eventType,
+// lalu mengembalikan jumlah subscription yang akan menerima event
+func (r *webhookRepository) EnqueueDeliveries(eventID, eventType, payload string) (int64, error) {
+	query := `INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, created_at, updated_at)
+		SELECT id, ?, ?, ?::jsonb, 'pending', 0, NOW(), NOW(), NOW()
+		FROM webhook_subscriptions
+		WHERE active AND ? = ANY(string_to_array(event_types, ','))`
+
+	result := r.DB.Exec(query, eventID, eventType, payload, eventType)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to enqueue %s webhooks: %w", eventType, result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// ClaimDueDeliveries bekerja seperti ClaimDueMessages di outbox: pengiriman yang diambil digeser sejauh lease
+// supaya tidak diambil worker lain
+func (r *webhookRepository) ClaimDueDeliveries(now time.Time, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, now).
+			Order("next_attempt_at ASC, id ASC").Limit(limit).Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]int, len(deliveries))
+		for i, delivery := range deliveries {
+			ids[i] = delivery.ID
+		}
+		if err := tx.Model(&models.WebhookDelivery{}).Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error; err != nil {
+			return err
+		}
+
+		// Subscription dimuat terpisah karena FOR UPDATE tidak bisa dipakai bersama Preload
+		var subscriptions []models.WebhookSubscription
+		subscriptionIDs := make([]int, len(deliveries))
+		for i, delivery := range deliveries {
+			subscriptionIDs[i] = delivery.SubscriptionID
+		}
+		if err := tx.Where("id IN ?", subscriptionIDs).Find(&subscriptions).Error; err != nil {
+			return err
+		}
+		byID := make(map[int]models.WebhookSubscription, len(subscriptions))
+		for _, subscription := range subscriptions {
+			byID[subscription.ID] = subscription
+		}
+		for i := range deliveries {
+			deliveries[i].Subscription = byID[deliveries[i].SubscriptionID]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (r *webhookRepository) MarkDelivered(id int, responseStatus int) error {
+	now := time.Now()
+	err := r.DB.Model(&models.WebhookDelivery{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          models.WebhookDeliveryDelivered,
+		"response_status": responseStatus,
+		"delivered_at":    now,
+		"last_error":      nil,
+		"updated_at":      now,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to mark webhook delivery %d as delivered: %w", id, err)
+	}
+	return nil
+}
+
+// MarkDeliveryFailed mencatat percobaan yang gagal. Jika dead, event tidak dikirim lagi ke subscription ini
+func (r *webhookRepository) MarkDeliveryFailed(id int, attempts int, nextAttemptAt time.Time, responseStatus *int, lastError string, dead bool) error {
+	status := models.WebhookDeliveryPending
+	if dead {
+		status = models.WebhookDeliveryDead
+	}
+
+	err := r.DB.Model(&models.WebhookDelivery{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          status,
+		"attempts":        attempts,
+		"next_attempt_at": nextAttemptAt,
+		"response_status": responseStatus,
+		"last_error":      lastError,
+		"updated_at":      time.Now(),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to mark webhook delivery %d as failed: %w", id, err)
+	}
+	return nil
+}
+
+// GetDeliveries mengembalikan log pengiriman sebuah subscription, terbaru lebih dulu
+func (r *webhookRepository) GetDeliveries(subscriptionID int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.DB.Where("subscription_id = ?", subscriptionID).Order("created_at DESC, id DESC").Find(&deliveries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
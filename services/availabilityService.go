@@ -0,0 +1,144 @@
+package services
+
+import (
+	"fmt"
+	"time"
+	"wereserve/apperror"
+	"wereserve/models"
+	"wereserve/repository"
+)
+
+// Berapa banyak slot alternatif yang dicek ke depan/ke belakang. Jarak antar slot mengikuti granularitas slot di jadwal
+const availabilityAlternateSlot = 3
+
+var ErrInvalidPartySize = apperror.Validation("invalid_party_size", "party size must be at least 1")
+
+type AvailabilityService struct {
+	tableRepo       repository.TableRepository
+	combinationRepo repository.TableCombinationRepository
+	reservationRepo repository.ReservationRepository
+	scheduleService *ScheduleService
+}
+
+type AvailabilitySlot struct {
+	Start                 time.Time
+	End                   time.Time
+	AvailableTables       []models.Table
+	AvailableCombinations []models.TableCombination
+}
+
+type AvailabilityResult struct {
+	PartySize        int
+	DurationMinutes  int
+	Requested        AvailabilitySlot
+	AlternativeSlots []AvailabilitySlot
+}
+
+func NewAvailabilityService(tableRepo repository.TableRepository, combinationRepo repository.TableCombinationRepository, reservationRepo repository.ReservationRepository, scheduleService *ScheduleService) *AvailabilityService {
+	return &AvailabilityService{
+		tableRepo:       tableRepo,
+		combinationRepo: combinationRepo,
+		reservationRepo: reservationRepo,
+		scheduleService: scheduleService,
+	}
+}
+
+// SearchAvailability mencari meja dan kombinasi meja yang kosong untuk partySize pada waktu start,
+// ditambah slot alternatif di sekitar waktu tersebut jika ada meja yang kosong
+func (s *AvailabilityService) SearchAvailability(start time.Time, partySize, durationMinutes int) (*AvailabilityResult, error) {
+	if partySize < 1 {
+		return nil, ErrInvalidPartySize
+	}
+	if durationMinutes == 0 {
+		durationMinutes = models.DefaultReservationDuration
+	}
+	duration := time.Duration(durationMinutes) * time.Minute
+
+	// Waktu yang diminta harus berada di dalam jadwal buka restoran
+	if err := s.scheduleService.ValidateSlot(start, start.Add(duration)); err != nil {
+		return nil, err
+	}
+
+	// Meja dengan kapasitas yang cukup
+	tables, err := s.tableRepo.GetTablesByMinCapacity(partySize)
+	if err != nil {
+		return nil, err
+	}
+
+	// Kombinasi meja untuk rombongan yang tidak muat di satu meja
+	combinations, err := s.combinationRepo.GetCombinationsByMinCapacity(partySize)
+	if err != nil {
+		return nil, err
+	}
+
+	step, err := s.scheduleService.SlotDuration()
+	if err != nil {
+		return nil, err
+	}
+
+	// Ambil semua reservasi di sekitar waktu yang diminta dalam satu query
+	window := step * availabilityAlternateSlot
+	reservations, err := s.reservationRepo.GetReservationsInRange(start.Add(-window), start.Add(window+duration))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check availability: %w", err)
+	}
+
+	result := &AvailabilityResult{
+		PartySize:       partySize,
+		DurationMinutes: durationMinutes,
+		Requested:       freeSlot(tables, combinations, reservations, start, duration),
+	}
+
+	for i := 1; i <= availabilityAlternateSlot; i++ {
+		offset := step * time.Duration(i)
+		for _, slotStart := range []time.Time{start.Add(-offset), start.Add(offset)} {
+			// Lewati slot yang sudah lewat atau di luar jadwal buka
+			if s.scheduleService.ValidateSlot(slotStart, slotStart.Add(duration)) != nil {
+				continue
+			}
+			slot := freeSlot(tables, combinations, reservations, slotStart, duration)
+			if len(slot.AvailableTables) > 0 || len(slot.AvailableCombinations) > 0 {
+				result.AlternativeSlots = append(result.AlternativeSlots, slot)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// freeSlot memfilter meja dan kombinasi yang tidak memiliki reservasi yang bersinggungan dengan [start, start+duration).
+// Sebuah kombinasi hanya tersedia jika semua mejanya kosong
+func freeSlot(tables []models.Table, combinations []models.TableCombination, reservations []models.Reservation, start time.Time, duration time.Duration) AvailabilitySlot {
+	end := start.Add(duration)
+	slot := AvailabilitySlot{Start: start, End: end}
+
+	busy := make(map[int]bool)
+	for _, reservation := range reservations {
+		if reservation.ReservationDateTime.Before(end) && reservation.EndTime().After(start) {
+			for _, tableID := range reservation.TableIDs() {
+				busy[tableID] = true
+			}
+		}
+	}
+
+	for _, table := range tables {
+		if !busy[table.ID] {
+			slot.AvailableTables = append(slot.AvailableTables, table)
+		}
+	}
+
+	for _, combination := range combinations {
+		free := true
+		for _, table := range combination.Tables {
+			if busy[table.ID] {
+				free = false
+				break
+			}
+		}
+		if free {
+			slot.AvailableCombinations = append(slot.AvailableCombinations, combination)
+		}
+	}
+
+	return slot
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"testing"
+	"time"
+	"wereserve/models"
+	"wereserve/repository/memory"
+)
+
+func TestAlternativeSlotsFollowSlotMinutes(t *testing.T) {
+	for _, slotMinutes := range []int{15, 60} {
+		store := memory.NewStore()
+		table := models.Table{TableName: "A1", Capacity: 4, Status: "available"}
+		if err := store.Tables().CreateTable(&table); err != nil {
+			t.Fatalf("failed to create table: %v", err)
+		}
+
+		scheduleService := NewScheduleService(store.Schedule())
+		if err := scheduleService.UpdateSlotMinutes(slotMinutes); err != nil {
+			t.Fatalf("UpdateSlotMinutes() error = %v", err)
+		}
+		service := NewAvailabilityService(store.Tables(), store.TableCombinations(), store.Reservations(), scheduleService)
+
+		start := tomorrowAt(14, 0)
+		result, err := service.SearchAvailability(start, 2, 0)
+		if err != nil {
+			t.Fatalf("SearchAvailability() error = %v", err)
+		}
+
+		step := time.Duration(slotMinutes) * time.Minute
+		if len(result.AlternativeSlots) != 2*availabilityAlternateSlot {
+			t.Fatalf("expected %d alternative slots for %d minute slots, got %d", 2*availabilityAlternateSlot, slotMinutes, len(result.AlternativeSlots))
+		}
+		for _, slot := range result.AlternativeSlots {
+			if offset := slot.Start.Sub(start); offset%step != 0 {
+				t.Errorf("alternative slot %s is not on the %d minute grid", slot.Start.Format("15:04"), slotMinutes)
+			}
+		}
+		if got := result.AlternativeSlots[0].Start; !got.Equal(start.Add(-step)) {
+			t.Errorf("expected the nearest alternative at %s, got %s", start.Add(-step).Format("15:04"), got.Format("15:04"))
+		}
+	}
+}
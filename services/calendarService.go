@@ -0,0 +1,84 @@
+package services
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"strings"
+	"time"
+	"wereserve/apperror"
+	"wereserve/notification"
+	"wereserve/repository"
+	"wereserve/utils"
+)
+
+var (
+	// Link yang salah dijawab 404 supaya keberadaan user tidak bisa ditebak
+	ErrInvalidCalendarToken = apperror.NotFound("invalid_calendar_token", "calendar link is invalid or has been replaced")
+	ErrCalendarForbidden    = apperror.Forbidden("calendar_forbidden", "you are not allowed to manage this calendar")
+)
+
+type CalendarService struct {
+	userRepo        repository.UserRepository
+	reservationRepo repository.ReservationRepository
+	feedBaseURL     string
+}
+
+func NewCalendarService(userRepo repository.UserRepository, reservationRepo repository.ReservationRepository, feedBaseURL string) *CalendarService {
+	return &CalendarService{
+		userRepo:        userRepo,
+		reservationRepo: reservationRepo,
+		feedBaseURL:     strings.TrimRight(feedBaseURL, "/"),
+	}
+}
+
+// RotateFeedToken membuat token feed kalender baru dan mengembalikan link feed lengkap.
+// Link lama langsung tidak berlaku. Hanya pemilik akun atau user dengan permission user:write yang boleh membuat link
+func (s *CalendarService) RotateFeedToken(userID int, actor Actor) (string, error) {
+	if err := PolicyUserWrite.Authorize(actor, userID); err != nil {
+		return "", ErrCalendarForbidden
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate calendar token: %w", err)
+	}
+
+	if err := s.userRepo.SetCalendarTokenHash(userID, utils.HashToken(token)); err != nil {
+		return "", err
+	}
+
+	return linkWithToken(s.feedURL(userID), token), nil
+}
+
+func (s *CalendarService) feedURL(userID int) string {
+	return fmt.Sprintf("%s/users/%d/calendar.ics", s.feedBaseURL, userID)
+}
+
+// Feed mengembalikan file .ics berisi reservasi user yang belum selesai. Token dicek karena aplikasi kalender
+// mengambil feed tanpa header Authorization
+func (s *CalendarService) Feed(userID int, token string) ([]byte, error) {
+	hash, err := s.userRepo.GetCalendarTokenHash(userID)
+	if err != nil || hash == "" || token == "" {
+		return nil, ErrInvalidCalendarToken
+	}
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(utils.HashToken(token))) != 1 {
+		return nil, ErrInvalidCalendarToken
+	}
+
+	reservations, err := s.reservationRepo.GetReservationByUserLogin(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	events := []notification.CalendarEvent{}
+	for _, reservation := range reservations {
+		// Hanya reservasi yang akan datang atau sedang berjalan. Reservasi yang dibatalkan hilang dari feed
+		if !reservation.IsActive() || !reservation.EndTime().After(now) {
+			continue
+		}
+		events = append(events, notification.ReservationCalendarEvent(reservationNotification(reservation), false))
+	}
+
+	return notification.BuildCalendar("", events...), nil
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	"wereserve/models"
+	"wereserve/notification"
+)
+
+func TestCalendarFeed(t *testing.T) {
+	service, store, user, tables := newMemoryReservationService(t)
+	calendar := NewCalendarService(store.Users(), store.Reservations(), "http://localhost:8080/api/")
+	owner := Actor{UserID: user.ID, Role: "customer"}
+
+	upcoming := createReservationAt(t, store, user, tables[0], time.Now().Add(3*time.Hour))
+	cancelled := createReservationAt(t, store, user, tables[1], time.Now().Add(3*time.Hour))
+	past := createReservationAt(t, store, user, tables[0], time.Now().Add(-48*time.Hour))
+	if err := service.CancelReservation(cancelled.ID, owner, "plans changed"); err != nil {
+		t.Fatalf("CancelReservation() error = %v", err)
+	}
+
+	if _, err := calendar.Feed(user.ID, "anything"); !errors.Is(err, ErrInvalidCalendarToken) {
+		t.Fatalf("Feed() before a link exists error = %v, want %v", err, ErrInvalidCalendarToken)
+	}
+
+	feedURL, err := calendar.RotateFeedToken(user.ID, owner)
+	if err != nil {
+		t.Fatalf("RotateFeedToken() error = %v", err)
+	}
+	parsed, err := url.Parse(feedURL)
+	if err != nil || parsed.Path != "/api/users/1/calendar.ics" {
+		t.Fatalf("unexpected feed url %q", feedURL)
+	}
+	token := parsed.Query().Get("token")
+
+	feed, err := calendar.Feed(user.ID, token)
+	if err != nil {
+		t.Fatalf("Feed() error = %v", err)
+	}
+	ics := string(feed)
+	if !strings.Contains(ics, "UID:"+notification.ReservationCalendarUID(upcoming.ID)) {
+		t.Errorf("expected the upcoming reservation in the feed")
+	}
+	for _, reservation := range []models.Reservation{cancelled, past} {
+		if strings.Contains(ics, "UID:"+notification.ReservationCalendarUID(reservation.ID)) {
+			t.Errorf("expected reservation %d to be left out of the feed", reservation.ID)
+		}
+	}
+
+	// Token milik user lain atau token lama tidak bisa dipakai
+	if _, err := calendar.Feed(user.ID+1, token); !errors.Is(err, ErrInvalidCalendarToken) {
+		t.Errorf("Feed() for another user error = %v, want %v", err, ErrInvalidCalendarToken)
+	}
+	if _, err := calendar.RotateFeedToken(user.ID, owner); err != nil {
+		t.Fatalf("RotateFeedToken() error = %v", err)
+	}
+	if _, err := calendar.Feed(user.ID, token); !errors.Is(err, ErrInvalidCalendarToken) {
+		t.Errorf("Feed() with a replaced token error = %v, want %v", err, ErrInvalidCalendarToken)
+	}
+}
+
+func TestRotateFeedTokenForbidden(t *testing.T) {
+	_, store, user, _ := newMemoryReservationService(t)
+	calendar := NewCalendarService(store.Users(), store.Reservations(), "http://localhost:8080/api")
+
+	if _, err := calendar.RotateFeedToken(user.ID, Actor{UserID: user.ID + 1, Role: "customer"}); !errors.Is(err, ErrCalendarForbidden) {
+		t.Fatalf("RotateFeedToken() by another customer error = %v, want %v", err, ErrCalendarForbidden)
+	}
+	if _, err := calendar.RotateFeedToken(user.ID, Actor{UserID: user.ID + 1, Role: models.RoleAdmin, Permissions: []string{models.PermissionUserWrite}}); err != nil {
+		t.Fatalf("RotateFeedToken() by admin error = %v", err)
+	}
+}
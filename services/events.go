@@ -0,0 +1,78 @@
+package services
+
+import (
+	"log"
+	"time"
+	"wereserve/models"
+	"wereserve/realtime"
+)
+
+// reservationEventData adalah data reservasi yang dikirim lewat webhook dan stream realtime. Data user seperti email tidak ikut dikirim
+type reservationEventData struct {
+	ID                  int       `json:"id"`
+	UserID              int       `json:"user_id"`
+	GuestContactID      *int      `json:"guest_contact_id"`
+	TableIDs            []int     `json:"table_ids"`
+	CombinationID       *int      `json:"combination_id"`
+	ReservationDateTime time.Time `json:"reservation_datetime"`
+	EndDateTime         time.Time `json:"end_datetime"`
+	DurationMinutes     int       `json:"duration_minutes"`
+	NumberOfPeople      int       `json:"number_of_people"`
+	Status              string    `json:"status"`
+	PreviousStatus      string    `json:"previous_status,omitempty"`
+	CancellationReason  string    `json:"cancellation_reason,omitempty"`
+}
+
+// tableStatusEvent adalah data perubahan status meja, baik yang diatur admin maupun yang diturunkan dari reservasi
+type tableStatusEvent struct {
+	TableID        int    `json:"table_id"`
+	TableName      string `json:"table_name"`
+	Status         string `json:"status"`
+	PreviousStatus string `json:"previous_status"`
+}
+
+func reservationEvent(reservation models.Reservation, previousStatus string) reservationEventData {
+	return reservationEventData{
+		ID:                  reservation.ID,
+		UserID:              reservation.UserID,
+		GuestContactID:      reservation.GuestContactID,
+		TableIDs:            reservation.TableIDs(),
+		CombinationID:       reservation.CombinationID,
+		ReservationDateTime: reservation.ReservationDateTime,
+		EndDateTime:         reservation.EndTime(),
+		DurationMinutes:     reservation.DurationMinutes,
+		NumberOfPeople:      reservation.NumberOfPeople,
+		Status:              reservation.Status,
+		PreviousStatus:      previousStatus,
+		CancellationReason:  reservation.CancellationReason,
+	}
+}
+
+// broadcast mengirim event ke client yang terhubung ke stream. Dipanggil setelah transaksi di-commit
+// supaya client tidak pernah menerima perubahan yang kemudian di-rollback
+func broadcast(hub *realtime.Hub, eventType string, userID int, data interface{}) {
+	hub.Publish(realtime.Event{Type: eventType, UserID: userID, Data: data})
+}
+
+// broadcastTableStatus mengirim perubahan status meja yang diturunkan dari reservasi ke stream, supaya tampilan denah meja ikut berubah
+func broadcastTableStatus(hub *realtime.Hub, events []tableStatusEvent) {
+	for _, event := range events {
+		broadcast(hub, models.WebhookEventTableStatusChanged, 0, event)
+	}
+}
+
+// broadcastReservation memuat reservasi terbaru lalu mengirimnya ke stream
+func (s *ReservationService) broadcastReservation(id int, eventType, previousStatus string) {
+	reservation, err := s.reservationRepo.GetReservationDetail(id)
+	if err != nil {
+		log.Printf("Failed to load reservation %d for realtime event: %v", id, err)
+		return
+	}
+	// Reservasi tamu tidak punya UserID, jadi hanya dikirim ke user yang boleh melihat reservasi semua user
+	s.hub.Publish(realtime.Event{
+		Type:      eventType,
+		UserID:    reservation.UserID,
+		StaffOnly: reservation.UserID == 0,
+		Data:      reservationEvent(*reservation, previousStatus),
+	})
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"wereserve/apperror"
+	"wereserve/models"
+	"wereserve/notification"
+	"wereserve/repository"
+	"wereserve/utils"
+)
+
+var (
+	ErrGuestContactRequired          = apperror.Validation("guest_contact_required", "name, email and phone are required to book without an account")
+	ErrGuestPartyTooLarge            = apperror.Validation("guest_party_too_large", "party size exceeds the limit for online booking, please contact the restaurant")
+	ErrTooManyGuestReservations      = apperror.Conflict("too_many_guest_reservations", "too many upcoming reservations for this contact")
+	ErrInvalidReservationManageToken = apperror.Validation("invalid_reservation_manage_token", "link kelola reservasi tidak valid atau sudah kedaluwarsa")
+)
+
+// DefaultManageLinkTTL dipakai jika masa berlaku link kelola reservasi tidak diatur
+const DefaultManageLinkTTL = 30 * 24 * time.Hour
+
+// GuestReservationOptions berisi batasan untuk reservasi lewat halaman publik tanpa akun
+type GuestReservationOptions struct {
+	// Halaman frontend untuk mengelola reservasi, token ditambahkan sebagai query ?token=
+	ManageURL string
+	// Jumlah maksimal reservasi aktif yang akan datang per email atau nomor telepon. 0 berarti tanpa batas
+	MaxActivePerContact int
+	// Jumlah orang maksimal per reservasi. 0 berarti tanpa batas
+	MaxPartySize int
+	// Masa berlaku magic link. Mengubah jadwal reservasi tidak memperpanjangnya, tamu harus meminta link baru
+	ManageLinkTTL time.Duration
+}
+
+// GuestReservationService melayani tamu tanpa akun. Tamu memesan lewat endpoint publik lalu mengelola reservasinya
+// lewat magic link yang dikirim ke emailnya. Validasi meja, jadwal dan bentrok tetap dilakukan oleh ReservationService
+type GuestReservationService struct {
+	reservations *ReservationService
+	manageTokens repository.ReservationManageTokenRepository
+	options      GuestReservationOptions
+}
+
+func NewGuestReservationService(reservations *ReservationService, manageTokens repository.ReservationManageTokenRepository, options GuestReservationOptions) *GuestReservationService {
+	if options.ManageLinkTTL <= 0 {
+		options.ManageLinkTTL = DefaultManageLinkTTL
+	}
+	return &GuestReservationService{reservations: reservations, manageTokens: manageTokens, options: options}
+}
+
+// CreateReservation membuat reservasi untuk tamu tanpa akun dan mengirim magic link lewat email konfirmasi.
+// Token sengaja tidak dikembalikan ke client supaya hanya pemilik email yang bisa mengelola reservasi
+func (s *GuestReservationService) CreateReservation(reservation *models.Reservation) error {
+	guest := reservation.GuestContact
+	if guest == nil || strings.TrimSpace(guest.Name) == "" || strings.TrimSpace(guest.Email) == "" || strings.TrimSpace(guest.Phone) == "" {
+		return ErrGuestContactRequired
+	}
+	guest.Name, guest.Email, guest.Phone = strings.TrimSpace(guest.Name), strings.TrimSpace(guest.Email), strings.TrimSpace(guest.Phone)
+	reservation.UserID = 0
+
+	if err := s.reservations.Validator.Struct(reservation); err != nil {
+		return fmt.Errorf("invalid reservation data: %w", err)
+	}
+	if err := s.checkPartySize(reservation.NumberOfPeople); err != nil {
+		return err
+	}
+
+	err := s.reservations.createReservation(reservation, func(repos repository.Repositories, reservation *models.Reservation, data *notification.ReservationData) error {
+		if err := s.checkActiveLimit(repos, guest); err != nil {
+			return err
+		}
+		return s.issueManageLink(repos, reservation, data)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Muat ulang supaya status default dan relasi meja ikut terisi
+	created, err := s.reservations.reservationRepo.GetReservationDetail(reservation.ID)
+	if err != nil {
+		return err
+	}
+	*reservation = *created
+	return nil
+}
+
+// checkActiveLimit membatasi jumlah reservasi aktif per kontak supaya endpoint publik tidak dipakai untuk memborong meja.
+// Dipanggil di transaksi yang menyimpan reservasi baru, jadi reservasi itu ikut terhitung. Kontak dikunci lebih dulu
+// supaya request bersamaan dari kontak yang sama tidak bisa sama-sama lolos
+func (s *GuestReservationService) checkActiveLimit(repos repository.Repositories, guest *models.GuestContact) error {
+	if s.options.MaxActivePerContact <= 0 {
+		return nil
+	}
+
+	if err := repos.GuestContacts.LockContact(guest.Email, guest.Phone); err != nil {
+		return err
+	}
+	count, err := repos.Reservations.CountUpcomingGuestReservations(guest.Email, guest.Phone, time.Now())
+	if err != nil {
+		return err
+	}
+	if count > int64(s.options.MaxActivePerContact) {
+		return ErrTooManyGuestReservations.Withf("at most %d upcoming reservations are allowed for one contact", s.options.MaxActivePerContact)
+	}
+	return nil
+}
+
+// ResendManageLink mengirim link baru untuk setiap reservasi tamu yang akan datang dengan email tersebut.
+// Link lama langsung tidak berlaku. Email yang tidak dikenal tidak menghasilkan error supaya tidak bisa dipakai untuk menebak kontak tamu
+func (s *GuestReservationService) ResendManageLink(email string) error {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return ErrGuestContactRequired.Withf("email is required")
+	}
+
+	ids, err := s.reservations.reservationRepo.GetUpcomingGuestReservationIDs(email, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		reservation, err := s.reservations.reservationRepo.GetReservationDetail(id)
+		if err != nil {
+			return err
+		}
+
+		// Token baru dan email-nya ditulis dalam satu transaksi, jadi link yang dikirim selalu link yang tersimpan
+		err = s.reservations.uow.Do(func(repos repository.Repositories) error {
+			data := reservationNotification(*reservation)
+			if err := s.issueManageLink(repos, reservation, &data); err != nil {
+				return err
+			}
+			return NewOutboxNotifier(repos.Outbox).ReservationManageLink(reservation.ContactEmail(), data)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetReservation mengembalikan reservasi dari magic link. Link tidak berlaku lagi setelah reservasi selesai
+func (s *GuestReservationService) GetReservation(token string) (*models.Reservation, error) {
+	reservation, _, err := s.authorize(token)
+	if err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// UpdateReservation mengubah jadwal, meja atau jumlah orang dengan aturan yang sama seperti customer,
+// termasuk batas waktu perubahan sebelum reservasi dimulai
+func (s *GuestReservationService) UpdateReservation(token string, updatedReservation models.Reservation) (*models.Reservation, error) {
+	reservation, actor, err := s.authorize(token)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkPartySize(updatedReservation.NumberOfPeople); err != nil {
+		return nil, err
+	}
+
+	// Tamu tidak bisa memindahkan reservasi ke user lain
+	updatedReservation.UserID = 0
+	if err := s.reservations.UpdateReservation(reservation.ID, updatedReservation, actor); err != nil {
+		return nil, err
+	}
+	return s.reservations.reservationRepo.GetReservationDetail(reservation.ID)
+}
+
+// CancelReservation membatalkan reservasi dari magic link
+func (s *GuestReservationService) CancelReservation(token string, reason string) (*models.Reservation, error) {
+	reservation, actor, err := s.authorize(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.reservations.CancelReservation(reservation.ID, actor, reason); err != nil {
+		return nil, err
+	}
+	return s.reservations.reservationRepo.GetReservationDetail(reservation.ID)
+}
+
+// issueManageLink membuat token acak baru untuk reservasi, menyimpan hash-nya menggantikan token lama,
+// lalu mengisi link beserta masa berlakunya ke data email
+func (s *GuestReservationService) issueManageLink(repos repository.Repositories, reservation *models.Reservation, data *notification.ReservationData) error {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return fmt.Errorf("failed to generate manage token: %w", err)
+	}
+
+	record := &models.ReservationManageToken{
+		ReservationID:  reservation.ID,
+		GuestContactID: *reservation.GuestContactID,
+		TokenHash:      utils.HashToken(token),
+		ExpiresAt:      time.Now().Add(s.options.ManageLinkTTL),
+	}
+	if err := repos.ManageTokens.SaveReservationManageToken(record); err != nil {
+		return err
+	}
+
+	data.ManageLink = linkWithToken(s.options.ManageURL, token)
+	data.ManageLinkExpiresAt = record.ExpiresAt.Format("2006-01-02 15:04")
+	return nil
+}
+
+// authorize memvalidasi magic link lalu mengembalikan reservasinya beserta actor tamu pemiliknya.
+// Semua kegagalan dilaporkan sebagai token tidak valid supaya id reservasi lain tidak bisa ditebak
+func (s *GuestReservationService) authorize(token string) (*models.Reservation, Actor, error) {
+	if token == "" {
+		return nil, Actor{}, ErrInvalidReservationManageToken
+	}
+
+	record, err := s.manageTokens.GetReservationManageTokenByHash(utils.HashToken(token))
+	if err != nil {
+		return nil, Actor{}, err
+	}
+	if record == nil || !record.IsUsable(time.Now()) {
+		return nil, Actor{}, ErrInvalidReservationManageToken
+	}
+
+	reservation, err := s.reservations.reservationRepo.GetReservationDetail(record.ReservationID)
+	if err != nil {
+		return nil, Actor{}, ErrInvalidReservationManageToken
+	}
+
+	actor := Actor{GuestContactID: record.GuestContactID}
+	if !isGuestOwner(reservation, actor) || !reservation.EndTime().After(time.Now()) {
+		return nil, Actor{}, ErrInvalidReservationManageToken
+	}
+	return reservation, actor, nil
+}
+
+func (s *GuestReservationService) checkPartySize(numberOfPeople int) error {
+	if s.options.MaxPartySize > 0 && numberOfPeople > s.options.MaxPartySize {
+		return ErrGuestPartyTooLarge.Withf("online booking is limited to %d people, please contact the restaurant for larger parties", s.options.MaxPartySize)
+	}
+	return nil
+}
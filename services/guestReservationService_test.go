@@ -0,0 +1,228 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+	"wereserve/models"
+	"wereserve/notification"
+	"wereserve/repository/memory"
+	"wereserve/utils"
+)
+
+func newGuestReservationService(t *testing.T, options GuestReservationOptions) (*GuestReservationService, *memory.Store, []models.Table) {
+	t.Helper()
+	reservationService, store, _, tables := newMemoryReservationService(t)
+	return NewGuestReservationService(reservationService, store.ReservationManageTokens(), options), store, tables
+}
+
+func guestReservation(table models.Table, email, phone string, people int) models.Reservation {
+	return models.Reservation{
+		TableID:             table.ID,
+		ReservationDateTime: tomorrowAt(12, 0),
+		NumberOfPeople:      people,
+		GuestContact:        &models.GuestContact{Name: "Sari", Email: email, Phone: phone},
+	}
+}
+
+// manageTokenFromOutbox mengambil token magic link dari email konfirmasi atau kirim ulang link terakhir di outbox
+func manageTokenFromOutbox(t *testing.T, store *memory.Store, to string) string {
+	t.Helper()
+
+	// Pesan outbox diurutkan dari yang terbaru
+	messages, _ := store.Outbox().GetMessages(models.OutboxStatusPending)
+	for _, message := range messages {
+		if (message.EventType != notification.EventReservationCreated && message.EventType != notification.EventReservationManageLink) || message.Recipient != to {
+			continue
+		}
+		var data notification.ReservationData
+		if err := json.Unmarshal([]byte(message.Payload), &data); err != nil {
+			t.Fatalf("invalid outbox payload: %v", err)
+		}
+		link, err := url.Parse(data.ManageLink)
+		if err != nil || link.Query().Get("token") == "" {
+			t.Fatalf("manage link = %q, want a link with a token", data.ManageLink)
+		}
+		return link.Query().Get("token")
+	}
+	t.Fatalf("no manage link email to %s in the outbox", to)
+	return ""
+}
+
+func TestGuestReservationManageLink(t *testing.T) {
+	service, store, tables := newGuestReservationService(t, GuestReservationOptions{ManageURL: "http://localhost:3000/reservations/manage"})
+
+	reservation := guestReservation(tables[0], "sari@example.com", "0812", 2)
+	if err := service.CreateReservation(&reservation); err != nil {
+		t.Fatalf("CreateReservation() error = %v", err)
+	}
+	if reservation.UserID != 0 || reservation.GuestContactID == nil || reservation.Status != models.ReservationStatusPending {
+		t.Fatalf("reservation = user %d, guest %v, status %q, want a pending guest reservation", reservation.UserID, reservation.GuestContactID, reservation.Status)
+	}
+	token := manageTokenFromOutbox(t, store, "sari@example.com")
+
+	got, err := service.GetReservation(token)
+	if err != nil || got.ID != reservation.ID {
+		t.Fatalf("GetReservation() = %v, %v, want reservation %d", got, err, reservation.ID)
+	}
+
+	got, err = service.UpdateReservation(token, models.Reservation{NumberOfPeople: 3})
+	if err != nil || got.NumberOfPeople != 3 {
+		t.Fatalf("UpdateReservation() = %v, %v, want 3 people", got, err)
+	}
+
+	got, err = service.CancelReservation(token, "berubah rencana")
+	if err != nil || got.Status != models.ReservationStatusCancelled {
+		t.Fatalf("CancelReservation() = %v, %v, want cancelled", got, err)
+	}
+
+	// Reservasi yang sudah dibatalkan masih bisa dilihat, tapi tidak bisa diubah lagi
+	if _, err := service.GetReservation(token); err != nil {
+		t.Errorf("GetReservation() after cancel error = %v", err)
+	}
+	if _, err := service.UpdateReservation(token, models.Reservation{NumberOfPeople: 2}); !errors.Is(err, ErrReservationNotActive) {
+		t.Errorf("UpdateReservation() after cancel error = %v, want %v", err, ErrReservationNotActive)
+	}
+}
+
+func TestGuestReservationRejectsInvalidTokens(t *testing.T) {
+	service, store, tables := newGuestReservationService(t, GuestReservationOptions{})
+
+	reservation := guestReservation(tables[0], "sari@example.com", "0812", 2)
+	if err := service.CreateReservation(&reservation); err != nil {
+		t.Fatalf("CreateReservation() error = %v", err)
+	}
+	other := guestReservation(tables[1], "rina@example.com", "0813", 2)
+	if err := service.CreateReservation(&other); err != nil {
+		t.Fatalf("CreateReservation() error = %v", err)
+	}
+
+	// Token kedaluwarsa, token milik kontak lain, reservasi yang sudah selesai dan link pengingat tidak bisa dipakai sebagai magic link
+	saveToken := func(token string, reservationID, guestContactID int, expiresAt time.Time) {
+		t.Helper()
+		err := store.ReservationManageTokens().SaveReservationManageToken(&models.ReservationManageToken{
+			ReservationID: reservationID, GuestContactID: guestContactID, TokenHash: utils.HashToken(token), ExpiresAt: expiresAt,
+		})
+		if err != nil {
+			t.Fatalf("failed to save manage token: %v", err)
+		}
+	}
+	saveToken("expired", reservation.ID, *reservation.GuestContactID, time.Now().Add(-time.Minute))
+	saveToken("other-guest", other.ID, *reservation.GuestContactID, time.Now().Add(time.Hour))
+	finished := models.Reservation{TableID: tables[0].ID, ReservationDateTime: time.Now().Add(-3 * time.Hour), NumberOfPeople: 2,
+		GuestContactID: reservation.GuestContactID, Tables: []models.Table{tables[0]}}
+	if err := store.Reservations().CreateReservation(&finished); err != nil {
+		t.Fatalf("failed to create finished reservation: %v", err)
+	}
+	saveToken("finished", finished.ID, *reservation.GuestContactID, time.Now().Add(time.Hour))
+	reminder, _ := utils.GenerateReservationActionToken(reservation.ID, utils.ReservationActionCancel, time.Now().Add(time.Hour))
+
+	for name, token := range map[string]string{"garbage": "not-a-token", "expired": "expired", "other guest": "other-guest",
+		"finished reservation": "finished", "reminder token": reminder} {
+		if _, err := service.GetReservation(token); !errors.Is(err, ErrInvalidReservationManageToken) {
+			t.Errorf("GetReservation(%s) error = %v, want %v", name, err, ErrInvalidReservationManageToken)
+		}
+		if _, err := service.CancelReservation(token, ""); !errors.Is(err, ErrInvalidReservationManageToken) {
+			t.Errorf("CancelReservation(%s) error = %v, want %v", name, err, ErrInvalidReservationManageToken)
+		}
+	}
+}
+
+func TestGuestReservationResendRotatesManageLink(t *testing.T) {
+	service, store, tables := newGuestReservationService(t, GuestReservationOptions{ManageURL: "http://localhost:3000/reservations/manage", ManageLinkTTL: time.Hour})
+
+	reservation := guestReservation(tables[0], "sari@example.com", "0812", 2)
+	if err := service.CreateReservation(&reservation); err != nil {
+		t.Fatalf("CreateReservation() error = %v", err)
+	}
+	oldToken := manageTokenFromOutbox(t, store, "sari@example.com")
+
+	// Email yang tidak punya reservasi tidak menghasilkan error maupun email
+	before, _ := store.Outbox().GetMessages(models.OutboxStatusPending)
+	if err := service.ResendManageLink("tidak-ada@example.com"); err != nil {
+		t.Fatalf("ResendManageLink(unknown) error = %v", err)
+	}
+	if after, _ := store.Outbox().GetMessages(models.OutboxStatusPending); len(after) != len(before) {
+		t.Errorf("expected no email for an unknown address, outbox grew from %d to %d", len(before), len(after))
+	}
+
+	if err := service.ResendManageLink("SARI@example.com"); err != nil {
+		t.Fatalf("ResendManageLink() error = %v", err)
+	}
+	newToken := manageTokenFromOutbox(t, store, "sari@example.com")
+	if newToken == oldToken {
+		t.Fatalf("expected a new token after resend")
+	}
+
+	if _, err := service.GetReservation(oldToken); !errors.Is(err, ErrInvalidReservationManageToken) {
+		t.Errorf("GetReservation(old token) error = %v, want %v", err, ErrInvalidReservationManageToken)
+	}
+	if got, err := service.GetReservation(newToken); err != nil || got.ID != reservation.ID {
+		t.Errorf("GetReservation(new token) = %v, %v, want reservation %d", got, err, reservation.ID)
+	}
+}
+
+func TestGuestReservationAbuseLimits(t *testing.T) {
+	service, store, tables := newGuestReservationService(t, GuestReservationOptions{MaxActivePerContact: 1, MaxPartySize: 3})
+
+	missingEmail := guestReservation(tables[0], "", "0812", 2)
+	if err := service.CreateReservation(&missingEmail); !errors.Is(err, ErrGuestContactRequired) {
+		t.Errorf("CreateReservation() without email error = %v, want %v", err, ErrGuestContactRequired)
+	}
+
+	tooLarge := guestReservation(tables[0], "sari@example.com", "0812", 4)
+	if err := service.CreateReservation(&tooLarge); !errors.Is(err, ErrGuestPartyTooLarge) {
+		t.Errorf("CreateReservation() with 4 people error = %v, want %v", err, ErrGuestPartyTooLarge)
+	}
+
+	first := guestReservation(tables[0], "sari@example.com", "0812", 2)
+	if err := service.CreateReservation(&first); err != nil {
+		t.Fatalf("CreateReservation() error = %v", err)
+	}
+
+	// Kontak yang sama dikenali dari email tanpa membedakan huruf besar kecil atau dari nomor telepon
+	for _, contact := range [][2]string{{"SARI@example.com", "0899"}, {"lain@example.com", "0812"}} {
+		again := guestReservation(tables[1], contact[0], contact[1], 2)
+		if err := service.CreateReservation(&again); !errors.Is(err, ErrTooManyGuestReservations) {
+			t.Errorf("CreateReservation(%s, %s) error = %v, want %v", contact[0], contact[1], err, ErrTooManyGuestReservations)
+		}
+	}
+
+	// Batas dicek di transaksi yang menyimpan reservasi, jadi reservasi yang ditolak tidak tersimpan
+	if count, err := store.Reservations().CountUpcomingGuestReservations("sari@example.com", "0812", time.Now()); err != nil || count != 1 {
+		t.Errorf("CountUpcomingGuestReservations() = %d, %v, want 1, nil", count, err)
+	}
+
+	// Batas jumlah orang juga berlaku saat tamu mengubah reservasinya lewat magic link
+	token := manageTokenFromOutbox(t, store, "sari@example.com")
+	if _, err := service.UpdateReservation(token, models.Reservation{NumberOfPeople: 4}); !errors.Is(err, ErrGuestPartyTooLarge) {
+		t.Errorf("UpdateReservation() with 4 people error = %v, want %v", err, ErrGuestPartyTooLarge)
+	}
+
+	// Setelah dibatalkan, kontak yang sama boleh memesan lagi
+	if _, err := service.CancelReservation(token, ""); err != nil {
+		t.Fatalf("CancelReservation() error = %v", err)
+	}
+	again := guestReservation(tables[1], "sari@example.com", "0812", 2)
+	if err := service.CreateReservation(&again); err != nil {
+		t.Errorf("CreateReservation() after cancel error = %v", err)
+	}
+}
+
+func TestRespondToReminderCancelsGuestReservation(t *testing.T) {
+	service, _, tables := newGuestReservationService(t, GuestReservationOptions{})
+
+	reservation := guestReservation(tables[0], "sari@example.com", "0812", 2)
+	if err := service.CreateReservation(&reservation); err != nil {
+		t.Fatalf("CreateReservation() error = %v", err)
+	}
+
+	// Link batal di email pengingat dijalankan atas nama tamu pemilik reservasi
+	token, _ := utils.GenerateReservationActionToken(reservation.ID, utils.ReservationActionCancel, time.Now().Add(time.Hour))
+	got, err := service.reservations.RespondToReminder(token)
+	if err != nil || got.Status != models.ReservationStatusCancelled {
+		t.Fatalf("RespondToReminder(cancel) = %v, %v, want cancelled", got, err)
+	}
+}
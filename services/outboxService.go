@@ -0,0 +1,159 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+	"wereserve/apperror"
+	"wereserve/models"
+	"wereserve/notification"
+	"wereserve/repository"
+)
+
+var (
+	ErrInvalidOutboxStatus = apperror.Validation("invalid_outbox_status", "status must be one of pending, sent or dead")
+	ErrOutboxNotDead       = apperror.Conflict("outbox_not_dead", "only dead outbox messages can be retried")
+	ErrOutboxRedacted      = apperror.Conflict("outbox_redacted", "the link in this notification is no longer stored, ask the user to request a new one")
+)
+
+// OutboxOptions mengatur cara worker mengirim ulang notifikasi yang gagal
+type OutboxOptions struct {
+	// Jumlah percobaan sebelum pesan berstatus dead
+	MaxAttempts int
+	// Jeda sebelum percobaan kedua, berlipat dua setiap kali gagal sampai MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Jumlah pesan yang diambil setiap kali worker berjalan
+	BatchSize int
+	// Lama pesan yang sedang dikirim tidak diambil oleh worker lain
+	Lease time.Duration
+}
+
+type OutboxService struct {
+	outboxRepo repository.OutboxRepository
+	notifier   notification.Notifier
+	options    OutboxOptions
+}
+
+// NewOutboxService membuat worker yang mengirim pesan di outbox lewat notifier
+func NewOutboxService(outboxRepo repository.OutboxRepository, notifier notification.Notifier, options OutboxOptions) *OutboxService {
+	return &OutboxService{
+		outboxRepo: outboxRepo,
+		notifier:   notifier,
+		options:    options,
+	}
+}
+
+// NewOutboxNotifier mengembalikan Notifier yang menyimpan notifikasi ke outbox, bukan langsung mengirimnya.
+// Jika outboxRepo terikat ke transaksi, notifikasi ikut di-commit atau di-rollback bersama perubahan data
+func NewOutboxNotifier(outboxRepo repository.OutboxRepository) notification.Notifier {
+	return notification.NewEventNotifier(func(event notification.Event) error {
+		// Tamu yang dipesankan staff tanpa email tidak mendapat notifikasi
+		if event.To == "" {
+			return nil
+		}
+		return outboxRepo.CreateMessage(&models.OutboxMessage{
+			EventType: event.Type,
+			Recipient: event.To,
+			Payload:   string(event.Payload),
+			Status:    models.OutboxStatusPending,
+		})
+	})
+}
+
+// ProcessDue mengirim pesan yang sudah waktunya dikirim dan mengembalikan jumlah pesan yang berhasil
+func (s *OutboxService) ProcessDue() (int, error) {
+	now := time.Now()
+	messages, err := s.outboxRepo.ClaimDueMessages(now, s.options.BatchSize, s.options.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, message := range messages {
+		event := notification.Event{Type: message.EventType, To: message.Recipient, Payload: []byte(message.Payload)}
+		if err := notification.Deliver(s.notifier, event); err != nil {
+			if markErr := s.markFailed(message, err); markErr != nil {
+				return sent, markErr
+			}
+			continue
+		}
+
+		// Link bertoken di payload dihapus setelah terkirim, supaya isi outbox tidak bisa dipakai untuk mengambil alih akun
+		if err := s.outboxRepo.MarkSent(message.ID, redactedPayload(message)); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+func (s *OutboxService) markFailed(message models.OutboxMessage, cause error) error {
+	attempts := message.Attempts + 1
+	dead := attempts >= s.options.MaxAttempts
+	if dead {
+		log.Printf("Outbox message %d (%s) moved to dead letter after %d attempts: %v", message.ID, message.EventType, attempts, cause)
+	}
+	// Pesan dead tidak dikirim lagi oleh worker, jadi link bertoken di dalamnya juga dihapus
+	payload := message.Payload
+	if dead {
+		payload = redactedPayload(message)
+	}
+	return s.outboxRepo.MarkFailed(message.ID, attempts, time.Now().Add(s.backoff(attempts)), cause.Error(), dead, payload)
+}
+
+func redactedPayload(message models.OutboxMessage) string {
+	return string(notification.RedactPayload(message.EventType, []byte(message.Payload)))
+}
+
+// backoff menghitung jeda sebelum percobaan berikutnya: BaseBackoff, 2x, 4x, ... dibatasi MaxBackoff
+func (s *OutboxService) backoff(attempts int) time.Duration {
+	return retryBackoff(s.options.BaseBackoff, s.options.MaxBackoff, attempts)
+}
+
+// retryBackoff menggandakan base untuk setiap percobaan yang gagal, dibatasi max jika max diisi
+func retryBackoff(base, max time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if max > 0 && delay >= max {
+			return max
+		}
+	}
+	return delay
+}
+
+// GetMessages mengembalikan isi outbox untuk admin, bisa difilter berdasarkan status
+func (s *OutboxService) GetMessages(status string) ([]models.OutboxMessage, error) {
+	switch status {
+	case "", models.OutboxStatusPending, models.OutboxStatusSent, models.OutboxStatusDead:
+	default:
+		return nil, ErrInvalidOutboxStatus
+	}
+	return s.outboxRepo.GetMessages(status)
+}
+
+func (s *OutboxService) GetMessage(id int) (*models.OutboxMessage, error) {
+	return s.outboxRepo.GetMessageByID(id)
+}
+
+// RetryMessage mengembalikan pesan dead ke antrian supaya dikirim lagi oleh worker
+func (s *OutboxService) RetryMessage(id int) error {
+	message, err := s.outboxRepo.GetMessageByID(id)
+	if err != nil {
+		return err
+	}
+	// Token di link yang sudah dihapus tidak bisa dikembalikan, user perlu meminta link baru
+	if notification.IsPayloadRedacted(message.EventType, []byte(message.Payload)) {
+		return fmt.Errorf("outbox message %d: %w", id, ErrOutboxRedacted)
+	}
+
+	retried, err := s.outboxRepo.RetryMessage(id)
+	if err != nil {
+		return err
+	}
+	if !retried {
+		return fmt.Errorf("outbox message %d: %w", id, ErrOutboxNotDead)
+	}
+	return nil
+}
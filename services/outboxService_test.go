@@ -0,0 +1,219 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"wereserve/models"
+	"wereserve/notification"
+	"wereserve/repository/memory"
+)
+
+func testOutboxOptions() OutboxOptions {
+	return OutboxOptions{
+		MaxAttempts: 3,
+		BaseBackoff: time.Minute,
+		MaxBackoff:  10 * time.Minute,
+		BatchSize:   10,
+		Lease:       time.Minute,
+	}
+}
+
+// failingMailer selalu gagal mengirim email
+type failingMailer struct{}
+
+func (failingMailer) Send(notification.Message) error {
+	return errors.New("smtp unavailable")
+}
+
+func newFailingOutbox(t *testing.T) (*OutboxService, *memory.Store, int) {
+	t.Helper()
+
+	return newFailingOutboxWith(t, func(notifier notification.Notifier) error {
+		return notifier.PasswordReset("budi@example.com", notification.LinkData{Name: "Budi", Link: "http://example.com/reset?token=abc"})
+	})
+}
+
+// newFailingOutboxWith menyiapkan outbox dengan satu pesan dari enqueue dan worker yang mailernya selalu gagal
+func newFailingOutboxWith(t *testing.T, enqueue func(notification.Notifier) error) (*OutboxService, *memory.Store, int) {
+	t.Helper()
+
+	store := memory.NewStore()
+	if err := enqueue(NewOutboxNotifier(store.Outbox())); err != nil {
+		t.Fatalf("enqueue error = %v", err)
+	}
+
+	messages, _ := store.Outbox().GetMessages("")
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 outbox message, got %d", len(messages))
+	}
+
+	worker := NewOutboxService(store.Outbox(), notification.NewEmailNotifier(failingMailer{}, notification.EmailOptions{}), testOutboxOptions())
+	return worker, store, messages[0].ID
+}
+
+// makeDue memajukan jadwal percobaan supaya pesan bisa diambil lagi tanpa menunggu backoff
+func makeDue(t *testing.T, store *memory.Store, id int) {
+	t.Helper()
+
+	message, err := store.Outbox().GetMessageByID(id)
+	if err != nil {
+		t.Fatalf("GetMessageByID() error = %v", err)
+	}
+	if err := store.Outbox().MarkFailed(id, message.Attempts, time.Now().Add(-time.Second), "", false, message.Payload); err != nil {
+		t.Fatalf("MarkFailed() error = %v", err)
+	}
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	worker := NewOutboxService(nil, nil, testOutboxOptions())
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: time.Minute},
+		{attempts: 2, want: 2 * time.Minute},
+		{attempts: 3, want: 4 * time.Minute},
+		{attempts: 4, want: 8 * time.Minute},
+		{attempts: 5, want: 10 * time.Minute},
+		{attempts: 20, want: 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := worker.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestOutboxRetriesUntilDead(t *testing.T) {
+	worker, store, id := newFailingOutbox(t)
+
+	before := time.Now()
+	if sent, err := worker.ProcessDue(); err != nil || sent != 0 {
+		t.Fatalf("ProcessDue() = %d, %v, want 0, nil", sent, err)
+	}
+
+	message, _ := store.Outbox().GetMessageByID(id)
+	if message.Status != models.OutboxStatusPending || message.Attempts != 1 {
+		t.Fatalf("expected pending message with 1 attempt, got %s with %d", message.Status, message.Attempts)
+	}
+	if message.LastError == nil || *message.LastError != "smtp unavailable" {
+		t.Errorf("expected last error to be recorded, got %v", message.LastError)
+	}
+	if message.NextAttemptAt.Before(before.Add(time.Minute)) {
+		t.Errorf("expected next attempt after the backoff, got %s", message.NextAttemptAt)
+	}
+
+	// Pesan yang masih menunggu backoff tidak diambil lagi
+	if _, err := worker.ProcessDue(); err != nil {
+		t.Fatalf("ProcessDue() error = %v", err)
+	}
+	message, _ = store.Outbox().GetMessageByID(id)
+	if message.Attempts != 1 {
+		t.Fatalf("expected message to wait for its backoff, got %d attempts", message.Attempts)
+	}
+
+	for i := 0; i < 2; i++ {
+		makeDue(t, store, id)
+		if _, err := worker.ProcessDue(); err != nil {
+			t.Fatalf("ProcessDue() error = %v", err)
+		}
+	}
+
+	message, _ = store.Outbox().GetMessageByID(id)
+	if message.Status != models.OutboxStatusDead || message.Attempts != 3 {
+		t.Fatalf("expected dead message after 3 attempts, got %s with %d", message.Status, message.Attempts)
+	}
+}
+
+func TestOutboxRetryDeadMessage(t *testing.T) {
+	worker, store, id := newFailingOutboxWith(t, func(notifier notification.Notifier) error {
+		return notifier.ReservationCancelled("budi@example.com", notification.ReservationData{Name: "Budi", TableName: "Table 1", PartySize: 2})
+	})
+
+	if err := worker.RetryMessage(id); !errors.Is(err, ErrOutboxNotDead) {
+		t.Fatalf("RetryMessage() on pending message error = %v, want %v", err, ErrOutboxNotDead)
+	}
+
+	for i := 0; i < 3; i++ {
+		makeDue(t, store, id)
+		if _, err := worker.ProcessDue(); err != nil {
+			t.Fatalf("ProcessDue() error = %v", err)
+		}
+	}
+
+	if err := worker.RetryMessage(id); err != nil {
+		t.Fatalf("RetryMessage() error = %v", err)
+	}
+
+	// Setelah di-retry admin, pesan dikirim dengan mailer yang sudah pulih
+	mailer := notification.NewMemoryMailer()
+	worker.notifier = notification.NewEmailNotifier(mailer, notification.EmailOptions{})
+	if sent, err := worker.ProcessDue(); err != nil || sent != 1 {
+		t.Fatalf("ProcessDue() = %d, %v, want 1, nil", sent, err)
+	}
+
+	message, _ := store.Outbox().GetMessageByID(id)
+	if message.Status != models.OutboxStatusSent || message.SentAt == nil {
+		t.Fatalf("expected sent message, got %s", message.Status)
+	}
+	if last, ok := mailer.Last("budi@example.com"); !ok || !strings.Contains(last.Subject, "Cancelled") {
+		t.Errorf("expected the cancellation email to be delivered")
+	}
+
+	if err := worker.RetryMessage(9999); err == nil {
+		t.Errorf("expected an error for an unknown message")
+	}
+}
+
+func TestOutboxRedactsDeadMessage(t *testing.T) {
+	worker, store, id := newFailingOutbox(t)
+
+	for i := 0; i < 3; i++ {
+		makeDue(t, store, id)
+		if _, err := worker.ProcessDue(); err != nil {
+			t.Fatalf("ProcessDue() error = %v", err)
+		}
+	}
+
+	message, _ := store.Outbox().GetMessageByID(id)
+	if message.Status != models.OutboxStatusDead {
+		t.Fatalf("expected dead message, got %s", message.Status)
+	}
+	if strings.Contains(message.Payload, "token=abc") {
+		t.Errorf("expected the reset link to be redacted from a dead message, got %s", message.Payload)
+	}
+
+	// Link yang sudah dihapus tidak bisa dikirim ulang
+	if err := worker.RetryMessage(id); !errors.Is(err, ErrOutboxRedacted) {
+		t.Fatalf("RetryMessage() error = %v, want %v", err, ErrOutboxRedacted)
+	}
+}
+
+func TestOutboxRedactsSentMessage(t *testing.T) {
+	store := memory.NewStore()
+	notifier := NewOutboxNotifier(store.Outbox())
+	if err := notifier.PasswordReset("budi@example.com", notification.LinkData{Name: "Budi", Link: "http://example.com/reset?token=abc"}); err != nil {
+		t.Fatalf("PasswordReset() error = %v", err)
+	}
+
+	mailer := notification.NewMemoryMailer()
+	worker := NewOutboxService(store.Outbox(), notification.NewEmailNotifier(mailer, notification.EmailOptions{}), testOutboxOptions())
+	if sent, err := worker.ProcessDue(); err != nil || sent != 1 {
+		t.Fatalf("ProcessDue() = %d, %v, want 1, nil", sent, err)
+	}
+
+	// Email yang terkirim tetap berisi link, tapi salinan di outbox tidak
+	if last, ok := mailer.Last("budi@example.com"); !ok || !strings.Contains(last.HTMLBody, "token=abc") {
+		t.Fatalf("expected the delivered email to contain the reset link")
+	}
+	messages, _ := store.Outbox().GetMessages(models.OutboxStatusSent)
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 sent message, got %d", len(messages))
+	}
+	if strings.Contains(messages[0].Payload, "token=abc") {
+		t.Errorf("expected the reset link to be redacted from a sent message, got %s", messages[0].Payload)
+	}
+}
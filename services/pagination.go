@@ -0,0 +1,43 @@
+package services
+
+import (
+	"fmt"
+	"wereserve/apperror"
+	"wereserve/models"
+	"wereserve/repository"
+)
+
+var (
+	ErrInvalidSortField         = apperror.Validation("invalid_sort_field", "invalid sort field")
+	ErrInvalidReservationStatus = apperror.Validation("invalid_reservation_status", "invalid reservation status")
+)
+
+// checkPage memastikan field sort ada di whitelist dan limit berada di antara 1 dan MaxPageLimit
+func checkPage(page *repository.Page, sortFields []string) error {
+	if page.Sort == "" {
+		page.Sort = "id"
+	}
+	if !repository.IsSortField(sortFields, page.Sort) {
+		return fmt.Errorf("%w %q: must be one of %v", ErrInvalidSortField, page.Sort, sortFields)
+	}
+
+	if page.Limit <= 0 {
+		page.Limit = repository.DefaultPageLimit
+	}
+	if page.Limit > repository.MaxPageLimit {
+		page.Limit = repository.MaxPageLimit
+	}
+	if page.Offset < 0 {
+		page.Offset = 0
+	}
+	return nil
+}
+
+func isReservationStatus(status string) bool {
+	switch status {
+	case models.ReservationStatusPending, models.ReservationStatusConfirmed, models.ReservationStatusSeated,
+		models.ReservationStatusCompleted, models.ReservationStatusCancelled, models.ReservationStatusNoShow:
+		return true
+	}
+	return false
+}
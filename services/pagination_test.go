@@ -0,0 +1,81 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"wereserve/models"
+	"wereserve/repository"
+)
+
+func TestListReservationsFiltersAndPages(t *testing.T) {
+	service, store, user, tables := newMemoryReservationService(t)
+
+	// Lima reservasi di meja A1 dan A2, satu per jam mulai pukul 10
+	var created []models.Reservation
+	for i := 0; i < 5; i++ {
+		created = append(created, createReservationAt(t, store, user, tables[i%2], tomorrowAt(10+i, 0)))
+	}
+	dayAfter := tomorrowAt(0, 0).AddDate(0, 0, 1)
+	createReservationAt(t, store, user, tables[0], dayAfter.Add(12*time.Hour))
+
+	from := tomorrowAt(0, 0)
+	tests := []struct {
+		name    string
+		filter  repository.ReservationFilter
+		wantIDs []int
+		total   int64
+	}{
+		{
+			name:    "date range and table",
+			filter:  repository.ReservationFilter{From: &from, To: &dayAfter, TableID: tables[0].ID},
+			wantIDs: []int{created[0].ID, created[2].ID, created[4].ID},
+			total:   3,
+		},
+		{
+			name:    "second page sorted by start time descending",
+			filter:  repository.ReservationFilter{From: &from, To: &dayAfter, Page: repository.Page{Limit: 2, Offset: 2, Sort: "reservation_datetime", Desc: true}},
+			wantIDs: []int{created[2].ID, created[1].ID},
+			total:   5,
+		},
+		{
+			name:   "status without matches",
+			filter: repository.ReservationFilter{Status: models.ReservationStatusSeated},
+			total:  0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reservations, total, err := service.ListReservations(tt.filter)
+			if err != nil {
+				t.Fatalf("ListReservations() error = %v", err)
+			}
+			if total != tt.total {
+				t.Errorf("total = %d, want %d", total, tt.total)
+			}
+			var gotIDs []int
+			for _, reservation := range reservations {
+				gotIDs = append(gotIDs, reservation.ID)
+			}
+			if len(gotIDs) != len(tt.wantIDs) {
+				t.Fatalf("ids = %v, want %v", gotIDs, tt.wantIDs)
+			}
+			for i := range gotIDs {
+				if gotIDs[i] != tt.wantIDs[i] {
+					t.Fatalf("ids = %v, want %v", gotIDs, tt.wantIDs)
+				}
+			}
+		})
+	}
+}
+
+func TestListReservationsRejectsInvalidQuery(t *testing.T) {
+	service, _, _, _ := newMemoryReservationService(t)
+
+	if _, _, err := service.ListReservations(repository.ReservationFilter{Page: repository.Page{Sort: "password"}}); !errors.Is(err, ErrInvalidSortField) {
+		t.Errorf("ListReservations() error = %v, want %v", err, ErrInvalidSortField)
+	}
+	if _, _, err := service.ListReservations(repository.ReservationFilter{Status: "unknown"}); !errors.Is(err, ErrInvalidReservationStatus) {
+		t.Errorf("ListReservations() error = %v, want %v", err, ErrInvalidReservationStatus)
+	}
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"wereserve/apperror"
+	"wereserve/models"
+)
+
+var ErrUserForbidden = apperror.Forbidden("user_forbidden", "you are not allowed to access this user")
+
+// Policy adalah aturan ownership untuk satu jenis aksi: pemilik resource selalu boleh,
+// user lain hanya jika role-nya punya permission Override. Admin lolos karena role admin punya semua permission
+type Policy struct {
+	Override  string
+	Forbidden error
+}
+
+// Policy yang dipakai oleh service untuk akun user, reservasi dan waitlist.
+// PolicyReservationOwner berlaku saat memesan untuk user lain atau tamu, yang hanya boleh dilakukan staff
+var (
+	PolicyUserRead          = Policy{Override: models.PermissionUserRead, Forbidden: ErrUserForbidden}
+	PolicyUserWrite         = Policy{Override: models.PermissionUserWrite, Forbidden: ErrUserForbidden}
+	PolicyReservationRead   = Policy{Override: models.PermissionReservationRead, Forbidden: ErrReservationForbidden}
+	PolicyReservationUpdate = Policy{Override: models.PermissionReservationUpdate, Forbidden: ErrReservationForbidden}
+	PolicyReservationCancel = Policy{Override: models.PermissionReservationCancel, Forbidden: ErrReservationForbidden}
+	PolicyReservationOwner  = Policy{Override: models.PermissionReservationUpdate, Forbidden: ErrReservationForbidden}
+	PolicyWaitlist          = Policy{Override: models.PermissionWaitlistManage, Forbidden: ErrWaitlistForbidden}
+)
+
+// IsOverridden mengecek apakah actor boleh mengakses resource milik siapa saja
+func (p Policy) IsOverridden(actor Actor) bool {
+	return actor.Can(p.Override)
+}
+
+// Authorize mengembalikan p.Forbidden jika actor bukan pemilik resource dan tidak punya permission override.
+// ownerID 0 berarti resource tidak dimiliki user mana pun, jadi hanya bisa diakses lewat override
+func (p Policy) Authorize(actor Actor, ownerID int) error {
+	if p.IsOverridden(actor) || (ownerID != 0 && actor.UserID == ownerID) {
+		return nil
+	}
+	return p.Forbidden
+}
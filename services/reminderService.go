@@ -0,0 +1,114 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+	"wereserve/models"
+	"wereserve/notification"
+	"wereserve/repository"
+	"wereserve/utils"
+)
+
+// ReminderOptions mengatur kapan pengingat reservasi dikirim
+type ReminderOptions struct {
+	// Jarak pengingat sebelum reservation_datetime, misalnya 24 jam dan 2 jam
+	Offsets []time.Duration
+	// Endpoint yang menerima token dari link konfirmasi dan pembatalan
+	ActionURL string
+	// Batas waktu customer membatalkan reservasi. Link pembatalan tidak dikirim jika batas ini sudah lewat
+	Cutoff time.Duration
+}
+
+type ReminderService struct {
+	uow          repository.UnitOfWork
+	reminderRepo repository.ReminderRepository
+	options      ReminderOptions
+}
+
+func NewReminderService(uow repository.UnitOfWork, reminderRepo repository.ReminderRepository, options ReminderOptions) *ReminderService {
+	// Offset diurutkan dari yang terkecil supaya reservasi yang sudah dekat hanya mendapat pengingat terakhir
+	offsets := append([]time.Duration(nil), options.Offsets...)
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	options.Offsets = offsets
+
+	return &ReminderService{
+		uow:          uow,
+		reminderRepo: reminderRepo,
+		options:      options,
+	}
+}
+
+// SendDueReminders menulis pengingat untuk reservasi yang sudah memasuki salah satu offset ke outbox,
+// lalu mengembalikan jumlah pengingat yang dikirim. Setiap offset dicatat di reservation_reminders di transaksi yang sama,
+// sehingga restart tidak membuat pengingat terkirim dua kali
+func (s *ReminderService) SendDueReminders() (int, error) {
+	now := time.Now()
+	reminded := map[int]bool{}
+	sent := 0
+
+	for _, offset := range s.options.Offsets {
+		minutes := int(offset / time.Minute)
+		reservations, err := s.reminderRepo.GetReservationsDueForReminder(minutes, now)
+		if err != nil {
+			return sent, err
+		}
+
+		for _, reservation := range reservations {
+			// Reservasi yang sudah diingatkan untuk offset lebih kecil tidak perlu pengingat lagi,
+			// offset ini cukup dicatat supaya tidak diambil lagi
+			notify := !reminded[reservation.ID]
+			reminded[reservation.ID] = true
+
+			created := false
+			err := s.uow.Do(func(repos repository.Repositories) error {
+				var err error
+				created, err = repos.Reminders.CreateReminder(reservation.ID, minutes)
+				if err != nil || !created || !notify {
+					return err
+				}
+
+				data, err := s.reminderData(reservation, now)
+				if err != nil {
+					return err
+				}
+				return NewOutboxNotifier(repos.Outbox).ReservationReminder(reservation.ContactEmail(), data)
+			})
+			if err != nil {
+				return sent, fmt.Errorf("failed to send reminder for reservation %d: %w", reservation.ID, err)
+			}
+
+			if created && notify {
+				log.Printf("Queued %s reminder for reservation %d", offset, reservation.ID)
+				sent++
+			}
+		}
+	}
+
+	return sent, nil
+}
+
+func (s *ReminderService) reminderData(reservation models.Reservation, now time.Time) (notification.ReminderData, error) {
+	confirmToken, err := utils.GenerateReservationActionToken(reservation.ID, utils.ReservationActionConfirm, reservation.ReservationDateTime)
+	if err != nil {
+		return notification.ReminderData{}, fmt.Errorf("failed to generate confirmation link: %w", err)
+	}
+
+	data := notification.ReminderData{
+		ReservationData: reservationNotification(reservation),
+		ConfirmLink:     linkWithToken(s.options.ActionURL, confirmToken),
+	}
+
+	// Link pembatalan hanya berlaku sampai batas waktu pembatalan oleh customer
+	cancelUntil := reservation.ReservationDateTime.Add(-s.options.Cutoff)
+	if now.Before(cancelUntil) {
+		cancelToken, err := utils.GenerateReservationActionToken(reservation.ID, utils.ReservationActionCancel, cancelUntil)
+		if err != nil {
+			return notification.ReminderData{}, fmt.Errorf("failed to generate cancellation link: %w", err)
+		}
+		data.CancelLink = linkWithToken(s.options.ActionURL, cancelToken)
+	}
+
+	return data, nil
+}
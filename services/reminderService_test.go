@@ -0,0 +1,164 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+	"wereserve/models"
+	"wereserve/notification"
+	"wereserve/repository/memory"
+)
+
+const testReminderURL = "http://localhost:8080/api/reservation/respond"
+
+func newMemoryReminderService(store *memory.Store, cutoff time.Duration) *ReminderService {
+	return NewReminderService(store.UnitOfWork(), store.Reminders(), ReminderOptions{
+		Offsets:   []time.Duration{2 * time.Hour, 24 * time.Hour},
+		ActionURL: testReminderURL,
+		Cutoff:    cutoff,
+	})
+}
+
+// createReservationAt menyimpan reservasi langsung ke store tanpa validasi jadwal, supaya waktunya bisa relatif terhadap sekarang
+func createReservationAt(t *testing.T, store *memory.Store, user models.User, table models.Table, start time.Time) models.Reservation {
+	t.Helper()
+
+	reservation := models.Reservation{UserID: user.ID, TableID: table.ID, ReservationDateTime: start, NumberOfPeople: 2, Tables: []models.Table{table}}
+	if err := store.Reservations().CreateReservation(&reservation); err != nil {
+		t.Fatalf("failed to create reservation: %v", err)
+	}
+	return reservation
+}
+
+// deliverReminders mengirim isi outbox lalu mengembalikan link aksi dari email pengingat terakhir
+func deliverReminders(t *testing.T, store *memory.Store) (*notification.MemoryMailer, []string) {
+	t.Helper()
+
+	mailer := notification.NewMemoryMailer()
+	if _, err := NewOutboxService(store.Outbox(), notification.NewEmailNotifier(mailer, notification.EmailOptions{}), testOutboxOptions()).ProcessDue(); err != nil {
+		t.Fatalf("ProcessDue() error = %v", err)
+	}
+
+	pattern := regexp.MustCompile(regexp.QuoteMeta(testReminderURL) + `\?token=([A-Za-z0-9_.\-]+)`)
+	var tokens []string
+	messages := mailer.Messages()
+	if len(messages) > 0 {
+		for _, match := range pattern.FindAllStringSubmatch(messages[len(messages)-1].HTMLBody, -1) {
+			tokens = append(tokens, match[1])
+		}
+	}
+	return mailer, tokens
+}
+
+func TestSendDueRemindersOncePerOffset(t *testing.T) {
+	_, store, user, tables := newMemoryReservationService(t)
+	reminders := newMemoryReminderService(store, 0)
+
+	soon := createReservationAt(t, store, user, tables[0], time.Now().Add(time.Hour))
+	later := createReservationAt(t, store, user, tables[1], time.Now().Add(5*time.Hour))
+	createReservationAt(t, store, user, tables[0], time.Now().Add(48*time.Hour))
+
+	// Reservasi 1 jam lagi sudah melewati kedua offset, tapi hanya mendapat satu pengingat
+	if sent, err := reminders.SendDueReminders(); err != nil || sent != 2 {
+		t.Fatalf("SendDueReminders() = %d, %v, want 2, nil", sent, err)
+	}
+
+	// Pengingat yang sudah dicatat tidak dikirim lagi, misalnya setelah restart
+	if sent, err := NewReminderService(store.UnitOfWork(), store.Reminders(), reminders.options).SendDueReminders(); err != nil || sent != 0 {
+		t.Fatalf("second SendDueReminders() = %d, %v, want 0, nil", sent, err)
+	}
+
+	messages, _ := store.Outbox().GetMessages("")
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 outbox messages, got %d", len(messages))
+	}
+	for _, message := range messages {
+		if message.EventType != notification.EventReservationReminder {
+			t.Errorf("unexpected outbox event %q", message.EventType)
+		}
+	}
+
+	// Offset 24 jam ikut dicatat untuk reservasi yang sudah diingatkan
+	due, _ := store.Reminders().GetReservationsDueForReminder(24*60, time.Now())
+	for _, reservation := range due {
+		if reservation.ID == soon.ID || reservation.ID == later.ID {
+			t.Errorf("expected the 24h reminder of reservation %d to be recorded", reservation.ID)
+		}
+	}
+}
+
+func TestSendDueRemindersSkipsInactiveReservations(t *testing.T) {
+	service, store, user, tables := newMemoryReservationService(t)
+	reminders := newMemoryReminderService(store, 0)
+
+	reservation := createReservationAt(t, store, user, tables[0], time.Now().Add(time.Hour))
+	if err := service.CancelReservation(reservation.ID, Actor{UserID: user.ID, Role: "customer"}, "plans changed"); err != nil {
+		t.Fatalf("CancelReservation() error = %v", err)
+	}
+
+	if sent, err := reminders.SendDueReminders(); err != nil || sent != 0 {
+		t.Fatalf("SendDueReminders() = %d, %v, want 0, nil", sent, err)
+	}
+}
+
+func TestRespondToReminder(t *testing.T) {
+	service, store, user, tables := newMemoryReservationService(t)
+	reminders := newMemoryReminderService(store, 0)
+
+	reservation := createReservationAt(t, store, user, tables[0], time.Now().Add(time.Hour))
+	if _, err := reminders.SendDueReminders(); err != nil {
+		t.Fatalf("SendDueReminders() error = %v", err)
+	}
+
+	mailer, tokens := deliverReminders(t, store)
+	if last, ok := mailer.Last(user.Email); !ok || last.Subject != "Reminder: Your Upcoming WeReserve Reservation" {
+		t.Fatalf("expected a reminder email to %s", user.Email)
+	}
+	if len(tokens) != 2 {
+		t.Fatalf("expected confirm and cancel links, got %d", len(tokens))
+	}
+	confirmToken, cancelToken := tokens[0], tokens[1]
+
+	// Link konfirmasi bisa diklik lebih dari sekali
+	for i := 0; i < 2; i++ {
+		got, err := service.RespondToReminder(confirmToken)
+		if err != nil {
+			t.Fatalf("RespondToReminder(confirm) error = %v", err)
+		}
+		if got.ID != reservation.ID || got.Status != models.ReservationStatusConfirmed {
+			t.Fatalf("expected reservation %d to be confirmed, got %s", reservation.ID, got.Status)
+		}
+	}
+
+	got, err := service.RespondToReminder(cancelToken)
+	if err != nil {
+		t.Fatalf("RespondToReminder(cancel) error = %v", err)
+	}
+	if got.Status != models.ReservationStatusCancelled {
+		t.Fatalf("expected reservation to be cancelled, got %s", got.Status)
+	}
+
+	// Setelah dibatalkan, reservasi tidak bisa dikonfirmasi lagi
+	if _, err := service.RespondToReminder(confirmToken); !errors.Is(err, ErrInvalidStatusTransition) {
+		t.Errorf("RespondToReminder(confirm) after cancel error = %v, want %v", err, ErrInvalidStatusTransition)
+	}
+
+	if _, err := service.RespondToReminder("not-a-token"); !errors.Is(err, ErrInvalidReservationActionToken) {
+		t.Errorf("RespondToReminder() with invalid token error = %v, want %v", err, ErrInvalidReservationActionToken)
+	}
+}
+
+func TestReminderOmitsCancelLinkAfterCutoff(t *testing.T) {
+	_, store, user, tables := newMemoryReservationService(t)
+	reminders := newMemoryReminderService(store, 2*time.Hour)
+
+	createReservationAt(t, store, user, tables[0], time.Now().Add(time.Hour))
+	if _, err := reminders.SendDueReminders(); err != nil {
+		t.Fatalf("SendDueReminders() error = %v", err)
+	}
+
+	if _, tokens := deliverReminders(t, store); len(tokens) != 1 {
+		t.Fatalf("expected only the confirm link after the cutoff, got %d links", len(tokens))
+	}
+}
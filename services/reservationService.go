@@ -1,10 +1,14 @@
 package services
 
 import (
-	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"time"
+	"wereserve/apperror"
 	"wereserve/models"
+	"wereserve/notification"
+	"wereserve/realtime"
 	"wereserve/repository"
 	"wereserve/utils"
 
@@ -12,39 +16,169 @@ import (
 )
 
 
+// Transisi status reservasi yang diperbolehkan
+var reservationTransitions = map[string][]string{
+	models.ReservationStatusPending:   {models.ReservationStatusConfirmed, models.ReservationStatusCancelled},
+	models.ReservationStatusConfirmed: {models.ReservationStatusSeated, models.ReservationStatusCancelled, models.ReservationStatusNoShow},
+	models.ReservationStatusSeated:    {models.ReservationStatusCompleted},
+}
+
+var (
+	ErrInvalidStatusTransition = apperror.Conflict("invalid_status_transition", "invalid reservation status transition")
+	ErrReservationForbidden    = apperror.Forbidden("reservation_forbidden", "you are not allowed to modify this reservation")
+	ErrCutoffPassed            = apperror.Conflict("cutoff_passed", "reservation can no longer be changed this close to its start time")
+	ErrCapacityExceeded        = apperror.Validation("capacity_exceeded", "number of people exceeds table capacity")
+	ErrReservationNotActive    = apperror.Conflict("reservation_not_active", "reservation can no longer be updated")
+	ErrNoFieldsToUpdate        = apperror.Validation("no_fields_to_update", "at least one field must be updated")
+	ErrReservationOwnerConflict = apperror.Validation("reservation_owner_conflict", "a reservation belongs to either a user or a guest, not both")
+	ErrInvalidReservationActionToken = utils.ErrInvalidReservationActionToken
+)
+
+// Actor adalah user yang sedang login dan melakukan aksi terhadap reservasi.
+// Permissions berasal dari role user, lihat middleware.LoadPermissions
+type Actor struct {
+	UserID      int
+	Role        string
+	Permissions []string
+	// GuestContactID diisi untuk tamu tanpa akun yang membuka magic link, lihat GuestReservationService
+	GuestContactID int
+}
+
+// Can mengecek apakah actor punya permission tersebut
+func (a Actor) Can(permission string) bool {
+	for _, p := range a.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
 type ReservationService struct {
-	reservationRepo *repository.ReservationRepository
+	uow repository.UnitOfWork
+	reservationRepo repository.ReservationRepository
 	Validator *validator.Validate
-	tableRepo *repository.TableRepository
+	tableRepo repository.TableRepository
+	combinationRepo repository.TableCombinationRepository
+	scheduleService *ScheduleService
+	waitlistService *WaitlistService
+	cutoff time.Duration
+	hub *realtime.Hub
 }
 
-func NewReservationService(reservationRepo *repository.ReservationRepository, tableRepo *repository.TableRepository) *ReservationService {
+func NewReservationService(uow repository.UnitOfWork, reservationRepo repository.ReservationRepository, tableRepo repository.TableRepository, combinationRepo repository.TableCombinationRepository, scheduleService *ScheduleService, waitlistService *WaitlistService, cutoff time.Duration, hub *realtime.Hub) *ReservationService {
 	return &ReservationService{
+		uow: uow,
 		reservationRepo: reservationRepo,
 		tableRepo: tableRepo,
+		combinationRepo: combinationRepo,
+		scheduleService: scheduleService,
+		waitlistService: waitlistService,
         Validator:       validator.New(),
+		cutoff:          cutoff,
+		hub:             hub,
 	}
 }
 
+// resolveTables mengisi reservation.Tables dari kombinasi meja atau dari satu meja,
+// lalu memastikan total kapasitasnya cukup untuk jumlah orang
+func (s *ReservationService) resolveTables(reservation *models.Reservation) error {
+	if reservation.CombinationID != nil {
+		combination, err := s.combinationRepo.GetCombinationByID(*reservation.CombinationID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch table combination with ID %d: %w", *reservation.CombinationID, err)
+		}
+		if len(combination.Tables) == 0 {
+			return fmt.Errorf("table combination with ID %d has no tables", combination.ID)
+		}
 
-//Get All Reservation 
-func (s *ReservationService) GetAllReservation() ([]models.Reservation, error) {
-	reservations, err := s.reservationRepo.GetAllReservation()
-	if err != nil {
-		return nil, err
+		reservation.Tables = combination.Tables
+		reservation.TableID = combination.Tables[0].ID
+	} else {
+		table, err := s.tableRepo.GetTableByID(reservation.TableID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch table with ID %d: %w", reservation.TableID, err)
+		}
+		reservation.Tables = []models.Table{*table}
 	}
 
-	// Cek apakah reservation kosong
-	if len(reservations) == 0 {
-		return nil, errors.New("no reservations found")
+	capacity := 0
+	for _, table := range reservation.Tables {
+		capacity += table.Capacity
+	}
+	if reservation.NumberOfPeople > capacity {
+		return fmt.Errorf("%w: %d people for %d seats", ErrCapacityExceeded, reservation.NumberOfPeople, capacity)
 	}
 
-	return reservations, nil
+	return nil
+}
+
+// Nama meja untuk ditampilkan di email, digabung jika reservasi memakai lebih dari satu meja
+func tableLabel(tables []models.Table) string {
+	names := make([]string, 0, len(tables))
+	for _, table := range tables {
+		names = append(names, table.TableName)
+	}
+	return strings.Join(names, " + ")
+}
+
+// reservationNotification menyusun isi email dari data reservasi
+func reservationNotification(reservation models.Reservation) notification.ReservationData {
+	return notification.ReservationData{
+		Name:      reservation.ContactName(),
+		TableName: tableLabel(reservation.Tables),
+		DateTime:  reservation.ReservationDateTime.Format("2006-01-02 15:04"),
+		PartySize: reservation.NumberOfPeople,
+		Reason:    reservation.CancellationReason,
+
+		ReservationID: reservation.ID,
+		StartsAt:      reservation.ReservationDateTime,
+		EndsAt:        reservation.EndTime(),
+		UpdatedAt:     reservation.UpdatedAt,
+	}
 }
 
+// checkSelfService memastikan customer hanya mengubah reservasinya sendiri dan belum melewati batas waktu.
+// Actor yang punya permission tidak dibatasi oleh aturan ini
+func (s *ReservationService) checkSelfService(reservation *models.Reservation, actor Actor, policy Policy) error {
+	if policy.IsOverridden(actor) {
+		return nil
+	}
 
+	if !isGuestOwner(reservation, actor) {
+		if err := policy.Authorize(actor, reservation.UserID); err != nil {
+			return err
+		}
+	}
+
+	if time.Until(reservation.ReservationDateTime) < s.cutoff {
+		return fmt.Errorf("%w: changes must be made at least %s before the reservation", ErrCutoffPassed, s.cutoff)
+	}
 
-func (s *ReservationService) GetReservationDetail(id int) (*models.Reservation, error) {
+	return nil
+}
+
+// isGuestOwner mengecek apakah actor adalah tamu pemilik reservasi tanpa akun
+func isGuestOwner(reservation *models.Reservation, actor Actor) bool {
+	return actor.GuestContactID != 0 && reservation.GuestContactID != nil && *reservation.GuestContactID == actor.GuestContactID
+}
+
+// ListReservations mengembalikan satu halaman reservasi yang lolos filter beserta jumlah totalnya
+func (s *ReservationService) ListReservations(filter repository.ReservationFilter) ([]models.Reservation, int64, error) {
+	if err := checkPage(&filter.Page, repository.ReservationSortFields); err != nil {
+		return nil, 0, err
+	}
+	if filter.Status != "" && !isReservationStatus(filter.Status) {
+		return nil, 0, fmt.Errorf("%w: %s", ErrInvalidReservationStatus, filter.Status)
+	}
+
+	return s.reservationRepo.ListReservations(filter)
+}
+
+
+
+// GetReservationDetail hanya mengembalikan reservasi milik actor, kecuali actor boleh melihat reservasi semua user
+func (s *ReservationService) GetReservationDetail(id int, actor Actor) (*models.Reservation, error) {
 	
 	// validasi id tersedia di db atau tidak
 	reservation, err := s.reservationRepo.GetReservationDetail(id)
@@ -52,6 +186,10 @@ func (s *ReservationService) GetReservationDetail(id int) (*models.Reservation,
 		return nil,err
 	}
 
+	if err := PolicyReservationRead.Authorize(actor, reservation.UserID); err != nil {
+		return nil, err
+	}
+
 	return reservation, nil
 }
 
@@ -64,7 +202,9 @@ func (s *ReservationService) GetReservationByUserLogin(userID int) ([]models.Res
 	return reservations, nil
 }
 
-func (s *ReservationService) CreateReservation(reservation *models.Reservation, emailUser string) error {
+// CreateReservation membuat reservasi atas nama actor. Jika reservation.UserID kosong pemiliknya adalah actor,
+// sedangkan UserID user lain atau reservation.GuestContact hanya boleh diisi oleh actor dengan PolicyReservationOwner
+func (s *ReservationService) CreateReservation(reservation *models.Reservation, actor Actor) error {
 	
 // Validasi input menggunakan Validator
 	if err := s.Validator.Struct(reservation); err != nil {
@@ -72,84 +212,406 @@ func (s *ReservationService) CreateReservation(reservation *models.Reservation,
 		return fmt.Errorf("invalid reservation data: %w", err)
 	}
 
-	// Cek apakah meja tersedia
-	table, err := s.tableRepo.GetTableByID(reservation.TableID)
+	if err := resolveOwner(reservation, actor); err != nil {
+		return err
+	}
+
+	return s.createReservation(reservation, nil)
+}
+
+// createReservation menyimpan reservasi yang pemiliknya sudah ditentukan. decorate dipanggil setelah reservasi
+// tersimpan untuk menambah data email konfirmasi, misalnya magic link untuk tamu. decorate boleh nil
+func (s *ReservationService) createReservation(reservation *models.Reservation, decorate func(repos repository.Repositories, reservation *models.Reservation, data *notification.ReservationData) error) error {
+	// Cek apakah meja ada dan kapasitasnya cukup. Ketersediaan ditentukan dari rentang waktu reservasi, bukan dari tables.status
+	if err := s.resolveTables(reservation); err != nil {
+		log.Printf("Failed to resolve tables for reservation: %v", err)
+		return err
+	}
+
+	if reservation.DurationMinutes == 0 {
+		reservation.DurationMinutes = models.DefaultReservationDuration
+	}
+
+	// Cek jadwal buka restoran dan tolak reservasi di masa lalu
+	if err := s.scheduleService.ValidateSlot(reservation.ReservationDateTime, reservation.EndTime()); err != nil {
+		return err
+	}
+
+	// Kunci meja, cek bentrok lalu simpan reservasi dalam satu transaksi supaya request bersamaan tidak bisa memesan slot yang sama
+	var tableEvents []tableStatusEvent
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		var err error
+		tableEvents, err = insertReservation(repos, reservation, decorate)
+		return err
+	})
 	if err != nil {
-		log.Printf("Failed to fetch table with ID %d: %v", reservation.TableID, err)
-		return fmt.Errorf("failed to fetch table with ID %d: %w", reservation.TableID, err)
+		return err
 	}
-	
-	if table.Status == "reserved" {
-		log.Printf("Table with ID %d is already reserved", reservation.TableID)
-		return fmt.Errorf("table with ID %d is already reserved", reservation.TableID)
+
+	s.broadcastReservation(reservation.ID, models.WebhookEventReservationCreated, "")
+	broadcastTableStatus(s.hub, tableEvents)
+	return nil
+}
+
+// insertReservation mengunci meja, mengecek bentrok lalu menyimpan reservasi beserta email konfirmasi dan webhook-nya.
+// Harus dipanggil di dalam uow.Do, dipakai juga oleh waitlist saat customer menerima penawaran meja.
+// Perubahan status meja dikembalikan supaya pemanggil bisa mengirimnya ke stream setelah transaksi di-commit
+func insertReservation(repos repository.Repositories, reservation *models.Reservation, decorate func(repos repository.Repositories, reservation *models.Reservation, data *notification.ReservationData) error) ([]tableStatusEvent, error) {
+	if err := repos.Tables.LockTables(reservation.TableIDs()); err != nil {
+		return nil, err
+	}
+	tables, err := snapshotTableStatus(repos, reservation.TableIDs())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := saveOwner(repos, reservation); err != nil {
+		return nil, err
+	}
+
+	// Cek apakah rentang waktu reservasi bertabrakan dengan reservasi lain di salah satu meja
+	overlap, err := repos.Reservations.IsReservationOverlap(reservation.TableIDs(), reservation.ReservationDateTime, reservation.EndTime(), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate reservation: %w", err)
+	}
+	if overlap {
+		log.Printf("Table with ID %d is already reserved at %s", reservation.TableID, reservation.ReservationDateTime.Format("2006-01-02 15:04"))
+		return nil, fmt.Errorf("table with ID %d: %w", reservation.TableID, repository.ErrReservationOverlap)
 	}
 
 	// Buat reservasi
-	if err := s.reservationRepo.CreateReservation(reservation); err != nil {
+	if err := repos.Reservations.CreateReservation(reservation); err != nil {
 		log.Printf("Failed to create reservation for table ID %d: %v", reservation.TableID, err)
-		return fmt.Errorf("failed to create reservation for table ID %d: %w", reservation.TableID, err)
+		return nil, fmt.Errorf("failed to create reservation for table ID %d: %w", reservation.TableID, err)
 	}
 
-	// Update status meja menjadi "reserved"
-	table.Status = "reserved"
-	if err := s.tableRepo.UpdateTable(reservation.TableID, table); err != nil {
-		log.Printf("Failed to update status for table ID %d: %v", reservation.TableID, err)
-		return fmt.Errorf("failed to update status for table ID %d: %w", reservation.TableID, err)
+	// Email konfirmasi dan webhook ditulis di transaksi yang sama, lalu dikirim oleh worker
+	data := reservationNotification(*reservation)
+	if decorate != nil {
+		if err := decorate(repos, reservation, &data); err != nil {
+			return nil, err
+		}
+	}
+	if err := NewOutboxNotifier(repos.Outbox).ReservationCreated(reservation.ContactEmail(), data); err != nil {
+		return nil, err
 	}
+	if err := publishReservationWebhook(repos, reservation.ID, models.WebhookEventReservationCreated, ""); err != nil {
+		return nil, err
+	}
+	return tables.publishChanges(repos)
+}
 
-	err = utils.SendEmail( emailUser, table.TableName, reservation.ReservationDateTime.Format("2006-01-02 15:04"))
-	if err != nil {
-		log.Printf("Failed to send Email Confirmation")
+// resolveOwner menentukan pemilik reservasi baru. Customer selalu memesan untuk dirinya sendiri,
+// staff boleh memesan untuk user lain atau untuk tamu tanpa akun
+func resolveOwner(reservation *models.Reservation, actor Actor) error {
+	if reservation.GuestContact != nil {
+		if reservation.UserID != 0 {
+			return ErrReservationOwnerConflict
+		}
+		if !PolicyReservationOwner.IsOverridden(actor) {
+			return ErrReservationForbidden.Withf("you are not allowed to book for a guest")
+		}
+		return nil
 	}
 
+	if reservation.UserID == 0 {
+		reservation.UserID = actor.UserID
+	}
+	if err := PolicyReservationOwner.Authorize(actor, reservation.UserID); err != nil {
+		return ErrReservationForbidden.Withf("you are not allowed to book for another user")
+	}
 	return nil
 }
 
-// Delete 
-func (s *ReservationService) DeleteReservation(id int) error {
-	_, err := s.reservationRepo.GetReservationDetail(id)
+// saveOwner menyimpan kontak tamu atau memuat user pemilik reservasi supaya nama dan emailnya bisa dipakai untuk notifikasi
+func saveOwner(repos repository.Repositories, reservation *models.Reservation) error {
+	if reservation.GuestContact != nil {
+		if err := repos.GuestContacts.CreateGuestContact(reservation.GuestContact); err != nil {
+			return err
+		}
+		reservation.GuestContactID = &reservation.GuestContact.ID
+		return nil
+	}
+
+	owner, err := repos.Users.GetUserByid(reservation.UserID)
 	if err != nil {
-		return errors.New("reservation id tidak ditemukan")
+		return err
 	}
+	reservation.User = *owner
+	return nil
+}
+
+// Delete membatalkan reservasi. Data reservasi tetap disimpan dengan status cancelled
+func (s *ReservationService) DeleteReservation(id int, actor Actor, reason string) error {
+	return s.CancelReservation(id, actor, reason)
+}
 
-	err = s.reservationRepo.DeleteReservation(id)
+// CancelReservation membatalkan reservasi beserta alasannya
+func (s *ReservationService) CancelReservation(id int, actor Actor, reason string) error {
+	var reservation *models.Reservation
+	var tableEvents []tableStatusEvent
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		var err error
+		reservation, err = repos.Reservations.GetReservationForUpdate(id)
+		if err != nil {
+			return err
+		}
+
+		if err := s.checkSelfService(reservation, actor, PolicyReservationCancel); err != nil {
+			return err
+		}
+
+		if !canTransition(reservation.Status, models.ReservationStatusCancelled) {
+			return fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, reservation.Status, models.ReservationStatusCancelled)
+		}
+
+		tables, err := snapshotTableStatus(repos, reservation.TableIDs())
+		if err != nil {
+			return err
+		}
+		if err := repos.Reservations.CancelReservation(id, reason); err != nil {
+			return err
+		}
+
+		if err := notifyReservation(repos, id, NewOutboxNotifier(repos.Outbox).ReservationCancelled); err != nil {
+			return err
+		}
+		if err := publishReservationWebhook(repos, id, models.WebhookEventReservationCancelled, reservation.Status); err != nil {
+			return err
+		}
+		tableEvents, err = tables.publishChanges(repos)
+		return err
+	})
 	if err != nil {
 		return err
 	}
 
+	s.broadcastReservation(id, models.WebhookEventReservationCancelled, reservation.Status)
+	broadcastTableStatus(s.hub, tableEvents)
+
+	// Tawarkan meja yang kosong ke customer di waitlist. Kegagalan di sini tidak membatalkan pembatalan reservasi
+	if err := s.waitlistService.OfferFreedSlot(*reservation); err != nil {
+		log.Printf("Failed to offer cancelled reservation %d to the waitlist: %v", id, err)
+	}
+
 	return nil
 }
 
 // update 
-func (s *ReservationService) UpdateReservation(id int, updatedReservation models.Reservation) error {
+func (s *ReservationService) UpdateReservation(id int, updatedReservation models.Reservation, actor Actor) error {
+	// Reservasi dikunci selama validasi dan update supaya tidak diubah atau dibatalkan oleh request lain
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		if err := s.updateReservation(repos, id, updatedReservation, actor); err != nil {
+			return err
+		}
+		if err := notifyReservation(repos, id, NewOutboxNotifier(repos.Outbox).ReservationUpdated); err != nil {
+			return err
+		}
+		return publishReservationWebhook(repos, id, models.WebhookEventReservationUpdated, "")
+	})
+	if err != nil {
+		return err
+	}
+
+	s.broadcastReservation(id, models.WebhookEventReservationUpdated, "")
+	return nil
+}
+
+// notifyReservation memberi tahu pemilik reservasi dengan data terbaru di dalam transaksi yang sama
+func notifyReservation(repos repository.Repositories, id int, notify func(to string, data notification.ReservationData) error) error {
+	reservation, err := repos.Reservations.GetReservationDetail(id)
+	if err != nil {
+		return fmt.Errorf("failed to load reservation %d for notification: %w", id, err)
+	}
+	return notify(reservation.ContactEmail(), reservationNotification(*reservation))
+}
+
+func (s *ReservationService) updateReservation(repos repository.Repositories, id int, updatedReservation models.Reservation, actor Actor) error {
 	// Ambil reservasi saat ini
-	currentReservation, err := s.reservationRepo.GetReservationDetail(id)
+	currentReservation, err := repos.Reservations.GetReservationForUpdate(id)
 	if err != nil {
 		return fmt.Errorf("failed to fetch reservation with ID %d: %w", id, err)
 	}
 
+	// Reservasi yang sudah selesai atau dibatalkan tidak bisa diubah
+	if !currentReservation.IsActive() {
+		return ErrReservationNotActive.Withf("reservation with ID %d is %s and can no longer be updated", id, currentReservation.Status)
+	}
+
+	// Customer hanya boleh mengubah jadwal reservasinya sendiri, dan tidak boleh memindahkannya ke user lain
+	if err := s.checkSelfService(currentReservation, actor, PolicyReservationUpdate); err != nil {
+		return err
+	}
+	if !PolicyReservationUpdate.IsOverridden(actor) && updatedReservation.UserID != 0 && updatedReservation.UserID != actor.UserID {
+		return ErrReservationForbidden
+	}
+
 	// Periksa apakah ada perubahan
-	if updatedReservation.TableID == 0 && updatedReservation.UserID == 0 &&
-		updatedReservation.ReservationDateTime.IsZero() && updatedReservation.NumberOfPeople == 0 {
-		return errors.New("at least one field must be updated")
+	if updatedReservation.TableID == 0 && updatedReservation.CombinationID == nil && updatedReservation.UserID == 0 &&
+		updatedReservation.ReservationDateTime.IsZero() && updatedReservation.NumberOfPeople == 0 &&
+		updatedReservation.DurationMinutes == 0 {
+		return ErrNoFieldsToUpdate
 	}
 
-	// Periksa konflik reservasi
-	if updatedReservation.TableID != currentReservation.TableID ||
-		!updatedReservation.ReservationDateTime.Equal(currentReservation.ReservationDateTime) {
-		exists, err := s.reservationRepo.IsReservationExists(updatedReservation.TableID, updatedReservation.ReservationDateTime)
-		if err != nil {
-			return fmt.Errorf("failed to validate reservation: %w", err)
+	// Gabungkan nilai lama dengan nilai baru untuk mengecek rentang waktu akhir
+	target := *currentReservation
+	if updatedReservation.CombinationID != nil {
+		target.CombinationID = updatedReservation.CombinationID
+	} else if updatedReservation.TableID != 0 {
+		target.TableID = updatedReservation.TableID
+		target.CombinationID = nil
+	}
+	if updatedReservation.NumberOfPeople != 0 {
+		target.NumberOfPeople = updatedReservation.NumberOfPeople
+	}
+	if !updatedReservation.ReservationDateTime.IsZero() {
+		target.ReservationDateTime = updatedReservation.ReservationDateTime
+	}
+	if updatedReservation.DurationMinutes != 0 {
+		target.DurationMinutes = updatedReservation.DurationMinutes
+	}
+
+	// Batas waktu perubahan juga berlaku untuk jadwal baru, supaya reservasi tidak dipindah ke waktu yang sudah terlalu dekat
+	if !PolicyReservationUpdate.IsOverridden(actor) && time.Until(target.ReservationDateTime) < s.cutoff {
+		return fmt.Errorf("%w: the new time must be at least %s from now", ErrCutoffPassed, s.cutoff)
+	}
+
+	// Jadwal dicek ulang jika waktu atau durasi berubah
+	if !updatedReservation.ReservationDateTime.IsZero() || updatedReservation.DurationMinutes != 0 {
+		if err := s.scheduleService.ValidateSlot(target.ReservationDateTime, target.EndTime()); err != nil {
+			return err
 		}
-		if exists {
-			return errors.New("reservation conflict: table and datetime already reserved")
+	}
+
+	// Meja dan kapasitas dicek ulang jika meja atau jumlah orang berubah
+	if updatedReservation.CombinationID != nil || updatedReservation.TableID != 0 || updatedReservation.NumberOfPeople != 0 {
+		if err := s.resolveTables(&target); err != nil {
+			return err
 		}
+		updatedReservation.TableID = target.TableID
+		updatedReservation.Tables = target.Tables
+	}
+
+	// Kunci meja tujuan sebelum cek bentrok supaya tidak ada reservasi lain yang masuk di antaranya
+	if err := repos.Tables.LockTables(target.TableIDs()); err != nil {
+		return err
+	}
+
+	// Periksa konflik reservasi
+	overlap, err := repos.Reservations.IsReservationOverlap(target.TableIDs(), target.ReservationDateTime, target.EndTime(), id)
+	if err != nil {
+		return fmt.Errorf("failed to validate reservation: %w", err)
+	}
+	if overlap {
+		return fmt.Errorf("reservation conflict: %w", repository.ErrReservationOverlap)
 	}
 
 	// Update reservasi
-	if err := s.reservationRepo.UpdateReservation(id, &updatedReservation); err != nil {
+	if err := repos.Reservations.UpdateReservation(id, &updatedReservation); err != nil {
 		return fmt.Errorf("failed to update reservation with ID %d: %w", id, err)
 	}
 
  return nil
-}
\ No newline at end of file
+}
+
+// ChangeReservationStatus memindahkan reservasi ke status baru jika transisinya valid
+func (s *ReservationService) ChangeReservationStatus(id int, status string) error {
+	var previousStatus string
+	var tableEvents []tableStatusEvent
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		reservation, err := repos.Reservations.GetReservationForUpdate(id)
+		if err != nil {
+			return fmt.Errorf("failed to fetch reservation with ID %d: %w", id, err)
+		}
+
+		if !canTransition(reservation.Status, status) {
+			return fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, reservation.Status, status)
+		}
+		previousStatus = reservation.Status
+
+		tables, err := snapshotTableStatus(repos, reservation.TableIDs())
+		if err != nil {
+			return err
+		}
+		if err := repos.Reservations.UpdateReservationStatus(id, status); err != nil {
+			return err
+		}
+		// Email saat reservasi dibuat hanya menyatakan reservasi diterima, konfirmasinya dikirim di sini
+		if previousStatus == models.ReservationStatusPending && status == models.ReservationStatusConfirmed {
+			if err := notifyReservation(repos, id, NewOutboxNotifier(repos.Outbox).ReservationConfirmed); err != nil {
+				return err
+			}
+		}
+		if err := publishReservationWebhook(repos, id, models.WebhookEventReservationStatusChanged, previousStatus); err != nil {
+			return err
+		}
+		tableEvents, err = tables.publishChanges(repos)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.broadcastReservation(id, models.WebhookEventReservationStatusChanged, previousStatus)
+	broadcastTableStatus(s.hub, tableEvents)
+	return nil
+}
+
+// PreviewReminderAction membaca aksi dari link di email pengingat tanpa menjalankannya.
+// Link dibuka dengan GET oleh pemindai link email dan prefetch browser, jadi membuka link tidak boleh mengubah reservasi
+func (s *ReservationService) PreviewReminderAction(token string) (*models.Reservation, string, error) {
+	id, action, err := utils.ParseReservationActionToken(token)
+	if err != nil {
+		return nil, "", ErrInvalidReservationActionToken
+	}
+
+	reservation, err := s.reservationRepo.GetReservationDetail(id)
+	if err != nil {
+		return nil, "", ErrInvalidReservationActionToken
+	}
+	return reservation, action, nil
+}
+
+// RespondToReminder menjalankan aksi dari link di email pengingat tanpa perlu login.
+// Aksi yang sudah pernah dijalankan dianggap berhasil supaya link yang diklik dua kali tidak menampilkan error
+func (s *ReservationService) RespondToReminder(token string) (*models.Reservation, error) {
+	reservation, action, err := s.PreviewReminderAction(token)
+	if err != nil {
+		return nil, err
+	}
+	id := reservation.ID
+
+	switch action {
+	case utils.ReservationActionConfirm:
+		if reservation.Status != models.ReservationStatusConfirmed {
+			if err := s.ChangeReservationStatus(id, models.ReservationStatusConfirmed); err != nil {
+				return nil, err
+			}
+		}
+	case utils.ReservationActionCancel:
+		if reservation.Status != models.ReservationStatusCancelled {
+			owner := Actor{UserID: reservation.UserID}
+			if reservation.GuestContactID != nil {
+				owner.GuestContactID = *reservation.GuestContactID
+			}
+			if err := s.CancelReservation(id, owner, "Cancelled from the reminder email"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	reservation, err = s.reservationRepo.GetReservationDetail(id)
+	if err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+func canTransition(from, to string) bool {
+	for _, next := range reservationTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
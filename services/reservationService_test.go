@@ -0,0 +1,512 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"wereserve/database"
+	"wereserve/models"
+	"wereserve/notification"
+	"wereserve/realtime"
+	"wereserve/repository"
+	"wereserve/repository/memory"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Test ini butuh database Postgres sungguhan karena penguncian baris dan exclusion constraint
+// tidak bisa ditiru tanpa database. Contoh:
+// WERESERVE_TEST_DATABASE_DSN="host=localhost user=postgres password=postgres dbname=wereserve_test port=5432 sslmode=disable" go test ./services/...
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("WERESERVE_TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("WERESERVE_TEST_DATABASE_DSN is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get sql.DB: %v", err)
+	}
+	database.DBMigrate(sqlDB)
+
+	return db
+}
+
+func TestCreateReservationConcurrentNoDoubleBooking(t *testing.T) {
+	db := openTestDB(t)
+
+	suffix := time.Now().UnixNano()
+	user := models.User{Name: "Concurrency", Email: fmt.Sprintf("concurrency-%d@example.com", suffix), Password: "secret", Role: "customer"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	table := models.Table{TableName: fmt.Sprintf("concurrency-%d", suffix), Capacity: 4, Status: "available"}
+	if err := db.Create(&table).Error; err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Exec("DELETE FROM reservations WHERE table_id = ?", table.ID)
+		db.Exec("DELETE FROM tables WHERE id = ?", table.ID)
+		db.Exec("DELETE FROM users WHERE id = ?", user.ID)
+	})
+
+	reservationRepo := repository.NewReservationRepository(db)
+	tableRepo := repository.NewTableRepository(db)
+	combinationRepo := repository.NewTableCombinationRepository(db)
+	scheduleService := NewScheduleService(repository.NewScheduleRepository(db))
+	notifier := notification.NewEmailNotifier(notification.NewMemoryMailer(), notification.EmailOptions{})
+	waitlistService := NewWaitlistService(repository.NewUnitOfWork(db), repository.NewWaitlistRepository(db), reservationRepo, tableRepo, combinationRepo, scheduleService, time.Minute, notifier, realtime.NewHub(0))
+	service := NewReservationService(repository.NewUnitOfWork(db), reservationRepo, tableRepo, combinationRepo, scheduleService, waitlistService, 0, realtime.NewHub(0))
+
+	// Jam buka default dari migrasi adalah 10:00-22:00 dengan last seating 20:00
+	tomorrow := time.Now().AddDate(0, 0, 1)
+	start := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 12, 0, 0, 0, time.Local)
+
+	const attempts = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			reservation := models.Reservation{
+				UserID:  user.ID,
+				TableID: table.ID,
+				// Waktu mulai sedikit digeser supaya rentangnya berbeda tapi tetap bertabrakan
+				ReservationDateTime: start.Add(time.Duration(i%2) * 15 * time.Minute),
+				DurationMinutes:     60,
+				NumberOfPeople:      2,
+			}
+			errs <- service.CreateReservation(&reservation, Actor{UserID: user.ID})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case errors.Is(err, repository.ErrReservationOverlap):
+		default:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if created != 1 {
+		t.Fatalf("expected exactly 1 reservation to be created, got %d", created)
+	}
+
+	var count int64
+	db.Model(&models.Reservation{}).Where("table_id = ?", table.ID).Count(&count)
+	if count != 1 {
+		t.Fatalf("expected 1 reservation row for the table, got %d", count)
+	}
+}
+
+// newMemoryReservationService membangun ReservationService di atas store in-memory dengan satu user dan dua meja
+func newMemoryReservationService(t *testing.T) (*ReservationService, *memory.Store, models.User, []models.Table) {
+	t.Helper()
+	return newMemoryReservationServiceWithHub(t, realtime.NewHub(0))
+}
+
+func newMemoryReservationServiceWithHub(t *testing.T, hub *realtime.Hub) (*ReservationService, *memory.Store, models.User, []models.Table) {
+	t.Helper()
+
+	store := memory.NewStore()
+	user := models.User{Name: "Budi", Email: "budi@example.com", Password: "secret", Role: "customer"}
+	if err := store.Users().CreateUser(&user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	tables := []models.Table{
+		{TableName: "A1", Capacity: 4, Status: "available"},
+		{TableName: "A2", Capacity: 2, Status: "available"},
+	}
+	for i := range tables {
+		if err := store.Tables().CreateTable(&tables[i]); err != nil {
+			t.Fatalf("failed to create table: %v", err)
+		}
+	}
+
+	scheduleService := NewScheduleService(store.Schedule())
+	notifier := notification.NewEmailNotifier(notification.NewMemoryMailer(), notification.EmailOptions{})
+	waitlistService := NewWaitlistService(store.UnitOfWork(), store.Waitlist(), store.Reservations(), store.Tables(), store.TableCombinations(), scheduleService, time.Minute, notifier, hub)
+	service := NewReservationService(store.UnitOfWork(), store.Reservations(), store.Tables(), store.TableCombinations(), scheduleService, waitlistService, 0, hub)
+
+	return service, store, user, tables
+}
+
+// tomorrowAt mengembalikan waktu besok pada jam dan menit tertentu di zona waktu lokal
+func tomorrowAt(hour, minute int) time.Time {
+	tomorrow := time.Now().AddDate(0, 0, 1)
+	return time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), hour, minute, 0, 0, time.Local)
+}
+
+func TestCreateReservationConflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		table    int
+		start    time.Time
+		duration int
+		wantErr  error
+	}{
+		{name: "same slot", table: 0, start: tomorrowAt(12, 0), duration: 120, wantErr: repository.ErrReservationOverlap},
+		{name: "starts during existing", table: 0, start: tomorrowAt(13, 0), duration: 60, wantErr: repository.ErrReservationOverlap},
+		{name: "ends during existing", table: 0, start: tomorrowAt(11, 0), duration: 90, wantErr: repository.ErrReservationOverlap},
+		{name: "ends when existing starts", table: 0, start: tomorrowAt(11, 0), duration: 60},
+		{name: "starts when existing ends", table: 0, start: tomorrowAt(14, 0), duration: 60},
+		{name: "other table", table: 1, start: tomorrowAt(12, 0), duration: 120},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _, user, tables := newMemoryReservationService(t)
+
+			existing := models.Reservation{UserID: user.ID, TableID: tables[0].ID, ReservationDateTime: tomorrowAt(12, 0), DurationMinutes: 120, NumberOfPeople: 2}
+			if err := service.CreateReservation(&existing, Actor{UserID: user.ID}); err != nil {
+				t.Fatalf("CreateReservation() error = %v", err)
+			}
+
+			reservation := models.Reservation{UserID: user.ID, TableID: tables[tt.table].ID, ReservationDateTime: tt.start, DurationMinutes: tt.duration, NumberOfPeople: 2}
+			err := service.CreateReservation(&reservation, Actor{UserID: user.ID})
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("CreateReservation() error = %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateReservation() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateReservationRejectsTooManyPeople(t *testing.T) {
+	service, _, user, tables := newMemoryReservationService(t)
+
+	reservation := models.Reservation{UserID: user.ID, TableID: tables[1].ID, ReservationDateTime: tomorrowAt(12, 0), NumberOfPeople: 3}
+	if err := service.CreateReservation(&reservation, Actor{UserID: user.ID}); !errors.Is(err, ErrCapacityExceeded) {
+		t.Fatalf("CreateReservation() error = %v, want %v", err, ErrCapacityExceeded)
+	}
+}
+
+func TestCancelledReservationFreesSlot(t *testing.T) {
+	service, _, user, tables := newMemoryReservationService(t)
+	actor := Actor{UserID: user.ID, Role: "customer"}
+
+	first := models.Reservation{UserID: user.ID, TableID: tables[0].ID, ReservationDateTime: tomorrowAt(12, 0), NumberOfPeople: 2}
+	if err := service.CreateReservation(&first, Actor{UserID: user.ID}); err != nil {
+		t.Fatalf("CreateReservation() error = %v", err)
+	}
+
+	if err := service.CancelReservation(first.ID, actor, "plans changed"); err != nil {
+		t.Fatalf("CancelReservation() error = %v", err)
+	}
+
+	second := models.Reservation{UserID: user.ID, TableID: tables[0].ID, ReservationDateTime: tomorrowAt(12, 0), NumberOfPeople: 2}
+	if err := service.CreateReservation(&second, Actor{UserID: user.ID}); err != nil {
+		t.Fatalf("expected cancelled slot to be bookable again, got %v", err)
+	}
+}
+
+func TestUpdateReservationConflict(t *testing.T) {
+	service, store, user, tables := newMemoryReservationService(t)
+	actor := Actor{UserID: user.ID, Role: "customer"}
+
+	lunch := models.Reservation{UserID: user.ID, TableID: tables[0].ID, ReservationDateTime: tomorrowAt(12, 0), NumberOfPeople: 2}
+	dinner := models.Reservation{UserID: user.ID, TableID: tables[0].ID, ReservationDateTime: tomorrowAt(18, 0), NumberOfPeople: 2}
+	for _, reservation := range []*models.Reservation{&lunch, &dinner} {
+		if err := service.CreateReservation(reservation, Actor{UserID: user.ID}); err != nil {
+			t.Fatalf("CreateReservation() error = %v", err)
+		}
+	}
+
+	err := service.UpdateReservation(dinner.ID, models.Reservation{ReservationDateTime: tomorrowAt(13, 0)}, actor)
+	if !errors.Is(err, repository.ErrReservationOverlap) {
+		t.Fatalf("UpdateReservation() error = %v, want %v", err, repository.ErrReservationOverlap)
+	}
+
+	// Reservasi yang gagal diubah tidak boleh berubah sama sekali
+	got, _ := store.Reservations().GetReservationDetail(dinner.ID)
+	if !got.ReservationDateTime.Equal(tomorrowAt(18, 0)) {
+		t.Errorf("expected reservation time to stay at 18:00, got %s", got.ReservationDateTime)
+	}
+
+	if err := service.UpdateReservation(dinner.ID, models.Reservation{TableID: tables[1].ID, ReservationDateTime: tomorrowAt(13, 0)}, actor); err != nil {
+		t.Fatalf("UpdateReservation() to a free table error = %v", err)
+	}
+}
+
+func TestUpdateReservationCutoffAppliesToNewTime(t *testing.T) {
+	service, store, user, tables := newMemoryReservationService(t)
+	service.cutoff = 36 * time.Hour
+	staffPermissions, _ := NewRoleService(store.Roles()).GetPermissions("staff")
+
+	tests := []struct {
+		name    string
+		actor   Actor
+		newTime time.Time
+		wantErr error
+	}{
+		{name: "customer moves it closer than the cutoff", actor: Actor{UserID: user.ID, Role: models.RoleCustomer}, newTime: tomorrowAt(12, 0), wantErr: ErrCutoffPassed},
+		{name: "customer moves it further away", actor: Actor{UserID: user.ID, Role: models.RoleCustomer}, newTime: tomorrowAt(12, 0).AddDate(0, 0, 3)},
+		{name: "staff moves it closer than the cutoff", actor: Actor{UserID: user.ID + 100, Role: "staff", Permissions: staffPermissions}, newTime: tomorrowAt(12, 0)},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Jadwal awal masih jauh di luar batas waktu perubahan
+			reservation := models.Reservation{UserID: user.ID, TableID: tables[0].ID, ReservationDateTime: tomorrowAt(18, 0).AddDate(0, 0, 2+i), NumberOfPeople: 2}
+			if err := service.CreateReservation(&reservation, Actor{UserID: user.ID}); err != nil {
+				t.Fatalf("CreateReservation() error = %v", err)
+			}
+
+			err := service.UpdateReservation(reservation.ID, models.Reservation{ReservationDateTime: tt.newTime}, tt.actor)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateReservation() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReservationNotifications(t *testing.T) {
+	service, store, user, tables := newMemoryReservationService(t)
+	actor := Actor{UserID: user.ID, Role: "customer"}
+
+	reservation := models.Reservation{UserID: user.ID, TableID: tables[0].ID, ReservationDateTime: tomorrowAt(12, 0), NumberOfPeople: 2}
+	if err := service.CreateReservation(&reservation, Actor{UserID: user.ID}); err != nil {
+		t.Fatalf("CreateReservation() error = %v", err)
+	}
+	if err := service.UpdateReservation(reservation.ID, models.Reservation{NumberOfPeople: 3}, actor); err != nil {
+		t.Fatalf("UpdateReservation() error = %v", err)
+	}
+	if err := service.ChangeReservationStatus(reservation.ID, models.ReservationStatusConfirmed); err != nil {
+		t.Fatalf("ChangeReservationStatus() error = %v", err)
+	}
+	if err := service.CancelReservation(reservation.ID, actor, "<b>sakit</b>"); err != nil {
+		t.Fatalf("CancelReservation() error = %v", err)
+	}
+
+	// Email belum dikirim sampai worker outbox berjalan
+	pending, _ := store.Outbox().GetMessages(models.OutboxStatusPending)
+	if len(pending) != 4 {
+		t.Fatalf("expected 4 pending outbox messages, got %d", len(pending))
+	}
+
+	mailer := notification.NewMemoryMailer()
+	worker := NewOutboxService(store.Outbox(), notification.NewEmailNotifier(mailer, notification.EmailOptions{}), testOutboxOptions())
+	if sent, err := worker.ProcessDue(); err != nil || sent != 4 {
+		t.Fatalf("ProcessDue() = %d, %v, want 4, nil", sent, err)
+	}
+
+	messages := mailer.Messages()
+	wantSubjects := []string{
+		"We Received Your WeReserve Reservation",
+		"Your WeReserve Reservation Has Been Updated",
+		"Your WeReserve Reservation Is Confirmed",
+		"Your WeReserve Reservation Has Been Cancelled",
+	}
+	if len(messages) != len(wantSubjects) {
+		t.Fatalf("expected %d emails, got %d", len(wantSubjects), len(messages))
+	}
+	for i, subject := range wantSubjects {
+		if messages[i].To != user.Email || messages[i].Subject != subject {
+			t.Errorf("email %d = %q to %q, want %q to %q", i, messages[i].Subject, messages[i].To, subject, user.Email)
+		}
+	}
+
+	// Alasan pembatalan berasal dari input user, jadi harus di-escape
+	cancelled := messages[3].HTMLBody
+	if strings.Contains(cancelled, "<b>sakit</b>") || !strings.Contains(cancelled, "&lt;b&gt;sakit&lt;/b&gt;") {
+		t.Errorf("expected the cancellation reason to be escaped")
+	}
+}
+
+func TestFailedReservationWritesNoOutboxMessage(t *testing.T) {
+	service, store, user, tables := newMemoryReservationService(t)
+
+	first := models.Reservation{UserID: user.ID, TableID: tables[0].ID, ReservationDateTime: tomorrowAt(12, 0), NumberOfPeople: 2}
+	if err := service.CreateReservation(&first, Actor{UserID: user.ID}); err != nil {
+		t.Fatalf("CreateReservation() error = %v", err)
+	}
+
+	second := models.Reservation{UserID: user.ID, TableID: tables[0].ID, ReservationDateTime: tomorrowAt(12, 0), NumberOfPeople: 2}
+	if err := service.CreateReservation(&second, Actor{UserID: user.ID}); !errors.Is(err, repository.ErrReservationOverlap) {
+		t.Fatalf("CreateReservation() error = %v, want %v", err, repository.ErrReservationOverlap)
+	}
+
+	// Notifikasi ikut di-rollback bersama reservasi yang gagal
+	messages, _ := store.Outbox().GetMessages("")
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 outbox message, got %d", len(messages))
+	}
+}
+
+func TestReservationChangesAreBroadcast(t *testing.T) {
+	hub := realtime.NewHub(10)
+	subscriber := hub.Subscribe()
+	service, _, user, tables := newMemoryReservationServiceWithHub(t, hub)
+
+	reservation := models.Reservation{UserID: user.ID, TableID: tables[0].ID, ReservationDateTime: tomorrowAt(12, 0), NumberOfPeople: 2}
+	if err := service.CreateReservation(&reservation, Actor{UserID: user.ID}); err != nil {
+		t.Fatalf("CreateReservation() error = %v", err)
+	}
+
+	// Reservasi yang gagal di-rollback dan tidak boleh muncul di stream
+	conflict := models.Reservation{UserID: user.ID, TableID: tables[0].ID, ReservationDateTime: tomorrowAt(12, 0), NumberOfPeople: 2}
+	if err := service.CreateReservation(&conflict, Actor{UserID: user.ID}); !errors.Is(err, repository.ErrReservationOverlap) {
+		t.Fatalf("CreateReservation() error = %v, want %v", err, repository.ErrReservationOverlap)
+	}
+
+	if err := service.ChangeReservationStatus(reservation.ID, models.ReservationStatusConfirmed); err != nil {
+		t.Fatalf("ChangeReservationStatus() error = %v", err)
+	}
+	if err := service.CancelReservation(reservation.ID, Actor{UserID: user.ID, Role: "customer"}, ""); err != nil {
+		t.Fatalf("CancelReservation() error = %v", err)
+	}
+
+	want := []string{
+		models.WebhookEventReservationCreated,
+		models.WebhookEventReservationStatusChanged,
+		models.WebhookEventReservationCancelled,
+	}
+	for _, eventType := range want {
+		select {
+		case event := <-subscriber.Events():
+			data, ok := event.Data.(reservationEventData)
+			if event.Type != eventType || !ok || data.ID != reservation.ID || event.UserID != user.ID {
+				t.Errorf("event = %s %+v, want %s for reservation %d", event.Type, event.Data, eventType, reservation.ID)
+			}
+		default:
+			t.Fatalf("expected a %s event", eventType)
+		}
+	}
+	select {
+	case event := <-subscriber.Events():
+		t.Errorf("unexpected event %s", event.Type)
+	default:
+	}
+}
+
+func TestDerivedTableStatusIsBroadcast(t *testing.T) {
+	hub := realtime.NewHub(10)
+	service, _, user, tables := newMemoryReservationServiceWithHub(t, hub)
+
+	reservation := models.Reservation{UserID: user.ID, TableID: tables[0].ID, ReservationDateTime: tomorrowAt(12, 0), NumberOfPeople: 2}
+	if err := service.CreateReservation(&reservation, Actor{UserID: user.ID}); err != nil {
+		t.Fatalf("CreateReservation() error = %v", err)
+	}
+	if err := service.ChangeReservationStatus(reservation.ID, models.ReservationStatusConfirmed); err != nil {
+		t.Fatalf("ChangeReservationStatus() error = %v", err)
+	}
+
+	// Denah meja di stream ikut berubah saat tamu duduk dan saat reservasi selesai
+	subscriber := hub.Subscribe()
+	for _, want := range []struct{ status, tableStatus string }{
+		{models.ReservationStatusSeated, "occupied"},
+		{models.ReservationStatusCompleted, "available"},
+	} {
+		if err := service.ChangeReservationStatus(reservation.ID, want.status); err != nil {
+			t.Fatalf("ChangeReservationStatus(%s) error = %v", want.status, err)
+		}
+
+		var tableEvents []tableStatusEvent
+		for done := false; !done; {
+			select {
+			case event := <-subscriber.Events():
+				if event.Type == models.WebhookEventTableStatusChanged {
+					tableEvents = append(tableEvents, event.Data.(tableStatusEvent))
+				}
+			default:
+				done = true
+			}
+		}
+		if len(tableEvents) != 1 || tableEvents[0].TableID != tables[0].ID || tableEvents[0].Status != want.tableStatus {
+			t.Errorf("after %s: table events = %+v, want table %d %s", want.status, tableEvents, tables[0].ID, want.tableStatus)
+		}
+	}
+}
+
+func TestCreateReservationOwner(t *testing.T) {
+	service, store, user, tables := newMemoryReservationService(t)
+	other := models.User{Name: "Sari", Email: "sari@example.com", Password: "secret", Role: models.RoleCustomer}
+	if err := store.Users().CreateUser(&other); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	staffPermissions, _ := NewRoleService(store.Roles()).GetPermissions("staff")
+	customer := Actor{UserID: user.ID, Role: models.RoleCustomer}
+	staff := Actor{UserID: user.ID + 100, Role: "staff", Permissions: staffPermissions}
+
+	tests := []struct {
+		name      string
+		actor     Actor
+		userID    int
+		guest     *models.GuestContact
+		wantErr   error
+		wantOwner int
+		wantEmail string
+	}{
+		{name: "customer books for themselves", actor: customer, wantOwner: user.ID, wantEmail: user.Email},
+		{name: "customer sends own user_id", actor: customer, userID: user.ID, wantOwner: user.ID, wantEmail: user.Email},
+		{name: "customer books for another user", actor: customer, userID: other.ID, wantErr: ErrReservationForbidden},
+		{name: "customer books for a guest", actor: customer, guest: &models.GuestContact{Name: "Tamu", Phone: "0812"}, wantErr: ErrReservationForbidden},
+		{name: "staff books for a user", actor: staff, userID: other.ID, wantOwner: other.ID, wantEmail: other.Email},
+		{name: "staff books for an unknown user", actor: staff, userID: 9999, wantErr: repository.ErrUserNotFound},
+		{name: "staff books for a guest", actor: staff, guest: &models.GuestContact{Name: "Tamu", Email: "tamu@example.com"}, wantEmail: "tamu@example.com"},
+		{name: "staff books for a guest without email", actor: staff, guest: &models.GuestContact{Name: "Tamu", Phone: "0812"}},
+		{name: "user and guest together", actor: staff, userID: other.ID, guest: &models.GuestContact{Name: "Tamu", Phone: "0812"}, wantErr: ErrReservationOwnerConflict},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, _ := store.Outbox().GetMessages("")
+			reservation := models.Reservation{
+				UserID:              tt.userID,
+				GuestContact:        tt.guest,
+				TableID:             tables[0].ID,
+				ReservationDateTime: tomorrowAt(10, 0).AddDate(0, 0, i),
+				NumberOfPeople:      2,
+			}
+			err := service.CreateReservation(&reservation, tt.actor)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateReservation() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			created, _ := store.Reservations().GetReservationDetail(reservation.ID)
+			if created.UserID != tt.wantOwner {
+				t.Errorf("owner = %d, want %d", created.UserID, tt.wantOwner)
+			}
+			if tt.guest != nil && (created.GuestContact == nil || created.GuestContact.Name != tt.guest.Name) {
+				t.Errorf("guest contact = %+v, want %+v", created.GuestContact, tt.guest)
+			}
+
+			// Email konfirmasi dikirim ke pemilik reservasi, bukan ke staff yang memesankan
+			after, _ := store.Outbox().GetMessages("")
+			var recipients []string
+			for _, message := range after[:len(after)-len(before)] {
+				recipients = append(recipients, message.Recipient)
+			}
+			if tt.wantEmail == "" && len(recipients) != 0 {
+				t.Errorf("expected no notification, got %v", recipients)
+			}
+			if tt.wantEmail != "" && (len(recipients) != 1 || recipients[0] != tt.wantEmail) {
+				t.Errorf("notification recipients = %v, want %s", recipients, tt.wantEmail)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"regexp"
+	"sort"
+	"wereserve/apperror"
+	"wereserve/models"
+	"wereserve/repository"
+)
+
+var (
+	ErrInvalidRoleName   = apperror.Validation("invalid_role_name", "role name must be 2-50 lowercase letters, digits, '-' or '_' and start with a letter")
+	ErrInvalidPermission = apperror.Validation("invalid_permission", "unknown permission")
+	ErrRoleProtected     = apperror.Conflict("role_protected", "built-in role cannot be changed this way")
+	ErrRoleInUse         = apperror.Conflict("role_in_use", "role is still assigned to users")
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,49}$`)
+
+// RoleInput adalah data role dari admin. Permissions mengganti seluruh permission role
+type RoleInput struct {
+	Name        string
+	Description string
+	Permissions []string
+}
+
+type RoleService struct {
+	roleRepo repository.RoleRepository
+}
+
+func NewRoleService(roleRepo repository.RoleRepository) *RoleService {
+	return &RoleService{roleRepo: roleRepo}
+}
+
+// GetPermissions dipakai oleh middleware.LoadPermissions untuk mengisi permission user yang sedang login
+func (s *RoleService) GetPermissions(role string) ([]string, error) {
+	return s.roleRepo.GetPermissions(role)
+}
+
+func (s *RoleService) GetRoles() ([]models.Role, error) {
+	return s.roleRepo.GetRoles()
+}
+
+func (s *RoleService) GetRole(name string) (*models.Role, error) {
+	return s.roleRepo.GetRoleByName(name)
+}
+
+func (s *RoleService) CreateRole(input RoleInput) (*models.Role, error) {
+	if !roleNamePattern.MatchString(input.Name) {
+		return nil, ErrInvalidRoleName
+	}
+	permissions, err := normalizePermissions(input.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &models.Role{
+		Name:        input.Name,
+		Description: input.Description,
+		Permissions: permissions,
+	}
+	if err := s.roleRepo.CreateRole(role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// UpdateRole mengganti deskripsi dan permission role. Permission admin tidak bisa diubah
+// supaya selalu ada role yang bisa mengelola role lain
+func (s *RoleService) UpdateRole(name string, input RoleInput) (*models.Role, error) {
+	role, err := s.roleRepo.GetRoleByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if role.Name == models.RoleAdmin {
+		return nil, ErrRoleProtected.Withf("permissions of role %q cannot be changed", role.Name)
+	}
+
+	permissions, err := normalizePermissions(input.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role.Description = input.Description
+	role.Permissions = permissions
+	if err := s.roleRepo.UpdateRole(name, role); err != nil {
+		return nil, err
+	}
+	return s.roleRepo.GetRoleByName(name)
+}
+
+// DeleteRole menghapus role yang sudah tidak dipakai. Role bawaan tidak bisa dihapus
+func (s *RoleService) DeleteRole(name string) error {
+	role, err := s.roleRepo.GetRoleByName(name)
+	if err != nil {
+		return err
+	}
+	if role.IsBuiltIn() {
+		return ErrRoleProtected.Withf("role %q cannot be deleted", role.Name)
+	}
+
+	users, err := s.roleRepo.CountUsersWithRole(name)
+	if err != nil {
+		return err
+	}
+	if users > 0 {
+		return ErrRoleInUse.Withf("role %q is still assigned to %d users", name, users)
+	}
+
+	return s.roleRepo.DeleteRole(name)
+}
+
+// normalizePermissions menolak permission yang tidak dikenal lalu membuang duplikat dan mengurutkannya
+func normalizePermissions(permissions []string) ([]string, error) {
+	seen := make(map[string]bool, len(permissions))
+	normalized := []string{}
+	for _, permission := range permissions {
+		if !models.IsValidPermission(permission) {
+			return nil, ErrInvalidPermission.Withf("unknown permission %q", permission)
+		}
+		if seen[permission] {
+			continue
+		}
+		seen[permission] = true
+		normalized = append(normalized, permission)
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
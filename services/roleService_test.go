@@ -0,0 +1,166 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"wereserve/models"
+	"wereserve/repository"
+	"wereserve/repository/memory"
+)
+
+func TestRoleServiceRejectsInvalidChanges(t *testing.T) {
+	store := memory.NewStore()
+	service := NewRoleService(store.Roles())
+	user := models.User{Name: "Sari", Email: "sari@example.com", Password: "secret", Role: "host"}
+	if err := store.Users().CreateUser(&user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		run     func() error
+		wantErr error
+	}{
+		{
+			name:    "invalid role name",
+			run:     func() error { _, err := service.CreateRole(RoleInput{Name: "Head Chef"}); return err },
+			wantErr: ErrInvalidRoleName,
+		},
+		{
+			name: "unknown permission",
+			run: func() error {
+				_, err := service.CreateRole(RoleInput{Name: "sommelier", Permissions: []string{"wine:pour"}})
+				return err
+			},
+			wantErr: ErrInvalidPermission,
+		},
+		{
+			name:    "duplicate role",
+			run:     func() error { _, err := service.CreateRole(RoleInput{Name: "staff"}); return err },
+			wantErr: repository.ErrRoleNameTaken,
+		},
+		{
+			name: "change admin permissions",
+			run: func() error {
+				_, err := service.UpdateRole(models.RoleAdmin, RoleInput{Permissions: []string{models.PermissionTableRead}})
+				return err
+			},
+			wantErr: ErrRoleProtected,
+		},
+		{
+			name:    "delete built-in role",
+			run:     func() error { return service.DeleteRole(models.RoleCustomer) },
+			wantErr: ErrRoleProtected,
+		},
+		{
+			name:    "delete role still in use",
+			run:     func() error { return service.DeleteRole("host") },
+			wantErr: ErrRoleInUse,
+		},
+		{
+			name:    "delete unknown role",
+			run:     func() error { return service.DeleteRole("sommelier") },
+			wantErr: repository.ErrRoleNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRoleServiceCreateUpdateDelete(t *testing.T) {
+	store := memory.NewStore()
+	service := NewRoleService(store.Roles())
+
+	role, err := service.CreateRole(RoleInput{
+		Name:        "sommelier",
+		Description: "Wine service",
+		Permissions: []string{models.PermissionReservationRead, models.PermissionTableRead, models.PermissionTableRead},
+	})
+	if err != nil {
+		t.Fatalf("CreateRole() error = %v", err)
+	}
+	if want := []string{models.PermissionReservationRead, models.PermissionTableRead}; !slices.Equal(role.Permissions, want) {
+		t.Errorf("permissions = %v, want %v", role.Permissions, want)
+	}
+
+	if _, err := service.UpdateRole("sommelier", RoleInput{Permissions: []string{models.PermissionReservationUpdate}}); err != nil {
+		t.Fatalf("UpdateRole() error = %v", err)
+	}
+	permissions, _ := service.GetPermissions("sommelier")
+	if want := []string{models.PermissionReservationUpdate}; !slices.Equal(permissions, want) {
+		t.Errorf("permissions after update = %v, want %v", permissions, want)
+	}
+
+	if err := service.DeleteRole("sommelier"); err != nil {
+		t.Fatalf("DeleteRole() error = %v", err)
+	}
+	if permissions, _ := service.GetPermissions("sommelier"); len(permissions) != 0 {
+		t.Errorf("expected deleted role to have no permissions, got %v", permissions)
+	}
+}
+
+func TestChangeUserRole(t *testing.T) {
+	store := memory.NewStore()
+	service := newMemoryUserService(store)
+	admin := Actor{UserID: 1000, Role: models.RoleAdmin}
+
+	user := models.User{Name: "Budi", Email: "budi@example.com", Password: "rahasia123"}
+	if err := service.RegisterUser(&user); err != nil {
+		t.Fatalf("RegisterUser() error = %v", err)
+	}
+
+	if err := service.ChangeUserRole(user.ID, "owner", admin); !errors.Is(err, ErrInvalidUserRole) {
+		t.Errorf("ChangeUserRole() to unknown role error = %v, want %v", err, ErrInvalidUserRole)
+	}
+	if err := service.ChangeUserRole(user.ID, "host", Actor{UserID: user.ID}); !errors.Is(err, ErrCannotChangeOwnRole) {
+		t.Errorf("ChangeUserRole() on own account error = %v, want %v", err, ErrCannotChangeOwnRole)
+	}
+
+	// Access token lama dicabut supaya role di claims ikut berganti setelah refresh
+	before, _ := service.CurrentTokenVersion(user.ID)
+	if err := service.ChangeUserRole(user.ID, "host", admin); err != nil {
+		t.Fatalf("ChangeUserRole() error = %v", err)
+	}
+	updated, _ := service.GetUserById(user.ID, Actor{UserID: user.ID})
+	if updated.Role != "host" {
+		t.Errorf("role = %q, want host", updated.Role)
+	}
+	if after, _ := service.CurrentTokenVersion(user.ID); after != before+1 {
+		t.Errorf("token version = %d, want %d", after, before+1)
+	}
+}
+
+func TestCancelReservationOfAnotherUserRequiresPermission(t *testing.T) {
+	service, store, user, tables := newMemoryReservationService(t)
+	roles := NewRoleService(store.Roles())
+
+	tests := []struct {
+		role    string
+		wantErr error
+	}{
+		{role: models.RoleCustomer, wantErr: ErrReservationForbidden},
+		{role: "staff"},
+		{role: "host"},
+	}
+	for i, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			reservation := createReservationAt(t, store, user, tables[0], tomorrowAt(10+3*i, 0))
+			permissions, err := roles.GetPermissions(tt.role)
+			if err != nil {
+				t.Fatalf("GetPermissions() error = %v", err)
+			}
+
+			actor := Actor{UserID: user.ID + 1, Role: tt.role, Permissions: permissions}
+			if err := service.CancelReservation(reservation.ID, actor, ""); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CancelReservation() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
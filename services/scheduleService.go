@@ -0,0 +1,182 @@
+package services
+
+import (
+	"fmt"
+	"time"
+	"wereserve/apperror"
+	"wereserve/models"
+	"wereserve/repository"
+)
+
+var (
+	ErrRestaurantClosed   = apperror.Validation("restaurant_closed", "restaurant is closed at the requested time")
+	ErrOutsideOpeningHour = apperror.Validation("outside_opening_hours", "reservation time is outside opening hours")
+	ErrSlotNotAligned     = apperror.Validation("slot_not_aligned", "reservation time does not match a bookable slot")
+	ErrReservationInPast  = apperror.Validation("reservation_in_past", "reservation time must be in the future")
+	ErrInvalidSchedule    = apperror.Validation("invalid_schedule", "invalid schedule")
+)
+
+type ScheduleService struct {
+	scheduleRepo repository.ScheduleRepository
+}
+
+type Schedule struct {
+	OpeningHours []models.OpeningHour
+	SlotMinutes  int
+	Closures     []models.ScheduleClosure
+}
+
+func NewScheduleService(scheduleRepo repository.ScheduleRepository) *ScheduleService {
+	return &ScheduleService{scheduleRepo: scheduleRepo}
+}
+
+// GetSchedule mengambil jam buka mingguan, granularitas slot dan penutupan yang akan datang
+func (s *ScheduleService) GetSchedule() (*Schedule, error) {
+	hours, err := s.scheduleRepo.GetOpeningHours()
+	if err != nil {
+		return nil, err
+	}
+
+	setting, err := s.scheduleRepo.GetSetting()
+	if err != nil {
+		return nil, err
+	}
+
+	closures, err := s.scheduleRepo.GetUpcomingClosures(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Schedule{
+		OpeningHours: hours,
+		SlotMinutes:  setting.SlotMinutes,
+		Closures:     closures,
+	}, nil
+}
+
+// SlotDuration mengembalikan granularitas slot reservasi
+func (s *ScheduleService) SlotDuration() (time.Duration, error) {
+	setting, err := s.scheduleRepo.GetSetting()
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(setting.SlotMinutes) * time.Minute, nil
+}
+
+// UpdateOpeningHours menyimpan jam buka untuk hari-hari yang dikirim. Hari yang tidak dikirim tidak berubah
+func (s *ScheduleService) UpdateOpeningHours(hours []models.OpeningHour) error {
+	seen := make(map[int]bool)
+	for _, hour := range hours {
+		if seen[hour.DayOfWeek] {
+			return ErrInvalidSchedule.Withf("day_of_week %d is listed more than once", hour.DayOfWeek)
+		}
+		seen[hour.DayOfWeek] = true
+
+		if hour.IsClosed {
+			continue
+		}
+
+		open, errOpen := parseClock(hour.OpenTime)
+		closing, errClose := parseClock(hour.CloseTime)
+		lastSeating, errLast := parseClock(hour.LastSeatingTime)
+		if errOpen != nil || errClose != nil || errLast != nil {
+			return ErrInvalidSchedule.Withf("day_of_week %d: open_time, close_time and last_seating_time are required in HH:MM format", hour.DayOfWeek)
+		}
+		if open >= closing {
+			return ErrInvalidSchedule.Withf("day_of_week %d: open_time must be before close_time", hour.DayOfWeek)
+		}
+		if lastSeating < open || lastSeating > closing {
+			return ErrInvalidSchedule.Withf("day_of_week %d: last_seating_time must be between open_time and close_time", hour.DayOfWeek)
+		}
+	}
+
+	return s.scheduleRepo.UpsertOpeningHours(hours)
+}
+
+func (s *ScheduleService) UpdateSlotMinutes(minutes int) error {
+	if minutes < 1 {
+		return ErrInvalidSchedule.Withf("slot_minutes must be at least 1")
+	}
+	return s.scheduleRepo.UpdateSlotMinutes(minutes)
+}
+
+func (s *ScheduleService) CreateClosure(closure *models.ScheduleClosure) error {
+	if closure.EndDate.Before(closure.StartDate) {
+		return ErrInvalidSchedule.Withf("end_date must not be before start_date")
+	}
+	return s.scheduleRepo.CreateClosure(closure)
+}
+
+func (s *ScheduleService) DeleteClosure(id int) error {
+	return s.scheduleRepo.DeleteClosure(id)
+}
+
+// ValidateSlot memastikan reservasi [start, end) berada di masa depan, tidak jatuh di hari tutup,
+// dimulai di antara jam buka dan last seating, selesai sebelum jam tutup, dan sesuai dengan granularitas slot
+func (s *ScheduleService) ValidateSlot(start, end time.Time) error {
+	if !start.After(time.Now()) {
+		return ErrReservationInPast
+	}
+
+	// Jam buka disimpan dalam waktu lokal restoran
+	start = start.In(time.Local)
+	end = end.In(time.Local)
+
+	closure, err := s.scheduleRepo.GetClosureOn(start)
+	if err != nil {
+		return err
+	}
+	if closure != nil {
+		return fmt.Errorf("%w: %s", ErrRestaurantClosed, closure.Reason)
+	}
+
+	hour, err := s.scheduleRepo.GetOpeningHourByDay(start.Weekday())
+	if err != nil {
+		return err
+	}
+	if hour == nil || hour.IsClosed {
+		return fmt.Errorf("%w on %s", ErrRestaurantClosed, start.Weekday())
+	}
+
+	open, err := parseClock(hour.OpenTime)
+	if err != nil {
+		return err
+	}
+	closing, err := parseClock(hour.CloseTime)
+	if err != nil {
+		return err
+	}
+	lastSeating, err := parseClock(hour.LastSeatingTime)
+	if err != nil {
+		return err
+	}
+
+	startMinute := start.Hour()*60 + start.Minute()
+	if startMinute < open || startMinute > lastSeating {
+		return fmt.Errorf("%w: seating on %s is between %s and %s", ErrOutsideOpeningHour, start.Weekday(), hour.OpenTime, hour.LastSeatingTime)
+	}
+
+	startDay := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)
+	if end.After(startDay.Add(time.Duration(closing) * time.Minute)) {
+		return fmt.Errorf("%w: the restaurant closes at %s", ErrOutsideOpeningHour, hour.CloseTime)
+	}
+
+	setting, err := s.scheduleRepo.GetSetting()
+	if err != nil {
+		return err
+	}
+	if start.Second() != 0 || start.Nanosecond() != 0 || (startMinute-open)%setting.SlotMinutes != 0 {
+		return fmt.Errorf("%w: slots start every %d minutes from %s", ErrSlotNotAligned, setting.SlotMinutes, hour.OpenTime)
+	}
+
+	return nil
+}
+
+// parseClock mengubah "HH:MM" menjadi jumlah menit sejak tengah malam
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"wereserve/models"
+	"wereserve/repository/memory"
+)
+
+func TestValidateSlot(t *testing.T) {
+	closedDay := tomorrowAt(12, 0).AddDate(0, 0, 2)
+	holiday := tomorrowAt(12, 0).AddDate(0, 0, 3)
+
+	tests := []struct {
+		name     string
+		setup    func(t *testing.T, service *ScheduleService)
+		start    time.Time
+		duration time.Duration
+		wantErr  error
+	}{
+		{name: "open slot", start: tomorrowAt(12, 0), duration: 2 * time.Hour},
+		{name: "last seating", start: tomorrowAt(20, 0), duration: 2 * time.Hour},
+		{name: "past time", start: tomorrowAt(12, 0).AddDate(0, 0, -2), duration: 2 * time.Hour, wantErr: ErrReservationInPast},
+		{name: "closed day", start: closedDay, duration: 2 * time.Hour, wantErr: ErrRestaurantClosed, setup: func(t *testing.T, service *ScheduleService) {
+			if err := service.UpdateOpeningHours([]models.OpeningHour{{DayOfWeek: int(closedDay.Weekday()), IsClosed: true}}); err != nil {
+				t.Fatalf("UpdateOpeningHours() error = %v", err)
+			}
+		}},
+		{name: "holiday closure", start: holiday, duration: 2 * time.Hour, wantErr: ErrRestaurantClosed, setup: func(t *testing.T, service *ScheduleService) {
+			date := time.Date(holiday.Year(), holiday.Month(), holiday.Day(), 0, 0, 0, 0, time.Local)
+			closure := models.ScheduleClosure{StartDate: date, EndDate: date, Reason: "Libur nasional"}
+			if err := service.CreateClosure(&closure); err != nil {
+				t.Fatalf("CreateClosure() error = %v", err)
+			}
+		}},
+		{name: "off-grid slot", start: tomorrowAt(12, 10), duration: 2 * time.Hour, wantErr: ErrSlotNotAligned},
+		{name: "off-grid for a longer slot", start: tomorrowAt(12, 15), duration: 2 * time.Hour, wantErr: ErrSlotNotAligned, setup: func(t *testing.T, service *ScheduleService) {
+			if err := service.UpdateSlotMinutes(30); err != nil {
+				t.Fatalf("UpdateSlotMinutes() error = %v", err)
+			}
+		}},
+		{name: "before opening", start: tomorrowAt(9, 45), duration: 2 * time.Hour, wantErr: ErrOutsideOpeningHour},
+		{name: "after last seating", start: tomorrowAt(20, 15), duration: time.Hour, wantErr: ErrOutsideOpeningHour},
+		{name: "ends after closing", start: tomorrowAt(20, 0), duration: 3 * time.Hour, wantErr: ErrOutsideOpeningHour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewScheduleService(memory.NewStore().Schedule())
+			if tt.setup != nil {
+				tt.setup(t, service)
+			}
+
+			err := service.ValidateSlot(tt.start, tt.start.Add(tt.duration))
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("ValidateSlot() error = %v", err)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ValidateSlot() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"fmt"
+	"time"
+	"wereserve/apperror"
+	"wereserve/models"
+	"wereserve/repository"
+	"wereserve/utils"
+)
+
+// DefaultStreamTicketTTL cukup untuk membuka EventSource tepat setelah tiket diminta
+const DefaultStreamTicketTTL = 30 * time.Second
+
+var ErrInvalidStreamTicket = apperror.Unauthorized("invalid_stream_ticket", "tiket stream tidak valid, sudah dipakai atau sudah kedaluwarsa")
+
+// StreamTicketService menerbitkan tiket sekali pakai untuk GET /api/stream.
+// EventSource di browser tidak bisa mengirim header Authorization, jadi yang dikirim lewat query adalah tiket ini, bukan access token
+type StreamTicketService struct {
+	ticketRepo repository.StreamTicketRepository
+	userRepo   repository.UserRepository
+	ttl        time.Duration
+	now        func() time.Time
+}
+
+func NewStreamTicketService(ticketRepo repository.StreamTicketRepository, userRepo repository.UserRepository, ttl time.Duration) *StreamTicketService {
+	if ttl <= 0 {
+		ttl = DefaultStreamTicketTTL
+	}
+	return &StreamTicketService{ticketRepo: ticketRepo, userRepo: userRepo, ttl: ttl, now: time.Now}
+}
+
+// IssueTicket membuat tiket baru untuk user yang sedang login. Hanya hash tiket yang disimpan
+func (s *StreamTicketService) IssueTicket(userID int) (string, time.Time, error) {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate stream ticket: %w", err)
+	}
+
+	expiresAt := s.now().Add(s.ttl)
+	ticket := &models.StreamTicket{
+		UserID:     userID,
+		TicketHash: utils.HashToken(token),
+		ExpiresAt:  expiresAt,
+	}
+	if err := s.ticketRepo.CreateStreamTicket(ticket); err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// RedeemStreamTicket memakai tiket lalu mengembalikan user pemiliknya.
+// User dibaca ulang dari database supaya perubahan role setelah tiket dibuat tetap berlaku
+func (s *StreamTicketService) RedeemStreamTicket(token string) (*models.User, error) {
+	if token == "" {
+		return nil, ErrInvalidStreamTicket
+	}
+
+	ticket, err := s.ticketRepo.ConsumeStreamTicket(utils.HashToken(token), s.now())
+	if err != nil {
+		return nil, err
+	}
+	if ticket == nil {
+		return nil, ErrInvalidStreamTicket
+	}
+
+	user, err := s.userRepo.GetUserByid(ticket.UserID)
+	if err != nil {
+		return nil, ErrInvalidStreamTicket
+	}
+	return user, nil
+}
+
+// CleanupExpiredTickets menghapus tiket yang sudah kedaluwarsa
+func (s *StreamTicketService) CleanupExpiredTickets() (int64, error) {
+	return s.ticketRepo.DeleteExpiredStreamTickets(s.now())
+}
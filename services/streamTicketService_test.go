@@ -0,0 +1,69 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"wereserve/models"
+	"wereserve/repository/memory"
+)
+
+func TestStreamTicketIsSingleUse(t *testing.T) {
+	store := memory.NewStore()
+	user := models.User{Name: "Budi", Email: "budi@example.com", Password: "rahasia123", Role: "staff"}
+	if err := store.Users().CreateUser(&user); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	service := NewStreamTicketService(store.StreamTickets(), store.Users(), time.Minute)
+
+	ticket, _, err := service.IssueTicket(user.ID)
+	if err != nil {
+		t.Fatalf("IssueTicket() error = %v", err)
+	}
+
+	redeemed, err := service.RedeemStreamTicket(ticket)
+	if err != nil {
+		t.Fatalf("RedeemStreamTicket() error = %v", err)
+	}
+	if redeemed.ID != user.ID || redeemed.Role != "staff" {
+		t.Errorf("expected ticket of user %d with role staff, got user %d with role %q", user.ID, redeemed.ID, redeemed.Role)
+	}
+
+	if _, err := service.RedeemStreamTicket(ticket); !errors.Is(err, ErrInvalidStreamTicket) {
+		t.Errorf("expected ErrInvalidStreamTicket on second use, got %v", err)
+	}
+}
+
+func TestStreamTicketRejectsExpiredAndUnknownTickets(t *testing.T) {
+	store := memory.NewStore()
+	user := models.User{Name: "Budi", Email: "budi@example.com", Password: "rahasia123", Role: "customer"}
+	if err := store.Users().CreateUser(&user); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	service := NewStreamTicketService(store.StreamTickets(), store.Users(), 30*time.Second)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	ticket, _, err := service.IssueTicket(user.ID)
+	if err != nil {
+		t.Fatalf("IssueTicket() error = %v", err)
+	}
+
+	now = now.Add(31 * time.Second)
+	if _, err := service.RedeemStreamTicket(ticket); !errors.Is(err, ErrInvalidStreamTicket) {
+		t.Errorf("expected ErrInvalidStreamTicket for expired ticket, got %v", err)
+	}
+	for _, token := range []string{"", "tidak-ada"} {
+		if _, err := service.RedeemStreamTicket(token); !errors.Is(err, ErrInvalidStreamTicket) {
+			t.Errorf("expected ErrInvalidStreamTicket for %q, got %v", token, err)
+		}
+	}
+
+	deleted, err := service.CleanupExpiredTickets()
+	if err != nil {
+		t.Fatalf("CleanupExpiredTickets() error = %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("expected 1 expired ticket deleted, got %d", deleted)
+	}
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"wereserve/apperror"
+	"wereserve/models"
+	"wereserve/repository"
+)
+
+var ErrInvalidTableCombination = apperror.Validation("invalid_table_combination", "kombinasi meja minimal terdiri dari dua meja")
+
+type TableCombinationService struct {
+	combinationRepo repository.TableCombinationRepository
+	tableRepo       repository.TableRepository
+}
+
+func NewTableCombinationService(combinationRepo repository.TableCombinationRepository, tableRepo repository.TableRepository) *TableCombinationService {
+	return &TableCombinationService{combinationRepo: combinationRepo, tableRepo: tableRepo}
+}
+
+func (s *TableCombinationService) GetAllCombinations() ([]models.TableCombination, error) {
+	combinations, err := s.combinationRepo.GetAllCombinations()
+	if err != nil {
+		return nil, err
+	}
+	return combinations, nil
+}
+
+func (s *TableCombinationService) GetCombinationByID(id int) (*models.TableCombination, error) {
+	combination, err := s.combinationRepo.GetCombinationByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return combination, nil
+}
+
+// CreateCombination membuat kombinasi dari minimal dua meja yang berbeda
+func (s *TableCombinationService) CreateCombination(name string, tableIDs []int) (*models.TableCombination, error) {
+	if len(tableIDs) < 2 {
+		return nil, ErrInvalidTableCombination
+	}
+
+	combination := &models.TableCombination{Name: name}
+	seen := make(map[int]bool)
+	for _, id := range tableIDs {
+		if seen[id] {
+			return nil, ErrInvalidTableCombination.Withf("table with ID %d is listed more than once", id)
+		}
+		seen[id] = true
+
+		table, err := s.tableRepo.GetTableByID(id)
+		if err != nil {
+			return nil, err
+		}
+		combination.Tables = append(combination.Tables, *table)
+	}
+
+	if err := s.combinationRepo.CreateCombination(combination); err != nil {
+		return nil, err
+	}
+
+	return combination, nil
+}
+
+func (s *TableCombinationService) DeleteCombination(id int) error {
+	_, err := s.combinationRepo.GetCombinationByID(id)
+	if err != nil {
+		return err
+	}
+
+	err = s.combinationRepo.DeleteCombination(id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
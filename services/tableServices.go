@@ -1,17 +1,125 @@
 package services
 
 import (
-	"errors"
+	"log"
+	"time"
 	"wereserve/models"
+	"wereserve/realtime"
 	"wereserve/repository"
 )
 
 type TableService struct {
-	tableRepo *repository.TableRepository
+	tableRepo repository.TableRepository
+	reservationRepo repository.ReservationRepository
+	webhookRepo repository.WebhookRepository
+	hub *realtime.Hub
 }
 
-func NewTableService(tableRepo *repository.TableRepository) *TableService {
-	return &TableService{tableRepo: tableRepo}
+func NewTableService(tableRepo repository.TableRepository, reservationRepo repository.ReservationRepository, webhookRepo repository.WebhookRepository, hub *realtime.Hub) *TableService {
+	return &TableService{tableRepo: tableRepo, reservationRepo: reservationRepo, webhookRepo: webhookRepo, hub: hub}
+}
+
+// applyCurrentStatus menurunkan status meja dari reservasi yang sedang aktif
+func (s *TableService) applyCurrentStatus(tables []models.Table) error {
+	reservations, err := s.reservationRepo.GetCurrentReservations(time.Now())
+	if err != nil {
+		return err
+	}
+
+	deriveTableStatus(tables, reservations)
+	return nil
+}
+
+// deriveTableStatus menurunkan status meja dari reservasi yang sedang aktif.
+// Meja dengan tamu yang sudah duduk menjadi occupied, meja dengan reservasi yang sedang berjalan menjadi reserved,
+// selain itu memakai status yang disimpan admin di tabel
+func deriveTableStatus(tables []models.Table, reservations []models.Reservation) {
+	current := make(map[int]string)
+	for _, reservation := range reservations {
+		for _, tableID := range reservation.TableIDs() {
+			if reservation.Status == models.ReservationStatusSeated {
+				current[tableID] = "occupied"
+			} else if current[tableID] == "" {
+				current[tableID] = "reserved"
+			}
+		}
+	}
+
+	for i := range tables {
+		if status, ok := current[tables[i].ID]; ok {
+			tables[i].Status = status
+		}
+	}
+}
+
+// tableStatusSnapshot menyimpan status meja sebelum reservasi diubah, supaya perubahan status meja
+// yang diturunkan dari reservasi bisa dikirim sebagai event table.status_changed
+type tableStatusSnapshot struct {
+	at     time.Time
+	tables []models.Table
+}
+
+// snapshotTableStatus mencatat status meja tableIDs. Dipanggil di dalam transaksi sebelum reservasi diubah
+func snapshotTableStatus(repos repository.Repositories, tableIDs []int) (*tableStatusSnapshot, error) {
+	snapshot := &tableStatusSnapshot{at: time.Now()}
+	tables, err := snapshot.load(repos, tableIDs)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.tables = tables
+	return snapshot, nil
+}
+
+// load memuat meja lalu menurunkan statusnya dari reservasi yang berjalan pada waktu snapshot,
+// supaya perbandingan sebelum dan sesudah perubahan tidak dipengaruhi waktu yang terus berjalan
+func (s *tableStatusSnapshot) load(repos repository.Repositories, tableIDs []int) ([]models.Table, error) {
+	reservations, err := repos.Reservations.GetCurrentReservations(s.at)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := make([]models.Table, 0, len(tableIDs))
+	for _, id := range tableIDs {
+		table, err := repos.Tables.GetTableByID(id)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, *table)
+	}
+
+	deriveTableStatus(tables, reservations)
+	return tables, nil
+}
+
+// publishChanges membandingkan status meja dengan snapshot, lalu mengantrikan webhook table.status_changed
+// untuk setiap meja yang statusnya berubah di transaksi yang sama dengan perubahan reservasi
+func (s *tableStatusSnapshot) publishChanges(repos repository.Repositories) ([]tableStatusEvent, error) {
+	ids := make([]int, 0, len(s.tables))
+	for _, table := range s.tables {
+		ids = append(ids, table.ID)
+	}
+	current, err := s.load(repos, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []tableStatusEvent
+	for i, table := range current {
+		if table.Status == s.tables[i].Status {
+			continue
+		}
+		event := tableStatusEvent{
+			TableID:        table.ID,
+			TableName:      table.TableName,
+			Status:         table.Status,
+			PreviousStatus: s.tables[i].Status,
+		}
+		if err := publishWebhook(repos.Webhooks, models.WebhookEventTableStatusChanged, event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
 }
 
 
@@ -21,9 +129,30 @@ func (s *TableService) GetAllTable() ([]models.Table, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	if err := s.applyCurrentStatus(tables); err != nil {
+		return nil, err
+	}
 	return tables, nil
 }
 
+// ListTables mengembalikan satu halaman meja dengan status yang sudah diturunkan dari reservasi aktif
+func (s *TableService) ListTables(filter repository.TableFilter) ([]models.Table, int64, error) {
+	if err := checkPage(&filter.Page, repository.TableSortFields); err != nil {
+		return nil, 0, err
+	}
+
+	tables, total, err := s.tableRepo.ListTables(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := s.applyCurrentStatus(tables); err != nil {
+		return nil, 0, err
+	}
+	return tables, total, nil
+}
+
 // get UserById
 func (s *TableService) GetTableById(id int) (*models.Table, error) {
 	table, err := s.tableRepo.GetTableByID(id)
@@ -31,16 +160,29 @@ func (s *TableService) GetTableById(id int) (*models.Table, error) {
 		return nil, err
 	}
 
-	return table, nil
+	tables := []models.Table{*table}
+	if err := s.applyCurrentStatus(tables); err != nil {
+		return nil, err
+	}
+
+	return &tables[0], nil
 }
 
 // get UserByStatus
 func (s *TableService) GetTableByStatus(status string) ([]models.Table, error) {
-	tables, err := s.tableRepo.GetTableByStatus(status)
+	// Status dihitung dari reservasi aktif, jadi filter dilakukan setelah status diturunkan
+	tables, err := s.GetAllTable()
 		if err != nil {
 			return nil, err
 		}
-	return tables, nil
+
+	var filtered []models.Table
+	for _, table := range tables {
+		if table.Status == status {
+			filtered = append(filtered, table)
+		}
+	}
+	return filtered, nil
 }
 
 
@@ -67,7 +209,7 @@ func (s *TableService) DeleteTable(id int) error {
 	//Cek Table Exist
 	_, err := s.tableRepo.GetTableByID(id)
 	if err != nil {
-		return errors.New("table id tidak di temukan")
+		return err
 	}
 
 	//Delete
@@ -83,10 +225,15 @@ func (s *TableService) DeleteTable(id int) error {
 func (s *TableService) UpdateTable(id int, table models.Table) error {
 	//Validasi Input min 1 field yang disi
 	if table.TableName == "" && table.Capacity == 0 && table.Status == "" {
-		return errors.New("minimal satu field harus diisi")
+		return ErrNoFieldsToUpdate.Withf("minimal satu field harus diisi")
 	}
 
-	err := s.tableRepo.UpdateTable(id, &models.Table{
+	current, err := s.tableRepo.GetTableByID(id)
+	if err != nil {
+		return err
+	}
+
+	err = s.tableRepo.UpdateTable(id, &models.Table{
 		TableName: table.TableName,
 		Capacity:  table.Capacity,
 		Status:    table.Status,
@@ -96,5 +243,23 @@ func (s *TableService) UpdateTable(id int, table models.Table) error {
 		return err
 	}
 
+	// Sistem lain diberi tahu jika admin mengubah status meja. Perubahan meja tetap tersimpan walaupun webhook gagal diantrikan
+	if table.Status != "" && table.Status != current.Status {
+		name := current.TableName
+		if table.TableName != "" {
+			name = table.TableName
+		}
+		event := tableStatusEvent{
+			TableID:        id,
+			TableName:      name,
+			Status:         table.Status,
+			PreviousStatus: current.Status,
+		}
+		if err := publishWebhook(s.webhookRepo, models.WebhookEventTableStatusChanged, event); err != nil {
+			log.Printf("Failed to queue table status webhook for table %d: %v", id, err)
+		}
+		broadcast(s.hub, models.WebhookEventTableStatusChanged, 0, event)
+	}
+
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"testing"
+	"wereserve/models"
+	"wereserve/realtime"
+	"wereserve/repository/memory"
+)
+
+func TestTableCRUD(t *testing.T) {
+	store := memory.NewStore()
+	service := NewTableService(store.Tables(), store.Reservations(), store.Webhooks(), realtime.NewHub(0))
+
+	table := models.Table{TableName: "A1", Capacity: 4, Status: "available"}
+	if err := service.CreateTable(&table); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+
+	duplicate := models.Table{TableName: "A1", Capacity: 2, Status: "available"}
+	if err := service.CreateTable(&duplicate); err == nil {
+		t.Errorf("expected CreateTable() with a duplicate name to fail")
+	}
+
+	got, err := service.GetTableById(table.ID)
+	if err != nil {
+		t.Fatalf("GetTableById() error = %v", err)
+	}
+	if got.TableName != "A1" || got.Capacity != 4 {
+		t.Errorf("GetTableById() = %+v", got)
+	}
+
+	if err := service.UpdateTable(table.ID, models.Table{Capacity: 6}); err != nil {
+		t.Fatalf("UpdateTable() error = %v", err)
+	}
+	got, _ = service.GetTableById(table.ID)
+	if got.Capacity != 6 || got.TableName != "A1" {
+		t.Errorf("expected only capacity to change, got %+v", got)
+	}
+
+	if err := service.UpdateTable(table.ID, models.Table{}); err == nil {
+		t.Errorf("expected UpdateTable() without fields to fail")
+	}
+
+	tables, err := service.GetAllTable()
+	if err != nil {
+		t.Fatalf("GetAllTable() error = %v", err)
+	}
+	if len(tables) != 1 {
+		t.Errorf("expected 1 table, got %d", len(tables))
+	}
+
+	if err := service.DeleteTable(table.ID); err != nil {
+		t.Fatalf("DeleteTable() error = %v", err)
+	}
+	if _, err := service.GetTableById(table.ID); err == nil {
+		t.Errorf("expected deleted table to be gone")
+	}
+	if err := service.DeleteTable(table.ID); err == nil {
+		t.Errorf("expected deleting a missing table to fail")
+	}
+}
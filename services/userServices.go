@@ -2,20 +2,65 @@ package services
 
 import (
 	"errors"
+	"log"
+	"net/url"
 	"strconv"
-	"wereserve/middleware"
+	"time"
+	"wereserve/apperror"
 	"wereserve/models"
+	"wereserve/notification"
 	"wereserve/repository"
+	"wereserve/utils"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrInvalidUserRole     = repository.ErrInvalidUserRole
+	ErrInvalidCredentials  = apperror.Unauthorized("invalid_credentials", "invalid email or password")
+	ErrInvalidRefreshToken = apperror.Unauthorized("invalid_refresh_token", "refresh token tidak valid atau sudah kedaluwarsa")
+	ErrRefreshTokenReused  = apperror.Unauthorized("refresh_token_reused", "refresh token sudah pernah dipakai, silakan login ulang")
+	ErrInvalidPasswordResetToken = apperror.Validation("invalid_password_reset_token", "token reset password tidak valid, sudah dipakai atau sudah kedaluwarsa")
+	ErrInvalidVerificationToken  = utils.ErrInvalidVerificationToken
+	ErrCannotChangeOwnRole       = apperror.Conflict("cannot_change_own_role", "you cannot change your own role")
+)
+
 type UserService struct {
-	userRepo *repository.UserRepository
+	userRepo          repository.UserRepository
+	refreshTokenRepo  repository.RefreshTokenRepository
+	passwordResetRepo repository.PasswordResetTokenRepository
+	notifier          notification.Notifier
+	options           UserServiceOptions
+}
+
+// UserServiceOptions berisi masa berlaku token dan URL yang dipakai di email
+type UserServiceOptions struct {
+	RefreshTTL       time.Duration
+	PasswordResetTTL time.Duration
+	// Halaman frontend untuk membuat password baru, token ditambahkan sebagai query ?token=
+	PasswordResetURL string
+	VerificationTTL  time.Duration
+	// Endpoint verifikasi email, token ditambahkan sebagai query ?token=
+	VerificationURL string
+	// Akun yang belum diverifikasi lebih lama dari ini dihapus oleh CleanupUnverifiedUsers
+	UnverifiedRetention time.Duration
+}
+
+// TokenPair adalah access token (JWT) dan refresh token yang dikembalikan saat login atau refresh
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
 }
 
-func NewUserService(userRepo *repository.UserRepository) *UserService {
-	return &UserService{userRepo: userRepo}
+func NewUserService(userRepo repository.UserRepository, refreshTokenRepo repository.RefreshTokenRepository,
+	passwordResetRepo repository.PasswordResetTokenRepository, notifier notification.Notifier, options UserServiceOptions) *UserService {
+	return &UserService{
+		userRepo:          userRepo,
+		refreshTokenRepo:  refreshTokenRepo,
+		passwordResetRepo: passwordResetRepo,
+		notifier:          notifier,
+		options:           options,
+	}
 }
 
 // RegisterUser
@@ -29,12 +74,16 @@ func (s *UserService) RegisterUser( user *models.User ) ( error) {
 
 	//Set default role to 'customer if not provided
 	if user.Role == "" {
-		user.Role = "customer"
+		user.Role = models.RoleCustomer
 	}
 
 	// Validate user role
-	if !s.userRepo.IsValidUserRole(user.Role) {
-		return errors.New("invalid user role")
+	validRole, err := s.userRepo.IsValidUserRole(user.Role)
+	if err != nil {
+		return err
+	}
+	if !validRole {
+		return ErrInvalidUserRole
 	}
 
 	//Check if email already use
@@ -44,45 +93,322 @@ func (s *UserService) RegisterUser( user *models.User ) ( error) {
 	}
 
 	if emailExist {
-		return errors.New("email sudah terdaftar")
+		return repository.ErrEmailTaken
 	}
 
+	// Akun baru belum terverifikasi sampai link dari email dibuka
+	user.EmailVerifiedAt = nil
+
 	// Create user
 	err = s.userRepo.CreateUser(user)
 	if err != nil {
 		return err
 	}
+
+	s.sendVerification(user)
 	return nil
 }
 
-func (s *UserService) LoginUser(email, password string) (string, error) {
+func (s *UserService) LoginUser(email, password string) (*TokenPair, error) {
 	// ngambil data 
-	var user models.User
-	err := s.userRepo.DB.Raw("SELECT id, name, email, password, role FROM users WHERE email = ?", email).Scan(&user).Error
+	// Email yang tidak terdaftar dan password salah memakai error yang sama
+	user, err := s.userRepo.GetUserByEmail(email)
 	if err != nil {
-		return "", nil
-	}
-
-	if user.ID == 0 {
-		return "", errors.New("user tidak ditemukan")
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
 	}
 
 	//compare
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	if err != nil {
-		return "", errors.New("invalid password")
+		return nil, ErrInvalidCredentials
 	}
 
+	// Setiap login membuka family refresh token baru
+	familyID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	pair, _, err := s.issueTokens(user, familyID)
+	return pair, err
+}
+
+// issueTokens membuat access token dan refresh token baru untuk user dalam family yang diberikan
+func (s *UserService) issueTokens(user *models.User, familyID string) (*TokenPair, *models.RefreshToken, error) {
 	// parse id int to string
 	strID := strconv.Itoa(user.ID)
 
 	// Generate JWT token
-	token, err := middleware.GenerateJWT(user.Email, user.Role, strID)
+	accessToken, err := utils.GenerateJWT(user.Email, user.Role, strID, user.TokenVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refreshToken, record, err := s.createRefreshToken(user.ID, familyID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &TokenPair{AccessToken: accessToken, RefreshToken: refreshToken}, record, nil
+}
+
+// createRefreshToken menyimpan hash dari refresh token baru, token aslinya hanya dikembalikan ke client
+func (s *UserService) createRefreshToken(userID int, familyID string) (string, *models.RefreshToken, error) {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", nil, err
+	}
+
+	record := &models.RefreshToken{
+		UserID:    userID,
+		TokenHash: utils.HashToken(token),
+		FamilyID:  familyID,
+		ExpiresAt: time.Now().Add(s.options.RefreshTTL),
+	}
+	if err := s.refreshTokenRepo.CreateRefreshToken(record); err != nil {
+		return "", nil, err
+	}
+
+	return token, record, nil
+}
+
+// RefreshToken menukar refresh token dengan pasangan token baru (rotation).
+// Jika token yang sudah di-revoke dipakai lagi, berarti token bocor: seluruh family dan access token user dicabut
+func (s *UserService) RefreshToken(token string) (*TokenPair, error) {
+	current, err := s.refreshTokenRepo.GetRefreshTokenByHash(utils.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if current.RevokedAt != nil {
+		return nil, s.revokeReusedFamily(current)
+	}
+
+	if !time.Now().Before(current.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.userRepo.GetUserByid(current.UserID)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	user.TokenVersion, err = s.userRepo.GetTokenVersion(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	pair, next, err := s.issueTokens(user, current.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Revoke bersyarat: jika request lain sudah memakai token ini lebih dulu, perlakukan sebagai reuse
+	revoked, err := s.refreshTokenRepo.RevokeRefreshToken(current.ID, &next.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		return nil, s.revokeReusedFamily(current)
+	}
+
+	return pair, nil
+}
+
+func (s *UserService) revokeReusedFamily(token *models.RefreshToken) error {
+	if err := s.refreshTokenRepo.RevokeFamily(token.FamilyID); err != nil {
+		return err
+	}
+	if err := s.userRepo.IncrementTokenVersion(token.UserID); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// Logout mencabut refresh token sesi ini (jika dikirim) dan semua access token user yang masih berlaku
+func (s *UserService) Logout(userID int, refreshToken string) error {
+	if refreshToken != "" {
+		current, err := s.refreshTokenRepo.GetRefreshTokenByHash(utils.HashToken(refreshToken))
+		if err != nil {
+			return err
+		}
+		if current != nil && current.UserID == userID {
+			if err := s.refreshTokenRepo.RevokeFamily(current.FamilyID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.userRepo.IncrementTokenVersion(userID)
+}
+
+// ForgotPassword mengirim link reset password ke email user. Jika email tidak terdaftar tidak ada error,
+// supaya endpoint tidak bisa dipakai untuk mengecek email mana yang terdaftar
+func (s *UserService) ForgotPassword(email string) error {
+	user, err := s.userRepo.GetUserByEmail(email)
+	if err != nil {
+		return nil
+	}
+
+	// Hanya link terakhir yang berlaku
+	if err := s.passwordResetRepo.InvalidatePasswordResetTokens(user.ID); err != nil {
+		return err
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	record := &models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(s.options.PasswordResetTTL),
+	}
+	if err := s.passwordResetRepo.CreatePasswordResetToken(record); err != nil {
+		return err
+	}
+
+	err = s.notifier.PasswordReset(user.Email, notification.LinkData{
+		Name:      user.Name,
+		Link:      s.passwordResetLink(token),
+		ExpiresAt: record.ExpiresAt.Format("2006-01-02 15:04"),
+	})
+	if err != nil {
+		log.Printf("Failed to send password reset email: %v", err)
+	}
+
+	return nil
+}
+
+func (s *UserService) passwordResetLink(token string) string {
+	return linkWithToken(s.options.PasswordResetURL, token)
+}
+
+// linkWithToken menambahkan token sebagai query ?token= ke base URL
+func linkWithToken(base, token string) string {
+	link, err := url.Parse(base)
+	if err != nil {
+		return base + "?token=" + url.QueryEscape(token)
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String()
+}
+
+// ResetPassword mengganti password memakai token dari email. Setelah berhasil, semua sesi user dicabut
+// sehingga user harus login ulang di semua perangkat
+func (s *UserService) ResetPassword(token, newPassword string) error {
+	current, err := s.passwordResetRepo.GetPasswordResetTokenByHash(utils.HashToken(token))
+	if err != nil {
+		return err
+	}
+	if current == nil || !current.IsUsable(time.Now()) {
+		return ErrInvalidPasswordResetToken
+	}
+
+	// Tandai terpakai lebih dulu supaya token yang sama tidak bisa dipakai dua kali secara bersamaan
+	marked, err := s.passwordResetRepo.MarkPasswordResetTokenUsed(current.ID)
+	if err != nil {
+		return err
+	}
+	if !marked {
+		return ErrInvalidPasswordResetToken
+	}
+
+	//Hashing password
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), 10)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdateUser(current.UserID, &models.User{Password: string(hashedPassword)}); err != nil {
+		return err
+	}
+
+	if err := s.passwordResetRepo.InvalidatePasswordResetTokens(current.UserID); err != nil {
+		return err
+	}
+	if err := s.refreshTokenRepo.RevokeAllForUser(current.UserID); err != nil {
+		return err
+	}
+	return s.userRepo.IncrementTokenVersion(current.UserID)
+}
+
+// sendVerification mengirim link verifikasi email. Gagal kirim hanya dicatat di log, user bisa meminta kirim ulang
+func (s *UserService) sendVerification(user *models.User) {
+	expiresAt := time.Now().Add(s.options.VerificationTTL)
+	token, err := utils.GenerateEmailVerificationToken(user.ID, user.Email, s.options.VerificationTTL)
+	if err != nil {
+		log.Printf("Failed to create email verification token: %v", err)
+		return
+	}
+
+	err = s.notifier.EmailVerification(user.Email, notification.LinkData{
+		Name:      user.Name,
+		Link:      linkWithToken(s.options.VerificationURL, token),
+		ExpiresAt: expiresAt.Format("2006-01-02 15:04"),
+	})
+	if err != nil {
+		log.Printf("Failed to send verification email: %v", err)
+	}
+}
+
+// VerifyEmail menandai email user terverifikasi memakai token dari link verifikasi.
+// Link yang dibuka ulang setelah verifikasi berhasil tidak dianggap error
+func (s *UserService) VerifyEmail(token string) error {
+	userID, email, err := utils.ParseEmailVerificationToken(token)
+	if err != nil {
+		return ErrInvalidVerificationToken
+	}
+
+	marked, err := s.userRepo.MarkEmailVerified(userID, email)
 	if err != nil {
-		return "", err
+		return err
+	}
+	if marked {
+		return nil
 	}
 
-	return token, nil
+	// Tidak ada yang diubah: user sudah dihapus, email sudah diganti, atau sudah terverifikasi sebelumnya
+	user, err := s.userRepo.GetUserByid(userID)
+	if err != nil || user.Email != email {
+		return ErrInvalidVerificationToken
+	}
+	return nil
+}
+
+// ResendVerification mengirim ulang link verifikasi. Sama seperti ForgotPassword, email yang tidak terdaftar
+// atau sudah terverifikasi tidak menghasilkan error
+func (s *UserService) ResendVerification(email string) error {
+	user, err := s.userRepo.GetUserByEmail(email)
+	if err != nil || user.IsEmailVerified() {
+		return nil
+	}
+
+	s.sendVerification(user)
+	return nil
+}
+
+// IsEmailVerified dipakai oleh VerifiedEmailMiddleware untuk memblokir reservasi dari akun yang belum diverifikasi
+func (s *UserService) IsEmailVerified(userID int) (bool, error) {
+	return s.userRepo.IsEmailVerified(userID)
+}
+
+// CleanupUnverifiedUsers menghapus akun yang tidak diverifikasi dalam batas waktu UnverifiedRetention
+func (s *UserService) CleanupUnverifiedUsers() (int64, error) {
+	return s.userRepo.DeleteUnverifiedUsers(time.Now().Add(-s.options.UnverifiedRetention))
+}
+
+// CurrentTokenVersion dipakai oleh JWTAuthMiddleware untuk menolak access token yang sudah dicabut
+func (s *UserService) CurrentTokenVersion(userID int) (int, error) {
+	return s.userRepo.GetTokenVersion(userID)
 }
 
 
@@ -92,7 +418,7 @@ func (s *UserService) DeleteUser(id int) error {
 	// Cek User Exist
 	_, err := s.userRepo.GetUserByid(id)
 	if err != nil {
-		return errors.New("user not Found")
+		return err
 	}
 
 	// Delete
@@ -104,18 +430,48 @@ func (s *UserService) DeleteUser(id int) error {
 	return nil
 }
 
-// Get All user 
-func (s *UserService) GetAllUser() ([]models.User, error) {
-	users, err := s.userRepo.GetAllUser()
+// ChangeUserRole mengganti role user. Access token user tersebut dicabut supaya role baru langsung berlaku
+// setelah token di-refresh. Admin tidak boleh mengganti role-nya sendiri agar tidak terkunci dari pengelolaan role
+func (s *UserService) ChangeUserRole(id int, role string, actor Actor) error {
+	if id == actor.UserID {
+		return ErrCannotChangeOwnRole
+	}
+
+	validRole, err := s.userRepo.IsValidUserRole(role)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if !validRole {
+		return ErrInvalidUserRole.Withf("role %q does not exist", role)
+	}
+
+	return s.userRepo.UpdateUserRole(id, role)
+}
+
+// ListUsers mengembalikan satu halaman user beserta jumlah totalnya
+func (s *UserService) ListUsers(filter repository.UserFilter) ([]models.User, int64, error) {
+	if err := checkPage(&filter.Page, repository.UserSortFields); err != nil {
+		return nil, 0, err
+	}
+	if filter.Role != "" {
+		validRole, err := s.userRepo.IsValidUserRole(filter.Role)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !validRole {
+			return nil, 0, ErrInvalidUserRole
+		}
 	}
 
-	return users, nil
+	return s.userRepo.ListUsers(filter)
 }
 
 // Get UserById
-func (s *UserService) GetUserById(id int) (*models.User, error) {
+// GetUserById hanya mengembalikan akun milik actor, kecuali actor boleh melihat semua user
+func (s *UserService) GetUserById(id int, actor Actor) (*models.User, error) {
+	if err := PolicyUserRead.Authorize(actor, id); err != nil {
+		return nil, err
+	}
 
 	user, err := s.userRepo.GetUserByid(id)
 	if err != nil {
@@ -127,29 +483,43 @@ func (s *UserService) GetUserById(id int) (*models.User, error) {
 
 // Update User
 
-func (s *UserService) UpdateUser(id int, user models.User)  error {
+// UpdateUser mengubah akun milik actor. User lain hanya boleh diubah oleh actor dengan permission user:write
+func (s *UserService) UpdateUser(id int, user models.User, actor Actor)  error {
+	if err := PolicyUserWrite.Authorize(actor, id); err != nil {
+		return err
+	}
+
 	// Validasi min satu field yang disi
 	if user.Name == "" && user.Email == "" && user.Password == "" {
-		return errors.New("minimal satu field harus diisi")
+		return ErrNoFieldsToUpdate.Withf("minimal satu field harus diisi")
 	}
 
-	//Hashing password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password),10)
+	//Hashing password, password kosong berarti tidak diubah
+	if user.Password != "" {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password),10)
 		if err != nil {
 			return err
 		}
-	user.Password = string(hashedPassword)
-	
+		user.Password = string(hashedPassword)
+	}
 	
-	err = s.userRepo.UpdateUser(id, &models.User{
+	err := s.userRepo.UpdateUser(id, &models.User{
 		Name:      user.Name,
 		Email:     user.Email,
-		Password:  string(hashedPassword),
+		Password:  user.Password,
 	})
 
 	if err != nil {
 		return err
 	}
 
+	// Email yang diganti harus diverifikasi ulang
+	if user.Email != "" {
+		updated, err := s.userRepo.GetUserByid(id)
+		if err == nil && !updated.IsEmailVerified() {
+			s.sendVerification(updated)
+		}
+	}
+
 	return nil
 }
\ No newline at end of file
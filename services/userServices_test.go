@@ -0,0 +1,416 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+	"wereserve/models"
+	"wereserve/notification"
+	"wereserve/repository/memory"
+)
+
+func testUserServiceOptions() UserServiceOptions {
+	return UserServiceOptions{
+		RefreshTTL:          time.Hour,
+		PasswordResetTTL:    30 * time.Minute,
+		PasswordResetURL:    "http://localhost:3000/reset-password",
+		VerificationTTL:     24 * time.Hour,
+		VerificationURL:     "http://localhost:8080/api/email/verify",
+		UnverifiedRetention: 72 * time.Hour,
+	}
+}
+
+func newMemoryUserService(store *memory.Store) *UserService {
+	return newMemoryUserServiceWithOptions(store, testUserServiceOptions())
+}
+
+func newMemoryUserServiceWithOptions(store *memory.Store, options UserServiceOptions) *UserService {
+	notifier := notification.NewEmailNotifier(notification.NewMemoryMailer(), notification.EmailOptions{})
+	return NewUserService(store.Users(), store.RefreshTokens(), store.PasswordResetTokens(), notifier, options)
+}
+
+func TestRegisterUser(t *testing.T) {
+	store := memory.NewStore()
+	service := newMemoryUserService(store)
+
+	user := models.User{Name: "Budi", Email: "budi@example.com", Password: "rahasia123"}
+	if err := service.RegisterUser(&user); err != nil {
+		t.Fatalf("RegisterUser() error = %v", err)
+	}
+
+	if user.ID == 0 {
+		t.Errorf("expected user to get an ID")
+	}
+	if user.Role != "customer" {
+		t.Errorf("expected default role customer, got %q", user.Role)
+	}
+	if user.Password == "rahasia123" {
+		t.Errorf("expected password to be hashed")
+	}
+}
+
+func TestRegisterUserRejectsInvalidInput(t *testing.T) {
+	tests := []struct {
+		name string
+		user models.User
+	}{
+		{name: "duplicate email", user: models.User{Name: "Budi Lagi", Email: "budi@example.com", Password: "rahasia123"}},
+		{name: "invalid role", user: models.User{Name: "Sari", Email: "sari@example.com", Password: "rahasia123", Role: "owner"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := memory.NewStore()
+			service := newMemoryUserService(store)
+			existing := models.User{Name: "Budi", Email: "budi@example.com", Password: "rahasia123"}
+			if err := service.RegisterUser(&existing); err != nil {
+				t.Fatalf("RegisterUser() error = %v", err)
+			}
+
+			if err := service.RegisterUser(&tt.user); err == nil {
+				t.Errorf("expected RegisterUser() to fail")
+			}
+		})
+	}
+}
+
+func TestLoginUser(t *testing.T) {
+	store := memory.NewStore()
+	service := newMemoryUserService(store)
+	user := models.User{Name: "Budi", Email: "budi@example.com", Password: "rahasia123"}
+	if err := service.RegisterUser(&user); err != nil {
+		t.Fatalf("RegisterUser() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		wantErr  bool
+	}{
+		{name: "valid credentials", email: "budi@example.com", password: "rahasia123"},
+		{name: "wrong password", email: "budi@example.com", password: "salah12345", wantErr: true},
+		{name: "unknown email", email: "tidakada@example.com", password: "rahasia123", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := service.LoginUser(tt.email, tt.password)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected LoginUser() to fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoginUser() error = %v", err)
+			}
+			if tokens.AccessToken == "" || tokens.RefreshToken == "" {
+				t.Errorf("expected an access token and a refresh token")
+			}
+		})
+	}
+}
+
+func loginTestUser(t *testing.T, service *UserService) (*models.User, *TokenPair) {
+	t.Helper()
+	user := models.User{Name: "Budi", Email: "budi@example.com", Password: "rahasia123"}
+	if err := service.RegisterUser(&user); err != nil {
+		t.Fatalf("RegisterUser() error = %v", err)
+	}
+	tokens, err := service.LoginUser("budi@example.com", "rahasia123")
+	if err != nil {
+		t.Fatalf("LoginUser() error = %v", err)
+	}
+	return &user, tokens
+}
+
+func TestRefreshTokenRotates(t *testing.T) {
+	service := newMemoryUserService(memory.NewStore())
+	_, tokens := loginTestUser(t, service)
+
+	next, err := service.RefreshToken(tokens.RefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshToken() error = %v", err)
+	}
+	if next.RefreshToken == tokens.RefreshToken {
+		t.Errorf("expected a new refresh token")
+	}
+
+	if _, err := service.RefreshToken(next.RefreshToken); err != nil {
+		t.Errorf("expected the rotated refresh token to be usable, got %v", err)
+	}
+}
+
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	service := newMemoryUserService(memory.NewStore())
+	user, tokens := loginTestUser(t, service)
+
+	next, err := service.RefreshToken(tokens.RefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshToken() error = %v", err)
+	}
+
+	if _, err := service.RefreshToken(tokens.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("expected ErrRefreshTokenReused, got %v", err)
+	}
+
+	// Token hasil rotasi ikut dicabut karena berada di family yang sama
+	if _, err := service.RefreshToken(next.RefreshToken); err == nil {
+		t.Errorf("expected the rest of the family to be revoked")
+	}
+
+	version, _ := service.CurrentTokenVersion(user.ID)
+	if version == 0 {
+		t.Errorf("expected access tokens to be revoked, token version = %d", version)
+	}
+}
+
+func TestRefreshTokenRejectsUnknownAndExpired(t *testing.T) {
+	store := memory.NewStore()
+	options := testUserServiceOptions()
+	options.RefreshTTL = -time.Minute
+	service := newMemoryUserServiceWithOptions(store, options)
+	_, tokens := loginTestUser(t, service)
+
+	if _, err := service.RefreshToken("bukan-token"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("expected ErrInvalidRefreshToken for unknown token, got %v", err)
+	}
+	if _, err := service.RefreshToken(tokens.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("expected ErrInvalidRefreshToken for expired token, got %v", err)
+	}
+}
+
+func TestLogoutRevokesTokens(t *testing.T) {
+	service := newMemoryUserService(memory.NewStore())
+	user, tokens := loginTestUser(t, service)
+
+	if err := service.Logout(user.ID, tokens.RefreshToken); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+
+	if _, err := service.RefreshToken(tokens.RefreshToken); err == nil {
+		t.Errorf("expected refresh token to be revoked after logout")
+	}
+	version, _ := service.CurrentTokenVersion(user.ID)
+	if version == 0 {
+		t.Errorf("expected access tokens to be revoked, token version = %d", version)
+	}
+}
+
+// captureLinkToken mengarahkan email service ke MemoryMailer dan mengembalikan fungsi yang membaca token
+// dari link terakhir yang dikirim dengan base URL tersebut
+func captureLinkToken(service *UserService, baseURL string) func() string {
+	mailer := notification.NewMemoryMailer()
+	service.notifier = notification.NewEmailNotifier(mailer, notification.EmailOptions{})
+	pattern := regexp.MustCompile(regexp.QuoteMeta(baseURL) + `\?token=([A-Za-z0-9_.\-]+)`)
+
+	return func() string {
+		messages := mailer.Messages()
+		for i := len(messages) - 1; i >= 0; i-- {
+			if match := pattern.FindStringSubmatch(messages[i].HTMLBody); match != nil {
+				return match[1]
+			}
+		}
+		return ""
+	}
+}
+
+func captureVerificationToken(service *UserService) func() string {
+	return captureLinkToken(service, service.options.VerificationURL)
+}
+
+func captureResetToken(service *UserService) func() string {
+	return captureLinkToken(service, service.options.PasswordResetURL)
+}
+
+func TestResetPassword(t *testing.T) {
+	service := newMemoryUserService(memory.NewStore())
+	token := captureResetToken(service)
+	user, tokens := loginTestUser(t, service)
+
+	if err := service.ForgotPassword("budi@example.com"); err != nil {
+		t.Fatalf("ForgotPassword() error = %v", err)
+	}
+	if token() == "" {
+		t.Fatalf("expected a reset link to be sent")
+	}
+
+	if err := service.ResetPassword(token(), "passwordbaru"); err != nil {
+		t.Fatalf("ResetPassword() error = %v", err)
+	}
+
+	if _, err := service.LoginUser("budi@example.com", "rahasia123"); err == nil {
+		t.Errorf("expected the old password to be rejected")
+	}
+	if _, err := service.LoginUser("budi@example.com", "passwordbaru"); err != nil {
+		t.Errorf("expected login with the new password, got %v", err)
+	}
+
+	// Sesi lama dicabut
+	if _, err := service.RefreshToken(tokens.RefreshToken); err == nil {
+		t.Errorf("expected refresh token to be revoked after reset")
+	}
+	if version, _ := service.CurrentTokenVersion(user.ID); version == 0 {
+		t.Errorf("expected access tokens to be revoked after reset")
+	}
+
+	// Token hanya bisa dipakai sekali
+	if err := service.ResetPassword(token(), "passwordlain"); !errors.Is(err, ErrInvalidPasswordResetToken) {
+		t.Errorf("expected ErrInvalidPasswordResetToken on reuse, got %v", err)
+	}
+}
+
+func TestResetPasswordRejectsInvalidTokens(t *testing.T) {
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		// prepare mengembalikan token yang dipakai untuk reset
+		prepare func(service *UserService, token func() string) string
+	}{
+		{
+			name:    "unknown token",
+			ttl:     30 * time.Minute,
+			prepare: func(*UserService, func() string) string { return "bukan-token" },
+		},
+		{
+			name: "expired token",
+			ttl:  -time.Minute,
+			prepare: func(service *UserService, token func() string) string {
+				service.ForgotPassword("budi@example.com")
+				return token()
+			},
+		},
+		{
+			name: "superseded by a newer token",
+			ttl:  30 * time.Minute,
+			prepare: func(service *UserService, token func() string) string {
+				service.ForgotPassword("budi@example.com")
+				old := token()
+				service.ForgotPassword("budi@example.com")
+				return old
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := memory.NewStore()
+			options := testUserServiceOptions()
+			options.PasswordResetTTL = tt.ttl
+			service := newMemoryUserServiceWithOptions(store, options)
+			token := captureResetToken(service)
+			loginTestUser(t, service)
+
+			err := service.ResetPassword(tt.prepare(service, token), "passwordbaru")
+			if !errors.Is(err, ErrInvalidPasswordResetToken) {
+				t.Errorf("expected ErrInvalidPasswordResetToken, got %v", err)
+			}
+		})
+	}
+}
+
+func TestForgotPasswordUnknownEmail(t *testing.T) {
+	service := newMemoryUserService(memory.NewStore())
+	token := captureResetToken(service)
+
+	if err := service.ForgotPassword("tidakada@example.com"); err != nil {
+		t.Errorf("expected no error for unknown email, got %v", err)
+	}
+	if token() != "" {
+		t.Errorf("expected no email to be sent")
+	}
+}
+
+func TestEmailVerification(t *testing.T) {
+	service := newMemoryUserService(memory.NewStore())
+	token := captureVerificationToken(service)
+
+	user := models.User{Name: "Budi", Email: "budi@example.com", Password: "rahasia123"}
+	if err := service.RegisterUser(&user); err != nil {
+		t.Fatalf("RegisterUser() error = %v", err)
+	}
+	if token() == "" {
+		t.Fatalf("expected a verification link to be sent")
+	}
+	if verified, _ := service.IsEmailVerified(user.ID); verified {
+		t.Fatalf("expected a new account to start unverified")
+	}
+
+	if err := service.VerifyEmail("bukan-token"); !errors.Is(err, ErrInvalidVerificationToken) {
+		t.Errorf("expected ErrInvalidVerificationToken, got %v", err)
+	}
+
+	if err := service.VerifyEmail(token()); err != nil {
+		t.Fatalf("VerifyEmail() error = %v", err)
+	}
+	if verified, _ := service.IsEmailVerified(user.ID); !verified {
+		t.Errorf("expected the account to be verified")
+	}
+
+	// Membuka link yang sama lagi tetap berhasil
+	if err := service.VerifyEmail(token()); err != nil {
+		t.Errorf("expected a repeated verification to succeed, got %v", err)
+	}
+}
+
+func TestVerificationLinkInvalidAfterEmailChange(t *testing.T) {
+	service := newMemoryUserService(memory.NewStore())
+	token := captureVerificationToken(service)
+
+	user := models.User{Name: "Budi", Email: "budi@example.com", Password: "rahasia123"}
+	if err := service.RegisterUser(&user); err != nil {
+		t.Fatalf("RegisterUser() error = %v", err)
+	}
+	oldToken := token()
+
+	if err := service.UpdateUser(user.ID, models.User{Email: "budi.baru@example.com"}, Actor{UserID: user.ID}); err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+	if token() == oldToken {
+		t.Fatalf("expected a new verification link for the new email")
+	}
+
+	if err := service.VerifyEmail(oldToken); !errors.Is(err, ErrInvalidVerificationToken) {
+		t.Errorf("expected the old link to be rejected, got %v", err)
+	}
+	if err := service.VerifyEmail(token()); err != nil {
+		t.Errorf("VerifyEmail() error = %v", err)
+	}
+}
+
+func TestCleanupUnverifiedUsers(t *testing.T) {
+	store := memory.NewStore()
+	options := testUserServiceOptions()
+	options.UnverifiedRetention = -time.Minute
+	service := newMemoryUserServiceWithOptions(store, options)
+	token := captureVerificationToken(service)
+
+	verified := models.User{Name: "Budi", Email: "budi@example.com", Password: "rahasia123"}
+	if err := service.RegisterUser(&verified); err != nil {
+		t.Fatalf("RegisterUser() error = %v", err)
+	}
+	if err := service.VerifyEmail(token()); err != nil {
+		t.Fatalf("VerifyEmail() error = %v", err)
+	}
+
+	unverified := models.User{Name: "Sari", Email: "sari@example.com", Password: "rahasia123"}
+	if err := service.RegisterUser(&unverified); err != nil {
+		t.Fatalf("RegisterUser() error = %v", err)
+	}
+
+	deleted, err := service.CleanupUnverifiedUsers()
+	if err != nil {
+		t.Fatalf("CleanupUnverifiedUsers() error = %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("expected 1 deleted user, got %d", deleted)
+	}
+	if _, err := service.GetUserById(unverified.ID, Actor{UserID: unverified.ID}); err == nil {
+		t.Errorf("expected the unverified user to be deleted")
+	}
+	if _, err := service.GetUserById(verified.ID, Actor{UserID: verified.ID}); err != nil {
+		t.Errorf("expected the verified user to be kept, got %v", err)
+	}
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+	"wereserve/apperror"
+	"wereserve/models"
+	"wereserve/notification"
+	"wereserve/realtime"
+	"wereserve/repository"
+)
+
+var (
+	ErrWaitlistForbidden    = apperror.Forbidden("waitlist_forbidden", "you are not allowed to modify this waitlist entry")
+	ErrWaitlistNoOffer      = apperror.Conflict("waitlist_no_offer", "waitlist entry has no open offer")
+	ErrWaitlistOfferExpired = apperror.Conflict("waitlist_offer_expired", "waitlist offer has expired")
+	ErrWaitlistNotActive    = apperror.Conflict("waitlist_not_active", "waitlist entry can no longer be cancelled")
+	ErrInvalidWaitlistRange = apperror.Validation("invalid_waitlist_range", "desired_end must not be before desired_start")
+)
+
+type WaitlistService struct {
+	uow             repository.UnitOfWork
+	waitlistRepo    repository.WaitlistRepository
+	reservationRepo repository.ReservationRepository
+	tableRepo       repository.TableRepository
+	combinationRepo repository.TableCombinationRepository
+	scheduleService *ScheduleService
+	offerTTL        time.Duration
+	notifier        notification.Notifier
+	hub             *realtime.Hub
+}
+
+// freedSlot adalah meja atau kombinasi meja yang kosong mulai waktu Start dan bisa ditawarkan ke waitlist
+type freedSlot struct {
+	Start         time.Time
+	TableID       *int
+	CombinationID *int
+	Tables        []models.Table
+}
+
+func NewWaitlistService(uow repository.UnitOfWork, waitlistRepo repository.WaitlistRepository, reservationRepo repository.ReservationRepository, tableRepo repository.TableRepository, combinationRepo repository.TableCombinationRepository, scheduleService *ScheduleService, offerTTL time.Duration, notifier notification.Notifier, hub *realtime.Hub) *WaitlistService {
+	return &WaitlistService{
+		uow:             uow,
+		waitlistRepo:    waitlistRepo,
+		reservationRepo: reservationRepo,
+		tableRepo:       tableRepo,
+		combinationRepo: combinationRepo,
+		scheduleService: scheduleService,
+		offerTTL:        offerTTL,
+		notifier:        notifier,
+		hub:             hub,
+	}
+}
+
+// JoinWaitlist mendaftarkan customer ke waitlist untuk rentang waktu mulai yang diinginkan
+func (s *WaitlistService) JoinWaitlist(entry *models.WaitlistEntry) error {
+	if entry.PartySize < 1 {
+		return ErrInvalidPartySize
+	}
+	if entry.DesiredEnd.Before(entry.DesiredStart) {
+		return ErrInvalidWaitlistRange
+	}
+	if !entry.DesiredEnd.After(time.Now()) {
+		return ErrReservationInPast
+	}
+	if entry.DurationMinutes == 0 {
+		entry.DurationMinutes = models.DefaultReservationDuration
+	}
+	entry.Status = models.WaitlistStatusWaiting
+
+	return s.waitlistRepo.CreateEntry(entry)
+}
+
+// GetWaitlist mengembalikan semua antrian untuk user dengan permission waitlist:manage, dan hanya antrian milik sendiri untuk yang lain
+func (s *WaitlistService) GetWaitlist(actor Actor) ([]models.WaitlistEntry, error) {
+	if PolicyWaitlist.IsOverridden(actor) {
+		return s.waitlistRepo.GetAllEntries()
+	}
+	return s.waitlistRepo.GetEntriesByUser(actor.UserID)
+}
+
+// OfferFreedSlot menawarkan meja dari reservasi yang dibatalkan ke customer waitlist yang paling cocok
+func (s *WaitlistService) OfferFreedSlot(reservation models.Reservation) error {
+	slot := freedSlot{
+		Start:         reservation.ReservationDateTime,
+		CombinationID: reservation.CombinationID,
+		Tables:        reservation.Tables,
+	}
+	if reservation.CombinationID == nil {
+		tableID := reservation.TableID
+		slot.TableID = &tableID
+	}
+	if len(slot.Tables) == 0 {
+		table, err := s.tableRepo.GetTableByID(reservation.TableID)
+		if err != nil {
+			return err
+		}
+		slot.Tables = []models.Table{*table}
+	}
+
+	return s.offerSlot(slot)
+}
+
+// offerSlot mencari customer dengan rombongan terbesar yang muat di slot, lalu mengirim penawaran lewat email.
+// Customer dilewati jika durasi yang diminta bertabrakan dengan reservasi lain atau melewati jam tutup
+func (s *WaitlistService) offerSlot(slot freedSlot) error {
+	capacity := 0
+	tableIDs := make([]int, 0, len(slot.Tables))
+	for _, table := range slot.Tables {
+		capacity += table.Capacity
+		tableIDs = append(tableIDs, table.ID)
+	}
+
+	candidates, err := s.waitlistRepo.GetCandidates(slot.Start, capacity)
+	if err != nil {
+		return err
+	}
+
+	for _, candidate := range candidates {
+		end := slot.Start.Add(time.Duration(candidate.DurationMinutes) * time.Minute)
+		if s.scheduleService.ValidateSlot(slot.Start, end) != nil {
+			continue
+		}
+
+		overlap, err := s.reservationRepo.IsReservationOverlap(tableIDs, slot.Start, end, 0)
+		if err != nil {
+			return err
+		}
+		if overlap {
+			continue
+		}
+
+		expiresAt := time.Now().Add(s.offerTTL)
+		offered, err := s.waitlistRepo.MarkOffered(candidate.ID, slot.TableID, slot.CombinationID, slot.Start, expiresAt)
+		if err != nil {
+			return err
+		}
+		if !offered {
+			continue
+		}
+
+		err = s.notifier.WaitlistOffer(candidate.User.Email, notification.WaitlistOfferData{
+			Name:      candidate.User.Name,
+			TableName: tableLabel(slot.Tables),
+			DateTime:  slot.Start.Format("2006-01-02 15:04"),
+			PartySize: candidate.PartySize,
+			ExpiresAt: expiresAt.Format("2006-01-02 15:04"),
+		})
+		if err != nil {
+			log.Printf("Failed to send waitlist offer to entry %d: %v", candidate.ID, err)
+		}
+		return nil
+	}
+
+	return nil
+}
+
+// slotFromOffer membangun ulang slot yang ditawarkan ke sebuah entry, dipakai saat menerima atau meneruskan penawaran
+func (s *WaitlistService) slotFromOffer(entry *models.WaitlistEntry) (freedSlot, error) {
+	slot := freedSlot{
+		TableID:       entry.OfferedTableID,
+		CombinationID: entry.OfferedCombinationID,
+	}
+	if entry.OfferedDateTime != nil {
+		slot.Start = *entry.OfferedDateTime
+	}
+
+	switch {
+	case entry.OfferedCombinationID != nil:
+		combination, err := s.combinationRepo.GetCombinationByID(*entry.OfferedCombinationID)
+		if err != nil {
+			return slot, err
+		}
+		slot.Tables = combination.Tables
+	case entry.OfferedTableID != nil:
+		table, err := s.tableRepo.GetTableByID(*entry.OfferedTableID)
+		if err != nil {
+			return slot, err
+		}
+		slot.Tables = []models.Table{*table}
+	default:
+		return slot, fmt.Errorf("waitlist entry with ID %d has no offered table", entry.ID)
+	}
+
+	if len(slot.Tables) == 0 {
+		return slot, fmt.Errorf("waitlist entry with ID %d has no offered table", entry.ID)
+	}
+	return slot, nil
+}
+
+// passOffer meneruskan slot dari entry yang kedaluwarsa atau keluar dari waitlist ke customer berikutnya
+func (s *WaitlistService) passOffer(entry *models.WaitlistEntry) {
+	slot, err := s.slotFromOffer(entry)
+	if err != nil {
+		log.Printf("Failed to rebuild waitlist offer %d: %v", entry.ID, err)
+		return
+	}
+	if err := s.offerSlot(slot); err != nil {
+		log.Printf("Failed to pass waitlist offer %d to the next guest: %v", entry.ID, err)
+	}
+}
+
+// AcceptOffer membuat reservasi dari slot yang ditawarkan. Meja tidak ditahan selama penawaran berlaku,
+// jadi reservasi tetap bisa gagal jika slot sudah dipesan orang lain lebih dulu.
+// Reservasi dibuat lewat jalur yang sama dengan ReservationService, dan penawaran ditandai diterima di transaksi yang sama
+func (s *WaitlistService) AcceptOffer(id int, actor Actor) (*models.Reservation, error) {
+	entry, err := s.waitlistRepo.GetEntryByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := PolicyWaitlist.Authorize(actor, entry.UserID); err != nil {
+		return nil, err
+	}
+	if entry.Status != models.WaitlistStatusOffered {
+		return nil, fmt.Errorf("%w: entry is %s", ErrWaitlistNoOffer, entry.Status)
+	}
+
+	if entry.IsOfferExpired(time.Now()) {
+		expired, err := s.waitlistRepo.UpdateStatus(entry.ID, models.WaitlistStatusOffered, models.WaitlistStatusExpired)
+		if err != nil {
+			return nil, err
+		}
+		if expired {
+			s.passOffer(entry)
+		}
+		return nil, ErrWaitlistOfferExpired
+	}
+
+	slot, err := s.slotFromOffer(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	reservation := models.Reservation{
+		UserID:              entry.UserID,
+		TableID:             slot.Tables[0].ID,
+		CombinationID:       slot.CombinationID,
+		ReservationDateTime: slot.Start,
+		DurationMinutes:     entry.DurationMinutes,
+		NumberOfPeople:      entry.PartySize,
+		Tables:              slot.Tables,
+	}
+
+	// Slot dicek ulang karena jadwal bisa berubah selama penawaran berlaku
+	if err := s.scheduleService.ValidateSlot(reservation.ReservationDateTime, reservation.EndTime()); err != nil {
+		return nil, err
+	}
+
+	var tableEvents []tableStatusEvent
+	err = s.uow.Do(func(repos repository.Repositories) error {
+		var err error
+		tableEvents, err = insertReservation(repos, &reservation, func(repos repository.Repositories, reservation *models.Reservation, _ *notification.ReservationData) error {
+			// Penawaran yang diterima dua kali bersamaan hanya menghasilkan satu reservasi
+			accepted, err := repos.Waitlist.MarkAccepted(entry.ID, reservation.ID)
+			if err != nil {
+				return err
+			}
+			if !accepted {
+				return ErrWaitlistNoOffer.Withf("waitlist offer %d has already been answered", entry.ID)
+			}
+			return nil
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.reservationRepo.GetReservationDetail(reservation.ID)
+	if err != nil {
+		return nil, err
+	}
+	broadcast(s.hub, models.WebhookEventReservationCreated, created.UserID, reservationEvent(*created, ""))
+	broadcastTableStatus(s.hub, tableEvents)
+
+	return created, nil
+}
+
+// CancelEntry mengeluarkan customer dari waitlist. Penawaran yang masih terbuka diteruskan ke customer berikutnya
+func (s *WaitlistService) CancelEntry(id int, actor Actor) error {
+	entry, err := s.waitlistRepo.GetEntryByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := PolicyWaitlist.Authorize(actor, entry.UserID); err != nil {
+		return err
+	}
+	if entry.Status != models.WaitlistStatusWaiting && entry.Status != models.WaitlistStatusOffered {
+		return ErrWaitlistNotActive.Withf("waitlist entry with ID %d is %s and can no longer be cancelled", id, entry.Status)
+	}
+
+	cancelled, err := s.waitlistRepo.UpdateStatus(entry.ID, entry.Status, models.WaitlistStatusCancelled)
+	if err != nil {
+		return err
+	}
+	if cancelled && entry.Status == models.WaitlistStatusOffered {
+		s.passOffer(entry)
+	}
+
+	return nil
+}
+
+// ExpireOffers menandai penawaran yang lewat batas waktu sebagai expired dan meneruskan slotnya ke customer berikutnya
+func (s *WaitlistService) ExpireOffers() error {
+	entries, err := s.waitlistRepo.GetExpiredOffers(time.Now())
+	if err != nil {
+		return err
+	}
+
+	for i := range entries {
+		expired, err := s.waitlistRepo.UpdateStatus(entries[i].ID, models.WaitlistStatusOffered, models.WaitlistStatusExpired)
+		if err != nil {
+			return err
+		}
+		if expired {
+			s.passOffer(&entries[i])
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"wereserve/models"
+	"wereserve/notification"
+	"wereserve/repository"
+	"wereserve/repository/memory"
+)
+
+// joinWaitlist mendaftarkan user baru ke waitlist untuk waktu mulai di sekitar start
+func joinWaitlist(t *testing.T, store *memory.Store, service *WaitlistService, name string, partySize int, start time.Time) models.WaitlistEntry {
+	t.Helper()
+
+	user := models.User{Name: name, Email: name + "@example.com", Password: "secret", Role: models.RoleCustomer}
+	if err := store.Users().CreateUser(&user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	entry := models.WaitlistEntry{UserID: user.ID, PartySize: partySize, DesiredStart: start.Add(-time.Hour), DesiredEnd: start.Add(time.Hour)}
+	if err := service.JoinWaitlist(&entry); err != nil {
+		t.Fatalf("JoinWaitlist() error = %v", err)
+	}
+	return entry
+}
+
+func waitlistStatus(t *testing.T, store *memory.Store, id int) string {
+	t.Helper()
+
+	entry, err := store.Waitlist().GetEntryByID(id)
+	if err != nil {
+		t.Fatalf("GetEntryByID() error = %v", err)
+	}
+	return entry.Status
+}
+
+func TestOfferFreedSlotPicksLargestPartyThatFits(t *testing.T) {
+	reservations, store, _, tables := newMemoryReservationService(t)
+	service := reservations.waitlistService
+	start := tomorrowAt(12, 0)
+
+	small := joinWaitlist(t, store, service, "kecil", 2, start)
+	best := joinWaitlist(t, store, service, "pas", 4, start)
+	tooLarge := joinWaitlist(t, store, service, "besar", 6, start)
+	sameSize := joinWaitlist(t, store, service, "menyusul", 4, start)
+
+	freed := models.Reservation{TableID: tables[0].ID, ReservationDateTime: start}
+	if err := service.OfferFreedSlot(freed); err != nil {
+		t.Fatalf("OfferFreedSlot() error = %v", err)
+	}
+
+	// Meja 4 kursi ditawarkan ke rombongan terbesar yang muat, yang lebih dulu mendaftar menang jika sama besar
+	want := map[int]string{
+		small.ID:    models.WaitlistStatusWaiting,
+		best.ID:     models.WaitlistStatusOffered,
+		tooLarge.ID: models.WaitlistStatusWaiting,
+		sameSize.ID: models.WaitlistStatusWaiting,
+	}
+	for id, status := range want {
+		if got := waitlistStatus(t, store, id); got != status {
+			t.Errorf("entry %d status = %q, want %q", id, got, status)
+		}
+	}
+}
+
+func TestCancelledReservationIsOfferedToWaitlist(t *testing.T) {
+	reservations, store, user, tables := newMemoryReservationService(t)
+	start := tomorrowAt(12, 0)
+
+	reservation := models.Reservation{UserID: user.ID, TableID: tables[0].ID, ReservationDateTime: start, NumberOfPeople: 2}
+	if err := reservations.CreateReservation(&reservation, Actor{UserID: user.ID}); err != nil {
+		t.Fatalf("CreateReservation() error = %v", err)
+	}
+	entry := joinWaitlist(t, store, reservations.waitlistService, "sari", 3, start)
+
+	if err := reservations.CancelReservation(reservation.ID, Actor{UserID: user.ID, Role: models.RoleCustomer}, "berubah rencana"); err != nil {
+		t.Fatalf("CancelReservation() error = %v", err)
+	}
+
+	offered, _ := store.Waitlist().GetEntryByID(entry.ID)
+	if offered.Status != models.WaitlistStatusOffered || offered.OfferedTableID == nil || *offered.OfferedTableID != tables[0].ID {
+		t.Fatalf("entry = status %q, table %v, want an offer for table %d", offered.Status, offered.OfferedTableID, tables[0].ID)
+	}
+}
+
+func TestExpiredAndCancelledOffersPassToNextGuest(t *testing.T) {
+	tests := []struct {
+		name    string
+		pass    func(service *WaitlistService, entry models.WaitlistEntry) error
+		wantOld string
+	}{
+		{name: "offer expires", wantOld: models.WaitlistStatusExpired, pass: func(service *WaitlistService, _ models.WaitlistEntry) error {
+			return service.ExpireOffers()
+		}},
+		{name: "guest leaves the waitlist", wantOld: models.WaitlistStatusCancelled, pass: func(service *WaitlistService, entry models.WaitlistEntry) error {
+			return service.CancelEntry(entry.ID, Actor{UserID: entry.UserID, Role: models.RoleCustomer})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reservations, store, _, tables := newMemoryReservationService(t)
+			service := reservations.waitlistService
+			start := tomorrowAt(12, 0)
+
+			first := joinWaitlist(t, store, service, "pertama", 4, start)
+			next := joinWaitlist(t, store, service, "berikutnya", 2, start)
+
+			// Penawaran pertama langsung kedaluwarsa supaya ExpireOffers bisa meneruskannya
+			service.offerTTL = -time.Minute
+			if err := service.OfferFreedSlot(models.Reservation{TableID: tables[0].ID, ReservationDateTime: start}); err != nil {
+				t.Fatalf("OfferFreedSlot() error = %v", err)
+			}
+			service.offerTTL = time.Minute
+
+			if err := tt.pass(service, first); err != nil {
+				t.Fatalf("pass offer error = %v", err)
+			}
+
+			if got := waitlistStatus(t, store, first.ID); got != tt.wantOld {
+				t.Errorf("first entry status = %q, want %q", got, tt.wantOld)
+			}
+			offered, _ := store.Waitlist().GetEntryByID(next.ID)
+			if offered.Status != models.WaitlistStatusOffered || offered.OfferedDateTime == nil || !offered.OfferedDateTime.Equal(start) {
+				t.Errorf("next entry = status %q, offered at %v, want the same slot at %s", offered.Status, offered.OfferedDateTime, start)
+			}
+		})
+	}
+}
+
+func TestAcceptOfferCreatesReservation(t *testing.T) {
+	reservations, store, _, tables := newMemoryReservationService(t)
+	service := reservations.waitlistService
+	start := tomorrowAt(12, 0)
+
+	entry := joinWaitlist(t, store, service, "sari", 3, start)
+	if err := service.OfferFreedSlot(models.Reservation{TableID: tables[0].ID, ReservationDateTime: start}); err != nil {
+		t.Fatalf("OfferFreedSlot() error = %v", err)
+	}
+	actor := Actor{UserID: entry.UserID, Role: models.RoleCustomer}
+
+	reservation, err := service.AcceptOffer(entry.ID, actor)
+	if err != nil {
+		t.Fatalf("AcceptOffer() error = %v", err)
+	}
+	if reservation.UserID != entry.UserID || !reservation.ReservationDateTime.Equal(start) || reservation.NumberOfPeople != 3 {
+		t.Errorf("reservation = user %d at %s for %d, want user %d at %s for 3", reservation.UserID, reservation.ReservationDateTime, reservation.NumberOfPeople, entry.UserID, start)
+	}
+
+	accepted, _ := store.Waitlist().GetEntryByID(entry.ID)
+	if accepted.Status != models.WaitlistStatusAccepted || accepted.ReservationID == nil || *accepted.ReservationID != reservation.ID {
+		t.Errorf("entry = status %q, reservation %v, want accepted with reservation %d", accepted.Status, accepted.ReservationID, reservation.ID)
+	}
+
+	// Email konfirmasi dan webhook ditulis ke outbox bersama reservasinya
+	messages, _ := store.Outbox().GetMessages(models.OutboxStatusPending)
+	if len(messages) != 1 || messages[0].EventType != notification.EventReservationCreated || messages[0].Recipient != "sari@example.com" {
+		t.Errorf("outbox = %+v, want one confirmation email to sari@example.com", messages)
+	}
+
+	if _, err := service.AcceptOffer(entry.ID, actor); !errors.Is(err, ErrWaitlistNoOffer) {
+		t.Errorf("second AcceptOffer() error = %v, want %v", err, ErrWaitlistNoOffer)
+	}
+}
+
+func TestAcceptOfferForTakenSlotKeepsOffer(t *testing.T) {
+	reservations, store, user, tables := newMemoryReservationService(t)
+	service := reservations.waitlistService
+	start := tomorrowAt(12, 0)
+
+	entry := joinWaitlist(t, store, service, "sari", 2, start)
+	if err := service.OfferFreedSlot(models.Reservation{TableID: tables[0].ID, ReservationDateTime: start}); err != nil {
+		t.Fatalf("OfferFreedSlot() error = %v", err)
+	}
+
+	// Meja tidak ditahan selama penawaran berlaku, jadi orang lain bisa memesannya lebih dulu
+	taken := models.Reservation{UserID: user.ID, TableID: tables[0].ID, ReservationDateTime: start, NumberOfPeople: 2}
+	if err := reservations.CreateReservation(&taken, Actor{UserID: user.ID}); err != nil {
+		t.Fatalf("CreateReservation() error = %v", err)
+	}
+
+	if _, err := service.AcceptOffer(entry.ID, Actor{UserID: entry.UserID, Role: models.RoleCustomer}); !errors.Is(err, repository.ErrReservationOverlap) {
+		t.Fatalf("AcceptOffer() error = %v, want %v", err, repository.ErrReservationOverlap)
+	}
+	if got := waitlistStatus(t, store, entry.ID); got != models.WaitlistStatusOffered {
+		t.Errorf("entry status = %q, want %q after the transaction is rolled back", got, models.WaitlistStatusOffered)
+	}
+}
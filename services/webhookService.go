@@ -0,0 +1,370 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"wereserve/apperror"
+	"wereserve/models"
+	"wereserve/repository"
+	"wereserve/utils"
+)
+
+// Header yang dikirim bersama setiap webhook. Penerima memverifikasi signature dari timestamp dan body
+const (
+	WebhookHeaderEvent     = "X-WeReserve-Event"
+	WebhookHeaderDelivery  = "X-WeReserve-Delivery"
+	WebhookHeaderTimestamp = "X-WeReserve-Timestamp"
+	WebhookHeaderSignature = "X-WeReserve-Signature"
+)
+
+var (
+	ErrInvalidWebhookURL   = apperror.Validation("invalid_webhook_url", "url must be an absolute http or https URL")
+	ErrInvalidWebhookEvent = apperror.Validation("invalid_webhook_event", "event_types must contain at least one supported event")
+	ErrWebhookTargetDenied = apperror.Validation("webhook_target_denied", "url must not point to a private, loopback or link-local address")
+)
+
+// maxWebhookErrorBody membatasi isi response penerima yang disimpan di delivery log
+const maxWebhookErrorBody = 512
+
+// WebhookOptions mengatur cara worker mengirim webhook dan mencoba ulang pengiriman yang gagal
+type WebhookOptions struct {
+	// Jumlah percobaan sebelum pengiriman berstatus dead
+	MaxAttempts int
+	// Jeda sebelum percobaan kedua, berlipat dua setiap kali gagal sampai MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Jumlah pengiriman yang diambil setiap kali worker berjalan
+	BatchSize int
+	// Lama pengiriman yang sedang berjalan tidak diambil oleh worker lain
+	Lease time.Duration
+	// Batas waktu menunggu response dari penerima
+	Timeout time.Duration
+	// Mengizinkan penerima di alamat private, loopback atau link-local, hanya untuk development dan test
+	AllowPrivateTargets bool
+}
+
+// WebhookSubscriptionInput adalah data subscription dari admin. Field kosong tidak diubah saat update
+type WebhookSubscriptionInput struct {
+	URL        string
+	Secret     string
+	EventTypes []string
+	Active     *bool
+}
+
+// WebhookEvent adalah body JSON yang dikirim ke penerima
+type WebhookEvent struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+type WebhookService struct {
+	webhookRepo repository.WebhookRepository
+	client      *http.Client
+	options     WebhookOptions
+}
+
+// NewWebhookService membuat service untuk mengelola subscription dan worker yang mengirim webhook
+func NewWebhookService(webhookRepo repository.WebhookRepository, options WebhookOptions) *WebhookService {
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+		client:      newWebhookClient(options),
+		options:     options,
+	}
+}
+
+// newWebhookClient membuat HTTP client yang hanya tersambung ke alamat publik.
+// Alamat diperiksa saat koneksi dibuka, setelah DNS di-resolve, supaya hostname yang berganti IP
+// (DNS rebinding) tidak bisa mengarahkan webhook ke jaringan internal. Redirect tidak diikuti karena
+// tujuan redirect tidak divalidasi seperti URL subscription
+func newWebhookClient(options WebhookOptions) *http.Client {
+	dialer := &net.Dialer{Timeout: options.Timeout}
+	if !options.AllowPrivateTargets {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			addr, err := netip.ParseAddr(host)
+			if err != nil || isDeniedWebhookAddr(addr) {
+				return fmt.Errorf("%w: %s", ErrWebhookTargetDenied, host)
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Proxy dimatikan supaya alamat yang diperiksa dialer adalah alamat penerima sebenarnya
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   options.Timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// isDeniedWebhookAddr menolak alamat yang hanya bisa dicapai dari dalam jaringan server
+func isDeniedWebhookAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified()
+}
+
+// SignWebhookPayload menghitung signature "sha256=<hex>" dari HMAC-SHA256 atas "<timestamp>.<body>".
+// Timestamp ikut ditandatangani supaya penerima bisa menolak request lama yang dikirim ulang
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// publishWebhook mengantrikan event untuk semua subscription aktif yang berlangganan eventType.
+// Jika webhookRepo terikat ke transaksi, event ikut di-commit atau di-rollback bersama perubahan data
+func publishWebhook(webhookRepo repository.WebhookRepository, eventType string, data interface{}) error {
+	eventID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return fmt.Errorf("failed to generate webhook event id: %w", err)
+	}
+
+	payload, err := json.Marshal(WebhookEvent{
+		ID:        eventID,
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook event %s: %w", eventType, err)
+	}
+
+	if _, err := webhookRepo.EnqueueDeliveries(eventID, eventType, string(payload)); err != nil {
+		return fmt.Errorf("failed to queue webhook event %s: %w", eventType, err)
+	}
+	return nil
+}
+
+// publishReservationWebhook mengirim event reservasi dengan data terbaru di dalam transaksi yang sama
+func publishReservationWebhook(repos repository.Repositories, id int, eventType, previousStatus string) error {
+	reservation, err := repos.Reservations.GetReservationDetail(id)
+	if err != nil {
+		return fmt.Errorf("failed to load reservation %d for webhook: %w", id, err)
+	}
+	return publishWebhook(repos.Webhooks, eventType, reservationEvent(*reservation, previousStatus))
+}
+
+// validateSubscription memastikan URL bisa dipanggil dan semua event dikenal, lalu menggabungkan event dengan koma.
+// Hostname tidak di-resolve di sini, alamat hasil DNS diperiksa lagi oleh client saat webhook dikirim
+func (s *WebhookService) validateSubscription(rawURL string, eventTypes []string) (string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", ErrInvalidWebhookURL
+	}
+	if !s.options.AllowPrivateTargets {
+		host := strings.ToLower(strings.TrimSuffix(parsed.Hostname(), "."))
+		if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+			return "", ErrWebhookTargetDenied
+		}
+		if addr, err := netip.ParseAddr(host); err == nil && isDeniedWebhookAddr(addr) {
+			return "", ErrWebhookTargetDenied
+		}
+	}
+
+	if len(eventTypes) == 0 {
+		return "", ErrInvalidWebhookEvent
+	}
+	seen := make(map[string]bool)
+	var events []string
+	for _, eventType := range eventTypes {
+		eventType = strings.TrimSpace(eventType)
+		if !isWebhookEvent(eventType) {
+			return "", fmt.Errorf("%w: unknown event %q", ErrInvalidWebhookEvent, eventType)
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			events = append(events, eventType)
+		}
+	}
+	return strings.Join(events, ","), nil
+}
+
+func isWebhookEvent(eventType string) bool {
+	for _, known := range models.WebhookEventTypes {
+		if known == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateSubscription mendaftarkan endpoint baru. Secret dibuat otomatis jika admin tidak mengisinya
+func (s *WebhookService) CreateSubscription(input WebhookSubscriptionInput) (*models.WebhookSubscription, error) {
+	events, err := s.validateSubscription(input.URL, input.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := input.Secret
+	if secret == "" {
+		secret, err = utils.GenerateRandomToken(32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+	}
+
+	subscription := models.WebhookSubscription{
+		URL:        input.URL,
+		Secret:     secret,
+		EventTypes: events,
+		Active:     input.Active == nil || *input.Active,
+	}
+	if err := s.webhookRepo.CreateSubscription(&subscription); err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (s *WebhookService) GetSubscriptions() ([]models.WebhookSubscription, error) {
+	return s.webhookRepo.GetSubscriptions()
+}
+
+func (s *WebhookService) GetSubscription(id int) (*models.WebhookSubscription, error) {
+	return s.webhookRepo.GetSubscriptionByID(id)
+}
+
+// UpdateSubscription mengubah field yang diisi. Mengisi Secret berarti merotasi secret penerima
+func (s *WebhookService) UpdateSubscription(id int, input WebhookSubscriptionInput) (*models.WebhookSubscription, error) {
+	current, err := s.webhookRepo.GetSubscriptionByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.URL == "" {
+		input.URL = current.URL
+	}
+	eventTypes := input.EventTypes
+	if len(eventTypes) == 0 {
+		eventTypes = current.Events()
+	}
+	events, err := s.validateSubscription(input.URL, eventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	active := current.Active
+	if input.Active != nil {
+		active = *input.Active
+	}
+
+	if err := s.webhookRepo.UpdateSubscription(id, &models.WebhookSubscription{
+		URL:        input.URL,
+		Secret:     input.Secret,
+		EventTypes: events,
+		Active:     active,
+	}); err != nil {
+		return nil, err
+	}
+	return s.webhookRepo.GetSubscriptionByID(id)
+}
+
+// DeleteSubscription menghapus subscription beserta delivery log-nya
+func (s *WebhookService) DeleteSubscription(id int) error {
+	return s.webhookRepo.DeleteSubscription(id)
+}
+
+// GetDeliveries mengembalikan delivery log sebuah subscription, terbaru lebih dulu
+func (s *WebhookService) GetDeliveries(subscriptionID int) ([]models.WebhookDelivery, error) {
+	if _, err := s.webhookRepo.GetSubscriptionByID(subscriptionID); err != nil {
+		return nil, err
+	}
+	return s.webhookRepo.GetDeliveries(subscriptionID)
+}
+
+// ProcessDue mengirim webhook yang sudah waktunya dikirim dan mengembalikan jumlah yang berhasil
+func (s *WebhookService) ProcessDue() (int, error) {
+	deliveries, err := s.webhookRepo.ClaimDueDeliveries(time.Now(), s.options.BatchSize, s.options.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, delivery := range deliveries {
+		responseStatus, err := s.send(delivery)
+		if err != nil {
+			if markErr := s.markFailed(delivery, responseStatus, err); markErr != nil {
+				return delivered, markErr
+			}
+			continue
+		}
+
+		if err := s.webhookRepo.MarkDelivered(delivery.ID, responseStatus); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+// send mengirim satu webhook. Hanya response 2xx yang dianggap berhasil
+func (s *WebhookService) send(delivery models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequest(http.MethodPost, delivery.Subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "WeReserve-Webhook/1.0")
+	req.Header.Set(WebhookHeaderEvent, delivery.EventType)
+	req.Header.Set(WebhookHeaderDelivery, strconv.Itoa(delivery.ID))
+	req.Header.Set(WebhookHeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookHeaderSignature, SignWebhookPayload(delivery.Subscription.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookErrorBody))
+		return resp.StatusCode, fmt.Errorf("receiver responded with status %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+func (s *WebhookService) markFailed(delivery models.WebhookDelivery, responseStatus int, cause error) error {
+	attempts := delivery.Attempts + 1
+	dead := attempts >= s.options.MaxAttempts
+	if dead {
+		log.Printf("Webhook delivery %d (%s) to %s marked dead after %d attempts: %v", delivery.ID, delivery.EventType, delivery.Subscription.URL, attempts, cause)
+	}
+
+	var status *int
+	if responseStatus != 0 {
+		status = &responseStatus
+	}
+	nextAttemptAt := time.Now().Add(retryBackoff(s.options.BaseBackoff, s.options.MaxBackoff, attempts))
+	return s.webhookRepo.MarkDeliveryFailed(delivery.ID, attempts, nextAttemptAt, status, cause.Error(), dead)
+}
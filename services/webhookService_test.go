@@ -0,0 +1,354 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"wereserve/models"
+	"wereserve/realtime"
+	"wereserve/repository"
+	"wereserve/repository/memory"
+)
+
+const testWebhookSecret = "test-webhook-secret-123"
+
+func testWebhookOptions() WebhookOptions {
+	return WebhookOptions{
+		MaxAttempts: 3,
+		BaseBackoff: time.Minute,
+		MaxBackoff:  10 * time.Minute,
+		BatchSize:   10,
+		Lease:       time.Minute,
+		Timeout:     5 * time.Second,
+		// Penerima test berjalan di httptest yang memakai 127.0.0.1
+		AllowPrivateTargets: true,
+	}
+}
+
+// webhookReceiver adalah endpoint penerima untuk test. Setiap request diverifikasi signature-nya
+type webhookReceiver struct {
+	t      *testing.T
+	mu     sync.Mutex
+	status int
+	events []WebhookEvent
+}
+
+func newWebhookReceiver(t *testing.T, status int) (*webhookReceiver, *httptest.Server) {
+	t.Helper()
+
+	receiver := &webhookReceiver{t: t, status: status}
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+	return receiver, server
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	timestamp, err := strconv.ParseInt(req.Header.Get(WebhookHeaderTimestamp), 10, 64)
+	if err != nil {
+		r.t.Errorf("invalid %s header: %v", WebhookHeaderTimestamp, err)
+	}
+	if got, want := req.Header.Get(WebhookHeaderSignature), SignWebhookPayload(testWebhookSecret, timestamp, body); got != want {
+		r.t.Errorf("signature = %q, want %q", got, want)
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		r.t.Errorf("invalid webhook body: %v", err)
+	}
+	if req.Header.Get(WebhookHeaderEvent) != event.Type {
+		r.t.Errorf("%s header = %q, want %q", WebhookHeaderEvent, req.Header.Get(WebhookHeaderEvent), event.Type)
+	}
+
+	r.mu.Lock()
+	r.events = append(r.events, event)
+	status := r.status
+	r.mu.Unlock()
+	w.WriteHeader(status)
+}
+
+func (r *webhookReceiver) eventTypes() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var types []string
+	for _, event := range r.events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func subscribeWebhook(t *testing.T, service *WebhookService, url string, eventTypes ...string) *models.WebhookSubscription {
+	t.Helper()
+
+	subscription, err := service.CreateSubscription(WebhookSubscriptionInput{URL: url, Secret: testWebhookSecret, EventTypes: eventTypes})
+	if err != nil {
+		t.Fatalf("CreateSubscription() error = %v", err)
+	}
+	return subscription
+}
+
+func TestWebhookDeliversSignedReservationEvents(t *testing.T) {
+	reservations, store, user, tables := newMemoryReservationService(t)
+	webhooks := NewWebhookService(store.Webhooks(), testWebhookOptions())
+	receiver, server := newWebhookReceiver(t, http.StatusNoContent)
+	subscription := subscribeWebhook(t, webhooks, server.URL, models.WebhookEventReservationCreated, models.WebhookEventReservationCancelled)
+
+	reservation := models.Reservation{UserID: user.ID, TableID: tables[0].ID, ReservationDateTime: tomorrowAt(12, 0), NumberOfPeople: 2}
+	if err := reservations.CreateReservation(&reservation, Actor{UserID: user.ID}); err != nil {
+		t.Fatalf("CreateReservation() error = %v", err)
+	}
+	actor := Actor{UserID: user.ID, Role: "customer"}
+	if err := reservations.UpdateReservation(reservation.ID, models.Reservation{NumberOfPeople: 3}, actor); err != nil {
+		t.Fatalf("UpdateReservation() error = %v", err)
+	}
+	if err := reservations.CancelReservation(reservation.ID, actor, "sakit"); err != nil {
+		t.Fatalf("CancelReservation() error = %v", err)
+	}
+
+	// reservation.updated tidak dilanggan, jadi hanya dua event yang dikirim
+	if delivered, err := webhooks.ProcessDue(); err != nil || delivered != 2 {
+		t.Fatalf("ProcessDue() = %d, %v, want 2, nil", delivered, err)
+	}
+
+	got := receiver.eventTypes()
+	if len(got) != 2 || got[0] != models.WebhookEventReservationCreated || got[1] != models.WebhookEventReservationCancelled {
+		t.Fatalf("received events = %v", got)
+	}
+
+	data, _ := json.Marshal(receiver.events[1].Data)
+	var cancelled reservationEventData
+	if err := json.Unmarshal(data, &cancelled); err != nil {
+		t.Fatalf("invalid event data: %v", err)
+	}
+	if cancelled.ID != reservation.ID || cancelled.Status != models.ReservationStatusCancelled || cancelled.PreviousStatus != models.ReservationStatusPending {
+		t.Errorf("cancelled event data = %+v", cancelled)
+	}
+
+	deliveries, err := webhooks.GetDeliveries(subscription.ID)
+	if err != nil {
+		t.Fatalf("GetDeliveries() error = %v", err)
+	}
+	for _, delivery := range deliveries {
+		if delivery.Status != models.WebhookDeliveryDelivered || delivery.ResponseStatus == nil || *delivery.ResponseStatus != http.StatusNoContent {
+			t.Errorf("delivery %d = %s (%v), want delivered with 204", delivery.ID, delivery.Status, delivery.ResponseStatus)
+		}
+	}
+}
+
+// makeWebhookDue memajukan jadwal percobaan supaya pengiriman bisa diambil lagi tanpa menunggu backoff
+func makeWebhookDue(t *testing.T, store *memory.Store, delivery models.WebhookDelivery) {
+	t.Helper()
+
+	if err := store.Webhooks().MarkDeliveryFailed(delivery.ID, delivery.Attempts, time.Now().Add(-time.Second), delivery.ResponseStatus, "", false); err != nil {
+		t.Fatalf("MarkDeliveryFailed() error = %v", err)
+	}
+}
+
+func TestWebhookRetriesUntilDead(t *testing.T) {
+	store := memory.NewStore()
+	webhooks := NewWebhookService(store.Webhooks(), testWebhookOptions())
+	receiver, server := newWebhookReceiver(t, http.StatusInternalServerError)
+	subscription := subscribeWebhook(t, webhooks, server.URL, models.WebhookEventTableStatusChanged)
+
+	table := models.Table{TableName: "A1", Capacity: 4, Status: "available"}
+	tableService := NewTableService(store.Tables(), store.Reservations(), store.Webhooks(), realtime.NewHub(0))
+	if err := tableService.CreateTable(&table); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+	if err := tableService.UpdateTable(table.ID, models.Table{Status: "occupied"}); err != nil {
+		t.Fatalf("UpdateTable() error = %v", err)
+	}
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		if delivered, err := webhooks.ProcessDue(); err != nil || delivered != 0 {
+			t.Fatalf("attempt %d: ProcessDue() = %d, %v, want 0, nil", attempt, delivered, err)
+		}
+
+		deliveries, _ := webhooks.GetDeliveries(subscription.ID)
+		if len(deliveries) != 1 {
+			t.Fatalf("expected 1 delivery, got %d", len(deliveries))
+		}
+		delivery := deliveries[0]
+		if delivery.Attempts != attempt || delivery.ResponseStatus == nil || *delivery.ResponseStatus != http.StatusInternalServerError {
+			t.Fatalf("attempt %d: delivery = %+v", attempt, delivery)
+		}
+
+		if attempt < 3 {
+			if delivery.Status != models.WebhookDeliveryPending || !delivery.NextAttemptAt.After(time.Now()) {
+				t.Fatalf("attempt %d: expected a pending delivery scheduled in the future, got %s at %s", attempt, delivery.Status, delivery.NextAttemptAt)
+			}
+			makeWebhookDue(t, store, delivery)
+		} else if delivery.Status != models.WebhookDeliveryDead {
+			t.Fatalf("expected the delivery to be dead after %d attempts, got %s", attempt, delivery.Status)
+		}
+	}
+
+	// Pengiriman yang sudah dead tidak diambil lagi oleh worker
+	if _, err := webhooks.ProcessDue(); err != nil {
+		t.Fatalf("ProcessDue() error = %v", err)
+	}
+	if got := receiver.eventTypes(); len(got) != 3 {
+		t.Errorf("expected 3 requests to the receiver, got %d", len(got))
+	}
+}
+
+func TestFailedReservationQueuesNoWebhook(t *testing.T) {
+	reservations, store, user, tables := newMemoryReservationService(t)
+	webhooks := NewWebhookService(store.Webhooks(), testWebhookOptions())
+	subscription := subscribeWebhook(t, webhooks, "http://pos.example.com/hooks", models.WebhookEventReservationCreated)
+
+	first := models.Reservation{UserID: user.ID, TableID: tables[0].ID, ReservationDateTime: tomorrowAt(12, 0), NumberOfPeople: 2}
+	if err := reservations.CreateReservation(&first, Actor{UserID: user.ID}); err != nil {
+		t.Fatalf("CreateReservation() error = %v", err)
+	}
+
+	second := models.Reservation{UserID: user.ID, TableID: tables[0].ID, ReservationDateTime: tomorrowAt(12, 0), NumberOfPeople: 2}
+	if err := reservations.CreateReservation(&second, Actor{UserID: user.ID}); !errors.Is(err, repository.ErrReservationOverlap) {
+		t.Fatalf("CreateReservation() error = %v, want %v", err, repository.ErrReservationOverlap)
+	}
+
+	// Webhook ikut di-rollback bersama reservasi yang gagal
+	deliveries, _ := webhooks.GetDeliveries(subscription.ID)
+	if len(deliveries) != 1 {
+		t.Fatalf("expected 1 webhook delivery, got %d", len(deliveries))
+	}
+}
+
+func TestCreateSubscriptionValidation(t *testing.T) {
+	options := testWebhookOptions()
+	options.AllowPrivateTargets = false
+	webhooks := NewWebhookService(memory.NewStore().Webhooks(), options)
+
+	tests := []struct {
+		name  string
+		input WebhookSubscriptionInput
+		want  error
+	}{
+		{"relative url", WebhookSubscriptionInput{URL: "/hooks", EventTypes: []string{models.WebhookEventReservationCreated}}, ErrInvalidWebhookURL},
+		{"unsupported scheme", WebhookSubscriptionInput{URL: "ftp://pos.example.com", EventTypes: []string{models.WebhookEventReservationCreated}}, ErrInvalidWebhookURL},
+		{"no events", WebhookSubscriptionInput{URL: "https://pos.example.com"}, ErrInvalidWebhookEvent},
+		{"unknown event", WebhookSubscriptionInput{URL: "https://pos.example.com", EventTypes: []string{"user.created"}}, ErrInvalidWebhookEvent},
+		{"loopback address", WebhookSubscriptionInput{URL: "http://127.0.0.1:8080/hooks", EventTypes: []string{models.WebhookEventReservationCreated}}, ErrWebhookTargetDenied},
+		{"loopback ipv6 address", WebhookSubscriptionInput{URL: "http://[::1]/hooks", EventTypes: []string{models.WebhookEventReservationCreated}}, ErrWebhookTargetDenied},
+		{"localhost", WebhookSubscriptionInput{URL: "http://localhost:8080/hooks", EventTypes: []string{models.WebhookEventReservationCreated}}, ErrWebhookTargetDenied},
+		{"private address", WebhookSubscriptionInput{URL: "https://10.0.0.5/hooks", EventTypes: []string{models.WebhookEventReservationCreated}}, ErrWebhookTargetDenied},
+		{"link-local metadata address", WebhookSubscriptionInput{URL: "http://169.254.169.254/latest", EventTypes: []string{models.WebhookEventReservationCreated}}, ErrWebhookTargetDenied},
+		{"ipv4-mapped private address", WebhookSubscriptionInput{URL: "http://[::ffff:192.168.1.1]/hooks", EventTypes: []string{models.WebhookEventReservationCreated}}, ErrWebhookTargetDenied},
+		{"unspecified address", WebhookSubscriptionInput{URL: "http://0.0.0.0/hooks", EventTypes: []string{models.WebhookEventReservationCreated}}, ErrWebhookTargetDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := webhooks.CreateSubscription(tt.input); !errors.Is(err, tt.want) {
+				t.Errorf("CreateSubscription() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// Secret dibuat otomatis jika tidak diisi
+	subscription, err := webhooks.CreateSubscription(WebhookSubscriptionInput{URL: "https://pos.example.com", EventTypes: []string{models.WebhookEventReservationCreated}})
+	if err != nil {
+		t.Fatalf("CreateSubscription() error = %v", err)
+	}
+	if subscription.Secret == "" || !subscription.Active {
+		t.Errorf("expected an active subscription with a generated secret, got %+v", subscription)
+	}
+}
+
+func TestWebhookDeliveryRefusesPrivateTargets(t *testing.T) {
+	store := memory.NewStore()
+	options := testWebhookOptions()
+	options.AllowPrivateTargets = false
+	webhooks := NewWebhookService(store.Webhooks(), options)
+	receiver, server := newWebhookReceiver(t, http.StatusNoContent)
+
+	// Subscription disimpan langsung seperti hostname publik yang kemudian di-resolve ke alamat internal
+	subscription := models.WebhookSubscription{URL: server.URL, Secret: testWebhookSecret, EventTypes: models.WebhookEventTableStatusChanged, Active: true}
+	if err := store.Webhooks().CreateSubscription(&subscription); err != nil {
+		t.Fatalf("CreateSubscription() error = %v", err)
+	}
+	if err := publishWebhook(store.Webhooks(), models.WebhookEventTableStatusChanged, tableStatusEvent{TableID: 1, Status: "occupied"}); err != nil {
+		t.Fatalf("publishWebhook() error = %v", err)
+	}
+
+	if delivered, err := webhooks.ProcessDue(); err != nil || delivered != 0 {
+		t.Fatalf("ProcessDue() = %d, %v, want 0, nil", delivered, err)
+	}
+	if got := receiver.eventTypes(); len(got) != 0 {
+		t.Fatalf("expected no request to reach the receiver, got %v", got)
+	}
+	deliveries, _ := webhooks.GetDeliveries(subscription.ID)
+	if len(deliveries) != 1 || deliveries[0].Status != models.WebhookDeliveryPending || deliveries[0].LastError == nil ||
+		!strings.Contains(*deliveries[0].LastError, ErrWebhookTargetDenied.Error()) {
+		t.Fatalf("expected a failed delivery refused by the dialer, got %+v", deliveries)
+	}
+}
+
+func TestWebhookDeliveryDoesNotFollowRedirects(t *testing.T) {
+	store := memory.NewStore()
+	webhooks := NewWebhookService(store.Webhooks(), testWebhookOptions())
+	target, targetServer := newWebhookReceiver(t, http.StatusNoContent)
+	redirect := httptest.NewServer(http.RedirectHandler(targetServer.URL, http.StatusFound))
+	t.Cleanup(redirect.Close)
+	subscription := subscribeWebhook(t, webhooks, redirect.URL, models.WebhookEventTableStatusChanged)
+
+	if err := publishWebhook(store.Webhooks(), models.WebhookEventTableStatusChanged, tableStatusEvent{TableID: 1, Status: "occupied"}); err != nil {
+		t.Fatalf("publishWebhook() error = %v", err)
+	}
+	if delivered, err := webhooks.ProcessDue(); err != nil || delivered != 0 {
+		t.Fatalf("ProcessDue() = %d, %v, want 0, nil", delivered, err)
+	}
+
+	// Redirect dicatat sebagai pengiriman gagal dan tujuan redirect tidak pernah dipanggil
+	if got := target.eventTypes(); len(got) != 0 {
+		t.Fatalf("expected the redirect target to receive nothing, got %v", got)
+	}
+	deliveries, _ := webhooks.GetDeliveries(subscription.ID)
+	if len(deliveries) != 1 || deliveries[0].ResponseStatus == nil || *deliveries[0].ResponseStatus != http.StatusFound {
+		t.Fatalf("expected a failed delivery with status 302, got %+v", deliveries)
+	}
+}
+
+func TestReservationStatusPublishesDerivedTableStatus(t *testing.T) {
+	reservations, store, user, tables := newMemoryReservationService(t)
+	webhooks := NewWebhookService(store.Webhooks(), testWebhookOptions())
+	receiver, server := newWebhookReceiver(t, http.StatusNoContent)
+	subscribeWebhook(t, webhooks, server.URL, models.WebhookEventTableStatusChanged)
+
+	reservation := models.Reservation{UserID: user.ID, TableID: tables[0].ID, ReservationDateTime: tomorrowAt(12, 0), NumberOfPeople: 2}
+	if err := reservations.CreateReservation(&reservation, Actor{UserID: user.ID}); err != nil {
+		t.Fatalf("CreateReservation() error = %v", err)
+	}
+
+	// Reservasi besok tidak mengubah status meja hari ini, meja baru berubah saat tamu duduk dan saat reservasi selesai
+	for _, status := range []string{models.ReservationStatusConfirmed, models.ReservationStatusSeated, models.ReservationStatusCompleted} {
+		if err := reservations.ChangeReservationStatus(reservation.ID, status); err != nil {
+			t.Fatalf("ChangeReservationStatus(%s) error = %v", status, err)
+		}
+	}
+
+	if delivered, err := webhooks.ProcessDue(); err != nil || delivered != 2 {
+		t.Fatalf("ProcessDue() = %d, %v, want 2, nil", delivered, err)
+	}
+
+	want := []tableStatusEvent{
+		{TableID: tables[0].ID, TableName: tables[0].TableName, Status: "occupied", PreviousStatus: "available"},
+		{TableID: tables[0].ID, TableName: tables[0].TableName, Status: "available", PreviousStatus: "occupied"},
+	}
+	for i, event := range receiver.events {
+		data, _ := json.Marshal(event.Data)
+		var got tableStatusEvent
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("invalid event data: %v", err)
+		}
+		if event.Type != models.WebhookEventTableStatusChanged || got != want[i] {
+			t.Errorf("event %d = %s %+v, want %+v", i, event.Type, got, want[i])
+		}
+	}
+}
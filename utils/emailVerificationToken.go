@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"strconv"
+	"time"
+	"wereserve/apperror"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const emailVerificationPurpose = "email_verification"
+
+var ErrInvalidVerificationToken = apperror.Validation("invalid_verification_token", "link verifikasi tidak valid atau sudah kedaluwarsa")
+
+// GenerateEmailVerificationToken membuat token bertanda tangan untuk link verifikasi email.
+// Token tidak punya claim role sehingga tidak bisa dipakai sebagai access token
+func GenerateEmailVerificationToken(userID int, email string, ttl time.Duration) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"purpose": emailVerificationPurpose,
+		"userID":  strconv.Itoa(userID),
+		"email":   email,
+		"exp":     time.Now().Add(ttl).Unix(),
+	})
+
+	return token.SignedString(secretKey())
+}
+
+// ParseEmailVerificationToken memvalidasi tanda tangan dan masa berlaku token, lalu mengembalikan user id dan email di dalamnya
+func ParseEmailVerificationToken(tokenString string) (int, string, error) {
+	claims, err := parseSignedToken(tokenString, emailVerificationPurpose)
+	if err != nil {
+		return 0, "", ErrInvalidVerificationToken
+	}
+
+	userID, userExist := claims["userID"].(string)
+	email, emailExist := claims["email"].(string)
+	if !userExist || !emailExist {
+		return 0, "", ErrInvalidVerificationToken
+	}
+
+	id, err := strconv.Atoi(userID)
+	if err != nil {
+		return 0, "", ErrInvalidVerificationToken
+	}
+
+	return id, email, nil
+}
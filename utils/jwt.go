@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/spf13/viper"
+)
+
+var errInvalidSignedToken = errors.New("invalid signed token")
+
+// Secret dibaca saat dipakai, bukan saat package di-init, supaya nilai dari file .env sudah dimuat oleh viper
+func secretKey() []byte {
+	return []byte(viper.GetString("JWT_SECRET_KEY"))
+}
+
+// GenerateJWT membuat access token untuk user yang login
+func GenerateJWT(email, role, id string, tokenVersion int) (string, error) {
+	jti, err := GenerateRandomToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"jti":    jti,
+		"email":  email,
+		"role":   role,
+		"userID": id,
+		"tv":     tokenVersion,
+		"exp":    time.Now().Add(time.Hour * 3).Unix(), // expired 3 jam
+	})
+
+	return token.SignedString(secretKey())
+}
+
+// ParseSignedToken memvalidasi access token yang dibuat oleh GenerateJWT, lalu mengembalikan claims-nya
+func ParseSignedToken(tokenString string) (jwt.MapClaims, error) {
+	return parseSignedToken(tokenString, "")
+}
+
+// parseSignedToken memvalidasi tanda tangan dan masa berlaku token yang dibuat oleh package ini, lalu mengembalikan claims-nya.
+// Token lain yang ditandatangani dengan secret yang sama, seperti link pengingat dan verifikasi email, selalu punya claim purpose.
+// Claim purpose harus sama dengan purpose, dan purpose kosong berarti token tidak boleh punya claim purpose sama sekali
+func parseSignedToken(tokenString, purpose string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return secretKey(), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, errInvalidSignedToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errInvalidSignedToken
+	}
+
+	tokenPurpose, hasPurpose := claims["purpose"]
+	if (purpose == "" && hasPurpose) || (purpose != "" && tokenPurpose != purpose) {
+		return nil, errInvalidSignedToken
+	}
+	return claims, nil
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"strconv"
+	"time"
+	"wereserve/apperror"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const reservationActionPurpose = "reservation_action"
+
+// Aksi yang bisa dilakukan tamu lewat link di email pengingat
+const (
+	ReservationActionConfirm = "confirm"
+	ReservationActionCancel  = "cancel"
+)
+
+var ErrInvalidReservationActionToken = apperror.Validation("invalid_reservation_action_token", "link reservasi tidak valid atau sudah kedaluwarsa")
+
+// GenerateReservationActionToken membuat token bertanda tangan untuk link konfirmasi atau pembatalan reservasi.
+// Token hanya berlaku untuk satu reservasi dan satu aksi, dan tidak punya claim role sehingga tidak bisa dipakai sebagai access token
+func GenerateReservationActionToken(reservationID int, action string, expiresAt time.Time) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"purpose":       reservationActionPurpose,
+		"reservationID": strconv.Itoa(reservationID),
+		"action":        action,
+		"exp":           expiresAt.Unix(),
+	})
+
+	return token.SignedString(secretKey())
+}
+
+// ParseReservationActionToken memvalidasi token lalu mengembalikan id reservasi dan aksi di dalamnya
+func ParseReservationActionToken(tokenString string) (int, string, error) {
+	claims, err := parseSignedToken(tokenString, reservationActionPurpose)
+	if err != nil {
+		return 0, "", ErrInvalidReservationActionToken
+	}
+
+	reservationID, idExist := claims["reservationID"].(string)
+	action, actionExist := claims["action"].(string)
+	if !idExist || !actionExist || (action != ReservationActionConfirm && action != ReservationActionCancel) {
+		return 0, "", ErrInvalidReservationActionToken
+	}
+
+	id, err := strconv.Atoi(reservationID)
+	if err != nil {
+		return 0, "", ErrInvalidReservationActionToken
+	}
+
+	return id, action, nil
+}